@@ -56,5 +56,12 @@ func MakeOpenAssetFunc(ctx *ge.Context, gamedataFolder string) func(path string)
 	}
 }
 
+// ReadEmbeddedFile returns the bundled asset file contents.
+// Unlike the loader, it doesn't require a game context,
+// so it can be used by the headless code (like the replay validation).
+func ReadEmbeddedFile(path string) ([]byte, error) {
+	return gameAssets.ReadFile("_data/" + path)
+}
+
 //go:embed all:_data
 var gameAssets embed.FS
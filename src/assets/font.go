@@ -13,6 +13,9 @@ var (
 	BitmapFont1 = bitmapfont.Face
 	BitmapFont2 = scaleFont(BitmapFont1, 2)
 	BitmapFont3 = scaleFont(BitmapFont1, 3)
+
+	// BitmapFont1Large is a 1.5x version of BitmapFont1 for the large UI scale.
+	BitmapFont1Large = scaleFontRatio(BitmapFont1, 3, 2)
 )
 
 func euclidianDiv(x, y int) int {
@@ -107,3 +110,122 @@ func (s *scaledFont) Metrics() font.Metrics {
 		Descent: m.Descent * fixed.Int26_6(s.scale),
 	}
 }
+
+// scaleFontRatio is like scaleFont, but the scale is num/den.
+//
+// The glyph masks are re-based to their own origin before scaling,
+// so the rounding errors can't make a neighbouring glyph of the font
+// atlas bleed into the rendered one.
+func scaleFontRatio(f font.Face, num, den int) font.Face {
+	return &ratioScaledFont{font: f, num: num, den: den}
+}
+
+type ratioScaledFont struct {
+	font font.Face
+	num  int
+	den  int
+}
+
+func (s *ratioScaledFont) scale(v int) int {
+	return euclidianDiv(v*s.num, s.den)
+}
+
+func (s *ratioScaledFont) scaleFixed(v fixed.Int26_6) fixed.Int26_6 {
+	return v * fixed.Int26_6(s.num) / fixed.Int26_6(s.den)
+}
+
+func (s *ratioScaledFont) Close() error {
+	return s.font.Close()
+}
+
+func (s *ratioScaledFont) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	dr, mask, maskp, advance, ok = s.font.Glyph(dot, r)
+	if !ok {
+		return
+	}
+	d := image.Pt(dot.X.Floor(), dot.Y.Floor())
+	w := dr.Dx()
+	h := dr.Dy()
+	scaledW := (w*s.num + s.den - 1) / s.den
+	scaledH := (h*s.num + s.den - 1) / s.den
+	min := dr.Min.Sub(d)
+	min = image.Pt(s.scale(min.X), s.scale(min.Y)).Add(d)
+	dr = image.Rectangle{Min: min, Max: min.Add(image.Pt(scaledW, scaledH))}
+	scaledMask := &ratioScaledImage{
+		image:  mask,
+		origin: maskp,
+		w:      w,
+		h:      h,
+		bounds: image.Rect(0, 0, scaledW, scaledH),
+		num:    s.num,
+		den:    s.den,
+	}
+	return dr, scaledMask, image.Point{}, s.scaleFixed(advance), ok
+}
+
+func (s *ratioScaledFont) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	bounds, advance, ok = s.font.GlyphBounds(r)
+	if !ok {
+		return
+	}
+	bounds.Min.X = s.scaleFixed(bounds.Min.X)
+	bounds.Min.Y = s.scaleFixed(bounds.Min.Y)
+	bounds.Max.X = s.scaleFixed(bounds.Max.X)
+	bounds.Max.Y = s.scaleFixed(bounds.Max.Y)
+	return bounds, s.scaleFixed(advance), ok
+}
+
+func (s *ratioScaledFont) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	advance, ok = s.font.GlyphAdvance(r)
+	if !ok {
+		return
+	}
+	return s.scaleFixed(advance), ok
+}
+
+func (s *ratioScaledFont) Kern(r0, r1 rune) fixed.Int26_6 {
+	return s.scaleFixed(s.font.Kern(r0, r1))
+}
+
+func (s *ratioScaledFont) Metrics() font.Metrics {
+	m := s.font.Metrics()
+	return font.Metrics{
+		Height:  s.scaleFixed(m.Height),
+		Ascent:  s.scaleFixed(m.Ascent),
+		Descent: s.scaleFixed(m.Descent),
+	}
+}
+
+// ratioScaledImage is a scaled w*h part of the image that starts at origin.
+type ratioScaledImage struct {
+	image  image.Image
+	origin image.Point
+	w      int
+	h      int
+	bounds image.Rectangle
+	num    int
+	den    int
+}
+
+func (s *ratioScaledImage) ColorModel() color.Model {
+	return s.image.ColorModel()
+}
+
+func (s *ratioScaledImage) Bounds() image.Rectangle {
+	return s.bounds
+}
+
+func (s *ratioScaledImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}).In(s.bounds) {
+		return color.Alpha{}
+	}
+	x = x * s.den / s.num
+	y = y * s.den / s.num
+	if x >= s.w {
+		x = s.w - 1
+	}
+	if y >= s.h {
+		y = s.h - 1
+	}
+	return s.image.At(s.origin.X+x, s.origin.Y+y)
+}
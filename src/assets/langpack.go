@@ -0,0 +1,283 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/langs"
+)
+
+// LangManifest describes a language pack.
+//
+// The embedded packs are described by the raw/*.lang.json files.
+// The user-provided packs are loaded from the "langs" sub-folder
+// of the game data folder; the manifest format is the same.
+type LangManifest struct {
+	// ID is a language code, like "en".
+	ID string `json:"id"`
+
+	// Name is a language name that is displayed in the language selector.
+	Name string `json:"name"`
+
+	// Fallback is an ID of the pack that is loaded before this one.
+	// It makes it possible to have partial translations:
+	// all missing keys are taken from the fallback pack.
+	Fallback string `json:"fallback"`
+
+	// Files is a list of the dictionary files (relative to the manifest).
+	Files []string `json:"files"`
+
+	// Font is a font family this pack requires.
+	// Only "bitmapfont" is supported right now.
+	Font string `json:"font"`
+
+	// Glyphs lists the language-specific glyphs
+	// that should be cached on the mobile devices.
+	Glyphs string `json:"glyphs"`
+}
+
+// minAutoSelectCompleteness is a fraction of the fallback pack keys
+// that should be translated for the pack to be selected automatically.
+const minAutoSelectCompleteness = 0.9
+
+type LangPack struct {
+	Manifest LangManifest
+
+	// External is true for the packs loaded from the game data folder.
+	External bool
+
+	// dir is a path prefix for the manifest files that is
+	// understood by the open asset func (see MakeOpenAssetFunc).
+	dir string
+}
+
+// FilePath returns the pack file path that can be opened by the
+// function returned from the MakeOpenAssetFunc.
+func (p *LangPack) FilePath(filename string) string {
+	return p.dir + filename
+}
+
+// DiscoverLanguagePacks returns all available language packs, embedded ones go first.
+// An empty gamedataFolder means that only embedded packs should be loaded.
+//
+// The returned errors are related to the broken user-provided packs;
+// these packs are not included into the results.
+func DiscoverLanguagePacks(gamedataFolder string) ([]*LangPack, []error) {
+	var packs []*LangPack
+	var errs []error
+
+	embeddedManifests, err := fs.Glob(gameAssets, "_data/raw/*.lang.json")
+	if err != nil {
+		panic(err)
+	}
+	for _, filename := range embeddedManifests {
+		data, err := gameAssets.ReadFile(filename)
+		if err != nil {
+			panic(err)
+		}
+		p, err := parseLangPack(data, "raw/")
+		if err != nil {
+			panic(fmt.Sprintf("%s: %v", filename, err))
+		}
+		packs = append(packs, p)
+	}
+
+	if gamedataFolder != "" {
+		filenames, err := listfiles(filepath.Join(gamedataFolder, "langs"))
+		if err != nil {
+			// The langs folder is optional.
+			filenames = nil
+		}
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			if !strings.HasSuffix(filename, ".lang.json") {
+				continue
+			}
+			p, err := loadExternalLangPack(gamedataFolder, filename)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", filename, err))
+				continue
+			}
+			if FindLanguagePack(packs, p.Manifest.ID) != nil {
+				errs = append(errs, fmt.Errorf("%s: %q language is already defined", filename, p.Manifest.ID))
+				continue
+			}
+			packs = append(packs, p)
+		}
+	}
+
+	return packs, errs
+}
+
+func FindLanguagePack(packs []*LangPack, id string) *LangPack {
+	for _, p := range packs {
+		if p.Manifest.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// IsCompleteLanguagePack reports whether the embedded pack can be
+// selected automatically based on the system language.
+//
+// The partial translations rely on the fallback pack too much,
+// so they're only used if the player selected them explicitly.
+func IsCompleteLanguagePack(packs []*LangPack, id string) bool {
+	p := FindLanguagePack(packs, id)
+	if p == nil || p.External {
+		return false
+	}
+	if p.Manifest.Fallback == "" {
+		return true
+	}
+	fallback := FindLanguagePack(packs, p.Manifest.Fallback)
+	if fallback == nil || fallback.External {
+		return false
+	}
+	numKeys := countEmbeddedPackKeys(p)
+	numFallbackKeys := countEmbeddedPackKeys(fallback)
+	return float64(numKeys) >= minAutoSelectCompleteness*float64(numFallbackKeys)
+}
+
+func countEmbeddedPackKeys(p *LangPack) int {
+	numKeys := 0
+	for _, filename := range p.Manifest.Files {
+		data, err := ReadEmbeddedFile(p.FilePath(filename))
+		if err != nil {
+			panic(err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "##") {
+				numKeys++
+			}
+		}
+	}
+	return numKeys
+}
+
+// LoadLanguagePack creates a dictionary for the specified language.
+// The fallback packs are loaded first, if there are any.
+func LoadLanguagePack(ctx *ge.Context, packs []*LangPack, id string) (*langs.Dictionary, error) {
+	var chain []*LangPack
+	for p := FindLanguagePack(packs, id); p != nil; p = FindLanguagePack(packs, p.Manifest.Fallback) {
+		for _, other := range chain {
+			if other == p {
+				return nil, fmt.Errorf("%q language has a fallback cycle", id)
+			}
+		}
+		chain = append(chain, p)
+		if p.Manifest.Fallback == "" {
+			break
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("unsupported lang: %q", id)
+	}
+	if fallback := chain[len(chain)-1].Manifest.Fallback; fallback != "" {
+		return nil, fmt.Errorf("%q language has an unknown fallback %q", id, fallback)
+	}
+
+	dict := langs.NewDictionary(id, 4)
+	for i := len(chain) - 1; i >= 0; i-- {
+		// Only the pack's own keys are checked for duplicates;
+		// overriding the fallback keys is the whole point.
+		dict.OverwriteAllowed = i != len(chain)-1
+		if err := loadPackFiles(ctx, dict, chain[i]); err != nil {
+			return nil, err
+		}
+	}
+	dict.OverwriteAllowed = false
+	return dict, nil
+}
+
+// LoadLanguagePackFiles creates a dictionary that contains only
+// the keys defined by the pack itself, without its fallback.
+func LoadLanguagePackFiles(ctx *ge.Context, p *LangPack) (*langs.Dictionary, error) {
+	dict := langs.NewDictionary(p.Manifest.ID, 4)
+	if err := loadPackFiles(ctx, dict, p); err != nil {
+		return nil, err
+	}
+	return dict, nil
+}
+
+func loadPackFiles(ctx *ge.Context, dict *langs.Dictionary, p *LangPack) error {
+	for _, filename := range p.Manifest.Files {
+		data, err := readAsset(ctx, p.FilePath(filename))
+		if err != nil {
+			return err
+		}
+		if err := dict.Load("", data); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+func loadExternalLangPack(gamedataFolder, filename string) (*LangPack, error) {
+	f, err := openfile(filepath.Join(gamedataFolder, "langs", filename))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	p, err := parseLangPack(data, "$langs/")
+	if err != nil {
+		return nil, err
+	}
+	p.External = true
+	// The open asset func treats a missing file as a critical error,
+	// so it's better to reject such packs during the discovery.
+	for _, name := range p.Manifest.Files {
+		f, err := openfile(filepath.Join(gamedataFolder, "langs", name))
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+	}
+	return p, nil
+}
+
+func parseLangPack(data []byte, dir string) (*LangPack, error) {
+	var m LangManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.ID == "" {
+		return nil, fmt.Errorf("empty language id")
+	}
+	if len(m.Files) == 0 {
+		return nil, fmt.Errorf("%q language has no files", m.ID)
+	}
+	for _, filename := range m.Files {
+		if path.Clean(filename) != filename || strings.HasPrefix(filename, "..") || strings.HasPrefix(filename, "/") {
+			return nil, fmt.Errorf("%q language has invalid file path %q", m.ID, filename)
+		}
+	}
+	if m.Name == "" {
+		m.Name = m.ID
+	}
+	if m.Font == "" {
+		m.Font = "bitmapfont"
+	}
+	return &LangPack{Manifest: m, dir: dir}, nil
+}
+
+func readAsset(ctx *ge.Context, path string) ([]byte, error) {
+	f := ctx.Loader.OpenAssetFunc(path)
+	if f == nil {
+		return nil, fmt.Errorf("can't open %q", path)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -10,3 +10,18 @@ import (
 func openfile(path string) (io.ReadCloser, error) {
 	return os.Open(path)
 }
+
+func listfiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
@@ -21,3 +21,8 @@ type nopCloser struct {
 }
 
 func (*nopCloser) Close() error { return nil }
+
+func listfiles(dir string) ([]string, error) {
+	// There is no way to list the remote folder contents.
+	return nil, nil
+}
@@ -14,15 +14,8 @@ func RegisterRawResources(ctx *ge.Context) {
 		RawInfernoTilesJSON: {Path: "raw/inferno_tiles.json"},
 		RawSnowTilesJSON:    {Path: "raw/snow_tiles.json"},
 
-		RawDictEn:             {Path: "raw/en.txt"},
-		RawDictTutorialEn:     {Path: "raw/en_intro.txt"},
-		RawDictAchievementsEn: {Path: "raw/en_achievements.txt"},
-		RawDictDronesEn:       {Path: "raw/en_drones.txt"},
-
-		RawDictRu:             {Path: "raw/ru.txt"},
-		RawDictTutorialRu:     {Path: "raw/ru_intro.txt"},
-		RawDictAchievementsRu: {Path: "raw/ru_achievements.txt"},
-		RawDictDronesRu:       {Path: "raw/ru_drones.txt"},
+		RawCustomWavesJSON: {Path: "raw/custom_waves.json"},
+		RawCampaignJSON:    {Path: "raw/campaign.json"},
 	}
 
 	for id, res := range rawResources {
@@ -39,13 +32,6 @@ const (
 	RawInfernoTilesJSON
 	RawSnowTilesJSON
 
-	RawDictEn
-	RawDictTutorialEn
-	RawDictAchievementsEn
-	RawDictDronesEn
-
-	RawDictRu
-	RawDictTutorialRu
-	RawDictAchievementsRu
-	RawDictDronesRu
+	RawCustomWavesJSON
+	RawCampaignJSON
 )
@@ -0,0 +1,138 @@
+package clientkit
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/httpfetch"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+// ErrSyncKeyMismatch is returned when the player progress
+// is stored on the server with a different sync key.
+var ErrSyncKeyMismatch = errors.New("sync key mismatch")
+
+// NewSyncKey generates a random cloud sync key.
+func NewSyncKey() (string, error) {
+	key := make([]byte, serverapi.SyncKeyLength)
+	alphabetSize := big.NewInt(int64(len(gamedata.SyncKeyAlphabet)))
+	for i := range key {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		key[i] = gamedata.SyncKeyAlphabet[n.Int64()]
+	}
+	return string(key), nil
+}
+
+// SyncProgress merges the local player progress with the one
+// stored on the server and uploads the results.
+//
+// The returned progress should be applied to the local stats,
+// see PlayerStats.ApplyCloudProgress.
+func SyncProgress(state *session.State) (*serverapi.PlayerProgress, error) {
+	key := state.Persistent.CloudSyncKey
+	if !gamedata.IsValidSyncKey(key) {
+		return nil, errors.New("invalid sync key")
+	}
+
+	progress := state.Persistent.PlayerStats.CloudProgress()
+	remote, err := DownloadProgress(state)
+	if err != nil {
+		return nil, err
+	}
+	if remote != nil {
+		progress = gamedata.MergePlayerProgress(progress, remote)
+	}
+	return UploadProgress(state, progress)
+}
+
+// DownloadProgress fetches the player progress from the server.
+// A nil result with nil error means that there is no progress stored yet.
+func DownloadProgress(state *session.State) (*serverapi.PlayerProgress, error) {
+	key := state.Persistent.CloudSyncKey
+	reqData, err := json.Marshal(serverapi.DownloadProgressReq{
+		PlayerName: state.Persistent.PlayerName,
+		SyncKey:    key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpfetch.PostJSON(progressURL(state, "download-progress"), reqData)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code == http.StatusNotFound {
+		return nil, nil
+	}
+	return decodeProgressResp(key, resp)
+}
+
+// UploadProgress sends the player progress to the server.
+// The server merges it with the stored progress and returns the results.
+func UploadProgress(state *session.State, progress *serverapi.PlayerProgress) (*serverapi.PlayerProgress, error) {
+	key := state.Persistent.CloudSyncKey
+	progressData, err := json.Marshal(progress)
+	if err != nil {
+		return nil, err
+	}
+	reqData, err := json.Marshal(serverapi.UploadProgressReq{
+		PlayerName: state.Persistent.PlayerName,
+		SyncKey:    key,
+		Progress: serverapi.SignedProgress{
+			Data:      progressData,
+			Signature: gamedata.SignProgress(key, progressData),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpfetch.PostJSON(progressURL(state, "upload-progress"), reqData)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProgressResp(key, resp)
+}
+
+func progressURL(state *session.State, endpoint string) string {
+	var u url.URL
+	u.Host = state.ServerHost
+	u.Scheme = state.ServerProtocol
+	u.Path = path.Join(state.ServerPath, endpoint)
+	return u.String()
+}
+
+func decodeProgressResp(key string, resp httpfetch.Response) (*serverapi.PlayerProgress, error) {
+	switch resp.Code {
+	case http.StatusOK:
+		// OK
+	case http.StatusForbidden:
+		return nil, ErrSyncKeyMismatch
+	default:
+		return nil, fmt.Errorf("unexpected status code %d", resp.Code)
+	}
+
+	var syncResp serverapi.SyncProgressResp
+	if err := json.Unmarshal(resp.Data, &syncResp); err != nil {
+		return nil, err
+	}
+	if !gamedata.IsValidProgressSignature(key, syncResp.Progress) {
+		return nil, errors.New("bad progress signature")
+	}
+	var progress serverapi.PlayerProgress
+	if err := json.Unmarshal(syncResp.Progress.Data, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
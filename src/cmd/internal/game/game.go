@@ -1,6 +1,7 @@
 package game
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"net/url"
@@ -133,9 +134,18 @@ func Main() {
 
 	state.Logf("buildinfo distribution tag: %s", buildinfo.Distribution)
 
+	state.GameDataFolder = gameDataFolder
 	ctx.Loader.OpenAssetFunc = assets.MakeOpenAssetFunc(ctx, gameDataFolder)
 	assets.RegisterRawResources(ctx)
 
+	langPacks, langErrors := assets.DiscoverLanguagePacks(gameDataFolder)
+	for _, err := range langErrors {
+		state.Logf("language pack error: %v", err)
+	}
+	state.LangPacks = langPacks
+
+	state.Campaign = loadCampaign(ctx)
+
 	keymaps := controls.BindKeymap(ctx)
 	state.TouchInput = gameinput.MakeHandler(gameinput.InputMethodTouch, ctx.Input.NewHandler(0, keymaps.TouchKeymap))
 	state.CombinedInput = gameinput.MakeHandler(gameinput.InputMethodCombined, ctx.Input.NewHandler(0, keymaps.CombinedKeymap))
@@ -144,20 +154,19 @@ func Main() {
 	state.SecondGamepadInput = gameinput.MakeHandler(gameinput.InputMethodGamepad2, ctx.Input.NewHandler(1, keymaps.SecondGamepadKeymap))
 
 	if state.CheckGameItem("save.json") {
-		if err := state.LoadGameItem("save.json", &state.Persistent); err != nil {
+		// The outdated saves are migrated here, see session.SaveDataVersion.
+		if err := state.LoadPersistentData(); err != nil {
 			state.Logf("can't load game data: %v", err)
+			if errors.Is(err, session.ErrNewerSaveData) {
+				// Play with the default data, but keep the newer save intact.
+				state.Logf("the progress will not be saved")
+				state.PersistentReadOnly = true
+			}
 			state.Persistent = contentlock.GetDefaultData()
 			contentlock.Update(state)
 			state.SaveGameItem("save.json", state.Persistent)
 		} else {
 			// Loaded without errors.
-			if state.Persistent.FirstLaunch && state.Persistent.PlayerStats.TotalPlayTime > 0 {
-				// We introduced the first launch flag after the release.
-				// Some players may have already played the game,
-				// so we don't want them to be marked as first-timers.
-				state.Persistent.FirstLaunch = false
-				state.SaveGameItem("save.json", state.Persistent)
-			}
 			// Re-check the content.
 			contentlock.Update(state)
 		}
@@ -173,6 +182,7 @@ func Main() {
 		state.Persistent.Settings.ScreenButtons = true
 	}
 	state.ReloadInputs()
+	state.ReloadKeymaps()
 	state.ReloadLanguage(ctx)
 
 	displayRatio := gamedata.SupportedDisplayRatios[state.Persistent.Settings.Graphics.AspectRatio]
@@ -250,6 +260,18 @@ func registerScenes(state *session.State) {
 	}
 }
 
+func loadCampaign(ctx *ge.Context) []*gamedata.CampaignMission {
+	schedules, err := gamedata.ParseCustomWaveSchedules(ctx.Loader.LoadRaw(assets.RawCustomWavesJSON).Data)
+	if err != nil {
+		panic(err)
+	}
+	missions, err := gamedata.ParseCampaign(ctx.Loader.LoadRaw(assets.RawCampaignJSON).Data, schedules)
+	if err != nil {
+		panic(err)
+	}
+	return missions
+}
+
 func newLevelConfig(options *gamedata.LevelConfig) *gamedata.LevelConfig {
 	config := gamedata.MakeLevelConfig(gamedata.ExecuteNormal, options.ReplayLevelConfig)
 
@@ -293,6 +315,7 @@ func getDefaultSessionState() *session.State {
 				RawGameMode:           "reverse",
 				TechProgressRate:      6,
 				ReverseSuperCreepRate: 3,
+				ReverseBotDifficulty:  1,
 				DronesPower:           1,
 				InitialCreeps:         1,
 				BossDifficulty:        2,
@@ -338,6 +361,19 @@ func getDefaultSessionState() *session.State {
 				StartingResources: true,
 			},
 		}),
+		CustomLevelConfig: newLevelConfig(&gamedata.LevelConfig{
+			ReplayLevelConfig: serverapi.ReplayLevelConfig{
+				InitialCreeps:  1,
+				NumCreepBases:  2,
+				CreepSpawnRate: 1,
+				Teleporters:    1,
+				RawGameMode:    "custom",
+				DronesPower:    1,
+				CustomRules: &serverapi.CustomModeRules{
+					Objectives: []string{gamedata.ObjectiveDestroyCreepBases.String()},
+				},
+			},
+		}),
 		Persistent: contentlock.GetDefaultData(),
 	}
 
@@ -1,24 +1,26 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"regexp"
 	"sort"
+	"strings"
+	"unicode"
 
-	resource "github.com/quasilyte/ebitengine-resource"
 	"github.com/quasilyte/ge"
 	"github.com/quasilyte/ge/langs"
 	"github.com/quasilyte/roboden-game/assets"
 )
 
+// placeholderRegexp matches the fmt verbs and the $-style template variables.
+var placeholderRegexp = regexp.MustCompile(`%[-+#0]*\d*(\.\d+)?[a-zA-Z%]|\$[a-z_]+`)
+
 func main() {
-	var translations = []struct {
-		key string
-	}{
-		{key: "en"},
-		{key: "ru"},
-	}
+	dataFlag := flag.String("data", "", "the game data folder to look for the user-provided language packs")
+	flag.Parse()
 
-	var missingKeys []string
+	var problems []string
 	missing := 0
 	invalid := 0
 
@@ -26,64 +28,95 @@ func main() {
 		Mute:       true,
 		FixedDelta: true,
 	})
-	ctx.Loader.OpenAssetFunc = assets.MakeOpenAssetFunc(ctx, "")
+	ctx.Loader.OpenAssetFunc = assets.MakeOpenAssetFunc(ctx, *dataFlag)
 	assets.RegisterRawResources(ctx)
 
-	eng := translations[0]
-	engData := langData{
-		dict: loadDict(ctx, eng.key),
+	packs, errs := assets.DiscoverLanguagePacks(*dataFlag)
+	for _, err := range errs {
+		invalid++
+		problems = append(problems, fmt.Sprintf("[!] %v", err))
 	}
-	for _, t := range translations[1:] {
-		dict := loadDict(ctx, t.key)
-		engData.dict.WalkKeys(func(k string) {
-			if dict.Has(k) {
+
+	engDict := loadDict(ctx, packs, "en")
+	for _, p := range packs {
+		id := p.Manifest.ID
+
+		if p.Manifest.Font != "bitmapfont" {
+			invalid++
+			problems = append(problems, fmt.Sprintf("[!] %s requires unsupported %q font", id, p.Manifest.Font))
+		}
+		// Make sure that the fallback chain is valid.
+		loadDict(ctx, packs, id)
+
+		dict, err := assets.LoadLanguagePackFiles(ctx, p)
+		if err != nil {
+			panic(err)
+		}
+
+		if id != "en" && p.Manifest.Fallback == "" {
+			// Partial translations are OK if there is a fallback.
+			engDict.WalkKeys(func(k string) {
+				if dict.Has(k) {
+					return
+				}
+				missing++
+				problems = append(problems, fmt.Sprintf("[-] %s misses %s translation", id, k))
+			})
+		}
+
+		unsupportedGlyphs := map[rune]struct{}{}
+		dict.WalkKeys(func(k string) {
+			s := dict.Get(k)
+			for _, r := range s {
+				if unicode.IsSpace(r) {
+					continue
+				}
+				if _, ok := assets.BitmapFont1.GlyphAdvance(r); !ok {
+					unsupportedGlyphs[r] = struct{}{}
+				}
+			}
+			if id == "en" {
 				return
 			}
-			missing++
-			missingKeys = append(missingKeys, fmt.Sprintf("[-] %s misses %s translation", t.key, k))
-		})
-		dict.WalkKeys(func(k string) {
-			if !engData.dict.Has(k) {
+			if !engDict.Has(k) {
 				invalid++
-				missingKeys = append(missingKeys, fmt.Sprintf("[!] %s has excessive %s key", t.key, k))
+				problems = append(problems, fmt.Sprintf("[!] %s has excessive %s key", id, k))
+				return
+			}
+			want := placeholders(engDict.Get(k))
+			have := placeholders(s)
+			if want != have {
+				invalid++
+				problems = append(problems, fmt.Sprintf("[!] %s %s placeholders mismatch: want [%s], have [%s]", id, k, want, have))
 			}
 		})
+		for r := range unsupportedGlyphs {
+			invalid++
+			problems = append(problems, fmt.Sprintf("[!] %s uses unsupported %q glyph", id, r))
+		}
 	}
 
-	sort.Strings(missingKeys)
-	for _, k := range missingKeys {
+	sort.Strings(problems)
+	for _, k := range problems {
 		fmt.Println(k)
 	}
+	fmt.Printf("language packs: %d\n", len(packs))
 	fmt.Printf("missing translations: %d\n", missing)
 	fmt.Printf("invalid keys: %d\n", invalid)
 }
 
-type langData struct {
-	dict *langs.Dictionary
-}
-
-func loadDict(ctx *ge.Context, key string) *langs.Dictionary {
-	var id resource.RawID
-	switch key {
-	case "en":
-		id = assets.RawDictEn
-	case "ru":
-		id = assets.RawDictRu
-	default:
-		panic(fmt.Sprintf("unsupported lang: %q", key))
-	}
-	dict, err := langs.ParseDictionary(key, 4, ctx.Loader.LoadRaw(id).Data)
+func loadDict(ctx *ge.Context, packs []*assets.LangPack, id string) *langs.Dictionary {
+	dict, err := assets.LoadLanguagePack(ctx, packs, id)
 	if err != nil {
 		panic(err)
 	}
-	if err := dict.Load("", ctx.Loader.LoadRaw(id+1).Data); err != nil {
-		panic(err)
-	}
-	if err := dict.Load("", ctx.Loader.LoadRaw(id+2).Data); err != nil {
-		panic(err)
-	}
-	if err := dict.Load("", ctx.Loader.LoadRaw(id+3).Data); err != nil {
-		panic(err)
-	}
 	return dict
 }
+
+// placeholders returns a sorted list of the placeholders used in s.
+// The order doesn't matter as translations can re-arrange the words.
+func placeholders(s string) string {
+	list := placeholderRegexp.FindAllString(s, -1)
+	sort.Strings(list)
+	return strings.Join(list, " ")
+}
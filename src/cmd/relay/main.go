@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"github.com/quasilyte/roboden-game/netplay"
+)
+
+func main() {
+	addrFlag := flag.String("addr", netplay.DefaultRelayAddr, "the address to listen on")
+	flag.Parse()
+
+	l, err := net.Listen("tcp", *addrFlag)
+	if err != nil {
+		panic(err)
+	}
+	log.Printf("listening on %s", l.Addr())
+
+	relay := netplay.NewRelay()
+	relay.Logf = log.Printf
+	if err := relay.Serve(l); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/langs"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/runsim"
+	"github.com/quasilyte/roboden-game/scenes/staging"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// This tool re-simulates the replay and renders its minimap images:
+// the periodic snapshots, the combat and mining heatmaps
+// and an animated timelapse.
+//
+// No GPU is required, so it can be used on a server.
+//
+// Usage example:
+//
+//	go run ./cmd/replayrender --out /tmp/replay < saved_replay_0.json
+
+func main() {
+	timeoutFlag := flag.Int("timeout", 120, "simulation timeout in seconds")
+	outFlag := flag.String("out", ".", "output files directory")
+	cellSizeFlag := flag.Int("cell-size", 4, "pathing grid cell size in pixels")
+	intervalFlag := flag.Int("interval", 30, "snapshot interval in game seconds")
+	gifDelayFlag := flag.Int("gif-delay", 50, "timelapse frame delay in 100ths of a second")
+	snapshotsFlag := flag.Bool("snapshots", true, "whether to write every snapshot as a PNG file")
+	flag.Parse()
+
+	if *cellSizeFlag < 1 {
+		panic(fmt.Sprintf("invalid --cell-size value: %d", *cellSizeFlag))
+	}
+	if *intervalFlag < 1 {
+		panic(fmt.Sprintf("invalid --interval value: %d", *intervalFlag))
+	}
+
+	replayDataBytes, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+	var replayData serverapi.GameReplay
+	if err := json.Unmarshal(replayDataBytes, &replayData); err != nil {
+		panic(err)
+	}
+
+	config := gamedata.MakeLevelConfig(gamedata.ExecuteSimulation, replayData.Config)
+	ctx := ge.NewContext(ge.ContextConfig{
+		Mute:       true,
+		FixedDelta: true,
+	})
+	ctx.Loader.OpenAssetFunc = assets.MakeOpenAssetFunc(ctx, "")
+	ctx.Dict = langs.NewDictionary("en", 2)
+
+	runsim.PrepareAssets(ctx)
+
+	state := runsim.NewState(ctx)
+
+	config.Finalize()
+
+	controller := staging.NewController(state, config, nil)
+	controller.SetReplayActions(replayData)
+	controller.EnableMinimap(staging.MinimapConfig{
+		CellSize:      *cellSizeFlag,
+		SnapshotTicks: *intervalFlag * 60,
+	})
+	if _, err := runsim.Run(state, replayData.LevelGenChecksum, *timeoutFlag, controller); err != nil {
+		panic(err)
+	}
+
+	if err := os.MkdirAll(*outFlag, os.ModePerm); err != nil {
+		panic(err)
+	}
+
+	recording := controller.GetMinimap()
+	if *snapshotsFlag {
+		for i, s := range recording.Snapshots {
+			writePNG(filepath.Join(*outFlag, fmt.Sprintf("snapshot_%03d.png", i)), s.Image)
+		}
+	}
+	writePNG(filepath.Join(*outFlag, "heatmap_combat.png"), recording.CombatHeatmap())
+	writePNG(filepath.Join(*outFlag, "heatmap_mining.png"), recording.MiningHeatmap())
+	writeGIF(filepath.Join(*outFlag, "timelapse.gif"), recording.Timelapse(*gifDelayFlag))
+}
+
+func writePNG(filename string, img image.Image) {
+	f, err := os.Create(filename)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		panic(err)
+	}
+}
+
+func writeGIF(filename string, g *gif.GIF) {
+	f, err := os.Create(filename)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	if err := gif.EncodeAll(f, g); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/langs"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/runsim"
+	"github.com/quasilyte/roboden-game/scenes/staging"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// This tool re-simulates the replay and prints its post-game stats
+// (the same data the in-game stats dashboard shows) as JSON.
+//
+// Usage example:
+//
+//	go run ./cmd/replaystats < saved_replay_0.json
+
+type statsOutput struct {
+	Config  serverapi.ReplayLevelConfig `json:"config"`
+	Results serverapi.GameResults       `json:"results"`
+	Stats   *staging.GameStats          `json:"stats"`
+}
+
+func main() {
+	timeoutFlag := flag.Int("timeout", 120, "simulation timeout in seconds")
+	flag.Parse()
+
+	replayDataBytes, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+	var replayData serverapi.GameReplay
+	if err := json.Unmarshal(replayDataBytes, &replayData); err != nil {
+		panic(err)
+	}
+
+	config := gamedata.MakeLevelConfig(gamedata.ExecuteSimulation, replayData.Config)
+	ctx := ge.NewContext(ge.ContextConfig{
+		Mute:       true,
+		FixedDelta: true,
+	})
+	ctx.Loader.OpenAssetFunc = assets.MakeOpenAssetFunc(ctx, "")
+	ctx.Dict = langs.NewDictionary("en", 2)
+
+	runsim.PrepareAssets(ctx)
+
+	state := runsim.NewState(ctx)
+
+	config.Finalize()
+
+	controller := staging.NewController(state, config, nil)
+	controller.SetReplayActions(replayData)
+	controller.EnableStats()
+	simResult, err := runsim.Run(state, replayData.LevelGenChecksum, *timeoutFlag, controller)
+	if err != nil {
+		panic(err)
+	}
+
+	out := statsOutput{
+		Config:  replayData.Config,
+		Results: simResult,
+		Stats:   controller.GetStats(),
+	}
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	w.Write(encoded)
+	w.WriteString("\n")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/langs"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/runsim"
+	"github.com/quasilyte/roboden-game/scenes/staging"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// This tool re-simulates the replay and prints its annotated timeline.
+//
+// Usage example:
+//
+//	go run ./cmd/replaytimeline --format text < saved_replay_0.json
+
+type timelineOutput struct {
+	Config  serverapi.ReplayLevelConfig `json:"config"`
+	Results serverapi.GameResults       `json:"results"`
+	Events  []staging.TimelineEvent     `json:"events"`
+}
+
+func main() {
+	timeoutFlag := flag.Int("timeout", 120, "simulation timeout in seconds")
+	formatFlag := flag.String("format", "text", "output format: text or json")
+	flag.Parse()
+
+	switch *formatFlag {
+	case "text", "json":
+		// OK.
+	default:
+		panic(fmt.Sprintf("unexpected --format value: %q", *formatFlag))
+	}
+
+	replayDataBytes, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+	var replayData serverapi.GameReplay
+	if err := json.Unmarshal(replayDataBytes, &replayData); err != nil {
+		panic(err)
+	}
+
+	config := gamedata.MakeLevelConfig(gamedata.ExecuteSimulation, replayData.Config)
+	ctx := ge.NewContext(ge.ContextConfig{
+		Mute:       true,
+		FixedDelta: true,
+	})
+	ctx.Loader.OpenAssetFunc = assets.MakeOpenAssetFunc(ctx, "")
+	ctx.Dict = langs.NewDictionary("en", 2)
+
+	runsim.PrepareAssets(ctx)
+
+	state := runsim.NewState(ctx)
+
+	config.Finalize()
+
+	controller := staging.NewController(state, config, nil)
+	controller.SetReplayActions(replayData)
+	controller.EnableTimeline()
+	simResult, err := runsim.Run(state, replayData.LevelGenChecksum, *timeoutFlag, controller)
+	if err != nil {
+		panic(err)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if *formatFlag == "json" {
+		out := timelineOutput{
+			Config:  replayData.Config,
+			Results: simResult,
+			Events:  controller.GetTimeline(),
+		}
+		encoded, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		w.Write(encoded)
+		w.WriteString("\n")
+		return
+	}
+
+	fmt.Fprintf(w, "mode: %s, seed: %d, players mode: %d\n",
+		replayData.Config.RawGameMode, replayData.Config.Seed, replayData.Config.PlayersMode)
+	for _, e := range controller.GetTimeline() {
+		fmt.Fprintln(w, staging.FormatTimelineEvent(e))
+	}
+	fmt.Fprintf(w, "result: victory=%v score=%d time=%ds ticks=%d\n",
+		simResult.Victory, simResult.Score, simResult.Time, simResult.Ticks)
+}
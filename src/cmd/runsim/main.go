@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -21,6 +22,7 @@ func main() {
 	timeoutFlag := flag.Int("timeout", 30, "simulation timeout in seconds")
 	debugFlag := flag.Bool("debug", false, "whether to enable debug logs")
 	trustFlag := flag.Bool("trust", false, "whether to allow 0 levelgen checksums")
+	eventsFlag := flag.String("events", "", "if not empty, write the simulation events to this file (JSONL)")
 	flag.Parse()
 
 	replayDataBytes, err := io.ReadAll(os.Stdin)
@@ -53,6 +55,21 @@ func main() {
 
 	controller := staging.NewController(state, config, nil)
 	controller.SetReplayActions(replayData)
+	if *eventsFlag != "" {
+		f, err := os.Create(*eventsFlag)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		w := bufio.NewWriter(f)
+		defer w.Flush()
+		encoder := json.NewEncoder(w)
+		controller.SubscribeEvents(func(e staging.GameEvent) {
+			if err := encoder.Encode(e); err != nil {
+				panic(err)
+			}
+		})
+	}
 	simResult, err := runsim.Run(state, replayData.LevelGenChecksum, *timeoutFlag, controller)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+func cmdCheck(args []string) error {
+	fs := flag.NewFlagSet("saverepair check", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("expected at least 1 save file argument")
+	}
+
+	numProblems := 0
+	for _, filename := range fs.Args() {
+		s, err := loadSave(filename)
+		if err != nil {
+			return err
+		}
+		printSaveInfo(filename, s)
+		numProblems += len(s.problems)
+	}
+
+	if numProblems != 0 {
+		return fmt.Errorf("found %d problems", numProblems)
+	}
+	return nil
+}
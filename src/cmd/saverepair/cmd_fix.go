@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func cmdFix(args []string) error {
+	fs := flag.NewFlagSet("saverepair fix", flag.ExitOnError)
+	outputName := fs.String("o", "", "output file name")
+	fs.Parse(args)
+
+	if *outputName == "" {
+		return errors.New("output file name can't be empty")
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly 1 save file argument")
+	}
+
+	filename := fs.Arg(0)
+	s, err := loadSave(filename)
+	if err != nil {
+		return err
+	}
+	printSaveInfo(filename, s)
+
+	// The game stores the save data without indentation.
+	data, err := json.Marshal(s.data)
+	if err != nil {
+		return fmt.Errorf("encode save data: %w", err)
+	}
+	if err := os.WriteFile(*outputName, data, 0o644); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+
+	return nil
+}
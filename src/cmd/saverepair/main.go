@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+
+	"github.com/cespare/subcmd"
+)
+
+// This tool validates the save.json files against the gamedata.
+// The outdated saves are migrated to the current schema version.
+//
+// Usage example:
+//
+//	go run ./cmd/saverepair check save.json
+//	go run ./cmd/saverepair fix -o fixed_save.json save.json
+
+func main() {
+	log.SetFlags(0)
+
+	cmds := []subcmd.Command{
+		{
+			Name:        "check",
+			Description: "report the save file problems",
+			Do:          makeMainFunc(cmdCheck),
+		},
+
+		{
+			Name:        "fix",
+			Description: "migrate the save file and remove its invalid entries",
+			Do:          makeMainFunc(cmdFix),
+		},
+	}
+
+	subcmd.Run(cmds)
+}
+
+func makeMainFunc(f func(args []string) error) func(args []string) {
+	return func(args []string) {
+		if err := f(args); err != nil {
+			log.Fatalf("error: %v", err)
+		}
+	}
+}
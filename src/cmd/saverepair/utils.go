@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/quasilyte/roboden-game/session"
+)
+
+type loadedSave struct {
+	data     session.PersistentData
+	version  int
+	problems []string
+}
+
+// loadSave reads, migrates and repairs the save file.
+// The file itself is never modified.
+func loadSave(filename string) (*loadedSave, error) {
+	fileData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, version, err := session.MigrateSaveData(fileData)
+	if err != nil {
+		return nil, fmt.Errorf("migrate %q: %w", filename, err)
+	}
+
+	result := &loadedSave{version: version}
+	if err := json.Unmarshal(migrated, &result.data); err != nil {
+		return nil, fmt.Errorf("decode %q: %w", filename, err)
+	}
+	result.problems = session.RepairPersistentData(&result.data)
+
+	return result, nil
+}
+
+func printSaveInfo(filename string, s *loadedSave) {
+	if s.version != session.SaveDataVersion {
+		fmt.Printf("%s: migrated from v%d to v%d\n", filename, s.version, session.SaveDataVersion)
+	}
+	for _, problem := range s.problems {
+		fmt.Printf("%s: %s\n", filename, problem)
+	}
+}
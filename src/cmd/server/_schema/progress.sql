@@ -0,0 +1,6 @@
+CREATE TABLE player_progress (
+    player_name TEXT NOT NULL PRIMARY KEY,
+    key_hash TEXT NOT NULL,
+    updated_at INTEGER NOT NULL,
+    progress_json BLOB NOT NULL
+);
@@ -4,6 +4,7 @@ import "errors"
 
 var (
 	errBadParams        = errors.New("bad params")
+	errForbidden        = errors.New("forbidden")
 	errNotFound         = errors.New("data not found")
 	errBadHTTPMethod    = errors.New("bad method")
 	errQueueIsFull      = errors.New("queue is full")
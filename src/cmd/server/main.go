@@ -65,6 +65,8 @@ func main() {
 	mux.HandleFunc("/get-player-board", server.NewHandler(h.HandleGetPlayerBoard))
 	mux.HandleFunc("/get-board", server.NewHandler(h.HandleGetBoard))
 	mux.HandleFunc("/save-player-score", server.NewHandler(h.HandleSavePlayerScore))
+	mux.HandleFunc("/upload-progress", server.NewHandler(h.HandleUploadProgress))
+	mux.HandleFunc("/download-progress", server.NewHandler(h.HandleDownloadProgress))
 
 	l.Info("starting server, listenning to %s", args.listenAddr)
 
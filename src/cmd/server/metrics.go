@@ -14,11 +14,13 @@ type metricsData struct {
 	MetricsSeq int
 
 	// Request counters.
-	NumReqErrors       int64
-	ReqGetPlayerBoard  int64
-	ReqGetBoard        int64
-	ReqSavePlayerScore int64
-	ReqVersion         int64
+	NumReqErrors        int64
+	ReqGetPlayerBoard   int64
+	ReqGetBoard         int64
+	ReqSavePlayerScore  int64
+	ReqVersion          int64
+	ReqUploadProgress   int64
+	ReqDownloadProgress int64
 
 	NumReplaysQueued    int64
 	NumReplaysCompleted int64
@@ -61,3 +63,11 @@ func (m *serverMetrics) IncReqSavePlayerScore() {
 func (m *serverMetrics) IncReqVersion() {
 	atomic.AddInt64(&m.data.ReqVersion, 1)
 }
+
+func (m *serverMetrics) IncReqUploadProgress() {
+	atomic.AddInt64(&m.data.ReqUploadProgress, 1)
+}
+
+func (m *serverMetrics) IncReqDownloadProgress() {
+	atomic.AddInt64(&m.data.ReqDownloadProgress, 1)
+}
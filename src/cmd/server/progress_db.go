@@ -0,0 +1,75 @@
+package main
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// progressDB stores the synchronized player progress.
+//
+// The sync keys are never stored as is: only their hashes are kept.
+type progressDB struct {
+	conn *sql.DB
+
+	// mu serializes the read-merge-write sequences.
+	mu sync.Mutex
+
+	selectStmt *sql.Stmt
+	upsertStmt *sql.Stmt
+}
+
+type progressRecord struct {
+	keyHash string
+	data    []byte
+}
+
+func newProgressDB(conn *sql.DB) *progressDB {
+	return &progressDB{conn: conn}
+}
+
+func (db *progressDB) PrepareQueries() error {
+	{
+		stmt, err := db.conn.Prepare(`
+			SELECT key_hash, progress_json
+			FROM player_progress
+			WHERE player_name = ?
+		`)
+		if err != nil {
+			return err
+		}
+		db.selectStmt = stmt
+	}
+
+	{
+		stmt, err := db.conn.Prepare(`
+			INSERT OR REPLACE INTO player_progress
+			       ('player_name', 'key_hash', 'updated_at', 'progress_json')
+			VALUES (?, ?, ?, ?)
+		`)
+		if err != nil {
+			return err
+		}
+		db.upsertStmt = stmt
+	}
+
+	return nil
+}
+
+// Find returns the player progress record.
+// A nil record with nil error means that there is no progress stored.
+func (db *progressDB) Find(playerName string) (*progressRecord, error) {
+	var record progressRecord
+	err := db.selectStmt.QueryRow(playerName).Scan(&record.keyHash, &record.data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *progressDB) Upsert(playerName, keyHash string, timestamp int64, data []byte) error {
+	_, err := db.upsertStmt.Exec(playerName, keyHash, timestamp, data)
+	return err
+}
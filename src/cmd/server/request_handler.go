@@ -270,3 +270,126 @@ func (h *requestHandler) calcReplayChecksum(replay *serverapi.GameReplay) string
 
 	return sha1encode(buf)
 }
+
+func (h *requestHandler) HandleUploadProgress(r *http.Request) (any, error) {
+	h.server.metrics.IncReqUploadProgress()
+
+	if r.Method != http.MethodPost {
+		return nil, errBadHTTPMethod
+	}
+
+	var req serverapi.UploadProgressReq
+	if err := h.decodeJSONBody(r, &req); err != nil {
+		return nil, err
+	}
+	playerName := strings.TrimSpace(req.PlayerName)
+	if !h.isValidSyncCredentials(playerName, req.SyncKey) {
+		return nil, errBadParams
+	}
+	if !gamedata.IsValidProgressSignature(req.SyncKey, req.Progress) {
+		return nil, errForbidden
+	}
+	var progress serverapi.PlayerProgress
+	if err := json.Unmarshal(req.Progress.Data, &progress); err != nil {
+		return nil, errBadParams
+	}
+	if !gamedata.IsValidPlayerProgress(&progress) {
+		return nil, errBadParams
+	}
+
+	db := h.server.progress
+	keyHash := sha256hex(req.SyncKey)
+
+	// The stored progress should not be overwritten by a concurrent upload:
+	// the merge result would lose the other device unlocks.
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	merged := &progress
+	record, err := db.Find(playerName)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		if record.keyHash != keyHash {
+			h.server.logger.Info("%q progress upload with a wrong sync key", playerName)
+			return nil, errForbidden
+		}
+		var stored serverapi.PlayerProgress
+		if err := json.Unmarshal(record.data, &stored); err != nil {
+			return nil, err
+		}
+		merged = gamedata.MergePlayerProgress(&stored, &progress)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Upsert(playerName, keyHash, time.Now().Unix(), data); err != nil {
+		return nil, err
+	}
+
+	h.server.logger.Info("updated %q progress", playerName)
+	resp := &serverapi.SyncProgressResp{
+		Progress: serverapi.SignedProgress{
+			Data:      data,
+			Signature: gamedata.SignProgress(req.SyncKey, data),
+		},
+	}
+	return resp, nil
+}
+
+func (h *requestHandler) HandleDownloadProgress(r *http.Request) (any, error) {
+	h.server.metrics.IncReqDownloadProgress()
+
+	if r.Method != http.MethodPost {
+		return nil, errBadHTTPMethod
+	}
+
+	var req serverapi.DownloadProgressReq
+	if err := h.decodeJSONBody(r, &req); err != nil {
+		return nil, err
+	}
+	playerName := strings.TrimSpace(req.PlayerName)
+	if !h.isValidSyncCredentials(playerName, req.SyncKey) {
+		return nil, errBadParams
+	}
+
+	record, err := h.server.progress.Find(playerName)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errNotFound
+	}
+	if record.keyHash != sha256hex(req.SyncKey) {
+		h.server.logger.Info("%q progress download with a wrong sync key", playerName)
+		return nil, errForbidden
+	}
+
+	resp := &serverapi.SyncProgressResp{
+		Progress: serverapi.SignedProgress{
+			Data:      record.data,
+			Signature: gamedata.SignProgress(req.SyncKey, record.data),
+		},
+	}
+	return resp, nil
+}
+
+func (h *requestHandler) isValidSyncCredentials(playerName, syncKey string) bool {
+	return playerName != "" &&
+		gamedata.IsValidUsername(playerName) &&
+		gamedata.IsValidSyncKey(syncKey)
+}
+
+func (h *requestHandler) decodeJSONBody(r *http.Request, dst any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return errBadParams
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return errBadParams
+	}
+	return nil
+}
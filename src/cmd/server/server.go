@@ -28,6 +28,8 @@ import (
 type apiServer struct {
 	queue *replayQueue
 
+	progress *progressDB
+
 	httpHandler http.Handler
 
 	seasons    []*seasonDB
@@ -133,6 +135,16 @@ func (s *apiServer) InitDatabases() error {
 		return fmt.Errorf("prepare queue queries: %w", err)
 	}
 
+	progressDBPath := filepath.Join(s.dataFolder, "progress.db")
+	progressConn, err := sqliteutil.Connect(progressDBPath)
+	if err != nil {
+		return err
+	}
+	s.progress = newProgressDB(progressConn)
+	if err := s.progress.PrepareQueries(); err != nil {
+		return fmt.Errorf("prepare progress queries: %w", err)
+	}
+
 	for i := 0; i <= currentSeason; i++ {
 		dbFilename := fmt.Sprintf("season%d.db", i)
 		dbPath := filepath.Join(s.dataFolder, dbFilename)
@@ -599,6 +611,8 @@ func (s *apiServer) writeError(w http.ResponseWriter, err error) {
 	switch err {
 	case errBadParams:
 		w.WriteHeader(http.StatusBadRequest)
+	case errForbidden:
+		w.WriteHeader(http.StatusForbidden)
 	case errNotFound:
 		w.WriteHeader(http.StatusNotFound)
 	case errBadHTTPMethod:
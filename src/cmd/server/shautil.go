@@ -2,9 +2,16 @@ package main
 
 import (
 	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 )
 
 func sha1encode(data []byte) string {
 	checksum := sha1.Sum(data)
 	return string(checksum[:])
 }
+
+func sha256hex(s string) string {
+	checksum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(checksum[:])
+}
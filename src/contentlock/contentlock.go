@@ -5,8 +5,11 @@ import (
 
 	"github.com/quasilyte/ge"
 	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/roboden-game/assets"
 	"github.com/quasilyte/roboden-game/gamedata"
 	"github.com/quasilyte/roboden-game/gameinput"
+	"github.com/quasilyte/roboden-game/netplay"
+	"github.com/quasilyte/roboden-game/serverapi"
 	"github.com/quasilyte/roboden-game/session"
 )
 
@@ -17,6 +20,8 @@ func GetDefaultData() session.PersistentData {
 		CursorSpeed:   3,
 	}
 	return session.PersistentData{
+		Version: session.SaveDataVersion,
+
 		// The default settings.
 		FirstLaunch: true,
 		Settings: session.GameSettings{
@@ -35,6 +40,8 @@ func GetDefaultData() session.PersistentData {
 			ScrollingSpeed:     2,
 			EdgeScrollRange:    2,
 			HintMode:           2,
+			NetplayRelay:       netplay.DefaultRelayAddr,
+			NetplayInputDelay:  1,
 			ScreenButtons:      true,
 			Demo:               true,
 			ShowFPS:            false,
@@ -56,11 +63,14 @@ func GetDefaultData() session.PersistentData {
 
 func inferDefaultLang() string {
 	languages := ge.InferLanguages()
+	// The user-provided packs can't be selected by default:
+	// the data folder is not known at this point.
+	// The partial translations can only be selected explicitly.
+	packs, _ := assets.DiscoverLanguagePacks("")
 	defaultLanguage := "en"
 	selectedLanguage := ""
 	for _, l := range languages {
-		switch l {
-		case "en", "ru":
+		if assets.IsCompleteLanguagePack(packs, l) {
 			if selectedLanguage != defaultLanguage {
 				selectedLanguage = l
 			}
@@ -152,5 +162,104 @@ func Update(state *session.State) *Result {
 		stats.TurretsUnlocked = append(stats.TurretsUnlocked, turret.Kind.String())
 	}
 
+	// The campaign missions unlock their rewards regardless of the score.
+	for _, m := range state.Campaign {
+		if stats.FindCampaignProgress(m.Name) == nil {
+			continue
+		}
+		for _, name := range m.Unlocks.Cores {
+			if xslices.Contains(stats.CoresUnlocked, name) {
+				continue
+			}
+			result.CoresUnlocked = append(result.CoresUnlocked, name)
+			stats.CoresUnlocked = append(stats.CoresUnlocked, name)
+		}
+		for _, name := range m.Unlocks.Drones {
+			if xslices.Contains(stats.DronesUnlocked, name) {
+				continue
+			}
+			result.DronesUnlocked = append(result.DronesUnlocked, gamedata.DroneKindByName[name])
+			stats.DronesUnlocked = append(stats.DronesUnlocked, name)
+		}
+		for _, name := range m.Unlocks.Turrets {
+			if xslices.Contains(stats.TurretsUnlocked, name) {
+				continue
+			}
+			result.TurretsUnlocked = append(result.TurretsUnlocked, gamedata.DroneKindByName[name])
+			stats.TurretsUnlocked = append(stats.TurretsUnlocked, name)
+		}
+	}
+
 	return result
 }
+
+// LockedContent lists the level config features that are not unlocked yet.
+type LockedContent struct {
+	Mode    string
+	Core    string
+	Turret  string
+	Drones  []string
+	Options []string
+}
+
+// CheckLevelConfig returns the config features that are not available
+// for the player with these stats.
+// A nil result means that the config can be used as is.
+func CheckLevelConfig(stats *session.PlayerStats, config *serverapi.ReplayLevelConfig) *LockedContent {
+	var result LockedContent
+	locked := false
+
+	// The custom mode becomes available with the classic mode.
+	mode := config.RawGameMode
+	if mode == "custom" {
+		mode = "classic"
+	}
+	if !xslices.Contains(stats.ModesUnlocked, mode) {
+		result.Mode = config.RawGameMode
+		locked = true
+	}
+
+	options := [...]struct {
+		id      string
+		enabled bool
+	}{
+		{"super_creeps", config.SuperCreeps},
+		{"creep_fortress", config.CreepFortress},
+		{"ion_mortars", config.IonMortars},
+		{"coordinator_creeps", config.CoordinatorCreeps},
+		{"grenadier_creeps", config.GrenadierCreeps},
+	}
+	for _, o := range options {
+		if !o.enabled {
+			continue
+		}
+		if stats.TotalScore < gamedata.LobbyOptionMap[o.id].ScoreCost {
+			result.Options = append(result.Options, o.id)
+			locked = true
+		}
+	}
+
+	// In a single player reverse mode the colony design is selected randomly
+	// among the unlocked ones when the game starts.
+	if config.RawGameMode != "reverse" || config.PlayersMode != serverapi.PmodeSinglePlayer {
+		if config.CoreDesign != "" && !xslices.Contains(stats.CoresUnlocked, config.CoreDesign) {
+			result.Core = config.CoreDesign
+			locked = true
+		}
+		if config.TurretDesign != "" && !xslices.Contains(stats.TurretsUnlocked, config.TurretDesign) {
+			result.Turret = config.TurretDesign
+			locked = true
+		}
+		for _, name := range config.Tier2Recipes {
+			if !xslices.Contains(stats.DronesUnlocked, name) {
+				result.Drones = append(result.Drones, name)
+				locked = true
+			}
+		}
+	}
+
+	if !locked {
+		return nil
+	}
+	return &result
+}
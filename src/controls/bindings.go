@@ -0,0 +1,194 @@
+package controls
+
+import (
+	"github.com/quasilyte/ge/input"
+)
+
+// ActionContext describes where the action can be activated.
+//
+// Two actions can share the same key only if their contexts don't overlap.
+// For instance, the same gamepad button is used for the menu "back"
+// and for the action card selection.
+type ActionContext uint8
+
+const (
+	ContextSinglePlayer ActionContext = 1 << iota
+	ContextCoop
+	ContextMenu
+	ContextTutorial
+	ContextDemo
+
+	ContextGame = ContextSinglePlayer | ContextCoop
+)
+
+// BindingDevice is a device kind a custom binding belongs to.
+type BindingDevice int
+
+const (
+	BindingKeyboard BindingDevice = iota
+	BindingGamepad
+)
+
+type RebindableAction struct {
+	Action input.Action
+
+	// Name is used as a persistent binding ID and as a translation key suffix.
+	Name string
+
+	Context ActionContext
+
+	Keyboard bool
+	Gamepad  bool
+}
+
+func (a *RebindableAction) HasDevice(device BindingDevice) bool {
+	if device == BindingKeyboard {
+		return a.Keyboard
+	}
+	return a.Gamepad
+}
+
+// RebindableActions lists the actions that can be re-assigned by the user.
+//
+// The internal actions (like ActionDebug) and the actions that
+// are essential for the UI to work (like ActionClick) are not listed.
+var RebindableActions = []RebindableAction{
+	{Action: ActionPanUp, Name: "pan_up", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionPanDown, Name: "pan_down", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionPanLeft, Name: "pan_left", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionPanRight, Name: "pan_right", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionPanAlt, Name: "pan_alt", Context: ContextGame, Keyboard: true},
+	{Action: ActionMoveChoice, Name: "move_choice", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionQueueMoveChoice, Name: "queue_move_choice", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionChoice1, Name: "choice1", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionChoice2, Name: "choice2", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionChoice3, Name: "choice3", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionChoice4, Name: "choice4", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionChoice5, Name: "choice5", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionToggleColony, Name: "toggle_colony", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionSelectGroup1, Name: "select_group1", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionSelectGroup2, Name: "select_group2", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionSelectGroup3, Name: "select_group3", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionSelectGroup4, Name: "select_group4", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionAssignGroup1, Name: "assign_group1", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionAssignGroup2, Name: "assign_group2", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionAssignGroup3, Name: "assign_group3", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionAssignGroup4, Name: "assign_group4", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionPause, Name: "pause", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionShowRecipes, Name: "show_recipes", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionToggleInterface, Name: "toggle_interface", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionToggleFastForward, Name: "toggle_fast_forward", Context: ContextSinglePlayer, Keyboard: true},
+	{Action: ActionToggleFastForwardAlt, Name: "toggle_fast_forward_alt", Context: ContextSinglePlayer, Gamepad: true},
+	{Action: ActionPing, Name: "ping", Context: ContextCoop, Keyboard: true, Gamepad: true},
+	{Action: ActionExit, Name: "exit", Context: ContextGame, Keyboard: true, Gamepad: true},
+	{Action: ActionNextTutorialMessage, Name: "next_tutorial_message", Context: ContextTutorial, Keyboard: true, Gamepad: true},
+	{Action: ActionSkipDemo, Name: "skip_demo", Context: ContextDemo, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuBack, Name: "menu_back", Context: ContextMenu, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuConfirm, Name: "menu_confirm", Context: ContextMenu, Gamepad: true},
+	{Action: ActionMenuFocusUp, Name: "menu_focus_up", Context: ContextMenu, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuFocusDown, Name: "menu_focus_down", Context: ContextMenu, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuFocusLeft, Name: "menu_focus_left", Context: ContextMenu, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuFocusRight, Name: "menu_focus_right", Context: ContextMenu, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuTabLeft, Name: "menu_tab_left", Context: ContextMenu, Keyboard: true, Gamepad: true},
+	{Action: ActionMenuTabRight, Name: "menu_tab_right", Context: ContextMenu, Keyboard: true, Gamepad: true},
+}
+
+// reservedActions can't be re-assigned, but their keys
+// can't be taken by the rebindable actions either.
+//
+// ActionExitConfirm is not listed: it always shares the key with ActionExit.
+var reservedActions = []RebindableAction{
+	{Action: ActionClick, Name: "click", Context: ContextGame | ContextMenu, Keyboard: true},
+	// The gamepad click is also a move choice key during the game.
+	{Action: ActionClick, Name: "click", Context: ContextMenu, Gamepad: true},
+	{Action: ActionEditorUndo, Name: "editor_undo", Context: ContextMenu, Keyboard: true},
+	{Action: ActionEditorRedo, Name: "editor_redo", Context: ContextMenu, Keyboard: true},
+}
+
+// FindRebindableAction returns the action info by its name.
+// It returns nil for the unknown names.
+func FindRebindableAction(name string) *RebindableAction {
+	for i := range RebindableActions {
+		if RebindableActions[i].Name == name {
+			return &RebindableActions[i]
+		}
+	}
+	return nil
+}
+
+// FindBindingConflict reports which action would conflict with a
+// if k would be bound to it; nil is returned if there are no conflicts.
+//
+// The keymap should be a device-specific keymap with the custom bindings applied.
+// The reserved actions (like ActionClick) are checked too.
+func FindBindingConflict(keymap input.Keymap, device BindingDevice, a *RebindableAction, k input.Key) *RebindableAction {
+	if conflict := findBindingConflict(RebindableActions, keymap, device, a, k); conflict != nil {
+		return conflict
+	}
+	return findBindingConflict(reservedActions, keymap, device, a, k)
+}
+
+func findBindingConflict(actions []RebindableAction, keymap input.Keymap, device BindingDevice, a *RebindableAction, k input.Key) *RebindableAction {
+	for i := range actions {
+		other := &actions[i]
+		if other.Action == a.Action || !other.HasDevice(device) {
+			continue
+		}
+		if other.Context&a.Context == 0 {
+			continue
+		}
+		for _, otherKey := range keymap[other.Action] {
+			if otherKey == k {
+				return other
+			}
+		}
+	}
+	return nil
+}
+
+// MakeKeymapSet creates the keymaps using the default bindings
+// that are overridden by the custom ones.
+//
+// The custom bindings map the action names (see RebindableAction)
+// to the key names (see input.ParseKey).
+// Unknown actions and keys are ignored.
+func MakeKeymapSet(keyboardBindings, gamepadBindings map[string][]string) KeymapSet {
+	set := makeDefaultKeymapSet()
+
+	applyBindings(set.KeyboardKeymap, BindingKeyboard, keyboardBindings)
+	applyBindings(set.FirstGamepadKeymap, BindingGamepad, gamepadBindings)
+
+	mainKeymap := input.Keymap{
+		ActionMoveChoice: {input.KeyTouchTap},
+
+		ActionClick: {input.KeyTouchTap},
+	}
+	for a, keys := range set.FirstGamepadKeymap {
+		mainKeymap[a] = append(mainKeymap[a], keys...)
+	}
+	for a, keys := range set.KeyboardKeymap {
+		mainKeymap[a] = append(mainKeymap[a], keys...)
+	}
+	set.CombinedKeymap = mainKeymap
+	set.SecondGamepadKeymap = set.FirstGamepadKeymap
+
+	return set
+}
+
+func applyBindings(keymap input.Keymap, device BindingDevice, bindings map[string][]string) {
+	for actionName, keyNames := range bindings {
+		a := FindRebindableAction(actionName)
+		if a == nil || !a.HasDevice(device) {
+			continue
+		}
+		keys := make([]input.Key, 0, len(keyNames))
+		for _, name := range keyNames {
+			k, err := input.ParseKey(name)
+			if err != nil {
+				continue
+			}
+			keys = append(keys, k)
+		}
+		keymap[a.Action] = keys
+	}
+}
@@ -56,6 +56,19 @@ const (
 	ActionChoice4
 	ActionChoice5
 	ActionMoveChoice
+	ActionQueueMoveChoice
+
+	ActionSelectGroup1
+	ActionSelectGroup2
+	ActionSelectGroup3
+	ActionSelectGroup4
+	ActionAssignGroup1
+	ActionAssignGroup2
+	ActionAssignGroup3
+	ActionAssignGroup4
+
+	ActionEditorUndo
+	ActionEditorRedo
 )
 
 type KeymapSet struct {
@@ -67,6 +80,10 @@ type KeymapSet struct {
 }
 
 func BindKeymap(ctx *ge.Context) KeymapSet {
+	return MakeKeymapSet(nil, nil)
+}
+
+func makeDefaultKeymapSet() KeymapSet {
 	touchKeymap := input.Keymap{
 		ActionSkipDemo: {input.KeyTouchTap},
 
@@ -167,27 +184,26 @@ func BindKeymap(ctx *ge.Context) KeymapSet {
 		ActionChoice5:    {input.Key5},
 		ActionMoveChoice: {input.KeyMouseRight},
 
-		ActionClick: {input.KeyMouseLeft},
-	}
+		ActionQueueMoveChoice: {input.KeyWithModifier(input.KeyMouseRight, input.ModShift)},
 
-	mainKeymap := input.Keymap{
-		ActionMoveChoice: {input.KeyTouchTap},
+		ActionSelectGroup1: {input.KeyF1},
+		ActionSelectGroup2: {input.KeyF2},
+		ActionSelectGroup3: {input.KeyF3},
+		ActionSelectGroup4: {input.KeyF4},
+		ActionAssignGroup1: {input.KeyWithModifier(input.KeyF1, input.ModControl)},
+		ActionAssignGroup2: {input.KeyWithModifier(input.KeyF2, input.ModControl)},
+		ActionAssignGroup3: {input.KeyWithModifier(input.KeyF3, input.ModControl)},
+		ActionAssignGroup4: {input.KeyWithModifier(input.KeyF4, input.ModControl)},
 
-		ActionClick: {input.KeyTouchTap},
-	}
+		ActionClick: {input.KeyMouseLeft},
 
-	for a, keys := range gamepadKeymap {
-		mainKeymap[a] = append(mainKeymap[a], keys...)
-	}
-	for a, keys := range keyboardKeymap {
-		mainKeymap[a] = append(mainKeymap[a], keys...)
+		ActionEditorUndo: {input.KeyWithModifier(input.KeyZ, input.ModControl)},
+		ActionEditorRedo: {input.KeyWithModifier(input.KeyY, input.ModControl)},
 	}
 
 	return KeymapSet{
-		TouchKeymap:         touchKeymap,
-		CombinedKeymap:      mainKeymap,
-		KeyboardKeymap:      keyboardKeymap,
-		FirstGamepadKeymap:  gamepadKeymap,
-		SecondGamepadKeymap: gamepadKeymap,
+		TouchKeymap:        touchKeymap,
+		KeyboardKeymap:     keyboardKeymap,
+		FirstGamepadKeymap: gamepadKeymap,
 	}
 }
@@ -32,6 +32,11 @@ func SchemaText(d *langs.Dictionary, id int, schema *gamedata.SavedSchema) strin
 	lines = append(lines, fmt.Sprintf("%s: %s", d.Get("menu.schema.turret"), d.Get("turret", strings.ToLower(schema.Config.TurretDesign))))
 	lines = append(lines, "")
 
+	if rules := schema.Config.CustomRules; rules != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s", d.Get("menu.schema.objectives"), ObjectivesText(d, rules)))
+		lines = append(lines, "")
+	}
+
 	{
 		var allDrones []string
 		for _, recipe := range schema.Config.Tier2Recipes {
@@ -89,6 +94,15 @@ func ReplayText(d *langs.Dictionary, r *session.SavedReplay) string {
 	return strings.Join(lines, "\n")
 }
 
+// ObjectivesText returns a comma-separated list of the custom mode objectives.
+func ObjectivesText(d *langs.Dictionary, rules *serverapi.CustomModeRules) string {
+	parts := make([]string, len(rules.Objectives))
+	for i, name := range rules.Objectives {
+		parts[i] = strings.ToLower(d.Get("game.objective", name))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func LockedDroneText(d *langs.Dictionary, stats *session.PlayerStats, drone *gamedata.AgentStats) string {
 	textLines := make([]string, 0, 4)
 	textLines = append(textLines, d.Get("drone.locked"))
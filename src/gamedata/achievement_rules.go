@@ -0,0 +1,637 @@
+package gamedata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// AchievementResults are the game results that can be used inside achievement rules.
+//
+// This struct doesn't depend on the game scenes, so it can be filled
+// from any source that has the data: a finished game or a simulated replay.
+type AchievementResults struct {
+	Victory              bool
+	TimePlayed           float64 // In seconds
+	Ticks                int
+	FastforwardTicks     int
+	SeedKind             SeedKind
+	Score                int
+	DifficultyScore      int
+	DronePointsAllocated int
+	ArenaLevel           int
+
+	Paused             bool
+	OpenedEvolutionTab bool
+	T3Created          int
+	ColoniesBuilt      int
+	RadiusIncreases    int
+	OnlyTier1Military  bool
+	FactionsUsed       int
+
+	CreepsStomped        int
+	CreepBasesDestroyed  int
+	DominatorsSurvived   int
+	GrenadierColonyHit   bool
+	GroundBossDefeat     bool
+	CoordinatorRallyUsed bool
+
+	EnemyColonyDamage            float64
+	EnemyColonyDamageFromTurrets float64
+
+	GroundControl     bool
+	AtomicBombUsed    bool
+	AtomicBombVictory bool
+}
+
+// AchievementContext is an environment in which achievement rules are evaluated.
+type AchievementContext struct {
+	Config  *serverapi.ReplayLevelConfig
+	Results *AchievementResults
+
+	// NumVictories and Tier3DronesSeen come from the player stats.
+	// They should already include the results of the game being checked.
+	NumVictories    int
+	Tier3DronesSeen int
+
+	// Roll is a random value in [0, 1) range.
+	// It's used for the luck-based achievements.
+	Roll float64
+}
+
+// AchievementRuleField describes a value that can be referenced inside an achievement rule.
+type AchievementRuleField struct {
+	Name string
+	Type string
+	Doc  string
+
+	kind ruleKind
+	get  func(ctx *AchievementContext) ruleValue
+}
+
+func numField(name, doc string, get func(ctx *AchievementContext) float64) *AchievementRuleField {
+	return &AchievementRuleField{
+		Name: name,
+		Type: "number",
+		Doc:  doc,
+		kind: ruleNumber,
+		get: func(ctx *AchievementContext) ruleValue {
+			return ruleValue{num: get(ctx)}
+		},
+	}
+}
+
+func boolField(name, doc string, get func(ctx *AchievementContext) bool) *AchievementRuleField {
+	return &AchievementRuleField{
+		Name: name,
+		Type: "bool",
+		Doc:  doc,
+		kind: ruleBool,
+		get: func(ctx *AchievementContext) ruleValue {
+			return ruleValue{b: get(ctx)}
+		},
+	}
+}
+
+func strField(name, doc string, get func(ctx *AchievementContext) string) *AchievementRuleField {
+	return &AchievementRuleField{
+		Name: name,
+		Type: "string",
+		Doc:  doc,
+		kind: ruleString,
+		get: func(ctx *AchievementContext) ruleValue {
+			return ruleValue{s: get(ctx)}
+		},
+	}
+}
+
+var achievementRuleFields = map[string]*AchievementRuleField{}
+
+// AchievementRuleFields returns all fields that can be used inside achievement rules.
+// The result is sorted by the field name.
+func AchievementRuleFields() []*AchievementRuleField {
+	list := make([]*AchievementRuleField, 0, len(achievementRuleFields))
+	for _, f := range achievementRuleFields {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+func init() {
+	fields := []*AchievementRuleField{
+		numField("tier3_recipes", "the total number of tier 3 drone recipes", func(ctx *AchievementContext) float64 {
+			return float64(len(Tier3agentMergeRecipes))
+		}),
+		numField("roll", "a random value in [0, 1) range", func(ctx *AchievementContext) float64 {
+			return ctx.Roll
+		}),
+
+		numField("stats.num_victories", "the number of victories, including this game", func(ctx *AchievementContext) float64 {
+			return float64(ctx.NumVictories)
+		}),
+		numField("stats.tier3_drones_seen", "the number of tier 3 drones ever created, including this game", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Tier3DronesSeen)
+		}),
+
+		strField("config.mode", "the game mode name, like \"classic\" or \"reverse\"", func(ctx *AchievementContext) string {
+			return ctx.Config.RawGameMode
+		}),
+		strField("config.core_design", "the colony core design name, like \"den\" or \"ark\"", func(ctx *AchievementContext) string {
+			return ctx.Config.CoreDesign
+		}),
+		strField("config.turret_design", "the turret design name, like \"Gunpoint\" or \"BeamTower\"", func(ctx *AchievementContext) string {
+			return ctx.Config.TurretDesign
+		}),
+		numField("config.num_tier2_recipes", "the number of selected tier 2 drone recipes", func(ctx *AchievementContext) float64 {
+			return float64(len(ctx.Config.Tier2Recipes))
+		}),
+		boolField("config.fog_of_war", "fog of war option", func(ctx *AchievementContext) bool {
+			return ctx.Config.FogOfWar
+		}),
+		boolField("config.grenadier_creeps", "grenadier creeps option", func(ctx *AchievementContext) bool {
+			return ctx.Config.GrenadierCreeps
+		}),
+		numField("config.world_shape", "world shape option index (0 is square)", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Config.WorldShape)
+		}),
+		numField("config.world_size", "world size option index (0 is the smallest)", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Config.WorldSize)
+		}),
+		numField("config.game_speed", "game speed option index", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Config.GameSpeed)
+		}),
+		numField("config.num_creep_bases", "the number of creep bases option", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Config.NumCreepBases)
+		}),
+		numField("config.boss_difficulty", "boss difficulty option index", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Config.BossDifficulty)
+		}),
+		numField("config.interface_mode", "interface mode option index (0 is no UI)", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Config.InterfaceMode)
+		}),
+
+		boolField("result.victory", "whether the game was won", func(ctx *AchievementContext) bool {
+			return ctx.Results.Victory
+		}),
+		numField("result.time_played", "the game duration in seconds", func(ctx *AchievementContext) float64 {
+			return ctx.Results.TimePlayed
+		}),
+		numField("result.ticks", "the game duration in ticks", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.Ticks)
+		}),
+		numField("result.fastforward_ticks", "the number of ticks played in the fast forward mode", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.FastforwardTicks)
+		}),
+		strField("result.seed_kind", "\"normal\" or a special seed kind: \"infernal\", \"leet\"", func(ctx *AchievementContext) string {
+			return ctx.Results.SeedKind.String()
+		}),
+		numField("result.score", "the game score", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.Score)
+		}),
+		numField("result.difficulty_score", "the game difficulty score", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.DifficultyScore)
+		}),
+		numField("result.drone_points_allocated", "the drone points spent on tier 2 recipes", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.DronePointsAllocated)
+		}),
+		numField("result.arena_level", "the last reached arena wave", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.ArenaLevel)
+		}),
+		boolField("result.paused", "whether the game was ever paused", func(ctx *AchievementContext) bool {
+			return ctx.Results.Paused
+		}),
+		boolField("result.opened_evolution_tab", "whether the evolution tab was ever opened", func(ctx *AchievementContext) bool {
+			return ctx.Results.OpenedEvolutionTab
+		}),
+		numField("result.t3_created", "the number of tier 3 drones created", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.T3Created)
+		}),
+		numField("result.colonies_built", "the number of colonies built (or destroyed in reverse mode)", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.ColoniesBuilt)
+		}),
+		numField("result.radius_increases", "the number of colony radius increase actions", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.RadiusIncreases)
+		}),
+		boolField("result.only_tier1_military", "whether only tier 1 drones were used for fighting", func(ctx *AchievementContext) bool {
+			return ctx.Results.OnlyTier1Military
+		}),
+		numField("result.factions_used", "the number of drone factions used (0-4)", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.FactionsUsed)
+		}),
+		numField("result.creeps_stomped", "the number of creeps crushed by a colony", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.CreepsStomped)
+		}),
+		numField("result.creep_bases_destroyed", "the number of creep bases destroyed", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.CreepBasesDestroyed)
+		}),
+		numField("result.dominators_survived", "the number of dominators that were not defeated", func(ctx *AchievementContext) float64 {
+			return float64(ctx.Results.DominatorsSurvived)
+		}),
+		boolField("result.grenadier_colony_hit", "whether a grenadier ever hit a colony", func(ctx *AchievementContext) bool {
+			return ctx.Results.GrenadierColonyHit
+		}),
+		boolField("result.ground_boss_defeat", "whether the boss was defeated while it was landed", func(ctx *AchievementContext) bool {
+			return ctx.Results.GroundBossDefeat
+		}),
+		numField("result.enemy_colony_damage", "the damage dealt to the enemy colonies", func(ctx *AchievementContext) float64 {
+			return ctx.Results.EnemyColonyDamage
+		}),
+		numField("result.enemy_colony_damage_from_turrets", "the damage dealt to the enemy colonies by turrets", func(ctx *AchievementContext) float64 {
+			return ctx.Results.EnemyColonyDamageFromTurrets
+		}),
+		boolField("result.coordinator_rally_used", "whether a coordinator rally was used", func(ctx *AchievementContext) bool {
+			return ctx.Results.CoordinatorRallyUsed
+		}),
+		boolField("result.ground_control", "whether only ground units were used to win in reverse mode", func(ctx *AchievementContext) bool {
+			return ctx.Results.GroundControl
+		}),
+		boolField("result.atomic_bomb_used", "whether an atomic bomb was used", func(ctx *AchievementContext) bool {
+			return ctx.Results.AtomicBombUsed
+		}),
+		boolField("result.atomic_bomb_victory", "whether the last colony was destroyed by an atomic bomb", func(ctx *AchievementContext) bool {
+			return ctx.Results.AtomicBombVictory
+		}),
+	}
+	for _, f := range fields {
+		achievementRuleFields[f.Name] = f
+	}
+
+	for _, a := range AchievementList {
+		if a.Rule == "" {
+			continue
+		}
+		rule, err := CompileAchievementRule(a.Rule)
+		if err != nil {
+			panic(fmt.Sprintf("%s achievement: %v", a.Name, err))
+		}
+		a.compiledRule = rule
+	}
+}
+
+// CheckRule reports whether the achievement condition is satisfied.
+// The mode and victory requirements are not checked here.
+// Achievements without a rule are never unlocked by the game results.
+func (a *Achievement) CheckRule(ctx *AchievementContext) bool {
+	if a.compiledRule == nil {
+		return false
+	}
+	return a.compiledRule.Eval(ctx)
+}
+
+// AchievementRule is a compiled achievement condition.
+//
+// The rule syntax is a simple expression language:
+//
+//	literals: 10, 0.85, "text", true, false
+//	fields:   result.victory, config.mode, ... (see AchievementRuleFields)
+//	unary:    !x, -x
+//	binary:   * /, + -, < <= > >=, == !=, &&, || (in the order of precedence)
+//
+// The rule expression result should have a bool type.
+type AchievementRule struct {
+	root ruleExpr
+}
+
+func (r *AchievementRule) Eval(ctx *AchievementContext) bool {
+	return r.root.eval(ctx).b
+}
+
+func CompileAchievementRule(src string) (*AchievementRule, error) {
+	p := ruleParser{src: src}
+	p.next()
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != ruleTokEOF {
+		return nil, fmt.Errorf("unexpected %s at %d", p.tok, p.tok.pos)
+	}
+	if root.kind() != ruleBool {
+		return nil, errors.New("rule result should be a bool")
+	}
+	return &AchievementRule{root: root}, nil
+}
+
+type ruleKind int
+
+const (
+	ruleBool ruleKind = iota
+	ruleNumber
+	ruleString
+)
+
+func (k ruleKind) String() string {
+	switch k {
+	case ruleBool:
+		return "bool"
+	case ruleNumber:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+type ruleValue struct {
+	b   bool
+	num float64
+	s   string
+}
+
+type ruleExpr interface {
+	kind() ruleKind
+	eval(ctx *AchievementContext) ruleValue
+}
+
+type ruleLiteral struct {
+	k ruleKind
+	v ruleValue
+}
+
+func (e *ruleLiteral) kind() ruleKind                     { return e.k }
+func (e *ruleLiteral) eval(*AchievementContext) ruleValue { return e.v }
+
+type ruleFieldExpr struct {
+	field *AchievementRuleField
+}
+
+func (e *ruleFieldExpr) kind() ruleKind { return e.field.kind }
+func (e *ruleFieldExpr) eval(ctx *AchievementContext) ruleValue {
+	return e.field.get(ctx)
+}
+
+type ruleUnaryExpr struct {
+	op string
+	x  ruleExpr
+}
+
+func (e *ruleUnaryExpr) kind() ruleKind { return e.x.kind() }
+func (e *ruleUnaryExpr) eval(ctx *AchievementContext) ruleValue {
+	x := e.x.eval(ctx)
+	if e.op == "!" {
+		return ruleValue{b: !x.b}
+	}
+	return ruleValue{num: -x.num}
+}
+
+type ruleBinaryExpr struct {
+	op   string
+	k    ruleKind
+	x, y ruleExpr
+}
+
+func (e *ruleBinaryExpr) kind() ruleKind { return e.k }
+func (e *ruleBinaryExpr) eval(ctx *AchievementContext) ruleValue {
+	switch e.op {
+	case "&&":
+		return ruleValue{b: e.x.eval(ctx).b && e.y.eval(ctx).b}
+	case "||":
+		return ruleValue{b: e.x.eval(ctx).b || e.y.eval(ctx).b}
+	}
+
+	x := e.x.eval(ctx)
+	y := e.y.eval(ctx)
+	switch e.op {
+	case "==":
+		return ruleValue{b: x == y}
+	case "!=":
+		return ruleValue{b: x != y}
+	case "<":
+		return ruleValue{b: x.num < y.num}
+	case "<=":
+		return ruleValue{b: x.num <= y.num}
+	case ">":
+		return ruleValue{b: x.num > y.num}
+	case ">=":
+		return ruleValue{b: x.num >= y.num}
+	case "+":
+		return ruleValue{num: x.num + y.num}
+	case "-":
+		return ruleValue{num: x.num - y.num}
+	case "*":
+		return ruleValue{num: x.num * y.num}
+	default: // "/"
+		return ruleValue{num: x.num / y.num}
+	}
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokEOF ruleTokenKind = iota
+	ruleTokIdent
+	ruleTokNumber
+	ruleTokString
+	ruleTokOp
+	ruleTokError
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+	pos  int
+}
+
+func (t ruleToken) String() string {
+	if t.kind == ruleTokEOF {
+		return "end of rule"
+	}
+	return strconv.Quote(t.text)
+}
+
+type ruleParser struct {
+	src    string
+	offset int
+	tok    ruleToken
+}
+
+// ruleBinaryPrecedence returns 0 for the tokens that are not binary operators.
+func ruleBinaryPrecedence(op string) int {
+	switch op {
+	case "||":
+		return 1
+	case "&&":
+		return 2
+	case "==", "!=":
+		return 3
+	case "<", "<=", ">", ">=":
+		return 4
+	case "+", "-":
+		return 5
+	case "*", "/":
+		return 6
+	default:
+		return 0
+	}
+}
+
+func (p *ruleParser) next() {
+	for p.offset < len(p.src) && (p.src[p.offset] == ' ' || p.src[p.offset] == '\t' || p.src[p.offset] == '\n') {
+		p.offset++
+	}
+	start := p.offset
+	if p.offset >= len(p.src) {
+		p.tok = ruleToken{kind: ruleTokEOF, pos: start}
+		return
+	}
+
+	isIdentChar := func(ch byte) bool {
+		return ch == '_' || ch == '.' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+	}
+	isDigit := func(ch byte) bool {
+		return ch >= '0' && ch <= '9'
+	}
+
+	ch := p.src[p.offset]
+	switch {
+	case isDigit(ch):
+		for p.offset < len(p.src) && (isDigit(p.src[p.offset]) || p.src[p.offset] == '.') {
+			p.offset++
+		}
+		p.tok = ruleToken{kind: ruleTokNumber, text: p.src[start:p.offset], pos: start}
+	case isIdentChar(ch):
+		for p.offset < len(p.src) && isIdentChar(p.src[p.offset]) {
+			p.offset++
+		}
+		p.tok = ruleToken{kind: ruleTokIdent, text: p.src[start:p.offset], pos: start}
+	case ch == '"':
+		end := strings.IndexByte(p.src[start+1:], '"')
+		if end == -1 {
+			p.tok = ruleToken{kind: ruleTokError, text: p.src[start:], pos: start}
+			p.offset = len(p.src)
+			return
+		}
+		p.offset = start + 1 + end + 1
+		p.tok = ruleToken{kind: ruleTokString, text: p.src[start+1 : p.offset-1], pos: start}
+	default:
+		for _, op := range [...]string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"} {
+			if strings.HasPrefix(p.src[start:], op) {
+				p.offset += len(op)
+				p.tok = ruleToken{kind: ruleTokOp, text: op, pos: start}
+				return
+			}
+		}
+		p.offset++
+		p.tok = ruleToken{kind: ruleTokError, text: p.src[start:p.offset], pos: start}
+	}
+}
+
+func (p *ruleParser) parseExpr(minPrecedence int) (ruleExpr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.tok.kind != ruleTokOp {
+			return x, nil
+		}
+		op := p.tok.text
+		precedence := ruleBinaryPrecedence(op)
+		if precedence == 0 || precedence <= minPrecedence {
+			return x, nil
+		}
+		pos := p.tok.pos
+		p.next()
+		y, err := p.parseExpr(precedence)
+		if err != nil {
+			return nil, err
+		}
+		x, err = newRuleBinaryExpr(op, x, y)
+		if err != nil {
+			return nil, fmt.Errorf("%s at %d: %w", op, pos, err)
+		}
+	}
+}
+
+func newRuleBinaryExpr(op string, x, y ruleExpr) (ruleExpr, error) {
+	if x.kind() != y.kind() {
+		return nil, fmt.Errorf("mismatching operand types: %s and %s", x.kind(), y.kind())
+	}
+	e := &ruleBinaryExpr{op: op, x: x, y: y}
+	switch op {
+	case "&&", "||":
+		if x.kind() != ruleBool {
+			return nil, fmt.Errorf("expected bool operands, found %s", x.kind())
+		}
+		e.k = ruleBool
+	case "==", "!=":
+		e.k = ruleBool
+	case "<", "<=", ">", ">=":
+		if x.kind() != ruleNumber {
+			return nil, fmt.Errorf("expected number operands, found %s", x.kind())
+		}
+		e.k = ruleBool
+	default:
+		if x.kind() != ruleNumber {
+			return nil, fmt.Errorf("expected number operands, found %s", x.kind())
+		}
+		e.k = ruleNumber
+	}
+	return e, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleExpr, error) {
+	tok := p.tok
+	switch tok.kind {
+	case ruleTokNumber:
+		p.next()
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", tok, tok.pos)
+		}
+		return &ruleLiteral{k: ruleNumber, v: ruleValue{num: v}}, nil
+
+	case ruleTokString:
+		p.next()
+		return &ruleLiteral{k: ruleString, v: ruleValue{s: tok.text}}, nil
+
+	case ruleTokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			return &ruleLiteral{k: ruleBool, v: ruleValue{b: tok.text == "true"}}, nil
+		}
+		f := achievementRuleFields[tok.text]
+		if f == nil {
+			return nil, fmt.Errorf("unknown field %s at %d", tok, tok.pos)
+		}
+		return &ruleFieldExpr{field: f}, nil
+
+	case ruleTokOp:
+		switch tok.text {
+		case "(":
+			p.next()
+			x, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			if p.tok.kind != ruleTokOp || p.tok.text != ")" {
+				return nil, fmt.Errorf("expected \")\", found %s at %d", p.tok, p.tok.pos)
+			}
+			p.next()
+			return x, nil
+		case "!", "-":
+			p.next()
+			x, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			want := ruleBool
+			if tok.text == "-" {
+				want = ruleNumber
+			}
+			if x.kind() != want {
+				return nil, fmt.Errorf("%s at %d: expected %s operand, found %s", tok.text, tok.pos, want, x.kind())
+			}
+			return &ruleUnaryExpr{op: tok.text, x: x}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %s at %d", tok, tok.pos)
+}
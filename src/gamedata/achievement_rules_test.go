@@ -0,0 +1,94 @@
+package gamedata
+
+import (
+	"testing"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+func TestAchievementRuleEval(t *testing.T) {
+	config := serverapi.ReplayLevelConfig{
+		RawGameMode:  "classic",
+		CoreDesign:   "tank",
+		TurretDesign: "BeamTower",
+		Tier2Recipes: []string{"Repair"},
+		FogOfWar:     true,
+	}
+	results := AchievementResults{
+		Victory:           true,
+		TimePlayed:        10 * 60,
+		Ticks:             1000,
+		FastforwardTicks:  900,
+		SeedKind:          SeedLeet,
+		DifficultyScore:   210,
+		FactionsUsed:      3,
+		EnemyColonyDamage: 100,
+	}
+	ctx := &AchievementContext{
+		Config:       &config,
+		Results:      &results,
+		NumVictories: 10,
+		Roll:         0.5,
+	}
+
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{`true`, true},
+		{`!true`, false},
+		{`result.victory`, true},
+		{`!result.victory || false`, false},
+		{`config.mode == "classic"`, true},
+		{`config.mode != "classic"`, false},
+		{`result.seed_kind == "leet"`, true},
+		{`result.time_played < 15*60`, true},
+		{`result.time_played >= 2*60*60`, false},
+		{`result.fastforward_ticks/result.ticks >= 0.85`, true},
+		{`result.factions_used < 4`, true},
+		{`-result.difficulty_score < -200`, true},
+		{`(1 + 2) * 3 == 9`, true},
+		{`1 + 2 * 3 == 7`, true},
+		{`stats.num_victories >= 64 || roll < 0.02`, false},
+		{`config.fog_of_war && config.world_shape != 0`, false},
+		{`result.enemy_colony_damage_from_turrets >= result.enemy_colony_damage*0.25`, false},
+		{`config.num_tier2_recipes <= 1 && config.core_design == "tank"`, true},
+	}
+
+	for _, test := range tests {
+		rule, err := CompileAchievementRule(test.rule)
+		if err != nil {
+			t.Fatalf("compile %q: %v", test.rule, err)
+		}
+		if have := rule.Eval(ctx); have != test.want {
+			t.Fatalf("eval %q:\nhave: %v\nwant: %v", test.rule, have, test.want)
+		}
+	}
+}
+
+func TestAchievementRuleErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`1`,
+		`result.time_played`,
+		`config.mode`,
+		`unknown.field`,
+		`result.victory &&`,
+		`(result.victory`,
+		`result.victory)`,
+		`!result.time_played`,
+		`-result.victory`,
+		`config.mode == 1`,
+		`result.victory < true`,
+		`result.time_played && true`,
+		`config.mode + "x" == "x"`,
+		`config.mode == "unterminated`,
+		`result.victory # true`,
+	}
+
+	for _, rule := range tests {
+		if _, err := CompileAchievementRule(rule); err == nil {
+			t.Fatalf("compile %q: expected an error", rule)
+		}
+	}
+}
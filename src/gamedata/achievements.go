@@ -11,6 +11,12 @@ type Achievement struct {
 	Icon         resource.ImageID
 	OnlyElite    bool
 	NeedsVictory bool
+
+	// Rule is an unlock condition expression, see AchievementRule.
+	// An empty rule means that this achievement is unlocked by some other means.
+	Rule string
+
+	compiledRule *AchievementRule
 }
 
 type Mode int
@@ -21,6 +27,7 @@ const (
 	ModeInfArena
 	ModeReverse
 	ModeBlitz
+	ModeCustom
 
 	ModeTutorial
 
@@ -41,6 +48,8 @@ func (m Mode) String() string {
 		return "reverse"
 	case ModeBlitz:
 		return "blitz"
+	case ModeCustom:
+		return "custom"
 	case ModeTutorial:
 		return "tutorial"
 	default:
@@ -55,42 +64,49 @@ var AchievementList = []*Achievement{
 		Mode:      ModeAny,
 		Icon:      assets.ImageAchievementT3Engineer,
 		OnlyElite: true,
+		Rule:      `stats.tier3_drones_seen >= tier3_recipes && config.mode != "reverse"`,
 	},
 	{
 		Name:         "trample",
 		Mode:         ModeAny,
 		Icon:         assets.ImageAchievementTrample,
 		NeedsVictory: true,
+		Rule:         `result.creeps_stomped != 0 && config.mode != "reverse"`,
 	},
 	{
 		Name:         "nopeeking",
 		Mode:         ModeAny,
 		Icon:         assets.ImageAchievementNoPeeking,
 		NeedsVictory: true,
+		Rule:         `!result.opened_evolution_tab && config.mode != "reverse"`,
 	},
 	{
 		Name:         "nonstop",
 		Mode:         ModeAny,
 		Icon:         assets.ImageAchievementNonstop,
 		NeedsVictory: true,
+		Rule:         `!result.paused && config.mode != "reverse"`,
 	},
 	{
 		Name:         "darkness",
 		Mode:         ModeAny,
 		Icon:         assets.ImageAchievementDarkness,
 		NeedsVictory: true,
+		Rule:         `config.world_shape != 0 && config.fog_of_war`,
 	},
 	{
 		Name:         "fastforward",
 		Mode:         ModeAny,
 		Icon:         assets.ImageAchievementFastforward,
 		NeedsVictory: true,
+		Rule:         `config.game_speed == 3 && result.fastforward_ticks/result.ticks >= 0.85`,
 	},
 	{
 		Name:         "lucky",
 		Mode:         ModeAny,
 		Icon:         assets.ImageAchievementLucky,
 		NeedsVictory: true,
+		Rule:         `stats.num_victories >= 64 || roll < 0.02`,
 	},
 
 	// Classic mode achievements.
@@ -100,78 +116,91 @@ var AchievementList = []*Achievement{
 		Icon:         assets.ImageAchievementImpossible,
 		OnlyElite:    true,
 		NeedsVictory: true,
+		Rule:         `result.difficulty_score > 200`,
 	},
 	{
 		Name:         "cheapbuild10",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementCheapBuild10,
 		NeedsVictory: true,
+		Rule:         `result.drone_points_allocated <= 10`,
 	},
 	{
 		Name:         "hightension",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementHighTension,
 		NeedsVictory: true,
+		Rule:         `config.world_size == 0 && config.num_creep_bases != 0 && result.creep_bases_destroyed == 0`,
 	},
 	{
 		Name:         "solobase",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementSoloBase,
 		NeedsVictory: true,
+		Rule:         `result.colonies_built == 0`,
 	},
 	{
 		Name:         "uiless",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementUILess,
 		NeedsVictory: true,
+		Rule:         `config.interface_mode == 0`,
 	},
 	{
 		Name:         "powerof3",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementPowerOf3,
 		NeedsVictory: true,
+		Rule:         `result.factions_used < 4`,
 	},
 	{
 		Name:         "tinyradius",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementTinyRadius,
 		NeedsVictory: true,
+		Rule:         `result.radius_increases == 0`,
 	},
 	{
 		Name:         "t1army",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementT1Army,
 		NeedsVictory: true,
+		Rule:         `result.only_tier1_military`,
 	},
 	{
 		Name:         "groundwin",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementGroundWin,
 		NeedsVictory: true,
+		Rule:         `result.ground_boss_defeat`,
 	},
 	{
 		Name:         "speedrunning",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementSpeedrunning,
 		NeedsVictory: true,
+		Rule:         `result.time_played < 15*60`,
 	},
 	{
 		Name:         "victorydrag",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementVictoryDrag,
 		NeedsVictory: true,
+		Rule:         `result.time_played >= 2*60*60`,
 	},
 	{
 		Name:         "t3less",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementT3Less,
 		NeedsVictory: true,
+		Rule:         `result.t3_created == 0`,
 	},
 	{
 		Name:         "turretdamage",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementTurretDamage,
 		NeedsVictory: true,
+		Rule:         `result.enemy_colony_damage_from_turrets >= result.enemy_colony_damage*0.25`,
 	},
 	{
 		Name:         "cheese",
@@ -179,12 +208,14 @@ var AchievementList = []*Achievement{
 		Icon:         assets.ImageAchievementCheese,
 		NeedsVictory: true,
 		OnlyElite:    true,
+		Rule:         `config.num_tier2_recipes <= 1 && config.turret_design == "BeamTower" && config.core_design == "tank" && config.boss_difficulty < 3 && result.difficulty_score >= 200 && result.time_played < 20*60`,
 	},
 	{
 		Name:         "leet",
 		Mode:         ModeClassic,
 		Icon:         assets.ImageAchievementLeet,
 		NeedsVictory: true,
+		Rule:         `result.seed_kind == "leet"`,
 	},
 
 	// Arena mode achievements.
@@ -193,18 +224,21 @@ var AchievementList = []*Achievement{
 		Mode:         ModeArena,
 		Icon:         assets.ImageAchievementAntiDominator,
 		NeedsVictory: true,
+		Rule:         `result.dominators_survived == 0`,
 	},
 	{
 		Name:         "quicksilver",
 		Mode:         ModeArena,
 		Icon:         assets.ImageAchievementQuicksilver,
 		NeedsVictory: true,
+		Rule:         `config.grenadier_creeps && !result.grenadier_colony_hit && config.core_design != "ark"`,
 	},
 	{
 		Name:         "infernal",
 		Mode:         ModeArena,
 		Icon:         assets.ImageAchievementInfernal,
 		NeedsVictory: true,
+		Rule:         `result.seed_kind == "infernal"`,
 	},
 
 	// Infinite arena mode achievements.
@@ -212,6 +246,7 @@ var AchievementList = []*Achievement{
 		Name: "infinite",
 		Mode: ModeInfArena,
 		Icon: assets.ImageAchievementInfinite,
+		Rule: `result.arena_level >= 35`,
 	},
 
 	// Reverse mode achievements.
@@ -220,30 +255,35 @@ var AchievementList = []*Achievement{
 		Mode:         ModeReverse,
 		Icon:         assets.ImageAchievementColonyHunter,
 		NeedsVictory: true,
+		Rule:         `result.colonies_built >= 3`,
 	},
 	{
 		Name:         "groundcontrol",
 		Mode:         ModeReverse,
 		Icon:         assets.ImageAchievementGroundControl,
 		NeedsVictory: true,
+		Rule:         `result.ground_control`,
 	},
 	{
 		Name:         "atomicfinisher",
 		Mode:         ModeReverse,
 		Icon:         assets.ImageAchievementAtomicFinisher,
 		NeedsVictory: true,
+		Rule:         `result.atomic_bomb_victory`,
 	},
 	{
 		Name:         "coordinator",
 		Mode:         ModeReverse,
 		Icon:         assets.ImageAchievementCoordinator,
 		NeedsVictory: true,
+		Rule:         `result.coordinator_rally_used`,
 	},
 	{
 		Name:         "siege",
 		Mode:         ModeReverse,
 		Icon:         assets.ImageAchievementSiege,
 		NeedsVictory: true,
+		Rule:         `!result.atomic_bomb_used && config.core_design == "hive"`,
 	},
 
 	// Other achievements.
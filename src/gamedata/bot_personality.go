@@ -0,0 +1,160 @@
+package gamedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/quasilyte/roboden-game/assets"
+)
+
+// DefaultBotPersonality is used when the replay config doesn't specify it.
+// It doesn't change the computer player behavior in any way,
+// so older replays are simulated exactly as they were recorded.
+const DefaultBotPersonality = "balanced"
+
+// MaxBotPersonalities is a max number of personalities in the level config.
+// There can be up to 2 bot-controlled players.
+const MaxBotPersonalities = 2
+
+// BotPersonality describes the computer player behavior tweaks.
+//
+// All multipliers are applied on top of the core-dependent defaults
+// that are hardcoded inside the computer player implementation.
+// A zero multiplier in the data file means "keep the default" (x1.0).
+type BotPersonality struct {
+	Name string `json:"name"`
+
+	MaxColoniesBonus int `json:"max_colonies_bonus"`
+	MaxTurretsBonus  int `json:"max_turrets_bonus"`
+
+	ColonyTargetRadiusMultiplier float64 `json:"colony_target_radius"`
+	TurretCostMultiplier         float64 `json:"turret_cost"`
+
+	BuildColonyDelayMultiplier float64 `json:"build_colony_delay"`
+	BuildTurretDelayMultiplier float64 `json:"build_turret_delay"`
+	CaptureDelayMultiplier     float64 `json:"capture_delay"`
+	AttackDelayMultiplier      float64 `json:"attack_delay"`
+
+	EvolutionChanceBonus float64 `json:"evolution_chance_bonus"`
+}
+
+// ParseBotPersonalities decodes the personalities data file.
+// The first personality in the list should be the DefaultBotPersonality.
+func ParseBotPersonalities(data []byte) ([]*BotPersonality, error) {
+	var list []*BotPersonality
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 || list[0].Name != DefaultBotPersonality {
+		return nil, fmt.Errorf("the first bot personality should be %q", DefaultBotPersonality)
+	}
+
+	names := make(map[string]struct{}, len(list))
+	for _, p := range list {
+		if p.Name == "" {
+			return nil, errors.New("found a bot personality without a name")
+		}
+		if _, ok := names[p.Name]; ok {
+			return nil, fmt.Errorf("duplicated %q bot personality", p.Name)
+		}
+		names[p.Name] = struct{}{}
+
+		multipliers := [...]*float64{
+			&p.ColonyTargetRadiusMultiplier,
+			&p.TurretCostMultiplier,
+			&p.BuildColonyDelayMultiplier,
+			&p.BuildTurretDelayMultiplier,
+			&p.CaptureDelayMultiplier,
+			&p.AttackDelayMultiplier,
+		}
+		for _, m := range multipliers {
+			if *m < 0 {
+				return nil, fmt.Errorf("%q bot personality: negative multipliers are not allowed", p.Name)
+			}
+			if *m == 0 {
+				*m = 1
+			}
+		}
+	}
+
+	return list, nil
+}
+
+// FindBotPersonality returns a personality with the specified name.
+// An empty name is resolved to the DefaultBotPersonality.
+func FindBotPersonality(list []*BotPersonality, name string) *BotPersonality {
+	if name == "" {
+		name = DefaultBotPersonality
+	}
+	for _, p := range list {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+var botPersonalities struct {
+	once sync.Once
+	list []*BotPersonality
+	err  error
+}
+
+// BotPersonalities returns the bundled personalities data file contents.
+// The file is parsed only once; the returned list is shared and should not be modified.
+//
+// It works without a game context, so it can be used by the validation code too.
+func BotPersonalities() ([]*BotPersonality, error) {
+	botPersonalities.once.Do(func() {
+		data, err := assets.ReadEmbeddedFile("raw/bot_personalities.json")
+		if err != nil {
+			botPersonalities.err = err
+			return
+		}
+		botPersonalities.list, botPersonalities.err = ParseBotPersonalities(data)
+	})
+	return botPersonalities.list, botPersonalities.err
+}
+
+// ResolveBotPersonality is like FindBotPersonality, but it never returns nil.
+// Unknown names are resolved to the DefaultBotPersonality.
+//
+// The validated configs can't have unknown personalities,
+// but the local saves and the share codes are not always validated.
+func ResolveBotPersonality(name string) *BotPersonality {
+	list, err := BotPersonalities()
+	if err == nil {
+		if p := FindBotPersonality(list, name); p != nil {
+			return p
+		}
+		return list[0]
+	}
+	// The default personality doesn't change anything,
+	// so it can be restored even without the data file.
+	return &BotPersonality{
+		Name:                         DefaultBotPersonality,
+		ColonyTargetRadiusMultiplier: 1,
+		TurretCostMultiplier:         1,
+		BuildColonyDelayMultiplier:   1,
+		BuildTurretDelayMultiplier:   1,
+		CaptureDelayMultiplier:       1,
+		AttackDelayMultiplier:        1,
+	}
+}
+
+// IsValidBotPersonality reports whether the name refers to a known bot personality.
+// An empty name is valid: it's resolved to the DefaultBotPersonality.
+//
+// The bundled personalities data file is used, so it works without a game context.
+func IsValidBotPersonality(name string) bool {
+	if name == "" {
+		return true
+	}
+	list, err := BotPersonalities()
+	if err != nil {
+		return false
+	}
+	return FindBotPersonality(list, name) != nil
+}
@@ -0,0 +1,31 @@
+package gamedata
+
+import (
+	"testing"
+)
+
+func TestBotPersonalities(t *testing.T) {
+	list, err := BotPersonalities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range list {
+		if ResolveBotPersonality(p.Name) != p {
+			t.Fatalf("%s: resolved to a different personality", p.Name)
+		}
+		if !IsValidBotPersonality(p.Name) {
+			t.Fatalf("%s: reported as invalid", p.Name)
+		}
+	}
+
+	for _, name := range []string{"", "unknown"} {
+		p := ResolveBotPersonality(name)
+		if p.Name != DefaultBotPersonality {
+			t.Fatalf("%q: resolved to %q, want %q", name, p.Name, DefaultBotPersonality)
+		}
+	}
+	if IsValidBotPersonality("unknown") {
+		t.Fatal("unknown personality is reported as valid")
+	}
+}
@@ -0,0 +1,161 @@
+package gamedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// MaxCampaignStars is a number of stars for a perfect mission run.
+// The victory gives the first star, the rest are given for
+// completing the mission faster than its star times.
+const MaxCampaignStars = 3
+
+// CampaignMission is one of the ordered campaign levels.
+//
+// Every mission is played as a custom mode game,
+// so its replays are validated just like any other custom mode replay.
+type CampaignMission struct {
+	// Name is used as a progress key and as a dictionary key prefix:
+	// "campaign.<name>" is a title and "campaign.<name>.briefing" is a briefing.
+	Name string `json:"name"`
+
+	// Config is a mission level config.
+	// Its game mode and custom rules are filled by ParseCampaign.
+	Config serverapi.ReplayLevelConfig `json:"config"`
+
+	Objectives     []string `json:"objectives"`
+	StartingDrones []string `json:"starting_drones"`
+	ColoniesGoal   int      `json:"colonies_goal"`
+	TimeLimit      int      `json:"time_limit"`
+	WaveSchedule   string   `json:"wave_schedule"`
+
+	// StarTimes are the time thresholds (in seconds) for the extra stars.
+	StarTimes []int `json:"star_times"`
+
+	Unlocks CampaignUnlocks `json:"unlocks"`
+}
+
+// CampaignUnlocks is a content that becomes available after the mission is completed.
+type CampaignUnlocks struct {
+	Cores   []string `json:"cores"`
+	Drones  []string `json:"drones"`
+	Turrets []string `json:"turrets"`
+}
+
+// MaxStars returns the number of stars for a perfect mission run.
+func (m *CampaignMission) MaxStars() int {
+	return 1 + len(m.StarTimes)
+}
+
+// CalcStars returns the number of stars earned by a mission victory.
+func (m *CampaignMission) CalcStars(secondsPlayed int) int {
+	stars := 1
+	for _, t := range m.StarTimes {
+		if secondsPlayed <= t {
+			stars++
+		}
+	}
+	return stars
+}
+
+// ParseCampaign decodes the campaign data file.
+// The mission wave schedules are resolved using the provided schedules list.
+func ParseCampaign(data []byte, schedules []*CustomWaveSchedule) ([]*CampaignMission, error) {
+	var list []*CampaignMission
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, errors.New("empty campaign missions list")
+	}
+
+	names := make(map[string]struct{}, len(list))
+	for _, m := range list {
+		if m.Name == "" {
+			return nil, errors.New("found a campaign mission without a name")
+		}
+		if _, ok := names[m.Name]; ok {
+			return nil, fmt.Errorf("duplicated %q campaign mission", m.Name)
+		}
+		names[m.Name] = struct{}{}
+		if err := initCampaignMission(m, schedules); err != nil {
+			return nil, fmt.Errorf("%q campaign mission: %w", m.Name, err)
+		}
+	}
+
+	return list, nil
+}
+
+func initCampaignMission(m *CampaignMission, schedules []*CustomWaveSchedule) error {
+	if m.Config.RawGameMode != "" && m.Config.RawGameMode != "custom" {
+		return errors.New("campaign missions can only use the custom mode")
+	}
+	if m.Config.PlayersMode != serverapi.PmodeSinglePlayer {
+		return errors.New("campaign missions are single player only")
+	}
+	if m.Config.Seed <= 0 {
+		return errors.New("campaign missions require a positive seed")
+	}
+
+	rules := &serverapi.CustomModeRules{
+		Objectives:     m.Objectives,
+		StartingDrones: m.StartingDrones,
+		ColoniesGoal:   m.ColoniesGoal,
+		TimeLimit:      m.TimeLimit,
+		WaveSchedule:   m.WaveSchedule,
+	}
+	if m.WaveSchedule != "" {
+		scheduleIndex := xslices.IndexWhere(schedules, func(schedule *CustomWaveSchedule) bool {
+			return schedule.Name == m.WaveSchedule
+		})
+		if scheduleIndex == -1 {
+			return fmt.Errorf("unknown %q wave schedule", m.WaveSchedule)
+		}
+		rules.Waves = schedules[scheduleIndex].Waves
+	}
+	m.Config.RawGameMode = "custom"
+	m.Config.CustomRules = CloneCustomRules(rules)
+	m.Config.DronePointsAllocated = CalcAllocatedPoints(m.Config.Tier2Recipes)
+	m.Config.DifficultyScore = CalcDifficultyScore(m.Config, m.Config.DronePointsAllocated)
+
+	if err := ValidateCustomRules(m.Config.CustomRules); err != nil {
+		return err
+	}
+	if !IsValidLevelConfig(&m.Config) {
+		return errors.New("invalid level config")
+	}
+
+	if len(m.StarTimes) >= MaxCampaignStars {
+		return errors.New("too many star times")
+	}
+	for i, t := range m.StarTimes {
+		if t <= 0 {
+			return errors.New("star times should be positive")
+		}
+		if i != 0 && t >= m.StarTimes[i-1] {
+			return errors.New("star times should be sorted in descending order")
+		}
+	}
+
+	for _, name := range m.Unlocks.Cores {
+		if !xslices.Any(CoreStatsList, func(core *ColonyCoreStats) bool { return core.Name == name }) {
+			return fmt.Errorf("unknown %q core unlock", name)
+		}
+	}
+	for _, name := range m.Unlocks.Drones {
+		if !xslices.Any(Tier2agentMergeRecipes, func(r AgentMergeRecipe) bool { return r.Result.Kind.String() == name }) {
+			return fmt.Errorf("unknown %q drone unlock", name)
+		}
+	}
+	for _, name := range m.Unlocks.Turrets {
+		if !xslices.Any(TurretStatsList, func(turret *AgentStats) bool { return turret.Kind.String() == name }) {
+			return fmt.Errorf("unknown %q turret unlock", name)
+		}
+	}
+
+	return nil
+}
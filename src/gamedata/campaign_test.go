@@ -0,0 +1,33 @@
+package gamedata
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCampaignData(t *testing.T) {
+	wavesData, err := os.ReadFile("../assets/_data/raw/custom_waves.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedules, err := ParseCustomWaveSchedules(wavesData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	campaignData, err := os.ReadFile("../assets/_data/raw/campaign.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	missions, err := ParseCampaign(campaignData, schedules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range missions {
+		if m.Config.RawGameMode != "custom" || m.Config.CustomRules == nil {
+			t.Fatalf("%s: mission is not initialized", m.Name)
+		}
+		if m.CalcStars(0) != m.MaxStars() {
+			t.Fatalf("%s: a fast victory should give all stars", m.Name)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package gamedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+const (
+	MaxCustomModeWaves     = 64
+	MaxCustomModeTimeLimit = 4 * 60 * 60
+
+	MaxCustomModeStartingDrones = 12
+)
+
+// CustomWaveCreeps maps the custom mode wave creep names to their stats.
+var CustomWaveCreeps = map[string]*CreepStats{
+	"crawler":         CrawlerCreepStats,
+	"elite_crawler":   EliteCrawlerCreepStats,
+	"stealth_crawler": StealthCrawlerCreepStats,
+	"heavy_crawler":   HeavyCrawlerCreepStats,
+	"wanderer":        WandererCreepStats,
+	"stunner":         StunnerCreepStats,
+	"assault":         AssaultCreepStats,
+	"builder":         BuilderCreepStats,
+	"templar":         TemplarCreepStats,
+	"grenadier":       GrenadierCreepStats,
+	"howitzer":        HowitzerCreepStats,
+	"servant":         ServantCreepStats,
+	"dominator":       DominatorCreepStats,
+}
+
+// CustomWaveSchedule is a named list of waves that can be
+// selected in the custom mode lobby.
+type CustomWaveSchedule struct {
+	Name  string                     `json:"name"`
+	Waves []serverapi.CustomModeWave `json:"waves"`
+}
+
+// ParseCustomWaveSchedules decodes the custom mode wave schedules data file.
+func ParseCustomWaveSchedules(data []byte) ([]*CustomWaveSchedule, error) {
+	var list []*CustomWaveSchedule
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, errors.New("empty wave schedules list")
+	}
+
+	names := make(map[string]struct{}, len(list))
+	for _, schedule := range list {
+		if schedule.Name == "" {
+			return nil, errors.New("found a wave schedule without a name")
+		}
+		if _, ok := names[schedule.Name]; ok {
+			return nil, fmt.Errorf("duplicated %q wave schedule", schedule.Name)
+		}
+		names[schedule.Name] = struct{}{}
+		if err := validateCustomWaves(schedule.Waves); err != nil {
+			return nil, fmt.Errorf("%q wave schedule: %w", schedule.Name, err)
+		}
+	}
+
+	return list, nil
+}
+
+// ValidateCustomRules reports whether the rules can be used to run a game.
+func ValidateCustomRules(rules *serverapi.CustomModeRules) error {
+	if len(rules.Objectives) == 0 {
+		return errors.New("at least one objective is required")
+	}
+	seen := make(map[GameObjective]bool, len(rules.Objectives))
+	for _, name := range rules.Objectives {
+		o, ok := ParseGameObjective(name)
+		if !ok {
+			return fmt.Errorf("unknown objective %q", name)
+		}
+		if seen[o] {
+			return fmt.Errorf("duplicated objective %q", name)
+		}
+		seen[o] = true
+	}
+	if seen[ObjectiveSurvive] && rules.TimeLimit == 0 {
+		return errors.New("survive objective requires a time limit")
+	}
+	if seen[ObjectiveTrigger] && len(rules.Waves) == 0 {
+		return errors.New("trigger objective requires at least one wave")
+	}
+	if rules.TimeLimit < 0 || rules.TimeLimit > MaxCustomModeTimeLimit {
+		return errors.New("invalid time limit")
+	}
+	if rules.ColoniesGoal < 0 || rules.ColoniesGoal > 8 {
+		return errors.New("invalid colonies goal")
+	}
+	if rules.ResourceCap < 0 {
+		return errors.New("invalid resource cap")
+	}
+	if len(rules.StartingDrones) > MaxCustomModeStartingDrones {
+		return errors.New("too many starting drones")
+	}
+	for _, name := range rules.StartingDrones {
+		if FindStartingDroneStats(name) == nil {
+			return fmt.Errorf("unexpected starting drone %q", name)
+		}
+	}
+	return validateCustomWaves(rules.Waves)
+}
+
+func validateCustomWaves(waves []serverapi.CustomModeWave) error {
+	if len(waves) > MaxCustomModeWaves {
+		return errors.New("too many waves")
+	}
+	prevTime := 0
+	for i, w := range waves {
+		if w.Time < prevTime {
+			return fmt.Errorf("wave %d: waves should be sorted by time", i)
+		}
+		prevTime = w.Time
+		if w.Budget <= 0 || w.Budget > 600 {
+			return fmt.Errorf("wave %d: invalid budget", i)
+		}
+		if w.Sides < 1 || w.Sides > 4 {
+			return fmt.Errorf("wave %d: invalid number of sides", i)
+		}
+		if len(w.Creeps) == 0 {
+			return fmt.Errorf("wave %d: empty creeps list", i)
+		}
+		for _, name := range w.Creeps {
+			if CustomWaveCreeps[name] == nil {
+				return fmt.Errorf("wave %d: unknown creep %q", i, name)
+			}
+		}
+	}
+	return nil
+}
+
+// CloneCustomRules returns a deep copy of the rules.
+func CloneCustomRules(rules *serverapi.CustomModeRules) *serverapi.CustomModeRules {
+	cloned := *rules
+	cloned.Objectives = make([]string, len(rules.Objectives))
+	copy(cloned.Objectives, rules.Objectives)
+	if rules.StartingDrones != nil {
+		cloned.StartingDrones = append([]string(nil), rules.StartingDrones...)
+	}
+	if rules.Waves != nil {
+		cloned.Waves = make([]serverapi.CustomModeWave, len(rules.Waves))
+		for i, w := range rules.Waves {
+			w.Creeps = append([]string(nil), w.Creeps...)
+			cloned.Waves[i] = w
+		}
+	}
+	return &cloned
+}
+
+// FindStartingDroneStats returns the stats of a drone that can be
+// used in the custom rules starting drones list.
+// Only the tier 1 and tier 2 drones are allowed there.
+// A nil result means that there is no such drone.
+func FindStartingDroneStats(name string) *AgentStats {
+	switch name {
+	case WorkerAgentStats.Kind.String():
+		return WorkerAgentStats
+	case ScoutAgentStats.Kind.String():
+		return ScoutAgentStats
+	}
+	for _, r := range Tier2agentMergeRecipes {
+		if r.Result.Kind.String() == name {
+			return r.Result
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package gamedata
 
 import (
+	"github.com/quasilyte/ge/xslices"
 	"github.com/quasilyte/gmath"
 	"github.com/quasilyte/roboden-game/serverapi"
 )
@@ -62,8 +63,17 @@ func CalcDifficultyScore(config serverapi.ReplayLevelConfig, pointsAllocated int
 		if config.CoreDesign != "ark" && config.CoreDesign != "hive" {
 			score += 5 - (config.Teleporters * 5)
 		}
+		if config.AdaptiveDifficulty {
+			// The creeps back off when the player is losing.
+			// The final score is adjusted by the actual difficulty level too.
+			score -= 10
+		}
 
 	case "reverse":
+		if IsCreepsBotGame(config) {
+			score += calcCreepsBotDifficultyScore(config)
+			break
+		}
 		score -= (config.BossDifficulty - 2) * 20
 		score += (3 - config.CreepDifficulty) * 15
 		score += (config.DronesPower - 1) * 15
@@ -182,6 +192,9 @@ func CalcDifficultyScore(config serverapi.ReplayLevelConfig, pointsAllocated int
 		if config.CoreDesign != "ark" && config.CoreDesign != "hive" {
 			score += 5 - (config.Teleporters * 5)
 		}
+		if config.AdaptiveDifficulty {
+			score -= 10
+		}
 
 	case "arena", "inf_arena":
 		if config.FogOfWar {
@@ -236,7 +249,84 @@ func CalcDifficultyScore(config serverapi.ReplayLevelConfig, pointsAllocated int
 		if config.CoreDesign != "ark" && config.CoreDesign != "hive" {
 			score += 5 - (config.Teleporters * 5)
 		}
+
+	case "custom":
+		// This is only an estimation: the custom rules
+		// make it hard to compare these games with the other modes.
+		score -= (config.Resources - 2) * 20
+		score += (config.NumCreepBases - 2) * 15
+		score += (config.InitialCreeps - 1) * 15
+		score += (config.CreepDifficulty - 3) * 15
+		score += 20 - (pointsAllocated)
+		if config.StartingResources {
+			score -= 10
+		}
+		if rules := config.CustomRules; rules != nil {
+			if xslices.Contains(rules.Objectives, ObjectiveBoss.String()) {
+				score += (config.BossDifficulty - 1) * 25
+			}
+			totalBudget := 0
+			for _, w := range rules.Waves {
+				totalBudget += w.Budget
+			}
+			score += totalBudget / 20
+			if rules.ResourceCap != 0 {
+				score += 20
+			}
+			if rules.TimeLimit != 0 && !xslices.Contains(rules.Objectives, ObjectiveSurvive.String()) {
+				score += 20
+			}
+		}
+	}
+
+	if config.WeatherEffects && EnvironmentKind(config.Environment) != EnvForest {
+		// The weather mostly hinders the drones.
+		if config.RawGameMode == "reverse" {
+			score -= 5
+		} else {
+			score += 5
+		}
 	}
 
 	return gmath.ClampMin(score, 1)
 }
+
+// calcCreepsBotDifficultyScore returns the reverse mode score terms
+// for the creeps bot games.
+//
+// The human player defends the colony here, so the options that
+// help the dreadnought in the normal reverse mode make the game harder.
+func calcCreepsBotDifficultyScore(config serverapi.ReplayLevelConfig) int {
+	score := 0
+	score += (config.ReverseBotDifficulty - 1) * 25
+	score += (config.BossDifficulty - 2) * 20
+	score -= (3 - config.CreepDifficulty) * 15
+	score -= (config.DronesPower - 1) * 15
+	score += (config.InitialCreeps - 1) * 20
+	score += (config.TechProgressRate - 6) * 10
+	score -= (config.OilRegenRate - 2) * 5
+	score -= (config.Resources - 2) * 20
+	score += (config.ReverseSuperCreepRate - 3) * 15
+	if config.EliteFleet {
+		score -= 20
+	}
+	if config.StartingResources {
+		score -= 20
+	}
+	if config.AtomicBomb {
+		score += 15
+	}
+	if config.CreepFortress {
+		score += 40
+	}
+	if !config.Relicts {
+		score += 10
+	}
+	if config.IonMortars {
+		score += 20
+	}
+	if !config.GoldEnabled {
+		score += 35
+	}
+	return score
+}
@@ -0,0 +1,46 @@
+package gamedata
+
+import (
+	"testing"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+func TestCreepsBotDifficultyScore(t *testing.T) {
+	config := serverapi.ReplayLevelConfig{
+		RawGameMode:           "reverse",
+		PlayersMode:           serverapi.PmodeSinglePlayer,
+		Resources:             2,
+		OilRegenRate:          2,
+		CreepDifficulty:       3,
+		DronesPower:           1,
+		InitialCreeps:         1,
+		TechProgressRate:      6,
+		ReverseSuperCreepRate: 3,
+		BossDifficulty:        2,
+		ReverseBotDifficulty:  1,
+		GoldEnabled:           true,
+		Relicts:               true,
+		AtomicBomb:            true,
+	}
+
+	// The bot difficulty doesn't affect the normal reverse mode score.
+	score := CalcDifficultyScore(config, 0)
+	config.ReverseBotDifficulty = 3
+	if have := CalcDifficultyScore(config, 0); have != score {
+		t.Fatalf("reverse score changed from %d to %d", score, have)
+	}
+
+	config.ReverseCreepsBot = true
+	config.ReverseBotDifficulty = 1
+	normal := CalcDifficultyScore(config, 0)
+	config.ReverseBotDifficulty = 3
+	if brutal := CalcDifficultyScore(config, 0); brutal <= normal {
+		t.Fatalf("brutal creeps bot score %d is not higher than %d", brutal, normal)
+	}
+	config.ReverseBotDifficulty = 1
+	config.DronesPower = 3
+	if stronger := CalcDifficultyScore(config, 0); stronger >= normal {
+		t.Fatalf("stronger drones score %d is not lower than %d", stronger, normal)
+	}
+}
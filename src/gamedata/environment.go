@@ -1,5 +1,12 @@
 package gamedata
 
+import (
+	"fmt"
+
+	resource "github.com/quasilyte/ebitengine-resource"
+	"github.com/quasilyte/roboden-game/assets"
+)
+
 type EnvironmentKind int
 
 const (
@@ -8,3 +15,19 @@ const (
 	EnvMoon
 	EnvSnow
 )
+
+// EnvironmentTileset returns the background tiles image and its tileset description.
+func EnvironmentTileset(env EnvironmentKind) (resource.ImageID, resource.RawID) {
+	switch env {
+	case EnvMoon:
+		return assets.ImageBackgroundTiles, assets.RawTilesJSON
+	case EnvForest:
+		return assets.ImageBackgroundForestTiles, assets.RawForestTilesJSON
+	case EnvInferno:
+		return assets.ImageBackgroundInfernoTiles, assets.RawInfernoTilesJSON
+	case EnvSnow:
+		return assets.ImageBackgroundSnowTiles, assets.RawSnowTilesJSON
+	default:
+		panic(fmt.Sprintf("unexpected environment: %d", env))
+	}
+}
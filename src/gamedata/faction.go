@@ -75,3 +75,52 @@ var (
 		Color: ge.RGB(0x7078db),
 	}
 )
+
+// FactionPalette is a faction colors set.
+// The alternative palettes make the factions distinguishable
+// for the players with the colour vision deficiencies.
+type FactionPalette int
+
+const (
+	FactionPaletteDefault FactionPalette = iota
+
+	// FactionPaletteRedGreen is for the deuteranopia and protanopia.
+	FactionPaletteRedGreen
+
+	// FactionPaletteBlueYellow is for the tritanopia.
+	FactionPaletteBlueYellow
+)
+
+// FactionColor returns the faction color for the given palette.
+// Neutral faction has no color.
+func FactionColor(tag FactionTag, palette FactionPalette) color.RGBA {
+	switch palette {
+	case FactionPaletteRedGreen:
+		return redGreenFactionColors[tag]
+	case FactionPaletteBlueYellow:
+		return blueYellowFactionColors[tag]
+	}
+	if f := FactionByTag(tag); f != nil {
+		return f.Color
+	}
+	return color.RGBA{}
+}
+
+// The colors are indexed by FactionTag.
+var (
+	redGreenFactionColors = [...]color.RGBA{
+		{},
+		ge.RGB(0xf0e442),
+		ge.RGB(0xd55e00),
+		ge.RGB(0x56b4e9),
+		ge.RGB(0xcc79a7),
+	}
+
+	blueYellowFactionColors = [...]color.RGBA{
+		{},
+		ge.RGB(0xfdfdfd),
+		ge.RGB(0xe23c3c),
+		ge.RGB(0x3cc8b4),
+		ge.RGB(0x9a5ccf),
+	}
+)
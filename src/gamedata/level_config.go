@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quasilyte/ge/xslices"
 	"github.com/quasilyte/roboden-game/serverapi"
 )
 
@@ -28,6 +29,35 @@ func (s WorldShape) String() string {
 	}
 }
 
+// WorldDimensions returns the world width and height in pixels.
+func WorldDimensions(worldSize int, shape WorldShape) (width, height float64) {
+	switch worldSize {
+	case 0:
+		width = 1856
+	case 1:
+		width = 2368
+	case 2:
+		width = 2880
+	case 3:
+		width = 3392
+	}
+	height = width
+	switch shape {
+	case WorldHorizontal:
+		width += float64(512 * (worldSize + 1))
+		height = 1088
+	case WorldVertical:
+		width = 1280
+		// Can't have a world width less than a max screen width.
+		// Otherwise that would create a buggy camera experience.
+		if width < MaxDisplayWidth() {
+			panic("new display ratio is added without adjusting the vertical world camera size")
+		}
+		height += float64(512 * (worldSize + 1))
+	}
+	return width, height
+}
+
 type ExecutionMode int
 
 const (
@@ -88,6 +118,16 @@ type LevelConfig struct {
 	EnemyBoss      bool
 
 	ExtraDrones []*AgentStats
+
+	// Mission is set for the campaign games.
+	// The mission rules are already inside the replay config,
+	// this field is used to show the briefing and to record the progress.
+	Mission *CampaignMission
+
+	// Map is set for the map editor test plays.
+	// The map is not a part of the replay config,
+	// so these games are not recorded.
+	Map *LevelMap
 }
 
 func (config *LevelConfig) Finalize() {
@@ -104,16 +144,32 @@ func (config *LevelConfig) Finalize() {
 		config.GameMode = ModeTutorial
 	case "blitz":
 		config.GameMode = ModeBlitz
+	case "custom":
+		config.GameMode = ModeCustom
+		if config.CustomRules == nil {
+			panic("custom game mode requires the custom rules")
+		}
+		config.EnemyBoss = xslices.Contains(config.CustomRules.Objectives, ObjectiveBoss.String())
 	default:
 		panic(fmt.Sprintf("unexpected game mode: %q", config.RawGameMode))
 	}
 
+	if !IsCreepsBotGame(config.ReplayLevelConfig) {
+		// The lobby keeps this option for the other players modes.
+		config.ReverseCreepsBot = false
+	}
+
 	if config.GameMode == ModeTutorial {
 		config.Players = []PlayerKind{PlayerHuman}
 	} else if config.GameMode == ModeReverse {
 		switch config.PlayersMode {
 		case serverapi.PmodeSinglePlayer:
-			config.Players = []PlayerKind{PlayerHuman, PlayerComputer}
+			if IsCreepsBotGame(config.ReplayLevelConfig) {
+				// The bot commands the creeps while the human defends the colony.
+				config.Players = []PlayerKind{PlayerComputer, PlayerHuman}
+			} else {
+				config.Players = []PlayerKind{PlayerHuman, PlayerComputer}
+			}
 		case serverapi.PmodeTwoPlayers:
 			config.Players = []PlayerKind{PlayerHuman, PlayerHuman}
 		default:
@@ -140,11 +196,29 @@ func (config *LevelConfig) Finalize() {
 	config.DifficultyScore = CalcDifficultyScore(config.ReplayLevelConfig, pointsAllocated)
 }
 
+// IsCreepsBotGame reports whether the creeps are commanded by the bot
+// while the human player defends the colony.
+// It's a single player reverse mode variation.
+func IsCreepsBotGame(config serverapi.ReplayLevelConfig) bool {
+	return config.RawGameMode == "reverse" &&
+		config.PlayersMode == serverapi.PmodeSinglePlayer &&
+		config.ReverseCreepsBot
+}
+
 func (config *LevelConfig) Clone() LevelConfig {
 	cloned := *config
 
 	cloned.Tier2Recipes = make([]string, len(config.Tier2Recipes))
 	copy(cloned.Tier2Recipes, config.Tier2Recipes)
 
+	if config.BotPersonalities != nil {
+		cloned.BotPersonalities = make([]string, len(config.BotPersonalities))
+		copy(cloned.BotPersonalities, config.BotPersonalities)
+	}
+
+	if config.CustomRules != nil {
+		cloned.CustomRules = CloneCustomRules(config.CustomRules)
+	}
+
 	return cloned
 }
@@ -0,0 +1,236 @@
+package gamedata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/ge/xslices"
+)
+
+// LevelMapVersion is a current version of the map file format.
+const LevelMapVersion = 1
+
+// LevelMapFileExt is an extension of the map editor files.
+const LevelMapFileExt = ".roboden-map"
+
+// LevelMapResourceKinds lists the resource names that can be placed on a map.
+var LevelMapResourceKinds = []string{
+	"iron",
+	"gold",
+	"crystal",
+	"red_crystal",
+	"oil",
+	"red_oil",
+	"sulfur",
+	"organic",
+	"mineral",
+	"scrap",
+	"artifact",
+}
+
+// LevelMap is a declarative level description created by the map editor.
+//
+// All coordinates are pathing grid cells (32x32 pixels).
+// Everything that is not described by the map (like the background
+// tiles that were not painted) is still generated randomly.
+type LevelMap struct {
+	Version int `json:"version"`
+
+	WorldSize   int `json:"world_size"`
+	WorldShape  int `json:"world_shape"`
+	Environment int `json:"environment"`
+
+	Tiles       []MapTile       `json:"tiles,omitempty"`
+	Walls       []MapCell       `json:"walls,omitempty"`
+	Forests     []MapRect       `json:"forests,omitempty"`
+	Resources   []MapResource   `json:"resources,omitempty"`
+	Teleporters []MapTeleporter `json:"teleporters,omitempty"`
+	CreepBases  []MapCell       `json:"creep_bases,omitempty"`
+
+	// SpawnPoints are the colony starting locations.
+	// The first one is used for the first player and so on.
+	SpawnPoints []MapCell `json:"spawn_points"`
+}
+
+type MapCell struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type MapTile struct {
+	MapCell
+	Frame int `json:"frame"`
+}
+
+type MapRect struct {
+	MapCell
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Contains reports whether the cell is inside the rect.
+func (r MapRect) Contains(c MapCell) bool {
+	return c.X >= r.X && c.X < r.X+r.Width &&
+		c.Y >= r.Y && c.Y < r.Y+r.Height
+}
+
+type MapResource struct {
+	MapCell
+	Kind string `json:"kind"`
+}
+
+type MapTeleporter struct {
+	From MapCell `json:"from"`
+	To   MapCell `json:"to"`
+}
+
+// NewLevelMap returns an empty map with the specified world settings.
+func NewLevelMap(worldSize int, shape WorldShape, env EnvironmentKind) *LevelMap {
+	return &LevelMap{
+		Version:     LevelMapVersion,
+		WorldSize:   worldSize,
+		WorldShape:  int(shape),
+		Environment: int(env),
+	}
+}
+
+// GridSize returns the map size in cells.
+func (m *LevelMap) GridSize() (numCols, numRows int) {
+	width, height := WorldDimensions(m.WorldSize, WorldShape(m.WorldShape))
+	return int(width / 32), int(height / 32)
+}
+
+// InBounds reports whether the cell is inside the map.
+func (m *LevelMap) InBounds(c MapCell) bool {
+	numCols, numRows := m.GridSize()
+	return c.X >= 0 && c.X < numCols && c.Y >= 0 && c.Y < numRows
+}
+
+// Crop removes all map objects that are outside of the map bounds.
+// It's used after the world size or shape is changed.
+func (m *LevelMap) Crop() {
+	outOfBounds := func(c MapCell) bool { return !m.InBounds(c) }
+	m.Tiles = xslices.RemoveIf(m.Tiles, func(t MapTile) bool {
+		return outOfBounds(t.MapCell)
+	})
+	m.Walls = xslices.RemoveIf(m.Walls, outOfBounds)
+	m.Forests = xslices.RemoveIf(m.Forests, func(r MapRect) bool {
+		return outOfBounds(r.MapCell) || outOfBounds(MapCell{X: r.X + r.Width - 1, Y: r.Y + r.Height - 1})
+	})
+	m.Resources = xslices.RemoveIf(m.Resources, func(r MapResource) bool {
+		return outOfBounds(r.MapCell)
+	})
+	m.Teleporters = xslices.RemoveIf(m.Teleporters, func(tp MapTeleporter) bool {
+		return outOfBounds(tp.From) || outOfBounds(tp.To)
+	})
+	m.CreepBases = xslices.RemoveIf(m.CreepBases, outOfBounds)
+	m.SpawnPoints = xslices.RemoveIf(m.SpawnPoints, outOfBounds)
+}
+
+// Clone returns a deep copy of the map.
+func (m *LevelMap) Clone() *LevelMap {
+	cloned := *m
+	cloned.Tiles = append([]MapTile(nil), m.Tiles...)
+	cloned.Walls = append([]MapCell(nil), m.Walls...)
+	cloned.Forests = append([]MapRect(nil), m.Forests...)
+	cloned.Resources = append([]MapResource(nil), m.Resources...)
+	cloned.Teleporters = append([]MapTeleporter(nil), m.Teleporters...)
+	cloned.CreepBases = append([]MapCell(nil), m.CreepBases...)
+	cloned.SpawnPoints = append([]MapCell(nil), m.SpawnPoints...)
+	return &cloned
+}
+
+// Validate reports the first problem that makes the map unplayable.
+func (m *LevelMap) Validate() error {
+	if err := m.validateSettings(); err != nil {
+		return err
+	}
+
+	if len(m.SpawnPoints) == 0 {
+		return errors.New("the map has no spawn points")
+	}
+
+	checkCells := func(kind string, cells []MapCell) error {
+		for _, c := range cells {
+			if !m.InBounds(c) {
+				return fmt.Errorf("%s at %d,%d is out of bounds", kind, c.X, c.Y)
+			}
+		}
+		return nil
+	}
+	if err := checkCells("spawn point", m.SpawnPoints); err != nil {
+		return err
+	}
+	if err := checkCells("wall", m.Walls); err != nil {
+		return err
+	}
+	if err := checkCells("creep base", m.CreepBases); err != nil {
+		return err
+	}
+	for _, t := range m.Tiles {
+		if err := checkCells("tile", []MapCell{t.MapCell}); err != nil {
+			return err
+		}
+		if t.Frame < 0 {
+			return fmt.Errorf("tile at %d,%d has invalid frame %d", t.X, t.Y, t.Frame)
+		}
+	}
+	for _, r := range m.Forests {
+		if r.Width < 1 || r.Height < 1 {
+			return fmt.Errorf("forest at %d,%d has invalid size", r.X, r.Y)
+		}
+		corner := MapCell{X: r.X + r.Width - 1, Y: r.Y + r.Height - 1}
+		if err := checkCells("forest", []MapCell{r.MapCell, corner}); err != nil {
+			return err
+		}
+	}
+	for _, r := range m.Resources {
+		if !xslices.Contains(LevelMapResourceKinds, r.Kind) {
+			return fmt.Errorf("resource at %d,%d has unknown kind %q", r.X, r.Y, r.Kind)
+		}
+		if err := checkCells("resource", []MapCell{r.MapCell}); err != nil {
+			return err
+		}
+	}
+	for _, tp := range m.Teleporters {
+		if tp.From == tp.To {
+			return fmt.Errorf("teleporter at %d,%d leads to itself", tp.From.X, tp.From.Y)
+		}
+		if err := checkCells("teleporter", []MapCell{tp.From, tp.To}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseLevelMap decodes the map file contents.
+// The decoded map may be unfinished, use Validate before playing it.
+func ParseLevelMap(data []byte) (*LevelMap, error) {
+	var m LevelMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if err := m.validateSettings(); err != nil {
+		return nil, err
+	}
+	m.Crop()
+	return &m, nil
+}
+
+func (m *LevelMap) validateSettings() error {
+	if m.Version != LevelMapVersion {
+		return fmt.Errorf("unsupported map version %d", m.Version)
+	}
+	if m.WorldSize < 0 || m.WorldSize > 3 {
+		return fmt.Errorf("invalid world size %d", m.WorldSize)
+	}
+	if m.WorldShape < 0 || m.WorldShape > int(WorldVertical) {
+		return fmt.Errorf("invalid world shape %d", m.WorldShape)
+	}
+	if m.Environment < 0 || m.Environment > int(EnvSnow) {
+		return fmt.Errorf("invalid environment %d", m.Environment)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package gamedata
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLevelMapEncoding(t *testing.T) {
+	m := NewLevelMap(1, WorldHorizontal, EnvSnow)
+	m.SpawnPoints = []MapCell{{X: 10, Y: 15}}
+	m.Tiles = []MapTile{{MapCell: MapCell{X: 1, Y: 2}, Frame: 3}}
+	m.Walls = []MapCell{{X: 20, Y: 5}, {X: 21, Y: 5}}
+	m.Forests = []MapRect{{MapCell: MapCell{X: 30, Y: 10}, Width: 4, Height: 6}}
+	m.Resources = []MapResource{{MapCell: MapCell{X: 12, Y: 16}, Kind: "iron"}}
+	m.Teleporters = []MapTeleporter{{From: MapCell{X: 5, Y: 5}, To: MapCell{X: 60, Y: 25}}}
+	m.CreepBases = []MapCell{{X: 70, Y: 20}}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ParseLevelMap(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data2, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(data2) {
+		t.Fatalf("encoding mismatch:\nhave: %s\nwant: %s", data2, data)
+	}
+}
+
+func TestLevelMapValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m *LevelMap)
+	}{
+		{"no spawn", func(m *LevelMap) { m.SpawnPoints = nil }},
+		{"bad version", func(m *LevelMap) { m.Version = 0 }},
+		{"bad world size", func(m *LevelMap) { m.WorldSize = 4 }},
+		{"wall out of bounds", func(m *LevelMap) { m.Walls = []MapCell{{X: -1, Y: 0}} }},
+		{"forest out of bounds", func(m *LevelMap) {
+			m.Forests = []MapRect{{MapCell: MapCell{X: 55, Y: 0}, Width: 4, Height: 4}}
+		}},
+		{"unknown resource", func(m *LevelMap) {
+			m.Resources = []MapResource{{MapCell: MapCell{X: 1, Y: 1}, Kind: "unobtainium"}}
+		}},
+		{"looped teleporter", func(m *LevelMap) {
+			m.Teleporters = []MapTeleporter{{From: MapCell{X: 3, Y: 3}, To: MapCell{X: 3, Y: 3}}}
+		}},
+	}
+
+	for _, test := range tests {
+		m := NewLevelMap(0, WorldSquare, EnvMoon)
+		m.SpawnPoints = []MapCell{{X: 20, Y: 20}}
+		test.modify(m)
+		if err := m.Validate(); err == nil {
+			t.Fatalf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestLevelMapCrop(t *testing.T) {
+	m := NewLevelMap(3, WorldSquare, EnvMoon)
+	m.SpawnPoints = []MapCell{{X: 10, Y: 10}, {X: 100, Y: 100}}
+	m.Walls = []MapCell{{X: 50, Y: 50}, {X: 58, Y: 1}}
+
+	m.WorldSize = 0
+	m.Crop()
+	if len(m.SpawnPoints) != 1 || len(m.Walls) != 1 {
+		t.Fatalf("unexpected crop results: %d spawn points, %d walls", len(m.SpawnPoints), len(m.Walls))
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
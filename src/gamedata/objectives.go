@@ -8,6 +8,7 @@ const (
 	ObjectiveDestroyCreepBases
 	ObjectiveAcquireSuperElite
 	ObjectiveTrigger
+	ObjectiveSurvive
 )
 
 func (o GameObjective) String() string {
@@ -20,7 +21,22 @@ func (o GameObjective) String() string {
 		return "destroy_creep_bases"
 	case ObjectiveAcquireSuperElite:
 		return "super_elite"
+	case ObjectiveTrigger:
+		return "trigger"
+	case ObjectiveSurvive:
+		return "survive"
 	default:
 		return ""
 	}
 }
+
+// ParseGameObjective returns an objective with the specified name.
+// The second result is false for unknown names.
+func ParseGameObjective(name string) (GameObjective, bool) {
+	for o := ObjectiveBoss; o <= ObjectiveSurvive; o++ {
+		if o.String() == name {
+			return o, true
+		}
+	}
+	return 0, false
+}
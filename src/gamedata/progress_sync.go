@@ -0,0 +1,171 @@
+package gamedata
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gmath"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// SyncKeyAlphabet lists the characters the sync keys are made of.
+// The similar-looking characters are excluded, so it's easier to type the key.
+const SyncKeyAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+func IsValidSyncKey(key string) bool {
+	if len(key) != serverapi.SyncKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(SyncKeyAlphabet, key[i]) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// SignProgress returns a serverapi.SignedProgress signature for the data.
+func SignProgress(key string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsValidProgressSignature reports whether the progress was signed with the key.
+func IsValidProgressSignature(key string, p serverapi.SignedProgress) bool {
+	return hmac.Equal([]byte(SignProgress(key, p.Data)), []byte(p.Signature))
+}
+
+// IsValidPlayerProgress does a superficial progress data check.
+// The unknown content names are allowed: they could come from a newer game build.
+func IsValidPlayerProgress(p *serverapi.PlayerProgress) bool {
+	lists := []int{
+		len(p.Achievements),
+		len(p.OptionsUnlocked),
+		len(p.CoresUnlocked),
+		len(p.TurretsUnlocked),
+		len(p.DronesUnlocked),
+		len(p.Tier3DronesSeen),
+		len(p.ModesUnlocked),
+		len(p.Campaign),
+		len(p.Highscores),
+	}
+	for _, n := range lists {
+		if n > serverapi.MaxProgressListLength {
+			return false
+		}
+	}
+
+	if p.NumVictories < 0 || p.TotalPlayTime < 0 || p.TotalScore < 0 {
+		return false
+	}
+	for _, m := range p.Campaign {
+		if m.Stars < 0 || m.Stars > MaxCampaignStars || m.BestTime < 0 {
+			return false
+		}
+	}
+	for _, h := range p.Highscores {
+		if _, ok := GameModeInfoMap[h.Mode]; !ok {
+			return false
+		}
+		if h.Score < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MergePlayerProgress combines the progress from two devices.
+//
+// Nothing that was achieved on any of the devices is lost:
+// the unlock sets are merged and the best results are selected.
+// The counters (like a total score) can't be summed up without counting
+// the shared part twice, so the max value is used instead.
+func MergePlayerProgress(a, b *serverapi.PlayerProgress) *serverapi.PlayerProgress {
+	result := &serverapi.PlayerProgress{
+		OptionsUnlocked: mergeNameSets(a.OptionsUnlocked, b.OptionsUnlocked),
+		CoresUnlocked:   mergeNameSets(a.CoresUnlocked, b.CoresUnlocked),
+		TurretsUnlocked: mergeNameSets(a.TurretsUnlocked, b.TurretsUnlocked),
+		DronesUnlocked:  mergeNameSets(a.DronesUnlocked, b.DronesUnlocked),
+		Tier3DronesSeen: mergeNameSets(a.Tier3DronesSeen, b.Tier3DronesSeen),
+		ModesUnlocked:   mergeNameSets(a.ModesUnlocked, b.ModesUnlocked),
+
+		TutorialCompleted: a.TutorialCompleted || b.TutorialCompleted,
+
+		NumVictories:  gmath.ClampMin(a.NumVictories, b.NumVictories),
+		TotalPlayTime: a.TotalPlayTime,
+		TotalScore:    gmath.ClampMin(a.TotalScore, b.TotalScore),
+	}
+	if b.TotalPlayTime > result.TotalPlayTime {
+		result.TotalPlayTime = b.TotalPlayTime
+	}
+
+	result.Achievements = append(result.Achievements, a.Achievements...)
+	for _, achievement := range b.Achievements {
+		i := xslices.IndexWhere(result.Achievements, func(x serverapi.ProgressAchievement) bool {
+			return x.Name == achievement.Name
+		})
+		if i == -1 {
+			result.Achievements = append(result.Achievements, achievement)
+			continue
+		}
+		// The elite version includes the normal one.
+		result.Achievements[i].Elite = result.Achievements[i].Elite || achievement.Elite
+	}
+
+	result.Campaign = append(result.Campaign, a.Campaign...)
+	for _, m := range b.Campaign {
+		i := xslices.IndexWhere(result.Campaign, func(x serverapi.ProgressMission) bool {
+			return x.Mission == m.Mission
+		})
+		if i == -1 {
+			result.Campaign = append(result.Campaign, m)
+			continue
+		}
+		merged := &result.Campaign[i]
+		merged.Stars = gmath.ClampMin(merged.Stars, m.Stars)
+		if merged.BestTime == 0 || (m.BestTime != 0 && m.BestTime < merged.BestTime) {
+			merged.BestTime = m.BestTime
+		}
+	}
+
+	result.Highscores = append(result.Highscores, a.Highscores...)
+	for _, h := range b.Highscores {
+		i := xslices.IndexWhere(result.Highscores, func(x serverapi.ProgressHighscore) bool {
+			return x.Mode == h.Mode
+		})
+		if i == -1 {
+			result.Highscores = append(result.Highscores, h)
+			continue
+		}
+		// The difficulty belongs to the score, so they're selected together.
+		if h.Score > result.Highscores[i].Score {
+			result.Highscores[i] = h
+		}
+	}
+
+	return result
+}
+
+func mergeNameSets(a, b []string) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(a)+len(b))
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for _, list := range [2][]string{a, b} {
+		for _, name := range list {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package gamedata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+func TestMergePlayerProgress(t *testing.T) {
+	desktop := &serverapi.PlayerProgress{
+		Achievements: []serverapi.ProgressAchievement{
+			{Name: "t3engineer"},
+			{Name: "speedrunning", Elite: true},
+		},
+		DronesUnlocked: []string{"Repeller", "Fighter"},
+		Campaign: []serverapi.ProgressMission{
+			{Mission: "first_landing", Stars: 1, BestTime: 900},
+		},
+		NumVictories:  4,
+		TotalPlayTime: 5000,
+		TotalScore:    1200,
+		Highscores: []serverapi.ProgressHighscore{
+			{Mode: "classic", Score: 900, Difficulty: 120},
+		},
+	}
+	mobile := &serverapi.PlayerProgress{
+		Achievements: []serverapi.ProgressAchievement{
+			{Name: "t3engineer", Elite: true},
+		},
+		DronesUnlocked:    []string{"Fighter", "Servo"},
+		TutorialCompleted: true,
+		Campaign: []serverapi.ProgressMission{
+			{Mission: "first_landing", Stars: 2, BestTime: 1000},
+			{Mission: "scorched_earth", Stars: 1, BestTime: 800},
+		},
+		NumVictories:  2,
+		TotalPlayTime: 7000,
+		TotalScore:    800,
+		Highscores: []serverapi.ProgressHighscore{
+			{Mode: "classic", Score: 1000, Difficulty: 90},
+			{Mode: "arena", Score: 300, Difficulty: 100},
+		},
+	}
+
+	want := &serverapi.PlayerProgress{
+		Achievements: []serverapi.ProgressAchievement{
+			{Name: "t3engineer", Elite: true},
+			{Name: "speedrunning", Elite: true},
+		},
+		DronesUnlocked:    []string{"Repeller", "Fighter", "Servo"},
+		TutorialCompleted: true,
+		Campaign: []serverapi.ProgressMission{
+			{Mission: "first_landing", Stars: 2, BestTime: 900},
+			{Mission: "scorched_earth", Stars: 1, BestTime: 800},
+		},
+		NumVictories:  4,
+		TotalPlayTime: 7000,
+		TotalScore:    1200,
+		Highscores: []serverapi.ProgressHighscore{
+			{Mode: "classic", Score: 1000, Difficulty: 90},
+			{Mode: "arena", Score: 300, Difficulty: 100},
+		},
+	}
+
+	have := MergePlayerProgress(desktop, mobile)
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("merge results mismatch:\nhave: %+v\nwant: %+v", have, want)
+	}
+
+	// Merging the same data again should not change anything.
+	if again := MergePlayerProgress(have, mobile); !reflect.DeepEqual(again, want) {
+		t.Fatalf("repeated merge changed the results:\nhave: %+v\nwant: %+v", again, want)
+	}
+}
+
+func TestProgressSignature(t *testing.T) {
+	key := "abcdefghjkmnpq23"
+	if !IsValidSyncKey(key) {
+		t.Fatalf("%q key is expected to be valid", key)
+	}
+	for _, badKey := range []string{"", "abc", "abcdefghjkmnpq2l", "ABCDEFGHJKMNPQ23"} {
+		if IsValidSyncKey(badKey) {
+			t.Fatalf("%q key is expected to be invalid", badKey)
+		}
+	}
+
+	data := []byte(`{"total_score":100}`)
+	p := serverapi.SignedProgress{Data: data, Signature: SignProgress(key, data)}
+	if !IsValidProgressSignature(key, p) {
+		t.Fatal("signature check failed")
+	}
+	if IsValidProgressSignature("abcdefghjkmnpq24", p) {
+		t.Fatal("signature check passed with a wrong key")
+	}
+	p.Data = []byte(`{"total_score":999}`)
+	if IsValidProgressSignature(key, p) {
+		t.Fatal("signature check passed for the modified data")
+	}
+}
@@ -0,0 +1,414 @@
+package gamedata
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/bits"
+	"strings"
+
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// ShareCodeVersion is the current share code format version.
+//
+// The encoded layout refers to the modes, cores, turrets and drone recipes
+// by their list indexes. Appending a new element to these lists is fine,
+// but any reordering (or a layout change) requires a version bump.
+const ShareCodeVersion = 4
+
+var (
+	ErrShareCodeFormat   = errors.New("malformed share code")
+	ErrShareCodeChecksum = errors.New("share code checksum mismatch")
+	ErrShareCodeVersion  = errors.New("unsupported share code version")
+)
+
+var shareCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var shareCodeModes = []string{"classic", "arena", "inf_arena", "reverse", "blitz", "custom"}
+
+// The characters that are easy to confuse with the base32 alphabet letters.
+var shareCodeReplacer = strings.NewReplacer(
+	"0", "O",
+	"1", "I",
+	"8", "B",
+)
+
+const shareCodeGroupLen = 5
+
+// EncodeShareCode returns a compact text representation of the level config.
+//
+// The code only includes the options selectable in the lobby.
+// The derived fields (like the difficulty score) are not encoded.
+func EncodeShareCode(cfg serverapi.ReplayLevelConfig) (string, error) {
+	w := &shareCodeWriter{
+		buf: []byte{ShareCodeVersion},
+	}
+
+	modeIndex := xslices.Index(shareCodeModes, cfg.RawGameMode)
+	if modeIndex == -1 {
+		return "", fmt.Errorf("unexpected game mode %q", cfg.RawGameMode)
+	}
+	w.WriteUint(uint64(modeIndex), 4)
+
+	for _, flag := range shareCodeFlags(&cfg) {
+		w.WriteBool(*flag)
+	}
+	for _, o := range shareCodeOptions(&cfg) {
+		if *o.value < 0 || *o.value >= (1<<o.bits) {
+			return "", fmt.Errorf("option value %d is out of range", *o.value)
+		}
+		w.WriteUint(uint64(*o.value), o.bits)
+	}
+
+	w.WriteBool(cfg.Seed < 0)
+	seed := uint64(cfg.Seed)
+	if cfg.Seed < 0 {
+		seed = uint64(-cfg.Seed)
+	}
+	seedLen := uint(bits.Len64(seed))
+	w.WriteUint(uint64(seedLen), 7)
+	w.WriteUint(seed, seedLen)
+
+	coreIndex := xslices.IndexWhere(CoreStatsList, func(core *ColonyCoreStats) bool {
+		return core.Name == cfg.CoreDesign
+	})
+	if coreIndex == -1 && cfg.CoreDesign != "" {
+		return "", fmt.Errorf("unexpected core design %q", cfg.CoreDesign)
+	}
+	w.WriteUint(uint64(coreIndex+1), 4)
+
+	turretIndex := xslices.IndexWhere(TurretStatsList, func(turret *AgentStats) bool {
+		return turret.Kind.String() == cfg.TurretDesign
+	})
+	if turretIndex == -1 && cfg.TurretDesign != "" {
+		return "", fmt.Errorf("unexpected turret design %q", cfg.TurretDesign)
+	}
+	w.WriteUint(uint64(turretIndex+1), 5)
+
+	if len(cfg.Tier2Recipes) >= (1 << 5) {
+		return "", errors.New("too many tier2 recipes")
+	}
+	w.WriteUint(uint64(len(cfg.Tier2Recipes)), 5)
+	for _, name := range cfg.Tier2Recipes {
+		recipeIndex := xslices.IndexWhere(Tier2agentMergeRecipes, func(r AgentMergeRecipe) bool {
+			return r.Result.Kind.String() == name
+		})
+		if recipeIndex == -1 {
+			return "", fmt.Errorf("unexpected tier2 recipe %q", name)
+		}
+		w.WriteUint(uint64(recipeIndex), 6)
+	}
+
+	if len(cfg.BotPersonalities) > MaxBotPersonalities {
+		return "", errors.New("too many bot personalities")
+	}
+	w.WriteUint(uint64(len(cfg.BotPersonalities)), 2)
+	for _, name := range cfg.BotPersonalities {
+		if err := w.WriteString(name); err != nil {
+			return "", err
+		}
+	}
+
+	w.WriteBool(cfg.CustomRules != nil)
+	if rules := cfg.CustomRules; rules != nil {
+		if len(rules.StartingDrones) != 0 {
+			// Only the campaign missions have the starting drones.
+			return "", errors.New("starting drones can't be encoded")
+		}
+		objectives := uint64(0)
+		for _, name := range rules.Objectives {
+			o, ok := ParseGameObjective(name)
+			if !ok {
+				return "", fmt.Errorf("unexpected objective %q", name)
+			}
+			objectives |= 1 << uint(o)
+		}
+		w.WriteUint(objectives, 8)
+		if rules.ColoniesGoal < 0 || rules.ColoniesGoal >= (1<<4) {
+			return "", errors.New("colonies goal is out of range")
+		}
+		w.WriteUint(uint64(rules.ColoniesGoal), 4)
+		if rules.TimeLimit < 0 || rules.TimeLimit > MaxCustomModeTimeLimit {
+			return "", errors.New("time limit is out of range")
+		}
+		w.WriteUint(uint64(rules.TimeLimit), 14)
+		if rules.ResourceCap < 0 {
+			return "", errors.New("resource cap is out of range")
+		}
+		resourceCapLen := uint(bits.Len64(uint64(rules.ResourceCap)))
+		w.WriteUint(uint64(resourceCapLen), 6)
+		w.WriteUint(uint64(rules.ResourceCap), resourceCapLen)
+		if err := w.WriteString(rules.WaveSchedule); err != nil {
+			return "", err
+		}
+	}
+
+	data := w.buf
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+	return formatShareCode(shareCodeEncoding.EncodeToString(data)), nil
+}
+
+// DecodeShareCode parses the code created by EncodeShareCode.
+//
+// The custom mode rules only contain the wave schedule name,
+// the caller is expected to resolve the waves list.
+// The resulting config is not validated, use IsValidLevelConfig
+// after the custom mode waves are resolved.
+func DecodeShareCode(code string) (serverapi.ReplayLevelConfig, error) {
+	var cfg serverapi.ReplayLevelConfig
+
+	data, err := shareCodeEncoding.DecodeString(normalizeShareCode(code))
+	if err != nil || len(data) < 1+crc32.Size {
+		return cfg, ErrShareCodeFormat
+	}
+	payload := data[:len(data)-crc32.Size]
+	checksum := data[len(data)-crc32.Size:]
+	if binary.BigEndian.Uint32(checksum) != crc32.ChecksumIEEE(payload) {
+		return cfg, ErrShareCodeChecksum
+	}
+	if payload[0] != ShareCodeVersion {
+		return cfg, ErrShareCodeVersion
+	}
+
+	r := &shareCodeReader{buf: payload[1:]}
+
+	modeIndex := int(r.ReadUint(4))
+	if modeIndex >= len(shareCodeModes) {
+		return cfg, ErrShareCodeFormat
+	}
+	cfg.RawGameMode = shareCodeModes[modeIndex]
+
+	for _, flag := range shareCodeFlags(&cfg) {
+		*flag = r.ReadBool()
+	}
+	for _, o := range shareCodeOptions(&cfg) {
+		*o.value = int(r.ReadUint(o.bits))
+	}
+
+	negativeSeed := r.ReadBool()
+	seed := r.ReadUint(uint(r.ReadUint(7)))
+	cfg.Seed = int64(seed)
+	if negativeSeed {
+		cfg.Seed = -cfg.Seed
+	}
+
+	if coreIndex := int(r.ReadUint(4)); coreIndex != 0 {
+		if coreIndex > len(CoreStatsList) {
+			return cfg, ErrShareCodeFormat
+		}
+		cfg.CoreDesign = CoreStatsList[coreIndex-1].Name
+	}
+	if turretIndex := int(r.ReadUint(5)); turretIndex != 0 {
+		if turretIndex > len(TurretStatsList) {
+			return cfg, ErrShareCodeFormat
+		}
+		cfg.TurretDesign = TurretStatsList[turretIndex-1].Kind.String()
+	}
+
+	numRecipes := int(r.ReadUint(5))
+	cfg.Tier2Recipes = make([]string, 0, numRecipes)
+	for i := 0; i < numRecipes; i++ {
+		recipeIndex := int(r.ReadUint(6))
+		if recipeIndex >= len(Tier2agentMergeRecipes) {
+			return cfg, ErrShareCodeFormat
+		}
+		cfg.Tier2Recipes = append(cfg.Tier2Recipes, Tier2agentMergeRecipes[recipeIndex].Result.Kind.String())
+	}
+
+	numBots := int(r.ReadUint(2))
+	if numBots > MaxBotPersonalities {
+		return cfg, ErrShareCodeFormat
+	}
+	if numBots != 0 {
+		cfg.BotPersonalities = make([]string, numBots)
+		for i := range cfg.BotPersonalities {
+			cfg.BotPersonalities[i] = r.ReadString()
+		}
+	}
+
+	if r.ReadBool() {
+		rules := &serverapi.CustomModeRules{}
+		objectives := r.ReadUint(8)
+		if objectives>>(uint(ObjectiveSurvive)+1) != 0 {
+			return cfg, ErrShareCodeFormat
+		}
+		for o := ObjectiveBoss; o <= ObjectiveSurvive; o++ {
+			if objectives&(1<<uint(o)) != 0 {
+				rules.Objectives = append(rules.Objectives, o.String())
+			}
+		}
+		rules.ColoniesGoal = int(r.ReadUint(4))
+		rules.TimeLimit = int(r.ReadUint(14))
+		rules.ResourceCap = int(r.ReadUint(uint(r.ReadUint(6))))
+		rules.WaveSchedule = r.ReadString()
+		cfg.CustomRules = rules
+	}
+
+	if r.err {
+		return cfg, ErrShareCodeFormat
+	}
+
+	cfg.DronePointsAllocated = CalcAllocatedPoints(cfg.Tier2Recipes)
+	cfg.DifficultyScore = CalcDifficultyScore(cfg, cfg.DronePointsAllocated)
+
+	return cfg, nil
+}
+
+// The order of these flags and options is a part of the share code format.
+// Only append new elements to the end and bump the ShareCodeVersion.
+
+func shareCodeFlags(cfg *serverapi.ReplayLevelConfig) []*bool {
+	return []*bool{
+		&cfg.GoldEnabled,
+		&cfg.WeatherEnabled,
+		&cfg.Relicts,
+		&cfg.FogOfWar,
+		&cfg.SuperCreeps,
+		&cfg.CreepFortress,
+		&cfg.CoordinatorCreeps,
+		&cfg.GrenadierCreeps,
+		&cfg.AtomicBomb,
+		&cfg.EliteFleet,
+		&cfg.IonMortars,
+		&cfg.StartingResources,
+		&cfg.AdaptiveDifficulty,
+		&cfg.WeatherEffects,
+		&cfg.ReverseCreepsBot,
+	}
+}
+
+type shareCodeOption struct {
+	value *int
+	bits  uint
+}
+
+func shareCodeOptions(cfg *serverapi.ReplayLevelConfig) []shareCodeOption {
+	return []shareCodeOption{
+		{&cfg.Resources, 3},
+		{&cfg.PlayersMode, 3},
+		{&cfg.InterfaceMode, 2},
+		{&cfg.InitialCreeps, 2},
+		{&cfg.NumCreepBases, 3},
+		{&cfg.CreepDifficulty, 4},
+		{&cfg.DronesPower, 3},
+		{&cfg.CreepSpawnRate, 3},
+		{&cfg.CreepProductionRate, 4},
+		{&cfg.TechProgressRate, 4},
+		{&cfg.ReverseSuperCreepRate, 3},
+		{&cfg.ReverseBotDifficulty, 2},
+		{&cfg.BossDifficulty, 2},
+		{&cfg.ArenaProgression, 3},
+		{&cfg.GameSpeed, 2},
+		{&cfg.Teleporters, 2},
+		{&cfg.WorldShape, 2},
+		{&cfg.WorldSize, 2},
+		{&cfg.OilRegenRate, 2},
+		{&cfg.Terrain, 2},
+		{&cfg.Environment, 2},
+	}
+}
+
+func formatShareCode(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s) + len(s)/shareCodeGroupLen)
+	for i := 0; i < len(s); i += shareCodeGroupLen {
+		if i != 0 {
+			buf.WriteByte('-')
+		}
+		end := i + shareCodeGroupLen
+		if end > len(s) {
+			end = len(s)
+		}
+		buf.WriteString(s[i:end])
+	}
+	return buf.String()
+}
+
+func normalizeShareCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, code)
+	return shareCodeReplacer.Replace(strings.ToUpper(code))
+}
+
+type shareCodeWriter struct {
+	buf   []byte
+	nbits uint
+}
+
+func (w *shareCodeWriter) WriteBool(v bool) {
+	if v {
+		w.WriteUint(1, 1)
+	} else {
+		w.WriteUint(0, 1)
+	}
+}
+
+func (w *shareCodeWriter) WriteUint(v uint64, n uint) {
+	for i := n; i > 0; i-- {
+		if w.nbits%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		bit := byte((v >> (i - 1)) & 1)
+		w.buf[len(w.buf)-1] |= bit << (7 - w.nbits%8)
+		w.nbits++
+	}
+}
+
+func (w *shareCodeWriter) WriteString(s string) error {
+	if len(s) >= (1 << 6) {
+		return fmt.Errorf("%q is too long", s)
+	}
+	w.WriteUint(uint64(len(s)), 6)
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return fmt.Errorf("%q contains non-ASCII chars", s)
+		}
+		w.WriteUint(uint64(s[i]), 7)
+	}
+	return nil
+}
+
+type shareCodeReader struct {
+	buf   []byte
+	nbits uint
+	err   bool
+}
+
+func (r *shareCodeReader) ReadBool() bool {
+	return r.ReadUint(1) == 1
+}
+
+func (r *shareCodeReader) ReadUint(n uint) uint64 {
+	if n > 64 || r.nbits+n > uint(len(r.buf))*8 {
+		r.err = true
+		return 0
+	}
+	v := uint64(0)
+	for i := uint(0); i < n; i++ {
+		bit := (r.buf[r.nbits/8] >> (7 - r.nbits%8)) & 1
+		v = (v << 1) | uint64(bit)
+		r.nbits++
+	}
+	return v
+}
+
+func (r *shareCodeReader) ReadString() string {
+	n := int(r.ReadUint(6))
+	if r.err {
+		return ""
+	}
+	s := make([]byte, 0, n)
+	for i := 0; i < n; i++ {
+		s = append(s, byte(r.ReadUint(7)))
+	}
+	return string(s)
+}
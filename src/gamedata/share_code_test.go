@@ -0,0 +1,90 @@
+package gamedata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+func TestShareCodeRoundtrip(t *testing.T) {
+	configs := []serverapi.ReplayLevelConfig{
+		{
+			RawGameMode:        "classic",
+			Resources:          2,
+			NumCreepBases:      2,
+			CreepDifficulty:    13,
+			DronesPower:        1,
+			Environment:        3,
+			GoldEnabled:        true,
+			IonMortars:         true,
+			StartingResources:  true,
+			AdaptiveDifficulty: true,
+			Seed:               8923475982734598234,
+			CoreDesign:         "ark",
+			TurretDesign:       "BeamTower",
+			Tier2Recipes:       []string{"Repair", "Mortar"},
+		},
+		{
+			RawGameMode:      "reverse",
+			PlayersMode:      serverapi.PmodeTwoBots,
+			Seed:             -5,
+			WeatherEffects:   true,
+			ReverseCreepsBot: true,
+			Tier2Recipes:     []string{},
+			BotPersonalities: []string{"aggressive", "turtle"},
+		},
+		{
+			RawGameMode:  "custom",
+			Tier2Recipes: []string{},
+			CustomRules: &serverapi.CustomModeRules{
+				Objectives:   []string{"boss", "survive"},
+				ColoniesGoal: 5,
+				TimeLimit:    MaxCustomModeTimeLimit,
+				ResourceCap:  300,
+				WaveSchedule: "air_raid",
+			},
+		},
+	}
+
+	for _, cfg := range configs {
+		code, err := EncodeShareCode(cfg)
+		if err != nil {
+			t.Fatalf("encode %s: %v", cfg.RawGameMode, err)
+		}
+		// The codes are case-insensitive.
+		decoded, err := DecodeShareCode(strings.ToLower(code))
+		if err != nil {
+			t.Fatalf("decode %s: %v", code, err)
+		}
+		decoded.DifficultyScore = 0
+		decoded.DronePointsAllocated = 0
+		if !reflect.DeepEqual(decoded, cfg) {
+			t.Fatalf("decode %s:\nhave: %+v\nwant: %+v", code, decoded, cfg)
+		}
+
+		corrupted := []byte(code)
+		if corrupted[3] == 'A' {
+			corrupted[3] = 'B'
+		} else {
+			corrupted[3] = 'A'
+		}
+		if _, err := DecodeShareCode(string(corrupted)); err != ErrShareCodeChecksum {
+			t.Fatalf("decode corrupted %s: expected a checksum error, got %v", corrupted, err)
+		}
+	}
+}
+
+func TestShareCodeMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"hello!",
+		"AAAA",
+	}
+	for _, code := range tests {
+		if _, err := DecodeShareCode(code); err != ErrShareCodeFormat {
+			t.Fatalf("decode %q: expected a format error, got %v", code, err)
+		}
+	}
+}
@@ -10,6 +10,17 @@ const (
 	SeedLeet              // 1337
 )
 
+func (k SeedKind) String() string {
+	switch k {
+	case SeedInfernal:
+		return "infernal"
+	case SeedLeet:
+		return "leet"
+	default:
+		return "normal"
+	}
+}
+
 func GetSeedKind(seed int64, config serverapi.ReplayLevelConfig) SeedKind {
 	switch config.RawGameMode {
 	case "classic":
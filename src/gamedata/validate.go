@@ -31,13 +31,30 @@ func Validate() {
 
 func IsRunnableReplay(r serverapi.GameReplay) bool {
 	switch r.Config.RawGameMode {
-	case "classic", "arena", "inf_arena", "reverse", "blitz":
+	case "classic", "arena", "inf_arena", "reverse", "blitz", "custom":
 		return true
 	default:
 		return false
 	}
 }
 
+// IsWellFormedReplay performs the build-independent replay checks.
+//
+// It's used for the imported replays: the older build replays
+// can't pass IsValidReplay as the difficulty formula and the option
+// ranges change over time, but they can still be watched with
+// the compatible build.
+func IsWellFormedReplay(r serverapi.GameReplay) bool {
+	if r.GameVersion < 0 {
+		return false
+	}
+	if !IsRunnableReplay(r) {
+		// An unknown game mode.
+		return false
+	}
+	return len(r.Actions) != 0
+}
+
 func IsSendableReplay(r serverapi.GameReplay) bool {
 	if !IsRunnableReplay(r) {
 		return false
@@ -51,6 +68,15 @@ func IsSendableReplay(r serverapi.GameReplay) bool {
 	if r.Config.PlayersMode != serverapi.PmodeSinglePlayer {
 		return false
 	}
+	if IsCreepsBotGame(r.Config) {
+		// Only the dreadnought side of the reverse mode is ranked.
+		return false
+	}
+	if r.Config.RawGameMode == "custom" {
+		// Custom rules can make the game arbitrarily easy,
+		// these results are not accepted by the leaderboard.
+		return false
+	}
 	switch r.Config.RawGameMode {
 	case "classic", "arena", "reverse", "blitz":
 		// There is no point in running a non-victory game replay
@@ -85,34 +111,62 @@ func IsValidReplay(replay serverapi.GameReplay) bool {
 		return false
 	}
 
-	switch replay.Config.RawGameMode {
+	if !IsValidLevelConfig(&replay.Config) {
+		return false
+	}
+
+	difficultyScore := CalcDifficultyScore(replay.Config, CalcAllocatedPoints(replay.Config.Tier2Recipes))
+	if difficultyScore != replay.Config.DifficultyScore {
+		return false
+	}
+
+	return true
+}
+
+// IsValidLevelConfig reports whether all config options are in their valid ranges.
+// The difficulty score is not checked here.
+func IsValidLevelConfig(cfg *serverapi.ReplayLevelConfig) bool {
+	switch cfg.RawGameMode {
 	case "blitz", "classic", "arena", "inf_arena", "reverse":
-		// OK.
+		if cfg.CustomRules != nil {
+			return false
+		}
+	case "custom":
+		if cfg.CustomRules == nil {
+			return false
+		}
+		if ValidateCustomRules(cfg.CustomRules) != nil {
+			return false
+		}
 	default:
 		return false
 	}
 
-	if replay.Config.RawGameMode != "reverse" {
-		if replay.Config.EliteFleet {
+	if cfg.RawGameMode != "reverse" {
+		if cfg.EliteFleet {
 			return false
 		}
-		if replay.Config.DronesPower != 1 {
+		if cfg.DronesPower != 1 {
 			return false
 		}
 	}
-	switch replay.Config.RawGameMode {
+	if cfg.AdaptiveDifficulty && cfg.RawGameMode != "classic" && cfg.RawGameMode != "blitz" {
+		return false
+	}
+	if cfg.ReverseCreepsBot && !IsCreepsBotGame(*cfg) {
+		return false
+	}
+	switch cfg.RawGameMode {
 	case "reverse":
-		if replay.Config.FogOfWar {
+		if cfg.FogOfWar {
 			return false
 		}
 	case "blitz":
-		if replay.Config.NumCreepBases < 2 || replay.Config.NumCreepBases > 4 {
+		if cfg.NumCreepBases < 2 || cfg.NumCreepBases > 4 {
 			return false
 		}
 	}
 
-	cfg := &replay.Config
-
 	pointsAllocated := 0
 	for _, droneName := range cfg.Tier2Recipes {
 		recipe := findRecipeByName(droneName)
@@ -121,14 +175,18 @@ func IsValidReplay(replay serverapi.GameReplay) bool {
 		}
 		pointsAllocated += recipe.Result.PointCost
 	}
-	if pointsAllocated > 20 {
+	if pointsAllocated > ClassicModePoints {
 		return false
 	}
 
-	difficultyScore := CalcDifficultyScore(replay.Config, pointsAllocated)
-	if difficultyScore != replay.Config.DifficultyScore {
+	if len(cfg.BotPersonalities) > MaxBotPersonalities {
 		return false
 	}
+	for _, name := range cfg.BotPersonalities {
+		if !IsValidBotPersonality(name) {
+			return false
+		}
+	}
 
 	type optionValidator struct {
 		actual int
@@ -143,6 +201,8 @@ func IsValidReplay(replay serverapi.GameReplay) bool {
 		{cfg.TechProgressRate, 0, 8},
 		{cfg.CreepSpawnRate, 0, 5},
 		{cfg.BossDifficulty, 0, 3},
+		{cfg.ReverseBotDifficulty, 0, 3},
+		{cfg.ReverseSuperCreepRate, 0, 4},
 		{cfg.ArenaProgression, 0, 7},
 		{cfg.GameSpeed, 0, 3},
 		{cfg.Teleporters, 0, 2},
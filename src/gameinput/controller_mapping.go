@@ -35,6 +35,26 @@ func getKeyName(layout GamepadLayoutKind, key string) string {
 
 	case "gamepad_l1":
 		return "L1"
+	case "gamepad_l2":
+		return "L2"
+	case "gamepad_r1":
+		return "R1"
+	case "gamepad_r2":
+		return "R2"
+	case "gamepad_home":
+		return "HOME"
+	case "gamepad_lstick":
+		return "L3"
+	case "gamepad_rstick":
+		return "R3"
+	case "gamepad_up":
+		return "D-PAD UP"
+	case "gamepad_down":
+		return "D-PAD DOWN"
+	case "gamepad_left":
+		return "D-PAD LEFT"
+	case "gamepad_right":
+		return "D-PAD RIGHT"
 
 	case "escape":
 		return "ESC"
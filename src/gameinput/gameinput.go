@@ -60,6 +60,11 @@ func MakeHandler(m PlayerInputMethod, h *input.Handler) Handler {
 	}
 }
 
+// Remap replaces the handler keymap; it's used to apply the custom key bindings.
+func (h *Handler) Remap(keymap input.Keymap) {
+	h.input.Remap(keymap)
+}
+
 func (h *Handler) IsClickDevice() bool {
 	switch h.InputMethod {
 	case InputMethodCombined:
@@ -104,6 +109,15 @@ func (h *Handler) PrettyActionName(d *langs.Dictionary, a input.Action) string {
 	return ""
 }
 
+// PrettyKeyName is like PrettyActionName, but for a single key name.
+// Unlike PrettyActionName, it never returns an empty string.
+func (h *Handler) PrettyKeyName(name string) string {
+	if pretty := getKeyName(h.layout, name); pretty != "" {
+		return pretty
+	}
+	return strings.ToUpper(name)
+}
+
 func (h *Handler) ReplaceKeyNames(s string) string {
 	if h.keysReplacer == nil {
 		// Keyboard input.
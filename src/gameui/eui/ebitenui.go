@@ -27,6 +27,12 @@ var (
 
 type Widget = widget.PreferredSizeLocateableWidget
 
+// The UI scale options; see LoadResources.
+const (
+	UIScaleNormal = iota
+	UIScaleLarge
+)
+
 type Resources struct {
 	Button         *ButtonResource
 	TabButton      *ButtonResource
@@ -37,6 +43,10 @@ type Resources struct {
 	Panel          *PanelResource
 	DarkPanel      *PanelResource
 
+	// TextFont is the font for the small texts like descriptions and hints.
+	// It depends on the UI scale.
+	TextFont font.Face
+
 	mobile bool
 }
 
@@ -469,7 +479,7 @@ type ButtonConfig struct {
 
 func NewSmallButton(res *Resources, scene *ge.Scene, text string, onclick func()) *widget.Button {
 	return NewButtonWithConfig(res, ButtonConfig{
-		Font:      assets.BitmapFont1,
+		Font:      res.TextFont,
 		Scene:     scene,
 		Text:      text,
 		OnPressed: onclick,
@@ -772,9 +782,13 @@ func WidgetRect(w *widget.Widget) gmath.Rect {
 	}
 }
 
-func LoadResources(device userdevice.Info, loader *resource.Loader) *Resources {
+func LoadResources(device userdevice.Info, loader *resource.Loader, uiScale int) *Resources {
 	result := &Resources{
-		mobile: device.IsMobile(),
+		mobile:   device.IsMobile(),
+		TextFont: assets.BitmapFont1,
+	}
+	if uiScale == UIScaleLarge {
+		result.TextFont = assets.BitmapFont1Large
 	}
 
 	{
@@ -791,7 +805,7 @@ func LoadResources(device userdevice.Info, loader *resource.Loader) *Resources {
 				Top:    14,
 				Bottom: 10,
 			},
-			FontFace: assets.BitmapFont1,
+			FontFace: result.TextFont,
 			TextColors: &widget.TextInputColor{
 				Idle:          NormalTextColor,
 				Disabled:      NormalTextColor,
@@ -0,0 +1,88 @@
+package gameui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+// DiodeStyle describes how the drone faction is displayed.
+type DiodeStyle struct {
+	Large    bool
+	Palette  gamedata.FactionPalette
+	Patterns bool
+}
+
+func NewDiodeStyle(settings *session.GameSettings) DiodeStyle {
+	return DiodeStyle{
+		Large:    settings.LargeDiodes,
+		Palette:  gamedata.FactionPalette(settings.Graphics.FactionPalette),
+		Patterns: settings.Graphics.FactionPatterns,
+	}
+}
+
+// factionPatternMasks are the 5x5 faction shapes.
+// They make it possible to tell the factions apart without relying on colors.
+var factionPatternMasks = [...][5]string{
+	gamedata.YellowFactionTag: {
+		"#####",
+		"#...#",
+		"#...#",
+		"#...#",
+		"#####",
+	},
+	gamedata.RedFactionTag: {
+		"#...#",
+		".#.#.",
+		"..#..",
+		".#.#.",
+		"#...#",
+	},
+	gamedata.GreenFactionTag: {
+		"..#..",
+		"..#..",
+		"#####",
+		"..#..",
+		"..#..",
+	},
+	gamedata.BlueFactionTag: {
+		"..#..",
+		".#.#.",
+		".#.#.",
+		"#...#",
+		"#####",
+	},
+}
+
+var factionPatternImages [len(factionPatternMasks)]*ebiten.Image
+
+// FactionPatternImage returns a white faction shape image.
+// It's intended to be tinted with the faction color.
+//
+// Returns nil for the neutral faction.
+func FactionPatternImage(tag gamedata.FactionTag) *ebiten.Image {
+	if tag == gamedata.NeutralFactionTag {
+		return nil
+	}
+	if img := factionPatternImages[tag]; img != nil {
+		return img
+	}
+	mask := factionPatternMasks[tag]
+	img := ebiten.NewImage(len(mask[0]), len(mask))
+	pixels := make([]byte, 4*len(mask[0])*len(mask))
+	for y, row := range mask {
+		for x, c := range row {
+			if c != '#' {
+				continue
+			}
+			i := 4 * (y*len(row) + x)
+			pixels[i+0] = 0xff
+			pixels[i+1] = 0xff
+			pixels[i+2] = 0xff
+			pixels[i+3] = 0xff
+		}
+	}
+	img.WritePixels(pixels)
+	factionPatternImages[tag] = img
+	return img
+}
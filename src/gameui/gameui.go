@@ -10,7 +10,7 @@ import (
 	"github.com/quasilyte/roboden-game/gamedata"
 )
 
-func GenerateRecipePreviews(scene *ge.Scene, needT3, largeDiode bool) map[gamedata.RecipeSubject]*ebiten.Image {
+func GenerateRecipePreviews(scene *ge.Scene, needT3 bool, style DiodeStyle) map[gamedata.RecipeSubject]*ebiten.Image {
 	createSubImage := func(img resource.Image) *ebiten.Image {
 		return img.Data.SubImage(image.Rectangle{
 			Max: image.Point{
@@ -24,7 +24,7 @@ func GenerateRecipePreviews(scene *ge.Scene, needT3, largeDiode bool) map[gameda
 	scoutFrame := createSubImage(scene.LoadImage(assets.ImageScoutAgent))
 
 	img := assets.ImageFactionDiode
-	if largeDiode {
+	if style.Large {
 		img = assets.ImageFactionDiodeLarge
 	}
 	diode := scene.LoadImage(img).Data
@@ -57,8 +57,15 @@ func GenerateRecipePreviews(scene *ge.Scene, needT3, largeDiode bool) map[gameda
 			drawOptions.GeoM.Reset()
 			drawOptions.GeoM.Scale(2, 2)
 			drawOptions.GeoM.Translate(16-float64(diodeSize.X), 16-float64(diodeSize.Y)+diodeOffset)
-			drawOptions.ColorM.ScaleWithColor(gamedata.FactionByTag(s.Faction).Color)
+			factionColor := gamedata.FactionColor(s.Faction, style.Palette)
+			drawOptions.ColorM.ScaleWithColor(factionColor)
 			img.DrawImage(diode, &drawOptions)
+			if style.Patterns {
+				pattern := FactionPatternImage(s.Faction)
+				drawOptions.GeoM.Reset()
+				drawOptions.GeoM.Translate(32-float64(pattern.Bounds().Dx()), 32-float64(pattern.Bounds().Dy()))
+				img.DrawImage(pattern, &drawOptions)
+			}
 
 			recipeIcons[s] = img
 		}
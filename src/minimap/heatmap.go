@@ -0,0 +1,93 @@
+package minimap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Heatmap accumulates the activity values per grid cell.
+type Heatmap struct {
+	cols   int
+	rows   int
+	values []float64
+}
+
+func NewHeatmap(cols, rows int) *Heatmap {
+	return &Heatmap{
+		cols:   cols,
+		rows:   rows,
+		values: make([]float64, cols*rows),
+	}
+}
+
+// Add increases the heat of the cell that contains the (x, y) point.
+// The coordinates are measured in grid cells.
+// The out of bounds points are ignored.
+func (h *Heatmap) Add(x, y, value float64) {
+	if x < 0 || y < 0 {
+		return
+	}
+	col := int(x)
+	row := int(y)
+	if col >= h.cols || row >= h.rows {
+		return
+	}
+	h.values[row*h.cols+col] += value
+}
+
+func (h *Heatmap) Get(col, row int) float64 {
+	if col < 0 || row < 0 || col >= h.cols || row >= h.rows {
+		return 0
+	}
+	return h.values[row*h.cols+col]
+}
+
+func (h *Heatmap) Max() float64 {
+	result := 0.0
+	for _, v := range h.values {
+		if v > result {
+			result = v
+		}
+	}
+	return result
+}
+
+// RenderHeatmap draws the heatmap over the dimmed terrain.
+//
+// The heat is normalized by the max value.
+// A square root scale is used, so the low activity areas remain visible.
+func (r *Renderer) RenderHeatmap(h *Heatmap, c color.RGBA) *image.RGBA {
+	if h.cols != r.cols || h.rows != r.rows {
+		panic("minimap heatmap size mismatch")
+	}
+
+	img := image.NewRGBA(r.Bounds())
+	r.drawTerrain(img)
+	dim := image.NewUniform(color.RGBA{A: 160})
+	draw.Draw(img, img.Bounds(), dim, image.Point{}, draw.Over)
+
+	maxValue := h.Max()
+	if maxValue == 0 {
+		return img
+	}
+	for y := 0; y < r.rows; y++ {
+		for x := 0; x < r.cols; x++ {
+			v := h.values[y*r.cols+x]
+			if v <= 0 {
+				continue
+			}
+			alpha := math.Sqrt(v / maxValue)
+			heat := color.RGBA{
+				R: uint8(float64(c.R) * alpha),
+				G: uint8(float64(c.G) * alpha),
+				B: uint8(float64(c.B) * alpha),
+				A: uint8(255 * alpha),
+			}
+			rect := image.Rect(x*r.cellSize, y*r.cellSize, (x+1)*r.cellSize, (y+1)*r.cellSize)
+			draw.Draw(img, rect, image.NewUniform(heat), image.Point{}, draw.Over)
+		}
+	}
+	return img
+}
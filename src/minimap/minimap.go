@@ -0,0 +1,183 @@
+// Package minimap implements a headless game world rasterizer.
+//
+// It doesn't depend on Ebitengine and doesn't need a GPU:
+// everything is drawn with image/draw, so it can be used
+// by the server and the command-line tools.
+package minimap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+type Tile uint8
+
+const (
+	TileFree Tile = iota
+	TileBlocked
+	TileForest
+	TileLava
+	TileWall
+)
+
+type UnitKind uint8
+
+const (
+	UnitColony UnitKind = iota
+	UnitDrone
+	UnitTurret
+	UnitCreep
+	UnitBoss
+	UnitResource
+	UnitTeleporter
+)
+
+// Unit is an object drawn over the terrain.
+type Unit struct {
+	Kind UnitKind
+
+	// Player is the owner player ID; -1 for creeps and neutral objects.
+	Player int
+
+	// X and Y are measured in grid cells (not in world pixels).
+	X float64
+	Y float64
+}
+
+var tileColors = [...]color.RGBA{
+	TileFree:    {R: 58, G: 52, B: 44, A: 255},
+	TileBlocked: {R: 26, G: 24, B: 22, A: 255},
+	TileForest:  {R: 30, G: 72, B: 36, A: 255},
+	TileLava:    {R: 140, G: 52, B: 20, A: 255},
+	TileWall:    {R: 96, G: 92, B: 88, A: 255},
+}
+
+// PlayerColors are indexed by the player ID.
+// The creeps and the neutral units use separate colors.
+var PlayerColors = [...]color.RGBA{
+	{R: 0x9d, G: 0xd7, B: 0x93, A: 255},
+	{R: 0x6c, G: 0xb6, B: 0xe0, A: 255},
+	{R: 0xc5, G: 0x8a, B: 0xe0, A: 255},
+	{R: 0xe7, G: 0xc3, B: 0x4b, A: 255},
+}
+
+var (
+	creepColor      = color.RGBA{R: 0xe0, G: 0x4c, B: 0x4c, A: 255}
+	bossColor       = color.RGBA{R: 0xff, G: 0x20, B: 0x60, A: 255}
+	resourceColor   = color.RGBA{R: 0xe0, G: 0xa2, B: 0x6c, A: 255}
+	teleporterColor = color.RGBA{R: 0x3c, G: 0x78, B: 0xc8, A: 255}
+	neutralColor    = color.RGBA{R: 0xd0, G: 0xd0, B: 0xd0, A: 255}
+)
+
+// Renderer draws the grid terrain and the units.
+//
+// The terrain can be updated between the Render calls:
+// the game world cells are occupied and freed during the game.
+type Renderer struct {
+	cols     int
+	rows     int
+	cellSize int
+
+	tiles []Tile
+}
+
+// NewRenderer creates a renderer for the cols x rows grid.
+// Every grid cell becomes a cellSize x cellSize pixels square.
+func NewRenderer(cols, rows, cellSize int) *Renderer {
+	if cellSize < 1 {
+		panic("minimap cell size can't be less than 1")
+	}
+	return &Renderer{
+		cols:     cols,
+		rows:     rows,
+		cellSize: cellSize,
+		tiles:    make([]Tile, cols*rows),
+	}
+}
+
+func (r *Renderer) Size() (cols, rows int) {
+	return r.cols, r.rows
+}
+
+// Bounds returns the rendered image bounds.
+func (r *Renderer) Bounds() image.Rectangle {
+	return image.Rect(0, 0, r.cols*r.cellSize, r.rows*r.cellSize)
+}
+
+func (r *Renderer) SetTile(x, y int, t Tile) {
+	if x < 0 || y < 0 || x >= r.cols || y >= r.rows {
+		return
+	}
+	r.tiles[y*r.cols+x] = t
+}
+
+func (r *Renderer) GetTile(x, y int) Tile {
+	if x < 0 || y < 0 || x >= r.cols || y >= r.rows {
+		return TileBlocked
+	}
+	return r.tiles[y*r.cols+x]
+}
+
+// Render draws the terrain and the units on top of it.
+// The units are drawn in the slice order.
+func (r *Renderer) Render(units []Unit) *image.RGBA {
+	img := image.NewRGBA(r.Bounds())
+	r.drawTerrain(img)
+	for _, u := range units {
+		r.drawUnit(img, u)
+	}
+	return img
+}
+
+func (r *Renderer) drawTerrain(img *image.RGBA) {
+	for y := 0; y < r.rows; y++ {
+		for x := 0; x < r.cols; x++ {
+			c := tileColors[r.tiles[y*r.cols+x]]
+			rect := image.Rect(x*r.cellSize, y*r.cellSize, (x+1)*r.cellSize, (y+1)*r.cellSize)
+			draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+}
+
+func (r *Renderer) drawUnit(img *image.RGBA, u Unit) {
+	var c color.RGBA
+	size := 1
+	switch u.Kind {
+	case UnitColony:
+		c = r.playerColor(u.Player)
+		size = r.cellSize + r.cellSize/2
+	case UnitTurret:
+		c = r.playerColor(u.Player)
+		size = r.cellSize / 2
+	case UnitDrone:
+		c = r.playerColor(u.Player)
+	case UnitCreep:
+		c = creepColor
+		size = r.cellSize / 4
+	case UnitBoss:
+		c = bossColor
+		size = r.cellSize + r.cellSize/2
+	case UnitResource:
+		c = resourceColor
+		size = r.cellSize / 2
+	case UnitTeleporter:
+		c = teleporterColor
+		size = r.cellSize
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	cx := int(u.X * float64(r.cellSize))
+	cy := int(u.Y * float64(r.cellSize))
+	rect := image.Rect(cx-size/2, cy-size/2, cx-size/2+size, cy-size/2+size)
+	draw.Draw(img, rect.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func (r *Renderer) playerColor(player int) color.RGBA {
+	if player < 0 || player >= len(PlayerColors) {
+		return neutralColor
+	}
+	return PlayerColors[player]
+}
@@ -0,0 +1,107 @@
+package minimap_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/quasilyte/roboden-game/minimap"
+)
+
+func TestRender(t *testing.T) {
+	r := minimap.NewRenderer(4, 3, 8)
+	r.SetTile(1, 0, minimap.TileForest)
+	r.SetTile(10, 10, minimap.TileLava) // Out of bounds, ignored
+
+	img := r.Render([]minimap.Unit{
+		{Kind: minimap.UnitColony, Player: 1, X: 2.5, Y: 1.5},
+		{Kind: minimap.UnitCreep, Player: -1, X: 3.9, Y: 2.9},
+	})
+	if have, want := img.Bounds(), image.Rect(0, 0, 32, 24); have != want {
+		t.Fatalf("image bounds mismatch:\nhave %v\nwant %v", have, want)
+	}
+
+	free := img.RGBAAt(0, 0)
+	forest := img.RGBAAt(12, 4)
+	if free == forest {
+		t.Fatalf("forest and free tiles have the same color %v", free)
+	}
+	if have := img.RGBAAt(20, 12); have != minimap.PlayerColors[1] {
+		t.Fatalf("colony pixel mismatch:\nhave %v\nwant %v", have, minimap.PlayerColors[1])
+	}
+	if img.RGBAAt(31, 23) == free {
+		t.Fatalf("creep is not drawn near the image corner")
+	}
+
+	// The rendered image should be encodable as is.
+	if err := png.Encode(&bytes.Buffer{}, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	h := minimap.NewHeatmap(3, 2)
+	h.Add(0.5, 0.5, 1)
+	h.Add(0.9, 0.1, 3)
+	h.Add(2.5, 1.5, 2)
+	h.Add(-1, 0, 10)
+	h.Add(3, 0, 10)
+	h.Add(0, 2, 10)
+
+	tests := []struct {
+		col, row int
+		want     float64
+	}{
+		{0, 0, 4},
+		{1, 0, 0},
+		{2, 1, 2},
+		{5, 5, 0},
+	}
+	for _, test := range tests {
+		if have := h.Get(test.col, test.row); have != test.want {
+			t.Fatalf("Get(%d, %d):\nhave %v\nwant %v", test.col, test.row, have, test.want)
+		}
+	}
+	if have := h.Max(); have != 4 {
+		t.Fatalf("Max():\nhave %v\nwant 4", have)
+	}
+
+	r := minimap.NewRenderer(3, 2, 4)
+	img := r.RenderHeatmap(h, color.RGBA{R: 255, A: 255})
+	hottest := img.RGBAAt(1, 1)
+	warm := img.RGBAAt(9, 5)
+	cold := img.RGBAAt(5, 1)
+	if !(hottest.R > warm.R && warm.R > cold.R) {
+		t.Fatalf("unexpected heat colors: hottest=%v warm=%v cold=%v", hottest, warm, cold)
+	}
+}
+
+func TestTimelapse(t *testing.T) {
+	r := minimap.NewRenderer(4, 4, 4)
+	var frames []*image.RGBA
+	for i := 0; i < 3; i++ {
+		frames = append(frames, r.Render([]minimap.Unit{
+			{Kind: minimap.UnitColony, Player: 0, X: float64(i) + 0.5, Y: 2},
+		}))
+	}
+
+	g := minimap.Timelapse(frames, 10)
+	if len(g.Image) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(g.Image))
+	}
+	if g.Delay[0] != 10 || g.Delay[2] <= g.Delay[0] {
+		t.Fatalf("unexpected frame delays: %v", g.Delay)
+	}
+	// The minimap colors should survive the palette conversion.
+	have := color.RGBAModel.Convert(g.Image[0].At(2, 8)).(color.RGBA)
+	if have != minimap.PlayerColors[0] {
+		t.Fatalf("colony pixel mismatch:\nhave %v\nwant %v", have, minimap.PlayerColors[0])
+	}
+
+	if err := gif.EncodeAll(&bytes.Buffer{}, g); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,52 @@
+package minimap
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+)
+
+// timelapsePalette contains all minimap colors, so the frames
+// don't lose them during the palette conversion.
+// The rest of the palette is used for the heatmap blends.
+var timelapsePalette = func() color.Palette {
+	p := make(color.Palette, 0, 256)
+	for _, c := range tileColors {
+		p = append(p, c)
+	}
+	for _, c := range PlayerColors {
+		p = append(p, c)
+	}
+	p = append(p, creepColor, bossColor, resourceColor, teleporterColor, neutralColor)
+	for _, c := range palette.WebSafe {
+		if len(p) == cap(p) {
+			break
+		}
+		p = append(p, c)
+	}
+	return p
+}()
+
+// Timelapse combines the frames into an animated GIF.
+// The delay is measured in 100ths of a second.
+func Timelapse(frames []*image.RGBA, delay int) *gif.GIF {
+	result := &gif.GIF{
+		Image: make([]*image.Paletted, 0, len(frames)),
+		Delay: make([]int, 0, len(frames)),
+	}
+	for _, frame := range frames {
+		paletted := image.NewPaletted(frame.Bounds(), timelapsePalette)
+		// The dithering makes the small unit dots unreadable,
+		// so the nearest palette colors are used instead.
+		draw.Draw(paletted, paletted.Bounds(), frame, frame.Bounds().Min, draw.Src)
+		result.Image = append(result.Image, paletted)
+		result.Delay = append(result.Delay, delay)
+	}
+	if len(frames) != 0 {
+		// Make the final state visible for a while before the loop restarts.
+		result.Delay[len(frames)-1] = delay * 4
+	}
+	return result
+}
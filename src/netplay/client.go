@@ -0,0 +1,146 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrClosed = errors.New("connection is closed")
+
+// Client is a relay connection.
+//
+// The incoming messages are read in the background,
+// so Poll can be used from the game loop without blocking it.
+type Client struct {
+	conn net.Conn
+
+	playerID int
+
+	sendMutex sync.Mutex
+	encoder   *json.Encoder
+
+	inbox chan Message
+
+	errMutex sync.Mutex
+	err      error
+}
+
+// Dial connects to the relay and joins the room.
+func Dial(addr, room string, gameVersion int) (*Client, error) {
+	return dial(addr, Message{Kind: MsgJoin, Room: room, GameVersion: gameVersion})
+}
+
+// DialSpectator connects to the relay and joins the room as a spectator.
+// The room should exist, but the game should not be started yet.
+func DialSpectator(addr, room string, gameVersion int) (*Client, error) {
+	return dial(addr, Message{Kind: MsgJoin, Room: room, GameVersion: gameVersion, Spectator: true})
+}
+
+func dial(addr string, join Message) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		inbox:   make(chan Message, 256),
+	}
+
+	decoder := json.NewDecoder(conn)
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	err = c.Send(join)
+	var welcome Message
+	if err == nil {
+		err = decoder.Decode(&welcome)
+	}
+	if err == nil {
+		switch welcome.Kind {
+		case MsgWelcome:
+			// OK.
+		case MsgError:
+			err = errors.New(welcome.Error)
+		default:
+			err = fmt.Errorf("unexpected %q message", welcome.Kind)
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	c.playerID = welcome.Player
+	go c.readLoop(decoder)
+
+	return c, nil
+}
+
+// PlayerID returns the player index assigned by the relay.
+// It's SpectatorID for the spectators.
+func (c *Client) PlayerID() int { return c.playerID }
+
+func (c *Client) Send(m Message) error {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	return c.encoder.Encode(m)
+}
+
+// Receive waits for the next message.
+// It returns an error if the connection is closed.
+func (c *Client) Receive() (Message, error) {
+	m, ok := <-c.inbox
+	if !ok {
+		return m, c.getErr()
+	}
+	return m, nil
+}
+
+// Poll returns the next message if there is any.
+// It returns an error if the connection is closed.
+func (c *Client) Poll() (Message, bool, error) {
+	select {
+	case m, ok := <-c.inbox:
+		if !ok {
+			return m, false, c.getErr()
+		}
+		return m, true, nil
+	default:
+		return Message{}, false, nil
+	}
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) getErr() error {
+	c.errMutex.Lock()
+	defer c.errMutex.Unlock()
+	return c.err
+}
+
+func (c *Client) readLoop(decoder *json.Decoder) {
+	defer close(c.inbox)
+	for {
+		var m Message
+		if err := decoder.Decode(&m); err != nil {
+			c.errMutex.Lock()
+			c.err = ErrClosed
+			c.errMutex.Unlock()
+			return
+		}
+		if m.Kind == MsgError {
+			c.errMutex.Lock()
+			c.err = errors.New(m.Error)
+			c.errMutex.Unlock()
+			return
+		}
+		c.inbox <- m
+	}
+}
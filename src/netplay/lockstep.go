@@ -0,0 +1,247 @@
+package netplay
+
+import (
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// TurnTicks is the number of simulation ticks per turn.
+//
+// It's an even number, so the turn boundaries are reached
+// even if the simulation runs 2 ticks per step (x2 game speed).
+const TurnTicks = 6
+
+// maxStepTicks is the max number of ticks the simulation can run per step.
+const maxStepTicks = 2
+
+// Conn is a connection to the other player (or to both players, for the spectator).
+// Client implements it.
+type Conn interface {
+	Send(m Message) error
+	Poll() (Message, bool, error)
+	Close() error
+}
+
+type LockstepStatus int
+
+const (
+	LockstepRunning LockstepStatus = iota
+	LockstepDisconnected
+	LockstepDesync
+)
+
+// Lockstep keeps two game simulations in sync.
+//
+// Every turn the local actions are sent to the other player;
+// they're scheduled for execution inputDelay turns later on both sides.
+// The simulation can't advance until the other player's actions
+// for the next ticks are received.
+//
+// The simulation state checksums are exchanged with the turn messages too.
+// If they don't match, the simulations went out of sync.
+//
+// The spectator lockstep doesn't send anything: it receives the turns
+// of both players and advances when both of them are received.
+// Its checksums are compared with the player 0 checksums.
+type Lockstep struct {
+	conn Conn
+
+	localPlayer int
+	inputDelay  int
+
+	status         LockstepStatus
+	desyncChecksum int
+
+	nextSendTurn int
+	remoteTurns  [2]int
+
+	pending   []serverapi.PlayerAction
+	scheduled [2][]serverapi.PlayerAction
+
+	localChecksums  []int
+	remoteChecksums []int
+	sentChecksums   int
+	checkedChecksum int
+}
+
+func NewLockstep(conn Conn, localPlayer, inputDelay int) *Lockstep {
+	if inputDelay < 1 {
+		panic("input delay can't be less than 1 turn")
+	}
+	return &Lockstep{
+		conn:        conn,
+		localPlayer: localPlayer,
+		inputDelay:  inputDelay,
+	}
+}
+
+// NewSpectatorLockstep creates a lockstep that only watches the game.
+func NewSpectatorLockstep(conn Conn, inputDelay int) *Lockstep {
+	return NewLockstep(conn, SpectatorID, inputDelay)
+}
+
+// LocalPlayer returns the local player ID.
+// It's SpectatorID for the spectator lockstep.
+func (l *Lockstep) LocalPlayer() int { return l.localPlayer }
+
+func (l *Lockstep) IsSpectator() bool { return l.localPlayer == SpectatorID }
+
+func (l *Lockstep) Status() LockstepStatus { return l.status }
+
+// DesyncChecksum returns the 1-based index of the first mismatching checksum.
+func (l *Lockstep) DesyncChecksum() int { return l.desyncChecksum }
+
+// AddLocalAction queues the action to be sent with the next turn.
+// The action tick is assigned by the lockstep.
+func (l *Lockstep) AddLocalAction(a serverapi.PlayerAction) {
+	l.pending = append(l.pending, a)
+}
+
+// Sync sends the local turns, receives the remote turns and
+// reports whether the simulation can run its next step.
+//
+// tick is the current simulation tick (before the step).
+// checksums are the simulation state checksums collected so far.
+func (l *Lockstep) Sync(tick int, checksums []int) bool {
+	if l.isStopped() {
+		return false
+	}
+	l.localChecksums = checksums
+
+	if l.status == LockstepRunning {
+		if !l.IsSpectator() {
+			for l.nextSendTurn*TurnTicks <= tick {
+				l.sendTurn()
+			}
+		}
+		l.receiveTurns()
+	}
+	l.compareChecksums()
+	if l.isStopped() {
+		return false
+	}
+
+	return (tick+maxStepTicks)/TurnTicks < l.inputDelay+l.receivedTurns()
+}
+
+// isStopped reports whether the simulation can't advance anymore.
+//
+// The players leave the room right after the game is over,
+// but the spectator can be a few turns behind them:
+// it can still play the turns that were received before the disconnect.
+func (l *Lockstep) isStopped() bool {
+	switch l.status {
+	case LockstepRunning:
+		return false
+	case LockstepDisconnected:
+		return !l.IsSpectator()
+	default:
+		return true
+	}
+}
+
+// receivedTurns returns the number of turns that are received from all remote players.
+func (l *Lockstep) receivedTurns() int {
+	if !l.IsSpectator() {
+		return l.remoteTurns[1-l.localPlayer]
+	}
+	if l.remoteTurns[0] < l.remoteTurns[1] {
+		return l.remoteTurns[0]
+	}
+	return l.remoteTurns[1]
+}
+
+// TakeActions removes and returns the player actions that should be executed at this tick.
+func (l *Lockstep) TakeActions(player, tick int) []serverapi.PlayerAction {
+	list := l.scheduled[player]
+	n := 0
+	for n < len(list) && list[n].Tick <= tick {
+		n++
+	}
+	l.scheduled[player] = list[n:]
+	return list[:n]
+}
+
+func (l *Lockstep) Close() error {
+	return l.conn.Close()
+}
+
+func (l *Lockstep) sendTurn() {
+	turn := l.nextSendTurn
+	l.nextSendTurn++
+
+	execTick := (turn + l.inputDelay) * TurnTicks
+	actions := l.pending
+	l.pending = nil
+	for i := range actions {
+		actions[i].Tick = execTick
+	}
+	l.scheduled[l.localPlayer] = append(l.scheduled[l.localPlayer], actions...)
+
+	m := Message{
+		Kind:      MsgTurn,
+		Turn:      turn,
+		Actions:   actions,
+		Checksums: l.localChecksums[l.sentChecksums:],
+	}
+	l.sentChecksums = len(l.localChecksums)
+	if err := l.conn.Send(m); err != nil {
+		l.status = LockstepDisconnected
+	}
+}
+
+func (l *Lockstep) receiveTurns() {
+	for l.status == LockstepRunning {
+		m, ok, err := l.conn.Poll()
+		if err != nil {
+			l.status = LockstepDisconnected
+			return
+		}
+		if !ok {
+			return
+		}
+		switch m.Kind {
+		case MsgLeave:
+			l.status = LockstepDisconnected
+		case MsgTurn:
+			remotePlayer := 1 - l.localPlayer
+			if l.IsSpectator() {
+				// The spectator gets the turns of both players.
+				remotePlayer = m.Player
+				if remotePlayer != 0 && remotePlayer != 1 {
+					l.status = LockstepDisconnected
+					return
+				}
+			}
+			if m.Turn != l.remoteTurns[remotePlayer] {
+				// The turns are sent in order over a reliable connection;
+				// something is badly wrong with the other side.
+				l.status = LockstepDisconnected
+				return
+			}
+			l.remoteTurns[remotePlayer]++
+			execTick := (m.Turn + l.inputDelay) * TurnTicks
+			for _, a := range m.Actions {
+				a.Tick = execTick
+				l.scheduled[remotePlayer] = append(l.scheduled[remotePlayer], a)
+			}
+			if !l.IsSpectator() || remotePlayer == 0 {
+				l.remoteChecksums = append(l.remoteChecksums, m.Checksums...)
+			}
+		}
+	}
+}
+
+func (l *Lockstep) compareChecksums() {
+	n := len(l.localChecksums)
+	if len(l.remoteChecksums) < n {
+		n = len(l.remoteChecksums)
+	}
+	for i := l.checkedChecksum; i < n; i++ {
+		if l.localChecksums[i] != l.remoteChecksums[i] {
+			l.status = LockstepDesync
+			l.desyncChecksum = i + 1
+			return
+		}
+	}
+	l.checkedChecksum = n
+}
@@ -0,0 +1,409 @@
+package netplay
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+func startTestRelay(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go NewRelay().Serve(l)
+	return l.Addr().String()
+}
+
+func receiveWithTimeout(t *testing.T, c *Client) (Message, error) {
+	type result struct {
+		m   Message
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		m, err := c.Receive()
+		ch <- result{m, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.m, r.err
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive timeout")
+		return Message{}, nil
+	}
+}
+
+func TestRelay(t *testing.T) {
+	addr := startTestRelay(t)
+
+	host, err := Dial(addr, "room", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+	if host.PlayerID() != 0 {
+		t.Fatalf("host player ID is %d", host.PlayerID())
+	}
+
+	if _, err := Dial(addr, "room", 11); err == nil {
+		t.Fatal("expected a version mismatch error")
+	}
+
+	// The start message is sent before the guest joins.
+	config := &serverapi.ReplayLevelConfig{Seed: 123}
+	if err := host.Send(Message{Kind: MsgStart, Config: config, InputDelay: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	guest, err := Dial(addr, "room", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer guest.Close()
+	if guest.PlayerID() != 1 {
+		t.Fatalf("guest player ID is %d", guest.PlayerID())
+	}
+
+	if _, err := Dial(addr, "room", 10); err == nil {
+		t.Fatal("expected a full room error")
+	}
+
+	for _, c := range []*Client{host, guest} {
+		m, err := receiveWithTimeout(t, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Kind != MsgStart || m.Config.Seed != 123 || m.InputDelay != 3 {
+			t.Fatalf("player %d: unexpected %+v message", c.PlayerID(), m)
+		}
+	}
+
+	turn := Message{
+		Kind:    MsgTurn,
+		Turn:    4,
+		Actions: []serverapi.PlayerAction{{Tick: 42, Kind: serverapi.ActionMove}},
+	}
+	if err := guest.Send(turn); err != nil {
+		t.Fatal(err)
+	}
+	m, err := receiveWithTimeout(t, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Kind != MsgTurn || m.Turn != 4 || m.Player != 1 || len(m.Actions) != 1 || m.Actions[0].Tick != 42 {
+		t.Fatalf("unexpected %+v message", m)
+	}
+
+	guest.Close()
+	m, err = receiveWithTimeout(t, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Kind != MsgLeave {
+		t.Fatalf("unexpected %+v message", m)
+	}
+	if _, err := receiveWithTimeout(t, host); err == nil {
+		t.Fatal("expected the connection to be closed")
+	}
+}
+
+func TestRelaySpectator(t *testing.T) {
+	addr := startTestRelay(t)
+
+	if _, err := DialSpectator(addr, "room", 10); err == nil {
+		t.Fatal("expected a missing room error")
+	}
+
+	host, err := Dial(addr, "room", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+
+	if _, err := DialSpectator(addr, "room", 11); err == nil {
+		t.Fatal("expected a version mismatch error")
+	}
+	spectator, err := DialSpectator(addr, "room", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spectator.Close()
+	if spectator.PlayerID() != SpectatorID {
+		t.Fatalf("spectator player ID is %d", spectator.PlayerID())
+	}
+
+	guest, err := Dial(addr, "room", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer guest.Close()
+	if guest.PlayerID() != 1 {
+		t.Fatalf("guest player ID is %d", guest.PlayerID())
+	}
+
+	config := &serverapi.ReplayLevelConfig{Seed: 123}
+	if err := host.Send(Message{Kind: MsgStart, Config: config, InputDelay: 3}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*Client{host, guest, spectator} {
+		m, err := receiveWithTimeout(t, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Kind != MsgStart || m.Config.Seed != 123 || m.InputDelay != 3 {
+			t.Fatalf("player %d: unexpected %+v message", c.PlayerID(), m)
+		}
+	}
+
+	if _, err := DialSpectator(addr, "room", 10); err == nil {
+		t.Fatal("expected a game started error")
+	}
+
+	for _, sender := range []*Client{host, guest} {
+		turn := Message{Kind: MsgTurn, Turn: 0, Checksums: []int{sender.PlayerID() + 10}}
+		if err := sender.Send(turn); err != nil {
+			t.Fatal(err)
+		}
+		m, err := receiveWithTimeout(t, spectator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Kind != MsgTurn || m.Player != sender.PlayerID() || len(m.Checksums) != 1 || m.Checksums[0] != sender.PlayerID()+10 {
+			t.Fatalf("unexpected %+v message", m)
+		}
+	}
+
+	guest.Close()
+	m, err := receiveWithTimeout(t, spectator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Kind != MsgLeave || m.Player != 1 {
+		t.Fatalf("unexpected %+v message", m)
+	}
+	if _, err := receiveWithTimeout(t, spectator); err == nil {
+		t.Fatal("expected the connection to be closed")
+	}
+}
+
+// pipeConn is an in-memory Conn implementation.
+type pipeConn struct {
+	out *[]Message
+	in  *[]Message
+}
+
+func newPipe() (*pipeConn, *pipeConn) {
+	var a, b []Message
+	return &pipeConn{out: &a, in: &b}, &pipeConn{out: &b, in: &a}
+}
+
+func (c *pipeConn) Send(m Message) error {
+	m.Checksums = append([]int(nil), m.Checksums...)
+	*c.out = append(*c.out, m)
+	return nil
+}
+
+func (c *pipeConn) Poll() (Message, bool, error) {
+	if len(*c.in) == 0 {
+		return Message{}, false, nil
+	}
+	m := (*c.in)[0]
+	*c.in = (*c.in)[1:]
+	return m, true, nil
+}
+
+func (c *pipeConn) Close() error { return nil }
+
+// spectatedConn is a pipeConn that also sends the messages to the spectator.
+// The sender player ID is set like the relay does it.
+type spectatedConn struct {
+	*pipeConn
+	player    int
+	spectator *[]Message
+}
+
+func (c *spectatedConn) Send(m Message) error {
+	m.Player = c.player
+	m.Checksums = append([]int(nil), m.Checksums...)
+	*c.spectator = append(*c.spectator, m)
+	return c.pipeConn.Send(m)
+}
+
+type testPeer struct {
+	lockstep  *Lockstep
+	tick      int
+	checksums []int
+	executed  [2][]serverapi.PlayerAction
+}
+
+func (p *testPeer) step(stepTicks int) bool {
+	if !p.lockstep.Sync(p.tick, p.checksums) {
+		return false
+	}
+	p.tick += stepTicks
+	for player := range p.executed {
+		for _, a := range p.lockstep.TakeActions(player, p.tick) {
+			a.Tick = p.tick
+			p.executed[player] = append(p.executed[player], a)
+		}
+	}
+	return true
+}
+
+func TestLockstep(t *testing.T) {
+	for _, stepTicks := range []int{1, 2} {
+		connA, connB := newPipe()
+		a := &testPeer{lockstep: NewLockstep(connA, 0, 2)}
+		b := &testPeer{lockstep: NewLockstep(connB, 1, 2)}
+
+		a.lockstep.AddLocalAction(serverapi.PlayerAction{Kind: serverapi.ActionMove})
+		for i := 0; i < 10; i++ {
+			a.step(stepTicks)
+		}
+		if a.tick >= 2*TurnTicks {
+			t.Fatalf("x%d: the simulation is not stalled without the remote input (tick=%d)", stepTicks, a.tick)
+		}
+		b.lockstep.AddLocalAction(serverapi.PlayerAction{Kind: serverapi.ActionCard1})
+
+		for i := 0; i < 200; i++ {
+			a.step(stepTicks)
+			b.step(stepTicks)
+			if i == 50 {
+				b.lockstep.AddLocalAction(serverapi.PlayerAction{Kind: serverapi.ActionCard2})
+			}
+		}
+		if a.tick < 100 || b.tick < 100 {
+			t.Fatalf("x%d: the simulation is stalled (%d and %d ticks)", stepTicks, a.tick, b.tick)
+		}
+
+		for player := 0; player < 2; player++ {
+			have := a.executed[player]
+			want := b.executed[player]
+			if len(have) == 0 || !reflect.DeepEqual(have, want) {
+				t.Fatalf("x%d: player %d actions mismatch:\n%v\n%v", stepTicks, player, have, want)
+			}
+		}
+	}
+}
+
+func TestLockstepDesync(t *testing.T) {
+	connA, connB := newPipe()
+	a := &testPeer{lockstep: NewLockstep(connA, 0, 1)}
+	b := &testPeer{lockstep: NewLockstep(connB, 1, 1)}
+
+	// There is no limit for the number of checksums:
+	// a desync can happen late in the game.
+	const numMatching = 100
+	for i := 0; i < 3*numMatching; i++ {
+		if i < numMatching {
+			a.checksums = append(a.checksums, i)
+			b.checksums = append(b.checksums, i)
+		}
+		if i == numMatching+20 {
+			a.checksums = append(a.checksums, 20)
+			b.checksums = append(b.checksums, 30)
+		}
+		a.step(1)
+		b.step(1)
+	}
+
+	for _, p := range []*testPeer{a, b} {
+		if p.lockstep.Status() != LockstepDesync {
+			t.Fatalf("player %d: desync is not detected", p.lockstep.LocalPlayer())
+		}
+		if p.lockstep.DesyncChecksum() != numMatching+1 {
+			t.Fatalf("player %d: reported checksum %d", p.lockstep.LocalPlayer(), p.lockstep.DesyncChecksum())
+		}
+	}
+}
+
+func TestLockstepSpectator(t *testing.T) {
+	connA, connB := newPipe()
+	var spectatorInbox []Message
+	spectatorConn := &pipeConn{out: new([]Message), in: &spectatorInbox}
+	a := &testPeer{lockstep: NewLockstep(&spectatedConn{pipeConn: connA, player: 0, spectator: &spectatorInbox}, 0, 2)}
+	b := &testPeer{lockstep: NewLockstep(&spectatedConn{pipeConn: connB, player: 1, spectator: &spectatorInbox}, 1, 2)}
+	s := &testPeer{lockstep: NewSpectatorLockstep(spectatorConn, 2)}
+
+	for i := 0; i < 200; i++ {
+		if i == 10 {
+			a.lockstep.AddLocalAction(serverapi.PlayerAction{Kind: serverapi.ActionMove})
+		}
+		if i == 50 {
+			b.lockstep.AddLocalAction(serverapi.PlayerAction{Kind: serverapi.ActionCard2})
+		}
+		a.checksums = append(a.checksums, i)
+		b.checksums = append(b.checksums, i)
+		a.step(1)
+		b.step(1)
+		// The spectator runs slower and falls behind.
+		if i%2 == 0 {
+			s.checksums = append(s.checksums, s.tick)
+			s.step(1)
+		}
+	}
+	if s.tick >= a.tick-TurnTicks {
+		t.Fatalf("the spectator is expected to fall behind (%d and %d ticks)", s.tick, a.tick)
+	}
+	if len(*spectatorConn.out) != 0 {
+		t.Fatal("the spectator sent some messages")
+	}
+
+	// The players are gone, but the spectator can play the received turns.
+	spectatorInbox = append(spectatorInbox, Message{Kind: MsgLeave, Player: 1})
+	for i := 0; i < 200; i++ {
+		s.step(1)
+	}
+	if s.lockstep.Status() != LockstepDisconnected {
+		t.Fatalf("unexpected spectator status %d", s.lockstep.Status())
+	}
+	if s.tick < a.tick-TurnTicks {
+		t.Fatalf("the spectator is stalled (%d and %d ticks)", s.tick, a.tick)
+	}
+
+	for player := 0; player < 2; player++ {
+		have := s.executed[player]
+		want := a.executed[player]
+		if len(have) == 0 || !reflect.DeepEqual(have, want) {
+			t.Fatalf("player %d actions mismatch:\n%v\n%v", player, have, want)
+		}
+	}
+}
+
+func TestLockstepSpectatorDesync(t *testing.T) {
+	connA, connB := newPipe()
+	var spectatorInbox []Message
+	a := &testPeer{lockstep: NewLockstep(&spectatedConn{pipeConn: connA, player: 0, spectator: &spectatorInbox}, 0, 1)}
+	b := &testPeer{lockstep: NewLockstep(&spectatedConn{pipeConn: connB, player: 1, spectator: &spectatorInbox}, 1, 1)}
+	s := &testPeer{lockstep: NewSpectatorLockstep(&pipeConn{out: new([]Message), in: &spectatorInbox}, 1)}
+
+	for i := 0; i < 100; i++ {
+		a.checksums = append(a.checksums, i)
+		b.checksums = append(b.checksums, i)
+		if len(s.checksums) < len(a.checksums) {
+			checksum := len(s.checksums)
+			if checksum == 20 {
+				checksum = -1
+			}
+			s.checksums = append(s.checksums, checksum)
+		}
+		a.step(1)
+		b.step(1)
+		s.step(1)
+	}
+
+	if a.lockstep.Status() != LockstepRunning || b.lockstep.Status() != LockstepRunning {
+		t.Fatal("the players are expected to be in sync")
+	}
+	if s.lockstep.Status() != LockstepDesync {
+		t.Fatal("spectator desync is not detected")
+	}
+	if s.lockstep.DesyncChecksum() != 21 {
+		t.Fatalf("reported checksum %d", s.lockstep.DesyncChecksum())
+	}
+}
@@ -0,0 +1,74 @@
+// Package netplay implements the two-player lockstep network games.
+//
+// The clients don't talk to each other directly: they connect
+// to a relay (see cmd/relay) that pairs them by the room name
+// and forwards the messages between them.
+// The relay also forwards the turns to the spectators:
+// they run the same simulation, but don't send anything.
+//
+// The protocol is a stream of JSON-encoded messages over TCP.
+package netplay
+
+import (
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// DefaultRelayAddr is the address cmd/relay listens on by default.
+const DefaultRelayAddr = "localhost:7650"
+
+// SpectatorID is the player ID assigned to the spectators.
+const SpectatorID = -1
+
+type MessageKind string
+
+const (
+	// MsgJoin is the first message sent by the client.
+	// It specifies the room and the game version.
+	// The spectators can only join the existing rooms
+	// before the game is started.
+	MsgJoin MessageKind = "join"
+
+	// MsgWelcome is sent by the relay in response to the join message.
+	// It contains the assigned player ID: the room host is player 0.
+	// The spectators get SpectatorID.
+	MsgWelcome MessageKind = "welcome"
+
+	// MsgStart is sent by the room host; it contains the game settings.
+	// The relay sends it to both players and the spectators when the room is full.
+	MsgStart MessageKind = "start"
+
+	// MsgTurn contains the player actions for the turn.
+	// It's sent for every turn, even if there are no actions.
+	// The relay sets the sender player ID.
+	MsgTurn MessageKind = "turn"
+
+	// MsgLeave is sent by the relay when the other player disconnects.
+	// The spectators get it when any of the players disconnects.
+	MsgLeave MessageKind = "leave"
+
+	// MsgError is sent by the relay when the request can't be handled.
+	// The connection is closed right after that.
+	MsgError MessageKind = "error"
+)
+
+type Message struct {
+	Kind MessageKind `json:"kind"`
+
+	Room        string `json:"room,omitempty"`
+	GameVersion int    `json:"game_version,omitempty"`
+	Spectator   bool   `json:"spectator,omitempty"`
+
+	Player int `json:"player,omitempty"`
+
+	Config     *serverapi.ReplayLevelConfig `json:"config,omitempty"`
+	InputDelay int                          `json:"input_delay,omitempty"`
+
+	Turn    int                      `json:"turn,omitempty"`
+	Actions []serverapi.PlayerAction `json:"actions,omitempty"`
+
+	// Checksums are the simulation state checksums
+	// that were calculated since the previous turn message.
+	Checksums []int `json:"checksums,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
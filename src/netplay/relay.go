@@ -0,0 +1,289 @@
+package netplay
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quasilyte/ge/xslices"
+)
+
+// relayWriteTimeout limits the time a single message write can take.
+// A peer that doesn't read its messages is disconnected.
+const relayWriteTimeout = 10 * time.Second
+
+// maxRoomSpectators limits the number of spectators per room.
+// Every turn message is written to all of them.
+const maxRoomSpectators = 8
+
+// Relay pairs the clients by the room name and forwards
+// the messages between them.
+//
+// The relay doesn't run the simulation; it doesn't even look
+// into the turn messages, so it can't detect the desyncs.
+//
+// The relay mutex only protects the rooms state:
+// the messages are never written while it's held,
+// so a slow peer can't stall the other rooms.
+type Relay struct {
+	// Logf is used to report the rooms activity.
+	// Can be nil.
+	Logf func(format string, args ...any)
+
+	mu    sync.Mutex
+	rooms map[string]*relayRoom
+}
+
+type relayRoom struct {
+	name        string
+	gameVersion int
+	peers       [2]*relayPeer
+	spectators  []*relayPeer
+	start       *Message
+	started     bool
+}
+
+type relayPeer struct {
+	conn    net.Conn
+	mu      sync.Mutex
+	encoder *json.Encoder
+
+	// welcomed is set after the welcome message is sent.
+	// The start message can't be sent before that.
+	// Protected by the relay mutex.
+	welcomed bool
+}
+
+func NewRelay() *Relay {
+	return &Relay{rooms: make(map[string]*relayRoom)}
+}
+
+// Serve accepts the connections until the listener is closed.
+func (r *Relay) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go r.handleConn(conn)
+	}
+}
+
+func (r *Relay) logf(format string, args ...any) {
+	if r.Logf != nil {
+		r.Logf(format, args...)
+	}
+}
+
+func (p *relayPeer) send(m Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sendLocked(m)
+}
+
+func (p *relayPeer) sendLocked(m Message) {
+	p.conn.SetWriteDeadline(time.Now().Add(relayWriteTimeout))
+	if err := p.encoder.Encode(m); err != nil {
+		// The reading goroutine will notice it and leave the room.
+		p.conn.Close()
+	}
+}
+
+func (r *Relay) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	peer := &relayPeer{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+	}
+	decoder := json.NewDecoder(conn)
+
+	var join Message
+	if err := decoder.Decode(&join); err != nil {
+		return
+	}
+	if join.Kind != MsgJoin || join.Room == "" {
+		peer.send(Message{Kind: MsgError, Error: "expected a join message"})
+		return
+	}
+
+	if join.Spectator {
+		r.handleSpectator(join, peer, decoder)
+		return
+	}
+
+	room, playerID, errMsg := r.joinRoom(join, peer)
+	if errMsg != "" {
+		peer.send(Message{Kind: MsgError, Error: errMsg})
+		return
+	}
+	peer.send(Message{Kind: MsgWelcome, Player: playerID})
+	r.mu.Lock()
+	peer.welcomed = true
+	r.mu.Unlock()
+	r.logf("%s: player %d joined", room.name, playerID)
+	r.tryStart(room)
+
+	defer r.leaveRoom(room, playerID)
+
+	for {
+		var m Message
+		if err := decoder.Decode(&m); err != nil {
+			return
+		}
+		switch m.Kind {
+		case MsgStart:
+			if playerID != 0 {
+				continue
+			}
+			r.mu.Lock()
+			if !room.started {
+				room.start = &m
+			}
+			r.mu.Unlock()
+			r.tryStart(room)
+		case MsgTurn:
+			m.Player = playerID
+			r.mu.Lock()
+			other := room.peers[1-playerID]
+			spectators := append([]*relayPeer(nil), room.spectators...)
+			r.mu.Unlock()
+			if other != nil {
+				other.send(m)
+			}
+			for _, p := range spectators {
+				p.send(m)
+			}
+		}
+	}
+}
+
+func (r *Relay) handleSpectator(join Message, peer *relayPeer, decoder *json.Decoder) {
+	// The spectator is locked until the welcome message is sent,
+	// so the start message can't be sent before it.
+	peer.mu.Lock()
+	room, errMsg := r.watchRoom(join, peer)
+	if errMsg != "" {
+		peer.sendLocked(Message{Kind: MsgError, Error: errMsg})
+		peer.mu.Unlock()
+		return
+	}
+	peer.sendLocked(Message{Kind: MsgWelcome, Player: SpectatorID})
+	peer.mu.Unlock()
+	r.logf("%s: spectator joined", room.name)
+	r.tryStart(room)
+
+	defer r.unwatchRoom(room, peer)
+
+	// The spectators don't send anything after the join message;
+	// wait for the disconnect.
+	for {
+		var m Message
+		if err := decoder.Decode(&m); err != nil {
+			return
+		}
+	}
+}
+
+func (r *Relay) watchRoom(join Message, peer *relayPeer) (*relayRoom, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	room := r.rooms[join.Room]
+	switch {
+	case room == nil:
+		return nil, "the room doesn't exist"
+	case room.gameVersion != join.GameVersion:
+		return nil, "game version mismatch"
+	case room.started:
+		return nil, "the game is already started"
+	case len(room.spectators) >= maxRoomSpectators:
+		return nil, "too many spectators"
+	}
+	room.spectators = append(room.spectators, peer)
+	return room, ""
+}
+
+func (r *Relay) unwatchRoom(room *relayRoom, peer *relayPeer) {
+	r.mu.Lock()
+	room.spectators = xslices.Remove(room.spectators, peer)
+	r.mu.Unlock()
+
+	r.logf("%s: spectator left", room.name)
+}
+
+func (r *Relay) joinRoom(join Message, peer *relayPeer) (*relayRoom, int, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	room := r.rooms[join.Room]
+	if room == nil {
+		room = &relayRoom{name: join.Room, gameVersion: join.GameVersion}
+		room.peers[0] = peer
+		r.rooms[join.Room] = room
+		return room, 0, ""
+	}
+	if room.peers[1] != nil {
+		return nil, 0, "the room is full"
+	}
+	if room.gameVersion != join.GameVersion {
+		return nil, 0, "game version mismatch"
+	}
+	room.peers[1] = peer
+	return room, 1, ""
+}
+
+func (r *Relay) leaveRoom(room *relayRoom, playerID int) {
+	r.mu.Lock()
+	other := room.peers[1-playerID]
+	room.peers[playerID] = nil
+	spectators := room.spectators
+	room.spectators = nil
+	if r.rooms[room.name] == room {
+		delete(r.rooms, room.name)
+	}
+	r.mu.Unlock()
+
+	r.logf("%s: player %d left", room.name, playerID)
+	if other != nil {
+		other.send(Message{Kind: MsgLeave, Player: playerID})
+		other.conn.Close()
+	}
+	for _, p := range spectators {
+		p.send(Message{Kind: MsgLeave, Player: playerID})
+		p.conn.Close()
+	}
+}
+
+// tryStart sends the start message to both players and the spectators
+// once the room is full and the host has sent the game settings.
+func (r *Relay) tryStart(room *relayRoom) {
+	r.mu.Lock()
+	peers := room.peers
+	ready := !room.started && room.start != nil &&
+		peers[0] != nil && peers[0].welcomed &&
+		peers[1] != nil && peers[1].welcomed
+	if !ready {
+		r.mu.Unlock()
+		return
+	}
+	room.started = true
+	start := *room.start
+	receivers := append(peers[:], room.spectators...)
+	r.mu.Unlock()
+
+	// All receivers are locked until the start message is sent to them,
+	// so no turn message can be forwarded before it.
+	for _, p := range receivers {
+		p.mu.Lock()
+	}
+	for _, p := range receivers {
+		p.sendLocked(start)
+	}
+	for _, p := range receivers {
+		p.mu.Unlock()
+	}
+
+	r.logf("%s: game started", room.name)
+}
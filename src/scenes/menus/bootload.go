@@ -222,7 +222,7 @@ func (c *BootloadController) steamSync(ctx *ge.Context, config *assets.Config, p
 
 func (c *BootloadController) loadUIResources(ctx *ge.Context, config *assets.Config, progress *float64) {
 	*progress = 0.1
-	c.state.Resources.UI = eui.LoadResources(c.state.Device, c.scene.Context().Loader)
+	c.state.Resources.UI = eui.LoadResources(c.state.Device, c.scene.Context().Loader, c.state.Persistent.Settings.Graphics.UIScale)
 }
 
 func (c *BootloadController) loadExtra(ctx *ge.Context, config *assets.Config, progress *float64) {
@@ -0,0 +1,186 @@
+package menus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/scenes/staging"
+	"github.com/quasilyte/roboden-game/session"
+	"github.com/quasilyte/roboden-game/timeutil"
+)
+
+type CampaignMenuController struct {
+	state *session.State
+
+	scene *ge.Scene
+
+	helpLabel *widget.Text
+}
+
+func NewCampaignMenuController(state *session.State) *CampaignMenuController {
+	return &CampaignMenuController{state: state}
+}
+
+func (c *CampaignMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+	c.initUI()
+}
+
+func (c *CampaignMenuController) Update(delta float64) {
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *CampaignMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainerWithMinWidth(440, 10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.play.campaign"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	rootGrid := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{false, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+	rowContainer.AddChild(rootGrid)
+
+	buttonsContainer := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Stretch: true,
+			}),
+		),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(1),
+			widget.GridLayoutOpts.Stretch([]bool{true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4),
+		)),
+	)
+
+	leftPanel := eui.NewPanel(uiResources, 360, 0)
+	leftPanel.AddChild(buttonsContainer)
+	rootGrid.AddChild(leftPanel)
+
+	helpLabel := eui.NewLabel(d.Get("menu.overview.campaign"), c.state.Resources.UI.TextFont)
+	helpLabel.MaxWidth = 320
+	c.helpLabel = helpLabel
+
+	rightPanel := eui.NewTextPanel(uiResources, 360, 0)
+	rightPanel.AddChild(helpLabel)
+	rootGrid.AddChild(rightPanel)
+
+	var buttons []eui.Widget
+
+	stats := &c.state.Persistent.PlayerStats
+	// Every mission is locked until the previous one is completed.
+	locked := false
+	for i, m := range c.state.Campaign {
+		m := m
+		progress := stats.FindCampaignProgress(m.Name)
+		label := fmt.Sprintf("%d. %s", i+1, d.Get("campaign", m.Name))
+		if progress != nil {
+			label += fmt.Sprintf(" (%d/%d)", progress.Stars, m.MaxStars())
+		}
+		missionLocked := locked
+		b := eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
+			Scene: c.scene,
+			Text:  label,
+			OnPressed: func() {
+				c.startMission(m)
+			},
+			OnHover: func() { c.helpLabel.Label = c.missionDescriptionText(m, progress, missionLocked) },
+		})
+		b.GetWidget().Disabled = missionLocked
+		buttonsContainer.AddChild(b)
+		buttons = append(buttons, b)
+		if progress == nil {
+			locked = true
+		}
+	}
+
+	{
+		b := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+			c.back()
+		})
+		rowContainer.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
+	navTree := createSimpleNavTree(buttons)
+	setupUI(c.scene, root, c.state.MenuInput, navTree)
+}
+
+func (c *CampaignMenuController) missionDescriptionText(m *gamedata.CampaignMission, progress *session.CampaignProgress, locked bool) string {
+	d := c.scene.Dict()
+
+	if locked {
+		return d.Get("menu.campaign.locked")
+	}
+
+	var lines []string
+	lines = append(lines, d.Get("campaign", m.Name, "briefing"), "")
+	if progress != nil {
+		lines = append(lines,
+			fmt.Sprintf("%s: %d/%d", d.Get("menu.campaign.stars"), progress.Stars, m.MaxStars()),
+			fmt.Sprintf("%s: %s", d.Get("menu.campaign.best_time"), timeutil.FormatDuration(d, time.Duration(progress.BestTime)*time.Second)))
+	}
+	if len(m.StarTimes) != 0 {
+		starTimes := make([]string, len(m.StarTimes))
+		for i, t := range m.StarTimes {
+			starTimes[i] = timeutil.FormatDuration(d, time.Duration(t)*time.Second)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", d.Get("menu.campaign.star_times"), strings.Join(starTimes, ", ")))
+	}
+
+	var rewards []string
+	for _, name := range m.Unlocks.Cores {
+		rewards = append(rewards, d.Get("core", name))
+	}
+	for _, name := range m.Unlocks.Drones {
+		rewards = append(rewards, d.Get("drone", strings.ToLower(name)))
+	}
+	for _, name := range m.Unlocks.Turrets {
+		rewards = append(rewards, d.Get("turret", strings.ToLower(name)))
+	}
+	if len(rewards) != 0 {
+		lines = append(lines, fmt.Sprintf("%s: %s", d.Get("menu.campaign.rewards"), strings.Join(rewards, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (c *CampaignMenuController) startMission(m *gamedata.CampaignMission) {
+	config := gamedata.MakeLevelConfig(gamedata.ExecuteNormal, m.Config)
+	config.Tier2Recipes = append([]string(nil), m.Config.Tier2Recipes...)
+	config.CustomRules = gamedata.CloneCustomRules(m.Config.CustomRules)
+	// The interface mode is a player preference, not a part of the mission.
+	config.InterfaceMode = c.state.CustomLevelConfig.InterfaceMode
+	config.Mission = m
+	config.Finalize()
+
+	c.scene.Context().ChangeScene(staging.NewController(c.state, config, NewCampaignMenuController(c.state)))
+}
+
+func (c *CampaignMenuController) back() {
+	c.scene.Context().ChangeScene(NewPlayMenuController(c.state))
+}
@@ -124,7 +124,7 @@ func (c *ControlsGamepadMenuController) initUI() {
 
 	var buttons []eui.Widget
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	options := &c.state.Persistent.Settings
 
@@ -46,7 +46,7 @@ func (c *ControlsKeyboardMenuController) initUI() {
 
 	var buttons []eui.Widget
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	options := &c.state.Persistent.Settings
 
@@ -0,0 +1,360 @@
+package menus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/input"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gameinput"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+const rebindActionsPerPage = 8
+
+// A max time to wait for the user to press the new key.
+const rebindScanTimeout = 5.0
+
+var rebindMouseKeys = []input.Key{
+	input.KeyMouseLeft,
+	input.KeyMouseRight,
+	input.KeyMouseMiddle,
+}
+
+var rebindGamepadKeys = []input.Key{
+	input.KeyGamepadStart,
+	input.KeyGamepadBack,
+	input.KeyGamepadHome,
+	input.KeyGamepadUp,
+	input.KeyGamepadRight,
+	input.KeyGamepadDown,
+	input.KeyGamepadLeft,
+	input.KeyGamepadLStick,
+	input.KeyGamepadRStick,
+	input.KeyGamepadA,
+	input.KeyGamepadB,
+	input.KeyGamepadX,
+	input.KeyGamepadY,
+	input.KeyGamepadL1,
+	input.KeyGamepadL2,
+	input.KeyGamepadR1,
+	input.KeyGamepadR2,
+}
+
+type ControlsRebindMenuController struct {
+	state *session.State
+
+	device int
+	page   int
+
+	actions []*controls.RebindableAction
+
+	// A non-nil value means that we're waiting for the new key.
+	scanAction  *controls.RebindableAction
+	scanTimeout float64
+	keyScanner  *input.KeyScanner
+	probeInputs []*input.Handler
+
+	actionLabels []*widget.Text
+	keyButtons   []*widget.Button
+	pageLabel    *widget.Text
+	statusLabel  *widget.Text
+
+	scene *ge.Scene
+}
+
+func NewControlsRebindMenuController(state *session.State, device controls.BindingDevice) *ControlsRebindMenuController {
+	return &ControlsRebindMenuController{
+		state:  state,
+		device: int(device),
+	}
+}
+
+func (c *ControlsRebindMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+
+	ctx := scene.Context()
+
+	// The probe handlers are used to detect the mouse and gamepad buttons;
+	// every probe action is bound to a single key.
+	mouseKeymap := input.Keymap{}
+	for i, k := range rebindMouseKeys {
+		mouseKeymap[input.Action(i+1)] = []input.Key{k}
+	}
+	gamepadKeymap := input.Keymap{}
+	for i, k := range rebindGamepadKeys {
+		gamepadKeymap[input.Action(i+1)] = []input.Key{k}
+	}
+	keyboardHandler := ctx.Input.NewHandler(0, mouseKeymap)
+	c.keyScanner = input.NewKeyScanner(keyboardHandler)
+	c.probeInputs = []*input.Handler{
+		keyboardHandler,
+		ctx.Input.NewHandler(0, gamepadKeymap),
+		ctx.Input.NewHandler(1, gamepadKeymap),
+	}
+
+	c.initUI()
+	c.updateRows()
+}
+
+func (c *ControlsRebindMenuController) Update(delta float64) {
+	c.state.MenuInput.Update()
+
+	if c.scanAction != nil {
+		c.scanTimeout = gmath.ClampMin(c.scanTimeout-delta, 0)
+		if c.scanTimeout == 0 {
+			c.stopScan()
+			c.statusLabel.Label = ""
+			return
+		}
+		if k, ok := c.scanKey(); ok {
+			c.applyBinding(c.scanAction, k)
+		}
+		return
+	}
+
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *ControlsRebindMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainerWithMinWidth(520, 10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+
+	smallFont := c.state.Resources.UI.TextFont
+
+	var buttons []eui.Widget
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.options.controls")+" -> "+d.Get("menu.controls.rebind"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	deviceSelect := eui.NewSelectButton(eui.SelectButtonConfig{
+		Resources: uiResources,
+		Input:     c.state.MenuInput,
+		Value:     &c.device,
+		Label:     d.Get("menu.controls.rebind.device"),
+		ValueNames: []string{
+			d.Get("menu.controls.keyboard"),
+			d.Get("menu.controls.gamepad"),
+		},
+		OnPressed: func() {
+			c.stopScan()
+			c.page = 0
+			c.updateRows()
+		},
+	})
+	c.scene.AddObject(deviceSelect)
+	rowContainer.AddChild(deviceSelect.Widget)
+	buttons = append(buttons, deviceSelect.Widget)
+
+	panel := eui.NewTextPanel(uiResources, 0, 0)
+	rowContainer.AddChild(panel)
+	grid := eui.NewGridContainer(2, widget.GridLayoutOpts.Spacing(24, 4),
+		widget.GridLayoutOpts.Stretch([]bool{true, false}, nil))
+	for i := 0; i < rebindActionsPerPage; i++ {
+		rowIndex := i
+		label := eui.NewLabel("", smallFont)
+		b := eui.NewSmallButton(uiResources, c.scene, "", func() {
+			c.startScan(rowIndex)
+		})
+		b.GetWidget().MinWidth = 220
+		grid.AddChild(label)
+		grid.AddChild(b)
+		c.actionLabels = append(c.actionLabels, label)
+		c.keyButtons = append(c.keyButtons, b)
+		buttons = append(buttons, b)
+	}
+	panel.AddChild(grid)
+
+	pageGrid := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(3),
+			widget.GridLayoutOpts.Stretch([]bool{true, true, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+	prevPageButton := eui.NewButton(uiResources, c.scene, "<", func() {
+		c.setPage(c.page - 1)
+	})
+	c.pageLabel = eui.NewCenteredLabel("", smallFont)
+	nextPageButton := eui.NewButton(uiResources, c.scene, ">", func() {
+		c.setPage(c.page + 1)
+	})
+	pageGrid.AddChild(prevPageButton)
+	pageGrid.AddChild(c.pageLabel)
+	pageGrid.AddChild(nextPageButton)
+	rowContainer.AddChild(pageGrid)
+	buttons = append(buttons, prevPageButton, nextPageButton)
+
+	c.statusLabel = eui.NewCenteredLabel("", smallFont)
+	rowContainer.AddChild(c.statusLabel)
+
+	resetButton := eui.NewButton(uiResources, c.scene, d.Get("menu.controls.rebind.reset"), func() {
+		c.stopScan()
+		*c.getBindings() = nil
+		c.state.ReloadKeymaps()
+		c.statusLabel.Label = ""
+		c.updateRows()
+	})
+	rowContainer.AddChild(resetButton)
+	buttons = append(buttons, resetButton)
+
+	rowContainer.AddChild(eui.NewTransparentSeparator())
+
+	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	})
+	rowContainer.AddChild(backButton)
+	buttons = append(buttons, backButton)
+
+	navTree := createSimpleNavTree(buttons)
+	setupUI(c.scene, root, c.state.MenuInput, navTree)
+}
+
+func (c *ControlsRebindMenuController) bindingDevice() controls.BindingDevice {
+	return controls.BindingDevice(c.device)
+}
+
+func (c *ControlsRebindMenuController) getBindings() *map[string][]string {
+	if c.bindingDevice() == controls.BindingKeyboard {
+		return &c.state.Persistent.Settings.KeyboardBindings
+	}
+	return &c.state.Persistent.Settings.GamepadBindings
+}
+
+func (c *ControlsRebindMenuController) getKeymap() input.Keymap {
+	settings := &c.state.Persistent.Settings
+	keymaps := controls.MakeKeymapSet(settings.KeyboardBindings, settings.GamepadBindings)
+	if c.bindingDevice() == controls.BindingKeyboard {
+		return keymaps.KeyboardKeymap
+	}
+	return keymaps.FirstGamepadKeymap
+}
+
+func (c *ControlsRebindMenuController) getInput() *gameinput.Handler {
+	if c.bindingDevice() == controls.BindingKeyboard {
+		return &c.state.KeyboardInput
+	}
+	return &c.state.FirstGamepadInput
+}
+
+func (c *ControlsRebindMenuController) numPages() int {
+	return gmath.ClampMin((len(c.actions)+rebindActionsPerPage-1)/rebindActionsPerPage, 1)
+}
+
+func (c *ControlsRebindMenuController) setPage(page int) {
+	c.stopScan()
+	c.page = gmath.Clamp(page, 0, c.numPages()-1)
+	c.updateRows()
+}
+
+func (c *ControlsRebindMenuController) updateRows() {
+	d := c.scene.Dict()
+
+	c.actions = c.actions[:0]
+	for i := range controls.RebindableActions {
+		a := &controls.RebindableActions[i]
+		if a.HasDevice(c.bindingDevice()) {
+			c.actions = append(c.actions, a)
+		}
+	}
+
+	keymap := c.getKeymap()
+	h := c.getInput()
+	for i := range c.keyButtons {
+		actionIndex := c.page*rebindActionsPerPage + i
+		if actionIndex >= len(c.actions) {
+			c.actionLabels[i].Label = ""
+			c.keyButtons[i].Text().Label = ""
+			c.keyButtons[i].GetWidget().Disabled = true
+			continue
+		}
+		a := c.actions[actionIndex]
+		keyNames := make([]string, 0, 2)
+		for _, k := range keymap[a.Action] {
+			keyNames = append(keyNames, h.PrettyKeyName(k.String()))
+		}
+		c.actionLabels[i].Label = d.Get("menu.controls.action", a.Name)
+		c.keyButtons[i].Text().Label = strings.Join(keyNames, ", ")
+		c.keyButtons[i].GetWidget().Disabled = false
+	}
+
+	c.pageLabel.Label = fmt.Sprintf("%s %d/%d", d.Get("menu.replay.page"), c.page+1, c.numPages())
+}
+
+func (c *ControlsRebindMenuController) startScan(rowIndex int) {
+	actionIndex := c.page*rebindActionsPerPage + rowIndex
+	if actionIndex >= len(c.actions) {
+		return
+	}
+	c.updateRows()
+	c.scanAction = c.actions[actionIndex]
+	c.scanTimeout = rebindScanTimeout
+	c.keyButtons[rowIndex].Text().Label = "..."
+	c.statusLabel.Label = c.scene.Dict().Get("menu.controls.rebind.press_key")
+}
+
+func (c *ControlsRebindMenuController) stopScan() {
+	c.scanAction = nil
+}
+
+func (c *ControlsRebindMenuController) scanKey() (input.Key, bool) {
+	if c.bindingDevice() == controls.BindingGamepad {
+		for _, h := range c.probeInputs[1:] {
+			for i, k := range rebindGamepadKeys {
+				if h.ActionIsJustPressed(input.Action(i + 1)) {
+					return k, true
+				}
+			}
+		}
+		return input.Key{}, false
+	}
+
+	for i, k := range rebindMouseKeys {
+		if c.probeInputs[0].ActionIsJustPressed(input.Action(i + 1)) {
+			return k, true
+		}
+	}
+	k, status := c.keyScanner.Scan()
+	return k, status == input.KeyScanCompleted
+}
+
+func (c *ControlsRebindMenuController) applyBinding(a *controls.RebindableAction, k input.Key) {
+	d := c.scene.Dict()
+
+	c.stopScan()
+
+	if conflict := controls.FindBindingConflict(c.getKeymap(), c.bindingDevice(), a, k); conflict != nil {
+		c.statusLabel.Label = fmt.Sprintf("%s: %s", d.Get("menu.controls.rebind.conflict"), d.Get("menu.controls.action", conflict.Name))
+		c.updateRows()
+		return
+	}
+
+	bindings := c.getBindings()
+	if *bindings == nil {
+		*bindings = make(map[string][]string)
+	}
+	(*bindings)[a.Name] = []string{k.String()}
+	c.state.ReloadKeymaps()
+	c.statusLabel.Label = ""
+	c.updateRows()
+}
+
+func (c *ControlsRebindMenuController) back() {
+	c.state.SaveGameItem("save.json", c.state.Persistent)
+	c.scene.Context().ChangeScene(NewControlsMenuController(c.state))
+}
@@ -44,7 +44,7 @@ func (c *ControlsTouchMenuController) initUI() {
 
 	d := c.scene.Dict()
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	titleLabel := eui.NewCenteredLabel(d.Get("menu.main.settings")+" -> "+d.Get("menu.options.controls"), assets.BitmapFont3)
 	rowContainer.AddChild(titleLabel)
@@ -71,6 +71,14 @@ func (c *ControlsMenuController) initUI() {
 		buttons = append(buttons, b)
 	}
 
+	if !c.state.Device.IsMobile() {
+		b := eui.NewButton(uiResources, c.scene, d.Get("menu.controls.rebind"), func() {
+			c.scene.Context().ChangeScene(NewControlsRebindMenuController(c.state, controls.BindingKeyboard))
+		})
+		rowContainer.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
 	// TODO: show it for mobile devices.
 	// touchButton := eui.NewButton(uiResources, c.scene, d.Get("menu.controls.touch"), func() {
 	// })
@@ -72,8 +72,8 @@ func (c *LeaderboardBrowserController) initUI() {
 		fetchErr := c.fetchErr
 
 		d := c.scene.Dict()
-		smallFont := assets.BitmapFont1
-		tinyFont := assets.BitmapFont1
+		smallFont := c.state.Resources.UI.TextFont
+		tinyFont := c.state.Resources.UI.TextFont
 
 		{
 			numSeasons := c.selectedSeason + 1
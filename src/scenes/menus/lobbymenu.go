@@ -27,6 +27,24 @@ import (
 
 const dronesPerRow = 8
 
+// customObjectives are the objectives that can be selected in the custom mode lobby.
+var customObjectives = [...]struct {
+	objective gamedata.GameObjective
+	icon      resource.ImageID
+}{
+	{gamedata.ObjectiveBoss, assets.ImageActionBossAttack},
+	{gamedata.ObjectiveBuildBase, assets.ImageActionBuildColony},
+	{gamedata.ObjectiveDestroyCreepBases, assets.ImageCreepBase},
+	{gamedata.ObjectiveAcquireSuperElite, assets.ImageActionIncreaseTech},
+	{gamedata.ObjectiveTrigger, assets.ImageActionSendCreeps},
+	{gamedata.ObjectiveSurvive, assets.ImageAchievementHighTension},
+}
+
+// Custom mode time limits in minutes.
+var customTimeLimitOptions = []int{0, 10, 20, 30, 45, 60}
+
+var customResourceCapOptions = []int{0, 75, 150, 300}
+
 type LobbyMenuController struct {
 	state *session.State
 
@@ -44,10 +62,16 @@ type LobbyMenuController struct {
 
 	seedInput *widget.TextInput
 
-	goButton         *widget.Button
-	schemaButton     *widget.Button
-	randSchemaButton *widget.Button
-	backButton       *widget.Button
+	// initialSeed is used instead of a random seed when it's not zero.
+	// It's set when the lobby config comes from a share code.
+	initialSeed int64
+
+	goButton          *widget.Button
+	schemaButton      *widget.Button
+	randSchemaButton  *widget.Button
+	reverseSideButton *widget.Button
+	shareButton       *widget.Button
+	backButton        *widget.Button
 
 	colonyTab     *widget.TabBookTab
 	worldTab      *widget.TabBookTab
@@ -67,6 +91,15 @@ type LobbyMenuController struct {
 
 	recipeIcons map[gamedata.RecipeSubject]*ebiten.Image
 
+	botPersonalities      []*gamedata.BotPersonality
+	botPersonalityIndexes [2]int
+
+	waveSchedules          []*gamedata.CustomWaveSchedule
+	customWaveSchedule     int
+	customTimeLimit        int
+	customResourceCap      int
+	customObjectiveToggles [len(customObjectives)]bool
+
 	keyboard *eui.Keyboard
 
 	ui *eui.SceneObject
@@ -98,6 +131,11 @@ func (c *LobbyMenuController) Init(scene *ge.Scene) {
 
 	c.config = *c.getConfigForMode()
 
+	c.loadBotPersonalities()
+	if c.mode == gamedata.ModeCustom {
+		c.loadCustomRules()
+	}
+
 	if c.state.Persistent.Settings.MusicVolumeLevel != 0 {
 		scene.Audio().ContinueMusic(assets.AudioMusicTrack3)
 	}
@@ -166,7 +204,7 @@ func (c *LobbyMenuController) Update(delta float64) {
 }
 
 func (c *LobbyMenuController) prepareRecipeIcons() {
-	c.recipeIcons = gameui.GenerateRecipePreviews(c.scene, false, c.state.Persistent.Settings.LargeDiodes)
+	c.recipeIcons = gameui.GenerateRecipePreviews(c.scene, false, gameui.NewDiodeStyle(&c.state.Persistent.Settings))
 }
 
 func (c *LobbyMenuController) initUI() {
@@ -238,7 +276,7 @@ func (c *LobbyMenuController) initUI() {
 	c.updateDifficultyScore(c.calcDifficultyScore())
 
 	if c.config.RawGameMode == "reverse" {
-		c.randSchemaButton.GetWidget().Disabled = c.config.PlayersMode != serverapi.PmodeTwoPlayers
+		c.randSchemaButton.GetWidget().Disabled = !c.hasColonyPlayer()
 	}
 }
 
@@ -274,6 +312,11 @@ func (c *LobbyMenuController) bindNavTree() {
 		backButtonElem.Edges[gameui.NavLeft] = goButtonElem
 		randSchemaButtonElem.Edges[gameui.NavLeft] = schemaButtonElem
 		randSchemaButtonElem.Edges[gameui.NavUp] = backButtonElem
+
+		shareButtonElem := c.rightNavBlock.NewElem(c.shareButton)
+		schemaButtonElem.Edges[gameui.NavDown] = shareButtonElem
+		randSchemaButtonElem.Edges[gameui.NavDown] = shareButtonElem
+		shareButtonElem.Edges[gameui.NavUp] = schemaButtonElem
 	}
 
 	{
@@ -342,11 +385,103 @@ func (c *LobbyMenuController) bindNavTree() {
 	}
 }
 
+func (c *LobbyMenuController) loadBotPersonalities() {
+	personalities, err := gamedata.BotPersonalities()
+	if err != nil {
+		c.state.Logf("load bot personalities: %v", err)
+		personalities = []*gamedata.BotPersonality{gamedata.ResolveBotPersonality("")}
+	}
+	c.botPersonalities = personalities
+	for i, name := range c.config.BotPersonalities {
+		if i >= len(c.botPersonalityIndexes) {
+			break
+		}
+		index := xslices.IndexWhere(personalities, func(p *gamedata.BotPersonality) bool {
+			return p.Name == name
+		})
+		if index != -1 {
+			c.botPersonalityIndexes[i] = index
+		}
+	}
+}
+
+func (c *LobbyMenuController) syncBotPersonalities() {
+	names := make([]string, len(c.botPersonalityIndexes))
+	for i, index := range c.botPersonalityIndexes {
+		names[i] = c.botPersonalities[index].Name
+	}
+	isDefault := xslices.All(names, func(name string) bool {
+		return name == gamedata.DefaultBotPersonality
+	})
+	if isDefault {
+		// Keep the replay config as compact as possible.
+		names = nil
+	}
+	c.config.BotPersonalities = names
+}
+
+func (c *LobbyMenuController) loadCustomRules() {
+	schedules, err := gamedata.ParseCustomWaveSchedules(c.scene.LoadRaw(assets.RawCustomWavesJSON).Data)
+	if err != nil {
+		panic(err)
+	}
+	c.waveSchedules = schedules
+
+	rules := c.config.CustomRules
+	c.customWaveSchedule = xslices.IndexWhere(schedules, func(schedule *gamedata.CustomWaveSchedule) bool {
+		return schedule.Name == rules.WaveSchedule
+	})
+	if c.customWaveSchedule == -1 {
+		c.customWaveSchedule = 0
+	}
+	c.customTimeLimit = xslices.Index(customTimeLimitOptions, rules.TimeLimit/60)
+	if c.customTimeLimit == -1 {
+		c.customTimeLimit = 0
+	}
+	c.customResourceCap = xslices.Index(customResourceCapOptions, rules.ResourceCap)
+	if c.customResourceCap == -1 {
+		c.customResourceCap = 0
+	}
+	for i, o := range customObjectives {
+		c.customObjectiveToggles[i] = xslices.Contains(rules.Objectives, o.objective.String())
+	}
+}
+
+// syncCustomRules rebuilds the custom mode rules from the lobby options.
+// The go button is disabled while these rules are not valid.
+func (c *LobbyMenuController) syncCustomRules() {
+	if c.mode != gamedata.ModeCustom {
+		return
+	}
+
+	schedule := c.waveSchedules[c.customWaveSchedule]
+	rules := &serverapi.CustomModeRules{
+		Objectives:   make([]string, 0, len(customObjectives)),
+		ColoniesGoal: c.config.CustomRules.ColoniesGoal,
+		TimeLimit:    customTimeLimitOptions[c.customTimeLimit] * 60,
+		ResourceCap:  customResourceCapOptions[c.customResourceCap],
+		WaveSchedule: schedule.Name,
+		Waves:        schedule.Waves,
+	}
+	for i, o := range customObjectives {
+		if c.customObjectiveToggles[i] {
+			rules.Objectives = append(rules.Objectives, o.objective.String())
+		}
+	}
+	c.config.CustomRules = gamedata.CloneCustomRules(rules)
+
+	if c.goButton != nil {
+		c.goButton.GetWidget().Disabled = gamedata.ValidateCustomRules(c.config.CustomRules) != nil
+	}
+}
+
 func (c *LobbyMenuController) getConfigForMode() *gamedata.LevelConfig {
 	return c.state.GetConfigForMode(c.mode)
 }
 
 func (c *LobbyMenuController) saveConfig() {
+	c.syncBotPersonalities()
+	c.syncCustomRules()
 	*c.getConfigForMode() = c.config.Clone()
 }
 
@@ -355,7 +490,7 @@ func (c *LobbyMenuController) createButtonsPanel(uiResources *eui.Resources) *wi
 
 	d := c.scene.Dict()
 
-	tinyFont := assets.BitmapFont1
+	tinyFont := c.state.Resources.UI.TextFont
 
 	c.difficultyLabel = eui.NewCenteredLabel("Difficulty: 1000%", tinyFont)
 	panel.AddChild(c.difficultyLabel)
@@ -383,11 +518,7 @@ func (c *LobbyMenuController) createButtonsPanel(uiResources *eui.Resources) *wi
 
 		c.config.GameMode = c.mode
 		c.config.DronePointsAllocated = c.calcAllocatedPoints()
-		if c.seedInput.GetText() != "" {
-			seed, err := strconv.ParseInt(c.seedInput.GetText(), 10, 64)
-			if err != nil {
-				panic(err)
-			}
+		if seed := c.currentSeed(); seed != 0 {
 			c.config.Seed = seed
 		} else {
 			c.config.Seed = c.randomSeed()
@@ -438,6 +569,22 @@ func (c *LobbyMenuController) createButtonsPanel(uiResources *eui.Resources) *wi
 	})
 	buttonsGrid.AddChild(c.randSchemaButton)
 
+	c.shareButton = eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
+		Scene: c.scene,
+		Text:  d.Get("menu.lobby.share_code"),
+		OnPressed: func() {
+			c.saveConfig()
+			c.scene.Context().ChangeScene(NewShareCodeMenuController(c.state, c.mode, c.currentSeed()))
+		},
+		OnHover: func() {
+			c.setHelpText(c.optionDescriptionText("menu.lobby.share_code"))
+		},
+	})
+	c.shareButton.GetWidget().LayoutData = widget.RowLayoutData{
+		Stretch: true,
+	}
+	panel.AddChild(c.shareButton)
+
 	return panel
 }
 
@@ -456,7 +603,7 @@ func (c *LobbyMenuController) createTabs(uiResources *eui.Resources) *widget.Tab
 	c.tabs = tabs
 
 	if c.config.RawGameMode == "reverse" {
-		c.maybeDisableColonyTab(c.config.PlayersMode != serverapi.PmodeTwoPlayers)
+		c.maybeDisableColonyTab(!c.hasColonyPlayer())
 	}
 
 	t := widget.NewTabBook(
@@ -480,6 +627,27 @@ func (c *LobbyMenuController) createTabs(uiResources *eui.Resources) *widget.Tab
 	return t
 }
 
+// hasColonyPlayer reports whether the colony is controlled by a human player.
+// In reverse mode, it's only true for the modes where the creeps are
+// controlled by a second player or by a bot.
+func (c *LobbyMenuController) hasColonyPlayer() bool {
+	if c.config.RawGameMode != "reverse" {
+		return true
+	}
+	return c.config.PlayersMode == serverapi.PmodeTwoPlayers ||
+		gamedata.IsCreepsBotGame(c.config.ReplayLevelConfig)
+}
+
+func (c *LobbyMenuController) updateReverseColonyOptions() {
+	if c.config.RawGameMode != "reverse" {
+		return
+	}
+	disable := !c.hasColonyPlayer()
+	c.maybeDisableColonyTab(disable)
+	c.randSchemaButton.GetWidget().Disabled = disable
+	c.reverseSideButton.GetWidget().Disabled = c.config.PlayersMode != serverapi.PmodeSinglePlayer
+}
+
 func (c *LobbyMenuController) maybeDisableColonyTab(disable bool) {
 	if c.config.RawGameMode != "reverse" {
 		return
@@ -525,14 +693,52 @@ func (c *LobbyMenuController) createExtraTab(uiResources *eui.Resources) *widget
 		})
 		tab.AddChild(b)
 		verticalButtons = append(verticalButtons, navBlock.NewElem(b))
-		b.PressedEvent.AddHandler(func(args interface{}) {
-			// This handler is called before the config value is changed.
-			disable := c.config.PlayersMode == serverapi.PmodeTwoPlayers
-			c.maybeDisableColonyTab(disable)
-			c.randSchemaButton.GetWidget().Disabled = disable
+		b.ClickedEvent.AddHandler(func(args interface{}) {
+			// This handler is called after the config value is changed.
+			c.updateReverseColonyOptions()
 		})
 	}
 
+	if c.config.RawGameMode == "reverse" {
+		key := "menu.lobby.reverse_side"
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			PlaySound: true,
+			Resources: uiResources,
+			Input:     c.state.MenuInput,
+			BoolValue: &c.config.ReverseCreepsBot,
+			Label:     d.Get(key),
+			ValueNames: []string{
+				d.Get("menu.lobby.reverse_side.dreadnought"),
+				d.Get("menu.lobby.reverse_side.colony"),
+			},
+			OnPressed: func() {
+				c.updateReverseColonyOptions()
+				c.updateDifficultyScore(c.calcDifficultyScore())
+			},
+			OnHover: func() {
+				c.setHelpText(c.optionDescriptionText(key))
+			},
+		})
+		c.scene.AddObject(b)
+		c.reverseSideButton = b.Widget
+		c.reverseSideButton.GetWidget().Disabled = c.config.PlayersMode != serverapi.PmodeSinglePlayer
+		tab.AddChild(b.Widget)
+		verticalButtons = append(verticalButtons, navBlock.NewElem(b.Widget))
+	}
+
+	if c.config.RawGameMode != "reverse" {
+		valueNames := make([]string, len(c.botPersonalities))
+		for i, p := range c.botPersonalities {
+			valueNames[i] = d.Get("menu.lobby.bot_personality", p.Name)
+		}
+		for i := range c.botPersonalityIndexes {
+			key := fmt.Sprintf("menu.lobby.bot%d_personality", i+1)
+			b := c.newOptionButton(&c.botPersonalityIndexes[i], key, valueNames)
+			tab.AddChild(b)
+			verticalButtons = append(verticalButtons, navBlock.NewElem(b))
+		}
+	}
+
 	if c.config.RawGameMode != "reverse" {
 		disabled := []int{}
 		if c.config.RawGameMode == "arena" || c.config.RawGameMode == "inf_arena" {
@@ -625,7 +831,7 @@ func (c *LobbyMenuController) createDifficultyTab(uiResources *eui.Resources) *w
 		)),
 	)
 
-	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeBlitz {
+	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeBlitz || c.mode == gamedata.ModeCustom {
 		disabled := []int{}
 		if c.mode == gamedata.ModeBlitz {
 			disabled = []int{0, 1, 5}
@@ -716,9 +922,18 @@ func (c *LobbyMenuController) createDifficultyTab(uiResources *eui.Resources) *w
 		})
 		tab.AddChild(superCreepRateSelect)
 		verticalButtons = append(verticalButtons, navBlock.NewElem(superCreepRateSelect))
+
+		botDifficultySelect := c.newOptionButton(&c.config.ReverseBotDifficulty, "menu.lobby.reverse_bot_difficulty", []string{
+			d.Get("menu.lobby.reverse_bot_difficulty.easy"),
+			d.Get("menu.lobby.reverse_bot_difficulty.normal"),
+			d.Get("menu.lobby.reverse_bot_difficulty.hard"),
+			d.Get("menu.lobby.reverse_bot_difficulty.brutal"),
+		})
+		tab.AddChild(botDifficultySelect)
+		verticalButtons = append(verticalButtons, navBlock.NewElem(botDifficultySelect))
 	}
 
-	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeReverse {
+	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeReverse || c.mode == gamedata.ModeCustom {
 		bossDifficultySelect := c.newOptionButton(&c.config.BossDifficulty, "menu.lobby.boss_difficulty", []string{
 			d.Get("menu.power.weak"),
 			d.Get("menu.power.normal"),
@@ -775,6 +990,40 @@ func (c *LobbyMenuController) createDifficultyTab(uiResources *eui.Resources) *w
 		verticalButtons = append(verticalButtons, navBlock.NewElem(b))
 	}
 
+	if c.mode == gamedata.ModeCustom {
+		scheduleNames := make([]string, len(c.waveSchedules))
+		for i, schedule := range c.waveSchedules {
+			scheduleNames[i] = d.Get("menu.lobby.wave_schedule", schedule.Name)
+		}
+		waveScheduleSelect := c.newOptionButton(&c.customWaveSchedule, "menu.lobby.wave_schedule", scheduleNames)
+		tab.AddChild(waveScheduleSelect)
+		verticalButtons = append(verticalButtons, navBlock.NewElem(waveScheduleSelect))
+
+		timeLimitNames := make([]string, len(customTimeLimitOptions))
+		for i, minutes := range customTimeLimitOptions {
+			if minutes == 0 {
+				timeLimitNames[i] = d.Get("menu.option.none")
+				continue
+			}
+			timeLimitNames[i] = fmt.Sprintf(d.Get("menu.lobby.time_limit.minutes_f"), minutes)
+		}
+		timeLimitSelect := c.newOptionButton(&c.customTimeLimit, "menu.lobby.time_limit", timeLimitNames)
+		tab.AddChild(timeLimitSelect)
+		verticalButtons = append(verticalButtons, navBlock.NewElem(timeLimitSelect))
+
+		resourceCapNames := make([]string, len(customResourceCapOptions))
+		for i, value := range customResourceCapOptions {
+			if value == 0 {
+				resourceCapNames[i] = d.Get("menu.option.none")
+				continue
+			}
+			resourceCapNames[i] = strconv.Itoa(value)
+		}
+		resourceCapSelect := c.newOptionButton(&c.customResourceCap, "menu.lobby.resource_cap", resourceCapNames)
+		tab.AddChild(resourceCapSelect)
+		verticalButtons = append(verticalButtons, navBlock.NewElem(resourceCapSelect))
+	}
+
 	panel := eui.NewPanel(uiResources, 0, 0)
 
 	grid := widget.NewContainer(
@@ -798,7 +1047,7 @@ func (c *LobbyMenuController) createDifficultyTab(uiResources *eui.Resources) *w
 	if c.mode == gamedata.ModeReverse {
 		toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.EliteFleet, "elite_fleet", assets.ImageItemEliteFleet))
 	}
-	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeBlitz {
+	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeBlitz || c.mode == gamedata.ModeCustom {
 		toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.SuperCreeps, "super_creeps", assets.ImageItemSuperCreeps))
 	}
 	toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.CreepFortress, "creep_fortress", assets.ImageItemFortress))
@@ -810,6 +1059,15 @@ func (c *LobbyMenuController) createDifficultyTab(uiResources *eui.Resources) *w
 	case gamedata.ModeClassic, gamedata.ModeArena, gamedata.ModeInfArena, gamedata.ModeBlitz:
 		toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.GrenadierCreeps, "grenadier_creeps", assets.ImageCreepGrenadier))
 	}
+	if c.mode == gamedata.ModeClassic || c.mode == gamedata.ModeBlitz {
+		toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.AdaptiveDifficulty, "adaptive_difficulty", assets.ImageCreepBase))
+	}
+	if c.mode == gamedata.ModeCustom {
+		for i, o := range customObjectives {
+			key := "objective." + o.objective.String()
+			toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.customObjectiveToggles[i], key, o.icon))
+		}
+	}
 
 	for _, b := range toggleButtons {
 		grid.AddChild(b.Widget)
@@ -1001,6 +1259,7 @@ func (c *LobbyMenuController) createWorldTab(uiResources *eui.Resources) *widget
 
 	toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.GoldEnabled, "gold_enabled", assets.ImageEssenceGoldSource))
 	toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.WeatherEnabled, "weather_enabled", assets.ImageItemWeather))
+	toggleButtons = append(toggleButtons, c.newToggleItemButton(&c.config.WeatherEffects, "weather_effects", assets.ImageItemWeather))
 
 	for _, b := range toggleButtons {
 		grid.AddChild(b.Widget)
@@ -1037,7 +1296,7 @@ func (c *LobbyMenuController) createColonyTab(uiResources *eui.Resources) *widge
 		)),
 	)
 
-	tinyFont := assets.BitmapFont1
+	tinyFont := c.state.Resources.UI.TextFont
 
 	tab.AddChild(c.createBasesPanel(uiResources))
 	tab.AddChild(c.createTurretsPanel(uiResources))
@@ -1059,6 +1318,7 @@ func (c *LobbyMenuController) createColonyTab(uiResources *eui.Resources) *widge
 }
 
 func (c *LobbyMenuController) calcDifficultyScore() int {
+	c.syncCustomRules()
 	return gamedata.CalcDifficultyScore(c.config.ReplayLevelConfig, c.calcAllocatedPoints())
 }
 
@@ -1105,7 +1365,7 @@ func (c *LobbyMenuController) createHelpPanel(uiResources *eui.Resources) *widge
 	panel := eui.NewTextPanel(uiResources, 0, 0)
 	c.helpPanel = panel
 
-	tinyFont := assets.BitmapFont1
+	tinyFont := c.state.Resources.UI.TextFont
 
 	label := eui.NewLabel("", tinyFont)
 	label.MaxWidth = 305
@@ -1127,10 +1387,23 @@ func (c *LobbyMenuController) randomSeed() int64 {
 	}
 }
 
+// currentSeed returns the seed from the seed input.
+// A zero value means that the seed will be selected randomly.
+func (c *LobbyMenuController) currentSeed() int64 {
+	if c.seedInput.GetText() == "" {
+		return 0
+	}
+	seed, err := strconv.ParseInt(c.seedInput.GetText(), 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	return seed
+}
+
 func (c *LobbyMenuController) createSeedPanel(uiResources *eui.Resources) *widget.Container {
 	worldSettingsPanel := eui.NewPanel(uiResources, 340, 0)
 
-	tinyFont := assets.BitmapFont1
+	tinyFont := c.state.Resources.UI.TextFont
 
 	d := c.scene.Dict()
 
@@ -1167,7 +1440,11 @@ func (c *LobbyMenuController) createSeedPanel(uiResources *eui.Resources) *widge
 				}
 				return onlyDigits, nil
 			}))
-		randSeed := strconv.FormatInt(c.randomSeed(), 10)
+		seed := c.initialSeed
+		if seed <= 0 {
+			seed = c.randomSeed()
+		}
+		randSeed := strconv.FormatInt(seed, 10)
 		if len(randSeed) >= maxSeedLen {
 			randSeed = randSeed[:maxSeedLen]
 		}
@@ -1323,7 +1600,7 @@ func (c *LobbyMenuController) createTurretsPanel(uiResources *eui.Resources) *wi
 func (c *LobbyMenuController) createDronesPanel(uiResources *eui.Resources) *widget.Container {
 	dronesPanel := eui.NewPanel(uiResources, 0, 0)
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	grid := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
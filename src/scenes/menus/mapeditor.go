@@ -0,0 +1,693 @@
+package menus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/scenes/staging"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+type mapEditorTool int
+
+const (
+	mapEditorToolTile mapEditorTool = iota
+	mapEditorToolWall
+	mapEditorToolForest
+	mapEditorToolResource
+	mapEditorToolTeleporter
+	mapEditorToolCreepBase
+	mapEditorToolSpawn
+)
+
+var mapEditorToolNames = []string{
+	"tile",
+	"wall",
+	"forest",
+	"resource",
+	"teleporter",
+	"creep_base",
+	"spawn",
+}
+
+const (
+	mapEditorPanelWidth = 240
+
+	mapEditorPanSpeed = 600.0
+
+	maxMapEditorHistory = 100
+)
+
+// MapEditorController is a scene that creates the gamedata.LevelMap files.
+//
+// The controller can be entered several times: the UI is re-created
+// after the world settings are changed and after the test play is over.
+type MapEditorController struct {
+	state *session.State
+
+	scene *ge.Scene
+
+	levelMap *gamedata.LevelMap
+	filename string
+
+	undoStack []*gamedata.LevelMap
+	redoStack []*gamedata.LevelMap
+
+	// These are the select button values.
+	tool         int
+	tileFrame    int
+	resourceKind int
+	worldSize    int
+	worldShape   int
+	environment  int
+
+	numTileFrames int
+
+	// strokeSnapshot is a map state before the current
+	// tile or wall stroke; the entire stroke is a single undo step.
+	strokeSnapshot *gamedata.LevelMap
+	strokeChanged  bool
+
+	forestStart       *gamedata.MapCell
+	pendingTeleporter *gamedata.MapCell
+
+	panning    bool
+	lastCursor gmath.Vec
+
+	canvas      *mapEditorCanvas
+	statusLabel *widget.Text
+	status      string
+}
+
+func NewMapEditorController(state *session.State) *MapEditorController {
+	return &MapEditorController{state: state}
+}
+
+func (c *MapEditorController) Init(scene *ge.Scene) {
+	c.scene = scene
+
+	if c.levelMap == nil {
+		c.openFirstMap()
+	}
+
+	c.worldSize = c.levelMap.WorldSize
+	c.worldShape = c.levelMap.WorldShape
+	c.environment = c.levelMap.Environment
+
+	imageID, _ := gamedata.EnvironmentTileset(gamedata.EnvironmentKind(c.levelMap.Environment))
+	c.numTileFrames = mapEditorNumTileFrames(scene.LoadImage(imageID).Data)
+	if c.tileFrame >= c.numTileFrames {
+		c.tileFrame = 0
+	}
+
+	c.resetInputState()
+
+	ctx := scene.Context()
+	c.canvas = newMapEditorCanvas(scene, c.levelMap, gmath.Vec{
+		X: ctx.ScreenWidth - mapEditorPanelWidth,
+		Y: ctx.ScreenHeight,
+	})
+	scene.AddGraphics(c.canvas)
+
+	c.initUI()
+}
+
+func (c *MapEditorController) openFirstMap() {
+	c.levelMap = gamedata.NewLevelMap(2, gamedata.WorldSquare, gamedata.EnvMoon)
+	c.filename = ""
+
+	filenames, err := c.state.ListLevelMaps()
+	if err != nil || len(filenames) == 0 {
+		return
+	}
+	m, err := c.state.LoadLevelMap(filenames[0])
+	if err != nil {
+		c.state.Logf("load %q map: %v", filenames[0], err)
+		return
+	}
+	c.levelMap = m
+	c.filename = filenames[0]
+}
+
+func (c *MapEditorController) Update(delta float64) {
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionEditorUndo) {
+		c.undo()
+		return
+	}
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionEditorRedo) {
+		c.redo()
+		return
+	}
+
+	c.handlePanning(delta)
+	c.handleCursor()
+	c.updateStatus()
+}
+
+func (c *MapEditorController) handlePanning(delta float64) {
+	input := c.state.MenuInput
+
+	var panDelta gmath.Vec
+	if input.ActionIsPressed(controls.ActionPanRight) {
+		panDelta.X += mapEditorPanSpeed * delta
+	}
+	if input.ActionIsPressed(controls.ActionPanLeft) {
+		panDelta.X -= mapEditorPanSpeed * delta
+	}
+	if input.ActionIsPressed(controls.ActionPanDown) {
+		panDelta.Y += mapEditorPanSpeed * delta
+	}
+	if input.ActionIsPressed(controls.ActionPanUp) {
+		panDelta.Y -= mapEditorPanSpeed * delta
+	}
+
+	cursorPos := input.AnyCursorPos()
+	if input.ActionIsPressed(controls.ActionPanAlt) {
+		if c.panning {
+			panDelta = panDelta.Add(c.lastCursor.Sub(cursorPos))
+		}
+		c.panning = true
+	} else {
+		c.panning = false
+	}
+	c.lastCursor = cursorPos
+
+	if !panDelta.IsZero() {
+		c.canvas.Pan(panDelta)
+	}
+}
+
+func (c *MapEditorController) handleCursor() {
+	input := c.state.MenuInput
+
+	cursorPos := input.AnyCursorPos()
+	if !c.canvas.ContainsScreenPos(cursorPos) {
+		c.canvas.cursorCell = gamedata.MapCell{X: -1, Y: -1}
+		c.finishStroke()
+		return
+	}
+	cell := c.canvas.ScreenPosToCell(cursorPos)
+	c.canvas.cursorCell = cell
+	if !c.levelMap.InBounds(cell) {
+		return
+	}
+
+	switch mapEditorTool(c.tool) {
+	case mapEditorToolTile, mapEditorToolWall:
+		c.handleStroke(cell)
+	case mapEditorToolForest:
+		c.handleForest(cell)
+	case mapEditorToolTeleporter:
+		c.handleTeleporter(cell)
+	default:
+		if input.ActionIsJustPressed(controls.ActionClick) {
+			c.edit(func(m *gamedata.LevelMap) bool { return c.place(m, cell) })
+		} else if input.ActionIsJustPressed(controls.ActionMoveChoice) {
+			c.edit(func(m *gamedata.LevelMap) bool { return c.erase(m, cell) })
+		}
+	}
+}
+
+func (c *MapEditorController) handleStroke(cell gamedata.MapCell) {
+	input := c.state.MenuInput
+
+	painting := input.ActionIsPressed(controls.ActionClick)
+	erasing := !painting && input.ActionIsPressed(controls.ActionMoveChoice)
+	if !painting && !erasing {
+		c.finishStroke()
+		return
+	}
+
+	if c.strokeSnapshot == nil {
+		c.strokeSnapshot = c.levelMap.Clone()
+		c.strokeChanged = false
+	}
+	var changed bool
+	if painting {
+		changed = c.place(c.levelMap, cell)
+	} else {
+		changed = c.erase(c.levelMap, cell)
+	}
+	if changed {
+		c.strokeChanged = true
+		c.canvas.MarkDirty()
+	}
+}
+
+func (c *MapEditorController) finishStroke() {
+	if c.strokeSnapshot == nil {
+		return
+	}
+	if c.strokeChanged {
+		c.pushUndo(c.strokeSnapshot)
+	}
+	c.strokeSnapshot = nil
+	c.strokeChanged = false
+}
+
+func (c *MapEditorController) handleForest(cell gamedata.MapCell) {
+	input := c.state.MenuInput
+
+	if input.ActionIsJustPressed(controls.ActionMoveChoice) {
+		c.forestStart = nil
+		c.canvas.previewRect = nil
+		c.edit(func(m *gamedata.LevelMap) bool { return c.erase(m, cell) })
+		return
+	}
+
+	if input.ActionIsJustPressed(controls.ActionClick) {
+		c.forestStart = &cell
+	}
+	if c.forestStart == nil {
+		return
+	}
+
+	rect := mapEditorMakeRect(*c.forestStart, cell)
+	if input.ActionIsPressed(controls.ActionClick) {
+		c.canvas.previewRect = &rect
+		return
+	}
+
+	c.forestStart = nil
+	c.canvas.previewRect = nil
+	c.edit(func(m *gamedata.LevelMap) bool {
+		m.Forests = append(m.Forests, rect)
+		return true
+	})
+}
+
+func (c *MapEditorController) handleTeleporter(cell gamedata.MapCell) {
+	input := c.state.MenuInput
+
+	if input.ActionIsJustPressed(controls.ActionMoveChoice) {
+		c.pendingTeleporter = nil
+		c.canvas.pendingCell = nil
+		c.edit(func(m *gamedata.LevelMap) bool { return c.erase(m, cell) })
+		return
+	}
+	if !input.ActionIsJustPressed(controls.ActionClick) {
+		return
+	}
+
+	if c.pendingTeleporter == nil {
+		c.pendingTeleporter = &cell
+		c.canvas.pendingCell = c.pendingTeleporter
+		return
+	}
+	from := *c.pendingTeleporter
+	c.pendingTeleporter = nil
+	c.canvas.pendingCell = nil
+	if from == cell {
+		return
+	}
+	c.edit(func(m *gamedata.LevelMap) bool {
+		m.Teleporters = append(m.Teleporters, gamedata.MapTeleporter{From: from, To: cell})
+		return true
+	})
+}
+
+// place puts the current tool object at the cell.
+// It reports whether the map was changed.
+func (c *MapEditorController) place(m *gamedata.LevelMap, cell gamedata.MapCell) bool {
+	switch mapEditorTool(c.tool) {
+	case mapEditorToolTile:
+		i := xslices.IndexWhere(m.Tiles, func(t gamedata.MapTile) bool { return t.MapCell == cell })
+		if i != -1 {
+			if m.Tiles[i].Frame == c.tileFrame {
+				return false
+			}
+			m.Tiles[i].Frame = c.tileFrame
+			return true
+		}
+		m.Tiles = append(m.Tiles, gamedata.MapTile{MapCell: cell, Frame: c.tileFrame})
+		return true
+
+	case mapEditorToolWall:
+		if xslices.Contains(m.Walls, cell) {
+			return false
+		}
+		m.Walls = append(m.Walls, cell)
+		return true
+
+	case mapEditorToolResource:
+		kind := gamedata.LevelMapResourceKinds[c.resourceKind]
+		m.Resources = xslices.RemoveIf(m.Resources, func(r gamedata.MapResource) bool { return r.MapCell == cell })
+		m.Resources = append(m.Resources, gamedata.MapResource{MapCell: cell, Kind: kind})
+		return true
+
+	case mapEditorToolCreepBase:
+		if xslices.Contains(m.CreepBases, cell) {
+			return false
+		}
+		m.CreepBases = append(m.CreepBases, cell)
+		return true
+
+	case mapEditorToolSpawn:
+		if xslices.Contains(m.SpawnPoints, cell) {
+			return false
+		}
+		m.SpawnPoints = append(m.SpawnPoints, cell)
+		return true
+
+	default:
+		return false
+	}
+}
+
+// erase removes the current tool objects from the cell.
+// It reports whether the map was changed.
+func (c *MapEditorController) erase(m *gamedata.LevelMap, cell gamedata.MapCell) bool {
+	n := mapEditorNumObjects(m)
+
+	switch mapEditorTool(c.tool) {
+	case mapEditorToolTile:
+		m.Tiles = xslices.RemoveIf(m.Tiles, func(t gamedata.MapTile) bool { return t.MapCell == cell })
+	case mapEditorToolWall:
+		m.Walls = xslices.RemoveIf(m.Walls, func(w gamedata.MapCell) bool { return w == cell })
+	case mapEditorToolForest:
+		m.Forests = xslices.RemoveIf(m.Forests, func(r gamedata.MapRect) bool { return r.Contains(cell) })
+	case mapEditorToolResource:
+		m.Resources = xslices.RemoveIf(m.Resources, func(r gamedata.MapResource) bool { return r.MapCell == cell })
+	case mapEditorToolTeleporter:
+		m.Teleporters = xslices.RemoveIf(m.Teleporters, func(tp gamedata.MapTeleporter) bool {
+			return tp.From == cell || tp.To == cell
+		})
+	case mapEditorToolCreepBase:
+		m.CreepBases = xslices.RemoveIf(m.CreepBases, func(b gamedata.MapCell) bool { return b == cell })
+	case mapEditorToolSpawn:
+		m.SpawnPoints = xslices.RemoveIf(m.SpawnPoints, func(p gamedata.MapCell) bool { return p == cell })
+	}
+
+	return n != mapEditorNumObjects(m)
+}
+
+// edit applies a single undoable change to the map.
+func (c *MapEditorController) edit(f func(m *gamedata.LevelMap) bool) {
+	snapshot := c.levelMap.Clone()
+	if !f(c.levelMap) {
+		return
+	}
+	c.pushUndo(snapshot)
+	c.canvas.MarkDirty()
+}
+
+func (c *MapEditorController) pushUndo(snapshot *gamedata.LevelMap) {
+	c.undoStack = append(c.undoStack, snapshot)
+	if len(c.undoStack) > maxMapEditorHistory {
+		c.undoStack = c.undoStack[1:]
+	}
+	c.redoStack = c.redoStack[:0]
+}
+
+func (c *MapEditorController) undo() {
+	if len(c.undoStack) == 0 {
+		return
+	}
+	c.finishStroke()
+	m := c.undoStack[len(c.undoStack)-1]
+	c.undoStack = c.undoStack[:len(c.undoStack)-1]
+	c.redoStack = append(c.redoStack, c.levelMap)
+	c.setMap(m)
+}
+
+func (c *MapEditorController) redo() {
+	if len(c.redoStack) == 0 {
+		return
+	}
+	c.finishStroke()
+	m := c.redoStack[len(c.redoStack)-1]
+	c.redoStack = c.redoStack[:len(c.redoStack)-1]
+	c.undoStack = append(c.undoStack, c.levelMap)
+	c.setMap(m)
+}
+
+// setMap replaces the edited map.
+// If the world settings are different, the UI is re-created.
+func (c *MapEditorController) setMap(m *gamedata.LevelMap) {
+	settingsChanged := m.WorldSize != c.levelMap.WorldSize ||
+		m.WorldShape != c.levelMap.WorldShape ||
+		m.Environment != c.levelMap.Environment
+	c.levelMap = m
+	if settingsChanged {
+		c.scene.Context().ChangeScene(c)
+		return
+	}
+	c.resetInputState()
+	c.canvas.SetMap(m)
+}
+
+func (c *MapEditorController) resetInputState() {
+	c.strokeSnapshot = nil
+	c.strokeChanged = false
+	c.forestStart = nil
+	c.pendingTeleporter = nil
+	if c.canvas != nil {
+		c.canvas.previewRect = nil
+		c.canvas.pendingCell = nil
+	}
+}
+
+func (c *MapEditorController) resetMap(m *gamedata.LevelMap, filename string) {
+	c.levelMap = m
+	c.filename = filename
+	c.undoStack = c.undoStack[:0]
+	c.redoStack = c.redoStack[:0]
+	c.scene.Context().ChangeScene(c)
+}
+
+func (c *MapEditorController) applySettings() {
+	if c.worldSize == c.levelMap.WorldSize && c.worldShape == c.levelMap.WorldShape && c.environment == c.levelMap.Environment {
+		return
+	}
+	c.pushUndo(c.levelMap.Clone())
+	c.levelMap.WorldSize = c.worldSize
+	c.levelMap.WorldShape = c.worldShape
+	c.levelMap.Environment = c.environment
+	c.levelMap.Crop()
+	c.scene.Context().ChangeScene(c)
+}
+
+func (c *MapEditorController) updateStatus() {
+	d := c.scene.Dict()
+
+	var lines []string
+	filename := c.filename
+	if filename == "" {
+		filename = "?"
+	}
+	lines = append(lines, filename)
+	if c.levelMap.InBounds(c.canvas.cursorCell) {
+		lines = append(lines, fmt.Sprintf("x=%d y=%d", c.canvas.cursorCell.X, c.canvas.cursorCell.Y))
+	}
+	if c.canvas.numUnreachable != 0 {
+		lines = append(lines, fmt.Sprintf("%s: %d", d.Get("menu.map_editor.unreachable"), c.canvas.numUnreachable))
+	}
+	if c.status != "" {
+		lines = append(lines, c.status)
+	}
+
+	c.statusLabel.Label = strings.Join(lines, "\n")
+}
+
+func (c *MapEditorController) setStatus(s string) {
+	c.status = s
+}
+
+func (c *MapEditorController) initUI() {
+	uiResources := c.state.Resources.UI
+	d := c.scene.Dict()
+
+	root := eui.NewAnchorContainer()
+
+	panel := eui.NewPanel(uiResources, mapEditorPanelWidth, 0,
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+			HorizontalPosition: widget.AnchorLayoutPositionEnd,
+			StretchVertical:    true,
+		})))
+	root.AddChild(panel)
+
+	var buttons []eui.Widget
+
+	addSelect := func(value *int, label string, valueNames []string, onPressed func()) {
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			PlaySound:  true,
+			Resources:  uiResources,
+			Input:      c.state.MenuInput,
+			Value:      value,
+			Label:      label,
+			ValueNames: valueNames,
+			OnPressed:  onPressed,
+			LayoutData: widget.RowLayoutData{Stretch: true},
+		})
+		c.scene.AddObject(b)
+		panel.AddChild(b.Widget)
+		buttons = append(buttons, b.Widget)
+	}
+
+	addButton := func(label string, onPressed func()) {
+		b := eui.NewSmallButton(uiResources, c.scene, label, onPressed)
+		b.GetWidget().LayoutData = widget.RowLayoutData{Stretch: true}
+		panel.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
+	{
+		toolNames := make([]string, len(mapEditorToolNames))
+		for i, name := range mapEditorToolNames {
+			toolNames[i] = d.Get("menu.map_editor.tool", name)
+		}
+		addSelect(&c.tool, d.Get("menu.map_editor.tool"), toolNames, func() {
+			c.resetInputState()
+		})
+	}
+
+	{
+		frameNames := make([]string, c.numTileFrames)
+		for i := range frameNames {
+			frameNames[i] = strconv.Itoa(i + 1)
+		}
+		addSelect(&c.tileFrame, d.Get("menu.map_editor.tile"), frameNames, nil)
+	}
+
+	{
+		kindNames := make([]string, len(gamedata.LevelMapResourceKinds))
+		for i, kind := range gamedata.LevelMapResourceKinds {
+			kindNames[i] = d.Get("menu.map_editor.resource", kind)
+		}
+		addSelect(&c.resourceKind, d.Get("menu.map_editor.resource"), kindNames, nil)
+	}
+
+	addSelect(&c.worldSize, d.Get("menu.lobby.world_size"), []string{
+		d.Get("menu.option.very_small"),
+		d.Get("menu.option.small"),
+		d.Get("menu.option.normal"),
+		d.Get("menu.option.big"),
+	}, c.applySettings)
+
+	addSelect(&c.worldShape, d.Get("menu.lobby.world_shape"), []string{
+		d.Get("menu.lobby.world_shape.square"),
+		d.Get("menu.lobby.world_shape.horizontal"),
+		d.Get("menu.lobby.world_shape.vertical"),
+	}, c.applySettings)
+
+	addSelect(&c.environment, d.Get("menu.lobby.environment"), []string{
+		d.Get("menu.lobby.forest"),
+		d.Get("menu.lobby.inferno"),
+		d.Get("menu.lobby.moon"),
+		d.Get("menu.lobby.snow"),
+	}, c.applySettings)
+
+	panel.AddChild(eui.NewTransparentSeparator())
+
+	addButton(d.Get("menu.map_editor.undo"), c.undo)
+	addButton(d.Get("menu.map_editor.redo"), c.redo)
+	addButton(d.Get("menu.map_editor.new"), func() {
+		c.resetMap(gamedata.NewLevelMap(c.levelMap.WorldSize, gamedata.WorldShape(c.levelMap.WorldShape), gamedata.EnvironmentKind(c.levelMap.Environment)), "")
+	})
+	addButton(d.Get("menu.map_editor.open"), c.openNextMap)
+	addButton(d.Get("menu.map_editor.save"), c.save)
+	addButton(d.Get("menu.map_editor.test_play"), c.testPlay)
+	addButton(d.Get("menu.back"), c.back)
+
+	panel.AddChild(eui.NewTransparentSeparator())
+
+	c.statusLabel = eui.NewLabel(d.Get("menu.map_editor.hint"), uiResources.TextFont)
+	c.statusLabel.MaxWidth = mapEditorPanelWidth - 20
+	panel.AddChild(c.statusLabel)
+
+	setupUI(c.scene, root, c.state.MenuInput, createSimpleNavTree(buttons))
+}
+
+// openNextMap cycles through the saved maps.
+func (c *MapEditorController) openNextMap() {
+	d := c.scene.Dict()
+
+	filenames, err := c.state.ListLevelMaps()
+	if err != nil || len(filenames) == 0 {
+		c.setStatus(d.Get("menu.map_editor.no_maps"))
+		return
+	}
+
+	filename := filenames[0]
+	if i := xslices.Index(filenames, c.filename); i != -1 {
+		filename = filenames[(i+1)%len(filenames)]
+	}
+	m, err := c.state.LoadLevelMap(filename)
+	if err != nil {
+		c.setStatus(err.Error())
+		return
+	}
+	c.status = ""
+	c.resetMap(m, filename)
+}
+
+func (c *MapEditorController) save() {
+	if c.filename == "" {
+		c.filename = c.state.NewLevelMapFilename()
+	}
+	if err := c.state.SaveLevelMap(c.filename, c.levelMap); err != nil {
+		c.setStatus(err.Error())
+		return
+	}
+	c.setStatus(c.scene.Dict().Get("menu.map_editor.saved"))
+}
+
+func (c *MapEditorController) testPlay() {
+	if err := c.levelMap.Validate(); err != nil {
+		c.setStatus(fmt.Sprintf("%s: %v", c.scene.Dict().Get("menu.map_editor.invalid"), err))
+		return
+	}
+
+	config := c.state.ClassicLevelConfig.Clone()
+	config.ExecMode = gamedata.ExecuteNormal
+	config.PlayersMode = serverapi.PmodeSinglePlayer
+	config.Seed = c.scene.Rand().PositiveInt64()
+	config.WorldSize = c.levelMap.WorldSize
+	config.WorldShape = c.levelMap.WorldShape
+	config.Environment = c.levelMap.Environment
+	config.NumCreepBases = len(c.levelMap.CreepBases)
+	config.Map = c.levelMap.Clone()
+	config.Finalize()
+
+	c.scene.Context().ChangeScene(staging.NewController(c.state, config, c))
+}
+
+func (c *MapEditorController) back() {
+	c.scene.Context().ChangeScene(NewPlayMenuController(c.state))
+}
+
+func mapEditorMakeRect(a, b gamedata.MapCell) gamedata.MapRect {
+	minX, maxX := a.X, b.X
+	if minX > maxX {
+		minX, maxX = maxX, minX
+	}
+	minY, maxY := a.Y, b.Y
+	if minY > maxY {
+		minY, maxY = maxY, minY
+	}
+	return gamedata.MapRect{
+		MapCell: gamedata.MapCell{X: minX, Y: minY},
+		Width:   maxX - minX + 1,
+		Height:  maxY - minY + 1,
+	}
+}
+
+func mapEditorNumObjects(m *gamedata.LevelMap) int {
+	return len(m.Tiles) + len(m.Walls) + len(m.Forests) + len(m.Resources) +
+		len(m.Teleporters) + len(m.CreepBases) + len(m.SpawnPoints)
+}
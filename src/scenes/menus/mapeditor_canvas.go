@@ -0,0 +1,349 @@
+package menus
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/gedraw"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/pathing"
+)
+
+// mapEditorReachLayer is the same as the staging normal pathing layer:
+// the free cells and forests can be walked, the walls are blocked.
+var mapEditorReachLayer = pathing.MakeGridLayer(1, 0, 1, 0)
+
+const (
+	mapEditorTagBlocked uint8 = 1
+	mapEditorTagForest  uint8 = 2
+)
+
+var (
+	mapEditorWallColor        = color.RGBA{R: 40, G: 28, B: 20, A: 230}
+	mapEditorForestColor      = color.RGBA{R: 10, G: 60, B: 20, A: 110}
+	mapEditorSnowForestColor  = color.RGBA{R: 110, G: 120, B: 120, A: 130}
+	mapEditorUnreachableColor = color.RGBA{R: 90, A: 90}
+	mapEditorTeleporterColor  = color.RGBA{R: 60, G: 120, B: 200, A: 200}
+	mapEditorCreepBaseColor   = color.RGBA{R: 200, G: 40, B: 40, A: 220}
+	mapEditorSpawnColor       = color.RGBA{R: 60, G: 200, B: 80, A: 200}
+	mapEditorCursorColor      = color.RGBA{R: 100, G: 100, B: 100, A: 100}
+)
+
+var mapEditorResourceColors = map[string]color.RGBA{
+	"iron":        {R: 150, G: 150, B: 160, A: 255},
+	"gold":        {R: 230, G: 190, B: 40, A: 255},
+	"crystal":     {R: 80, G: 200, B: 230, A: 255},
+	"red_crystal": {R: 230, G: 60, B: 90, A: 255},
+	"oil":         {R: 30, G: 30, B: 30, A: 255},
+	"red_oil":     {R: 120, G: 20, B: 20, A: 255},
+	"sulfur":      {R: 210, G: 220, B: 60, A: 255},
+	"organic":     {R: 90, G: 170, B: 60, A: 255},
+	"mineral":     {R: 170, G: 110, B: 200, A: 255},
+	"scrap":       {R: 130, G: 100, B: 70, A: 255},
+	"artifact":    {R: 240, G: 240, B: 240, A: 255},
+}
+
+// mapEditorCanvas renders the edited map and its reachability overlay.
+//
+// The background and the overlay are rendered into the world-sized images
+// only when the map is changed; every frame draws the visible part of them.
+type mapEditorCanvas struct {
+	scene *ge.Scene
+
+	levelMap *gamedata.LevelMap
+
+	// offset is a world position of the canvas top-left corner.
+	offset gmath.Vec
+	size   gmath.Vec
+
+	worldWidth  float64
+	worldHeight float64
+
+	bg      *ge.TiledBackground
+	overlay *ebiten.Image
+	dirty   bool
+
+	// numUnreachable is a number of walkable cells that
+	// can't be reached from any of the spawn points.
+	numUnreachable int
+
+	// cursorCell is highlighted if it's inside the map bounds.
+	cursorCell gamedata.MapCell
+	// previewRect is drawn while the forest rect is being selected.
+	previewRect *gamedata.MapRect
+	// pendingCell is a first end of the teleporter being placed.
+	pendingCell *gamedata.MapCell
+}
+
+func newMapEditorCanvas(scene *ge.Scene, m *gamedata.LevelMap, size gmath.Vec) *mapEditorCanvas {
+	return &mapEditorCanvas{
+		scene:    scene,
+		levelMap: m,
+		size:     size,
+		dirty:    true,
+	}
+}
+
+func (c *mapEditorCanvas) IsDisposed() bool { return false }
+
+func (c *mapEditorCanvas) SetMap(m *gamedata.LevelMap) {
+	c.levelMap = m
+	c.dirty = true
+}
+
+func (c *mapEditorCanvas) MarkDirty() {
+	c.dirty = true
+}
+
+// Pan moves the canvas view, keeping it inside the world bounds.
+func (c *mapEditorCanvas) Pan(delta gmath.Vec) {
+	c.offset = c.offset.Add(delta)
+	c.clampOffset()
+}
+
+func (c *mapEditorCanvas) clampOffset() {
+	c.offset.X = gmath.Clamp(c.offset.X, 0, gmath.ClampMin(c.worldWidth-c.size.X, 0))
+	c.offset.Y = gmath.Clamp(c.offset.Y, 0, gmath.ClampMin(c.worldHeight-c.size.Y, 0))
+}
+
+// ContainsScreenPos reports whether the screen position belongs to the canvas.
+func (c *mapEditorCanvas) ContainsScreenPos(pos gmath.Vec) bool {
+	return pos.X >= 0 && pos.Y >= 0 && pos.X < c.size.X && pos.Y < c.size.Y
+}
+
+func (c *mapEditorCanvas) ScreenPosToCell(pos gmath.Vec) gamedata.MapCell {
+	worldPos := pos.Add(c.offset)
+	return gamedata.MapCell{
+		X: int(worldPos.X / pathing.CellSize),
+		Y: int(worldPos.Y / pathing.CellSize),
+	}
+}
+
+func (c *mapEditorCanvas) rebuild() {
+	c.dirty = false
+
+	m := c.levelMap
+	width, height := gamedata.WorldDimensions(m.WorldSize, gamedata.WorldShape(m.WorldShape))
+	if c.overlay == nil || width != c.worldWidth || height != c.worldHeight {
+		if c.overlay != nil {
+			c.overlay.Dispose()
+		}
+		c.overlay = ebiten.NewImage(int(width), int(height))
+		c.worldWidth = width
+		c.worldHeight = height
+		c.clampOffset()
+	}
+
+	c.rebuildBackground()
+	c.rebuildOverlay()
+}
+
+func (c *mapEditorCanvas) rebuildBackground() {
+	m := c.levelMap
+	ctx := c.scene.Context()
+
+	// The random tiles should not change between the rebuilds,
+	// so the same seed is used every time.
+	var rand gmath.Rand
+	rand.SetSeed(int64(m.Environment) + 1)
+	imageID, tileset := gamedata.EnvironmentTileset(gamedata.EnvironmentKind(m.Environment))
+	c.bg = ge.NewTiledBackground(ctx)
+	c.bg.LoadTilesetWithRand(ctx, &rand, c.worldWidth, c.worldHeight, imageID, tileset)
+
+	tiles := c.scene.LoadImage(imageID).Data
+	numFrames := mapEditorNumTileFrames(tiles)
+	for _, t := range m.Tiles {
+		if t.Frame >= numFrames {
+			continue
+		}
+		var drawOptions ebiten.DrawImageOptions
+		drawOptions.GeoM.Translate(float64(t.X)*pathing.CellSize, float64(t.Y)*pathing.CellSize)
+		c.bg.DrawImage(mapEditorTileFrame(tiles, t.Frame), &drawOptions)
+	}
+}
+
+func (c *mapEditorCanvas) rebuildOverlay() {
+	m := c.levelMap
+	c.overlay.Clear()
+
+	grid := c.buildPathgrid()
+	snowy := gamedata.EnvironmentKind(m.Environment) == gamedata.EnvSnow
+
+	for _, r := range m.Forests {
+		clr := mapEditorForestColor
+		if snowy {
+			clr = mapEditorSnowForestColor
+		}
+		gedraw.DrawRect(c.overlay, mapEditorRectBounds(r), clr)
+	}
+	for _, w := range m.Walls {
+		gedraw.DrawRect(c.overlay, mapEditorCellBounds(w), mapEditorWallColor)
+	}
+
+	// Without the spawn points, there is nothing to reach the cells from.
+	c.numUnreachable = 0
+	if len(m.SpawnPoints) != 0 {
+		reachable := c.findReachable(grid)
+		numCols, numRows := grid.Size()
+		for y := 0; y < numRows; y++ {
+			for x := 0; x < numCols; x++ {
+				coord := pathing.GridCoord{X: x, Y: y}
+				if grid.GetCellValue(coord, mapEditorReachLayer) == 0 {
+					continue
+				}
+				if reachable[grid.CoordToIndex(coord)] {
+					continue
+				}
+				c.numUnreachable++
+				gedraw.DrawRect(c.overlay, mapEditorCellBounds(gamedata.MapCell{X: x, Y: y}), mapEditorUnreachableColor)
+			}
+		}
+	}
+
+	for _, tp := range m.Teleporters {
+		from := mapEditorCellCenter(tp.From)
+		to := mapEditorCellCenter(tp.To)
+		gedraw.DrawCircle(c.overlay, from, 20, mapEditorTeleporterColor)
+		gedraw.DrawCircle(c.overlay, to, 20, mapEditorTeleporterColor)
+		gedraw.DrawCircle(c.overlay, from, 6, color.RGBA{A: 255})
+		gedraw.DrawCircle(c.overlay, to, 6, color.RGBA{A: 255})
+	}
+	for _, b := range m.CreepBases {
+		gedraw.DrawCircle(c.overlay, mapEditorCellCenter(b), 24, mapEditorCreepBaseColor)
+	}
+	for _, r := range m.Resources {
+		gedraw.DrawCircle(c.overlay, mapEditorCellCenter(r.MapCell), 10, mapEditorResourceColors[r.Kind])
+	}
+	for _, p := range m.SpawnPoints {
+		gedraw.DrawCircle(c.overlay, mapEditorCellCenter(p), 28, mapEditorSpawnColor)
+	}
+}
+
+func (c *mapEditorCanvas) buildPathgrid() *pathing.Grid {
+	m := c.levelMap
+	grid := pathing.NewGrid(c.worldWidth, c.worldHeight, 0)
+
+	// Snowy forests can't be passed through.
+	forestTag := mapEditorTagForest
+	if gamedata.EnvironmentKind(m.Environment) == gamedata.EnvSnow {
+		forestTag = mapEditorTagBlocked
+	}
+	for _, r := range m.Forests {
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				grid.SetCellTag(pathing.GridCoord{X: x, Y: y}, forestTag)
+			}
+		}
+	}
+	for _, w := range m.Walls {
+		grid.SetCellTag(pathing.GridCoord{X: w.X, Y: w.Y}, mapEditorTagBlocked)
+	}
+
+	return grid
+}
+
+// findReachable does a flood fill from all spawn points.
+// The teleporters connect the regions they're placed in.
+func (c *mapEditorCanvas) findReachable(grid *pathing.Grid) map[int]bool {
+	m := c.levelMap
+	reachable := make(map[int]bool)
+
+	var queue []pathing.GridCoord
+	push := func(coord pathing.GridCoord) {
+		// Out of bounds cells are reported as blocked.
+		if grid.GetCellValue(coord, mapEditorReachLayer) == 0 {
+			return
+		}
+		index := grid.CoordToIndex(coord)
+		if reachable[index] {
+			return
+		}
+		reachable[index] = true
+		queue = append(queue, coord)
+	}
+
+	for _, p := range m.SpawnPoints {
+		push(pathing.GridCoord{X: p.X, Y: p.Y})
+	}
+	for len(queue) != 0 {
+		coord := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		push(pathing.GridCoord{X: coord.X + 1, Y: coord.Y})
+		push(pathing.GridCoord{X: coord.X - 1, Y: coord.Y})
+		push(pathing.GridCoord{X: coord.X, Y: coord.Y + 1})
+		push(pathing.GridCoord{X: coord.X, Y: coord.Y - 1})
+		for _, tp := range m.Teleporters {
+			switch {
+			case tp.From.X == coord.X && tp.From.Y == coord.Y:
+				push(pathing.GridCoord{X: tp.To.X, Y: tp.To.Y})
+			case tp.To.X == coord.X && tp.To.Y == coord.Y:
+				push(pathing.GridCoord{X: tp.From.X, Y: tp.From.Y})
+			}
+		}
+	}
+
+	return reachable
+}
+
+func (c *mapEditorCanvas) Draw(screen *ebiten.Image) {
+	if c.dirty {
+		c.rebuild()
+	}
+
+	section := gmath.Rect{
+		Min: c.offset,
+		Max: c.offset.Add(c.size),
+	}
+	c.bg.DrawPartialWithOffset(screen, section, c.offset.Neg())
+
+	src := c.overlay.SubImage(image.Rect(int(section.Min.X), int(section.Min.Y), int(section.Max.X), int(section.Max.Y))).(*ebiten.Image)
+	screen.DrawImage(src, nil)
+
+	if c.previewRect != nil {
+		gedraw.DrawRect(screen, c.toScreenRect(mapEditorRectBounds(*c.previewRect)), mapEditorCursorColor)
+	}
+	if c.pendingCell != nil {
+		gedraw.DrawCircle(screen, mapEditorCellCenter(*c.pendingCell).Sub(c.offset), 20, mapEditorTeleporterColor)
+	}
+	if c.levelMap.InBounds(c.cursorCell) {
+		gedraw.DrawRect(screen, c.toScreenRect(mapEditorCellBounds(c.cursorCell)), mapEditorCursorColor)
+	}
+}
+
+func (c *mapEditorCanvas) toScreenRect(rect gmath.Rect) gmath.Rect {
+	return gmath.Rect{
+		Min: rect.Min.Sub(c.offset),
+		Max: rect.Max.Sub(c.offset),
+	}
+}
+
+func mapEditorNumTileFrames(tiles *ebiten.Image) int {
+	return tiles.Bounds().Dx() / int(pathing.CellSize)
+}
+
+func mapEditorTileFrame(tiles *ebiten.Image, frame int) *ebiten.Image {
+	x := frame * int(pathing.CellSize)
+	return tiles.SubImage(image.Rect(x, 0, x+int(pathing.CellSize), int(pathing.CellSize))).(*ebiten.Image)
+}
+
+func mapEditorCellCenter(cell gamedata.MapCell) gmath.Vec {
+	return gmath.Vec{
+		X: float64(cell.X)*pathing.CellSize + pathing.CellSize/2,
+		Y: float64(cell.Y)*pathing.CellSize + pathing.CellSize/2,
+	}
+}
+
+func mapEditorCellBounds(cell gamedata.MapCell) gmath.Rect {
+	return mapEditorRectBounds(gamedata.MapRect{MapCell: cell, Width: 1, Height: 1})
+}
+
+func mapEditorRectBounds(r gamedata.MapRect) gmath.Rect {
+	min := gmath.Vec{X: float64(r.X) * pathing.CellSize, Y: float64(r.Y) * pathing.CellSize}
+	return gmath.Rect{
+		Min: min,
+		Max: min.Add(gmath.Vec{X: float64(r.Width) * pathing.CellSize, Y: float64(r.Height) * pathing.CellSize}),
+	}
+}
@@ -0,0 +1,343 @@
+package menus
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gsignal"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/gtask"
+	"github.com/quasilyte/roboden-game/netplay"
+	"github.com/quasilyte/roboden-game/scenes/staging"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+// NetplayMenuController connects two players via the relay (see cmd/relay).
+//
+// The host selects the game mode; the game settings are taken
+// from the host's lobby settings for that mode.
+//
+// The spectators join the room after the host, but before the game is started.
+type NetplayMenuController struct {
+	state *session.State
+
+	modes        []string
+	selectedMode int
+
+	addrInput      *widget.TextInput
+	roomInput      *widget.TextInput
+	statusLabel    *widget.Text
+	hostButton     *widget.Button
+	joinButton     *widget.Button
+	spectateButton *widget.Button
+
+	// The connection is established in the background task.
+	// The mutex protects it from a concurrent cancellation.
+	mu        sync.Mutex
+	client    *netplay.Client
+	cancelled bool
+
+	scene *ge.Scene
+}
+
+type netplayRole int
+
+const (
+	netplayHost netplayRole = iota
+	netplayGuest
+	netplaySpectator
+)
+
+func NewNetplayMenuController(state *session.State) *NetplayMenuController {
+	return &NetplayMenuController{state: state}
+}
+
+func (c *NetplayMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+
+	c.modes = append(c.modes, "blitz")
+	for _, mode := range []string{"classic", "arena", "reverse", "inf_arena"} {
+		if xslices.Contains(c.state.Persistent.PlayerStats.ModesUnlocked, mode) {
+			c.modes = append(c.modes, mode)
+		}
+	}
+
+	c.initUI()
+}
+
+func (c *NetplayMenuController) Update(delta float64) {
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *NetplayMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainerWithMinWidth(520, 10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+	settings := &c.state.Persistent.Settings
+
+	var widgets []eui.Widget
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.main.play")+" -> "+d.Get("menu.play.netplay"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	rowContainer.AddChild(eui.NewCenteredLabel(d.Get("menu.netplay.relay"), c.state.Resources.UI.TextFont))
+	c.addrInput = eui.NewTextInput(uiResources, eui.TextInputConfig{SteamDeck: c.state.Device.IsSteamDeck()},
+		widget.TextInputOpts.WidgetOpts(
+			widget.WidgetOpts.MinSize(480, 0),
+		),
+	)
+	c.addrInput.SetText(settings.NetplayRelay)
+	rowContainer.AddChild(c.addrInput)
+	widgets = append(widgets, c.addrInput)
+
+	rowContainer.AddChild(eui.NewCenteredLabel(d.Get("menu.netplay.room"), c.state.Resources.UI.TextFont))
+	c.roomInput = eui.NewTextInput(uiResources, eui.TextInputConfig{SteamDeck: c.state.Device.IsSteamDeck()},
+		widget.TextInputOpts.WidgetOpts(
+			widget.WidgetOpts.MinSize(480, 0),
+		),
+		widget.TextInputOpts.Validation(func(newInputText string) (bool, *string) {
+			return len(newInputText) <= serverapi.MaxNameLength, nil
+		}),
+	)
+	rowContainer.AddChild(c.roomInput)
+	widgets = append(widgets, c.roomInput)
+
+	{
+		modeNames := make([]string, len(c.modes))
+		for i, mode := range c.modes {
+			modeNames[i] = d.Get("menu.play." + mode)
+		}
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			Resources:  uiResources,
+			Input:      c.state.MenuInput,
+			Value:      &c.selectedMode,
+			Label:      d.Get("menu.netplay.mode"),
+			ValueNames: modeNames,
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+		widgets = append(widgets, b.Widget)
+	}
+
+	{
+		delayNames := make([]string, 6)
+		for i := range delayNames {
+			turns := i + 1
+			delayNames[i] = fmt.Sprintf("%d ms", turns*netplay.TurnTicks*1000/60)
+		}
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			Resources:  uiResources,
+			Input:      c.state.MenuInput,
+			Value:      &settings.NetplayInputDelay,
+			Label:      d.Get("menu.netplay.input_delay"),
+			ValueNames: delayNames,
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+		widgets = append(widgets, b.Widget)
+	}
+
+	c.statusLabel = eui.NewCenteredLabel(d.Get("menu.netplay.idle"), c.state.Resources.UI.TextFont)
+	rowContainer.AddChild(c.statusLabel)
+
+	c.hostButton = eui.NewButton(uiResources, c.scene, d.Get("menu.netplay.host"), func() {
+		c.connect(netplayHost)
+	})
+	rowContainer.AddChild(c.hostButton)
+	widgets = append(widgets, c.hostButton)
+
+	c.joinButton = eui.NewButton(uiResources, c.scene, d.Get("menu.netplay.join"), func() {
+		c.connect(netplayGuest)
+	})
+	rowContainer.AddChild(c.joinButton)
+	widgets = append(widgets, c.joinButton)
+
+	c.spectateButton = eui.NewButton(uiResources, c.scene, d.Get("menu.netplay.spectate"), func() {
+		c.connect(netplaySpectator)
+	})
+	rowContainer.AddChild(c.spectateButton)
+	widgets = append(widgets, c.spectateButton)
+
+	rowContainer.AddChild(eui.NewTransparentSeparator())
+
+	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	})
+	rowContainer.AddChild(backButton)
+	widgets = append(widgets, backButton)
+
+	navTree := createSimpleNavTree(widgets)
+	setupUI(c.scene, root, c.state.MenuInput, navTree)
+}
+
+func (c *NetplayMenuController) setBusy(busy bool) {
+	c.hostButton.GetWidget().Disabled = busy
+	c.joinButton.GetWidget().Disabled = busy
+	c.spectateButton.GetWidget().Disabled = busy
+}
+
+func (c *NetplayMenuController) hostConfig() serverapi.ReplayLevelConfig {
+	mode := gamedata.ModeBlitz
+	switch c.modes[c.selectedMode] {
+	case "classic":
+		mode = gamedata.ModeClassic
+	case "arena":
+		mode = gamedata.ModeArena
+	case "reverse":
+		mode = gamedata.ModeReverse
+	case "inf_arena":
+		mode = gamedata.ModeInfArena
+	}
+
+	config := c.state.GetConfigForMode(mode).Clone()
+	config.PlayersMode = serverapi.PmodeTwoPlayers
+	for {
+		config.Seed = c.scene.Rand().PositiveInt64()
+		if gamedata.GetSeedKind(config.Seed, config.ReplayLevelConfig) == gamedata.SeedNormal {
+			break
+		}
+	}
+	return config.ReplayLevelConfig
+}
+
+func (c *NetplayMenuController) connect(role netplayRole) {
+	d := c.scene.Dict()
+	settings := &c.state.Persistent.Settings
+
+	addr := c.addrInput.GetText()
+	room := c.roomInput.GetText()
+	if addr == "" || room == "" {
+		c.scene.Audio().PlaySound(assets.AudioError)
+		return
+	}
+	settings.NetplayRelay = addr
+	c.state.SaveGameItem("save.json", c.state.Persistent)
+
+	var start netplay.Message
+	if role == netplayHost {
+		config := c.hostConfig()
+		start = netplay.Message{
+			Kind:       netplay.MsgStart,
+			Config:     &config,
+			InputDelay: settings.NetplayInputDelay + 1,
+		}
+	}
+
+	c.setBusy(true)
+	c.statusLabel.Label = d.Get("menu.netplay.connecting")
+
+	var client *netplay.Client
+	var connErr error
+	connectTask := gtask.StartTask(func(ctx *gtask.TaskContext) {
+		if role == netplaySpectator {
+			client, connErr = netplay.DialSpectator(addr, room, gamedata.BuildNumber)
+		} else {
+			client, connErr = netplay.Dial(addr, room, gamedata.BuildNumber)
+		}
+		if connErr != nil {
+			return
+		}
+		c.mu.Lock()
+		c.client = client
+		cancelled := c.cancelled
+		c.mu.Unlock()
+		if cancelled {
+			client.Close()
+			return
+		}
+		ctx.Progress.Current = 1
+
+		if role == netplayHost {
+			if connErr = client.Send(start); connErr != nil {
+				return
+			}
+		}
+		for {
+			m, err := client.Receive()
+			if err != nil {
+				connErr = err
+				return
+			}
+			if m.Kind == netplay.MsgStart {
+				start = m
+				return
+			}
+		}
+	})
+	connectTask.EventProgress.Connect(nil, func(gtask.TaskProgress) {
+		if role == netplaySpectator {
+			c.statusLabel.Label = d.Get("menu.netplay.waiting_players")
+		} else {
+			c.statusLabel.Label = d.Get("menu.netplay.waiting")
+		}
+	})
+	connectTask.EventCompleted.Connect(nil, func(gsignal.Void) {
+		c.mu.Lock()
+		cancelled := c.cancelled
+		c.mu.Unlock()
+		if cancelled {
+			return
+		}
+		if connErr == nil && !isValidNetplayStart(start) {
+			connErr = fmt.Errorf("unexpected game settings")
+		}
+		if connErr != nil {
+			if client != nil {
+				client.Close()
+			}
+			c.state.Logf("netplay: %v", connErr)
+			c.statusLabel.Label = d.Get("menu.netplay.error") + ": " + connErr.Error()
+			c.setBusy(false)
+			return
+		}
+
+		config := gamedata.MakeLevelConfig(gamedata.ExecuteNormal, *start.Config)
+		config.Finalize()
+		controller := staging.NewController(c.state, config, NewNetplayMenuController(c.state))
+		if role == netplaySpectator {
+			controller.SetLockstep(netplay.NewSpectatorLockstep(client, start.InputDelay))
+		} else {
+			controller.SetLockstep(netplay.NewLockstep(client, client.PlayerID(), start.InputDelay))
+		}
+		c.scene.Context().ChangeScene(controller)
+	})
+	c.scene.AddObject(connectTask)
+}
+
+func isValidNetplayStart(m netplay.Message) bool {
+	if m.Config == nil || m.InputDelay < 1 {
+		return false
+	}
+	if m.Config.PlayersMode != serverapi.PmodeTwoPlayers {
+		return false
+	}
+	return gamedata.IsRunnableReplay(serverapi.GameReplay{Config: *m.Config})
+}
+
+func (c *NetplayMenuController) back() {
+	c.mu.Lock()
+	c.cancelled = true
+	if c.client != nil {
+		c.client.Close()
+	}
+	c.mu.Unlock()
+
+	c.state.SaveGameItem("save.json", c.state.Persistent)
+	c.scene.Context().ChangeScene(NewPlayMenuController(c.state))
+}
@@ -0,0 +1,125 @@
+package menus
+
+import (
+	"github.com/quasilyte/ge"
+
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+type OptionsAccessibilityMenuController struct {
+	state *session.State
+
+	scene *ge.Scene
+}
+
+func NewOptionsAccessibilityMenuController(state *session.State) *OptionsAccessibilityMenuController {
+	return &OptionsAccessibilityMenuController{state: state}
+}
+
+func (c *OptionsAccessibilityMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+	c.initUI()
+}
+
+func (c *OptionsAccessibilityMenuController) Update(delta float64) {
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *OptionsAccessibilityMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainerWithMinWidth(520, 10, nil)
+	root.AddChild(rowContainer)
+
+	normalFont := assets.BitmapFont3
+
+	d := c.scene.Dict()
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.main.settings")+" -> "+d.Get("menu.options.accessibility"), normalFont)
+	rowContainer.AddChild(titleLabel)
+
+	var buttons []eui.Widget
+
+	options := &c.state.Persistent.Settings
+
+	{
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			PlaySound: true,
+			Resources: uiResources,
+			Input:     c.state.MenuInput,
+			Value:     &options.Graphics.FactionPalette,
+			Label:     d.Get("menu.options.accessibility.palette"),
+			ValueNames: []string{
+				d.Get("menu.options.accessibility.palette.default"),
+				d.Get("menu.options.accessibility.palette.red_green"),
+				d.Get("menu.options.accessibility.palette.blue_yellow"),
+			},
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+		buttons = append(buttons, b.Widget)
+	}
+
+	{
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			PlaySound: true,
+			Resources: uiResources,
+			Input:     c.state.MenuInput,
+			BoolValue: &options.Graphics.FactionPatterns,
+			Label:     d.Get("menu.options.accessibility.patterns"),
+			ValueNames: []string{
+				d.Get("menu.option.off"),
+				d.Get("menu.option.on"),
+			},
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+		buttons = append(buttons, b.Widget)
+	}
+
+	{
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			PlaySound: true,
+			Resources: uiResources,
+			Input:     c.state.MenuInput,
+			Value:     &options.Graphics.UIScale,
+			Label:     d.Get("menu.options.accessibility.ui_scale"),
+			ValueNames: []string{
+				"100%",
+				"150%",
+			},
+			OnPressed: func() {
+				// The UI resources depend on the scale; rebuild the menu with the new ones.
+				c.state.Resources.UI = eui.LoadResources(c.state.Device, c.scene.Context().Loader, options.Graphics.UIScale)
+				c.scene.Context().ChangeScene(NewOptionsAccessibilityMenuController(c.state))
+			},
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+		buttons = append(buttons, b.Widget)
+	}
+
+	rowContainer.AddChild(eui.NewTransparentSeparator())
+
+	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	})
+	rowContainer.AddChild(backButton)
+	buttons = append(buttons, backButton)
+
+	navTree := createSimpleNavTree(buttons)
+	setupUI(c.scene, root, c.state.MenuInput, navTree)
+}
+
+func (c *OptionsAccessibilityMenuController) back() {
+	c.state.SaveGameItem("save.json", c.state.Persistent)
+	c.scene.Context().ChangeScene(NewOptionsController(c.state))
+}
@@ -126,6 +126,22 @@ func (c *OptionsGameplayMenuController) initUI() {
 		buttons = append(buttons, b.Widget)
 	}
 
+	{
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			Resources: uiResources,
+			Input:     c.state.MenuInput,
+			BoolValue: &options.ObserverMode,
+			Label:     d.Get("menu.options.observer_mode"),
+			ValueNames: []string{
+				d.Get("menu.option.off"),
+				d.Get("menu.option.on"),
+			},
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+		buttons = append(buttons, b.Widget)
+	}
+
 	rowContainer.AddChild(eui.NewTransparentSeparator())
 
 	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
@@ -80,13 +80,21 @@ func (c *OptionsMenuController) initUI() {
 	extraButton := eui.NewButton(uiResources, c.scene, d.Get("menu.options.extra"), func() {
 		c.scene.Context().ChangeScene(NewOptionsExtraMenuController(c.state))
 	})
+	accessibilityButton := eui.NewButton(uiResources, c.scene, d.Get("menu.options.accessibility"), func() {
+		c.scene.Context().ChangeScene(NewOptionsAccessibilityMenuController(c.state))
+	})
+	rowContainer.AddChild(accessibilityButton)
+	buttons = append(buttons, accessibilityButton)
+
 	rowContainer.AddChild(extraButton)
 	buttons = append(buttons, extraButton)
 
 	{
-		langOptions := []string{
-			"en",
-			"ru",
+		langOptions := make([]string, len(c.state.LangPacks))
+		langNames := make([]string, len(c.state.LangPacks))
+		for i, p := range c.state.LangPacks {
+			langOptions[i] = p.Manifest.ID
+			langNames[i] = p.Manifest.Name
 		}
 		langIndex := xslices.Index(langOptions, options.Lang)
 		langSelect := eui.NewSelectButton(eui.SelectButtonConfig{
@@ -95,7 +103,7 @@ func (c *OptionsMenuController) initUI() {
 			Input:      c.state.MenuInput,
 			Value:      &langIndex,
 			Label:      "Language/Язык",
-			ValueNames: langOptions,
+			ValueNames: langNames,
 			OnPressed: func() {
 				options.Lang = langOptions[langIndex]
 				c.state.ReloadLanguage(c.scene.Context())
@@ -2,6 +2,7 @@ package menus
 
 import (
 	"fmt"
+	"runtime"
 
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/quasilyte/ge"
@@ -100,7 +101,7 @@ func (c *PlayMenuController) initUI() {
 	leftPanel.AddChild(buttonsContainer)
 	rootGrid.AddChild(leftPanel)
 
-	helpLabel := eui.NewLabel(d.Get("menu.overview.intro_mission"), assets.BitmapFont1)
+	helpLabel := eui.NewLabel(d.Get("menu.overview.intro_mission"), c.state.Resources.UI.TextFont)
 	helpLabel.MaxWidth = 320
 	c.helpLabel = helpLabel
 
@@ -130,6 +131,21 @@ func (c *PlayMenuController) initUI() {
 
 	playerStats := &c.state.Persistent.PlayerStats
 
+	{
+		b := eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
+			Scene: c.scene,
+			Text:  d.Get("menu.play.campaign"),
+			OnPressed: func() {
+				c.scene.Context().ChangeScene(NewCampaignMenuController(c.state))
+			},
+			OnHover: func() { c.setHelpText(c.modeDescriptionText("campaign", gamedata.ClassicModeCost)) },
+		})
+		// The campaign missions are played using the custom mode rules.
+		b.GetWidget().Disabled = !xslices.Contains(playerStats.ModesUnlocked, "classic")
+		buttonsContainer.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
 	{
 		label := d.Get("menu.play.blitz")
 		b := eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
@@ -204,6 +220,52 @@ func (c *PlayMenuController) initUI() {
 		buttons = append(buttons, b)
 	}
 
+	{
+		label := d.Get("menu.play.custom")
+		b := eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
+			Scene: c.scene,
+			Text:  label,
+			OnPressed: func() {
+				c.scene.Context().ChangeScene(NewLobbyMenuController(c.state, gamedata.ModeCustom))
+			},
+			OnHover: func() { c.setHelpText(c.modeDescriptionText("custom", gamedata.ClassicModeCost)) },
+		})
+		// The custom mode is built on top of the classic mode rules.
+		b.GetWidget().Disabled = !xslices.Contains(playerStats.ModesUnlocked, "classic")
+		buttonsContainer.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
+	// The network games require a TCP connection to the relay,
+	// so they're not available for the browser builds.
+	if !c.state.Device.IsMobile() && runtime.GOARCH != "wasm" {
+		b := eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
+			Scene: c.scene,
+			Text:  d.Get("menu.play.netplay"),
+			OnPressed: func() {
+				c.scene.Context().ChangeScene(NewNetplayMenuController(c.state))
+			},
+			OnHover: func() { c.setHelpText(d.Get("menu.overview.netplay")) },
+		})
+		buttonsContainer.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
+	// The maps are saved to the data folder, so the editor
+	// is not available for the browser builds.
+	if !c.state.Device.IsMobile() && runtime.GOARCH != "wasm" {
+		b := eui.NewButtonWithConfig(uiResources, eui.ButtonConfig{
+			Scene: c.scene,
+			Text:  d.Get("menu.play.map_editor"),
+			OnPressed: func() {
+				c.scene.Context().ChangeScene(NewMapEditorController(c.state))
+			},
+			OnHover: func() { c.setHelpText(d.Get("menu.overview.map_editor")) },
+		})
+		buttonsContainer.AddChild(b)
+		buttons = append(buttons, b)
+	}
+
 	{
 		b := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
 			c.back()
@@ -62,7 +62,7 @@ func (c *ProfileAchievementsMenuController) initUI() {
 
 	d := c.scene.Dict()
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	helpLabel := eui.NewLabel("", smallFont)
 	helpLabel.MaxWidth = 320
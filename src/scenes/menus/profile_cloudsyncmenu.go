@@ -0,0 +1,260 @@
+package menus
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/gsignal"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/clientkit"
+	"github.com/quasilyte/roboden-game/contentlock"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/gtask"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+type ProfileCloudSyncMenuController struct {
+	state *session.State
+
+	errorSoundDelay float64
+
+	busy bool
+
+	ui             *eui.SceneObject
+	keyboard       *eui.Keyboard
+	textInput      *widget.TextInput
+	statusLabel    *widget.Text
+	generateButton *widget.Button
+	syncButton     *widget.Button
+
+	scene *ge.Scene
+}
+
+// NewProfileCloudSyncMenuController creates a screen that synchronizes
+// the player progress between the devices through the leaderboard server.
+//
+// The devices are linked by the same player name and the sync key.
+func NewProfileCloudSyncMenuController(state *session.State) *ProfileCloudSyncMenuController {
+	return &ProfileCloudSyncMenuController{state: state}
+}
+
+func (c *ProfileCloudSyncMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+	c.initUI()
+}
+
+func (c *ProfileCloudSyncMenuController) Update(delta float64) {
+	c.errorSoundDelay = gmath.ClampMin(c.errorSoundDelay-delta, 0)
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *ProfileCloudSyncMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainerWithMinWidth(400, 10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+
+	smallFont := assets.BitmapFont1
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.main.profile")+" -> "+d.Get("menu.profile.cloud_sync"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	var widgets []eui.Widget
+
+	rowContainer.AddChild(eui.NewCenteredLabel(d.Get("menu.cloud_sync.key"), smallFont))
+
+	textinput := eui.NewTextInput(uiResources, eui.TextInputConfig{SteamDeck: c.state.Device.IsSteamDeck()},
+		widget.TextInputOpts.WidgetOpts(
+			widget.WidgetOpts.MinSize(480, 0),
+		),
+		widget.TextInputOpts.SubmitHandler(func(args *widget.TextInputChangedEventArgs) {
+			if args.InputText == "" {
+				return
+			}
+			c.sync(args.InputText)
+		}),
+		widget.TextInputOpts.Validation(func(newInputText string) (bool, *string) {
+			good := len(newInputText) <= serverapi.SyncKeyLength && isSyncKeyText(newInputText)
+			if !good && c.errorSoundDelay == 0 {
+				c.scene.Audio().PlaySound(assets.AudioError)
+				c.errorSoundDelay = 0.2
+			}
+			return good, nil
+		}),
+	)
+	if c.state.Persistent.CloudSyncKey != "" {
+		textinput.SetText(c.state.Persistent.CloudSyncKey)
+	}
+	rowContainer.AddChild(textinput)
+	widgets = append(widgets, textinput)
+
+	c.textInput = textinput
+	if runtime.GOOS == "android" {
+		c.textInput.GetWidget().FocusEvent.AddHandler(func(args any) {
+			e := args.(*widget.WidgetFocusEventArgs)
+			if e.Focused {
+				if c.keyboard == nil {
+					c.openKeyboard()
+				}
+			}
+		})
+	}
+
+	statusPanel := eui.NewTextPanel(uiResources, 0, 0)
+	hint := d.Get("menu.cloud_sync.hint")
+	if c.state.Persistent.PlayerName == "" {
+		hint = d.Get("menu.cloud_sync.error.no_name")
+	}
+	c.statusLabel = eui.NewLabel(hint, smallFont)
+	c.statusLabel.MaxWidth = 640
+	statusPanel.AddChild(c.statusLabel)
+	rowContainer.AddChild(statusPanel)
+
+	c.generateButton = eui.NewButton(uiResources, c.scene, d.Get("menu.cloud_sync.generate"), func() {
+		c.generateKey()
+	})
+	rowContainer.AddChild(c.generateButton)
+	widgets = append(widgets, c.generateButton)
+
+	c.syncButton = eui.NewButton(uiResources, c.scene, d.Get("menu.cloud_sync.sync"), func() {
+		c.sync(textinput.GetText())
+	})
+	rowContainer.AddChild(c.syncButton)
+	widgets = append(widgets, c.syncButton)
+
+	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	})
+	rowContainer.AddChild(backButton)
+	widgets = append(widgets, backButton)
+
+	navTree := createSimpleNavTree(widgets)
+	c.ui = setupUI(c.scene, root, c.state.MenuInput, navTree).ui
+}
+
+func (c *ProfileCloudSyncMenuController) setBusy(busy bool) {
+	c.busy = busy
+	c.generateButton.GetWidget().Disabled = busy
+	c.syncButton.GetWidget().Disabled = busy
+}
+
+func (c *ProfileCloudSyncMenuController) generateKey() {
+	key, err := clientkit.NewSyncKey()
+	if err != nil {
+		c.state.Logf("generate sync key: %v", err)
+		c.scene.Audio().PlaySound(assets.AudioError)
+		return
+	}
+	c.textInput.SetText(key)
+	c.statusLabel.Label = c.scene.Dict().Get("menu.cloud_sync.generated")
+}
+
+func (c *ProfileCloudSyncMenuController) sync(key string) {
+	if c.busy {
+		return
+	}
+
+	d := c.scene.Dict()
+
+	key = strings.TrimSpace(key)
+	if c.state.Persistent.PlayerName == "" {
+		c.scene.Audio().PlaySound(assets.AudioError)
+		c.statusLabel.Label = d.Get("menu.cloud_sync.error.no_name")
+		return
+	}
+	if !gamedata.IsValidSyncKey(key) {
+		c.scene.Audio().PlaySound(assets.AudioError)
+		c.statusLabel.Label = d.Get("menu.cloud_sync.error.bad_key")
+		return
+	}
+
+	c.state.Persistent.CloudSyncKey = key
+	c.state.SaveGameItem("save.json", c.state.Persistent)
+
+	c.setBusy(true)
+	c.statusLabel.Label = d.Get("menu.cloud_sync.syncing")
+
+	var progress *serverapi.PlayerProgress
+	var syncErr error
+	syncTask := gtask.StartTask(func(ctx *gtask.TaskContext) {
+		progress, syncErr = clientkit.SyncProgress(c.state)
+	})
+	syncTask.EventCompleted.Connect(nil, func(gsignal.Void) {
+		c.setBusy(false)
+		if syncErr != nil {
+			c.state.Logf("cloud sync: %v", syncErr)
+			if syncErr == clientkit.ErrSyncKeyMismatch {
+				c.statusLabel.Label = d.Get("menu.cloud_sync.error.key_mismatch")
+			} else {
+				c.statusLabel.Label = d.Get("menu.cloud_sync.error") + ": " + syncErr.Error()
+			}
+			return
+		}
+
+		c.state.Persistent.PlayerStats.ApplyCloudProgress(progress)
+		contentlock.Update(c.state)
+		c.state.SaveGameItem("save.json", c.state.Persistent)
+		c.statusLabel.Label = d.Get("menu.cloud_sync.done")
+	})
+	c.scene.AddObject(syncTask)
+}
+
+func (c *ProfileCloudSyncMenuController) back() {
+	if c.busy {
+		c.scene.Audio().PlaySound(assets.AudioError)
+		return
+	}
+	c.scene.Context().ChangeScene(NewProfileMenuController(c.state))
+}
+
+func (c *ProfileCloudSyncMenuController) openKeyboard() {
+	k := eui.NewTextKeyboard(eui.KeyboardConfig{
+		Resources: c.state.Resources.UI,
+		Scene:     c.scene,
+		Input:     c.state.MenuInput,
+	})
+	c.ui.AddWindow(k.Window)
+
+	runeBuf := []rune{0}
+	k.EventKey.Connect(nil, func(ch rune) {
+		runeBuf[0] = ch
+		c.textInput.Insert(runeBuf)
+		c.textInput.Focus(true)
+	})
+	k.EventBackspace.Connect(nil, func(gsignal.Void) {
+		c.textInput.Backspace()
+		c.textInput.Focus(true)
+	})
+	k.EventSubmit.Connect(nil, func(gsignal.Void) {
+		c.textInput.Submit()
+		k.Close()
+	})
+	k.EventClosed.Connect(nil, func(gsignal.Void) {
+		c.keyboard = nil
+	})
+	c.keyboard = k
+	c.scene.AddObject(c.keyboard)
+}
+
+func isSyncKeyText(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(gamedata.SyncKeyAlphabet, s[i]) == -1 {
+			return false
+		}
+	}
+	return true
+}
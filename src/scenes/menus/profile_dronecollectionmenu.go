@@ -35,7 +35,7 @@ func NewProfileDroneCollectionMenuController(state *session.State) *ProfileDrone
 
 func (c *ProfileDroneCollectionMenuController) Init(scene *ge.Scene) {
 	c.scene = scene
-	c.recipeIcons = gameui.GenerateRecipePreviews(c.scene, true, c.state.Persistent.Settings.LargeDiodes)
+	c.recipeIcons = gameui.GenerateRecipePreviews(c.scene, true, gameui.NewDiodeStyle(&c.state.Persistent.Settings))
 	c.initUI()
 }
 
@@ -57,7 +57,7 @@ func (c *ProfileDroneCollectionMenuController) initUI() {
 
 	d := c.scene.Dict()
 
-	tinyFont := assets.BitmapFont1
+	tinyFont := c.state.Resources.UI.TextFont
 
 	helpLabel := eui.NewLabel("", tinyFont)
 	helpLabel.MaxWidth = 340
@@ -60,6 +60,9 @@ func (c *ProfileMenuController) initUI() {
 		eui.NewButton(uiResources, c.scene, d.Get("menu.profile.watch_replay"), func() {
 			c.scene.Context().ChangeScene(NewReplayMenuController(c.state))
 		}),
+		eui.NewButton(uiResources, c.scene, d.Get("menu.profile.cloud_sync"), func() {
+			c.scene.Context().ChangeScene(NewProfileCloudSyncMenuController(c.state))
+		}),
 	}
 
 	for _, b := range buttons {
@@ -0,0 +1,265 @@
+package menus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/gsignal"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/descriptions"
+	"github.com/quasilyte/roboden-game/gameui"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/gtask"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/session"
+	"github.com/quasilyte/roboden-game/timeutil"
+)
+
+const replayTimelineLinesPerPage = 14
+
+// ReplayCompatMenuController is a fallback replay viewer
+// for the replays that were recorded by the other game builds.
+//
+// These replays can't be played back by the current simulation,
+// so it shows the recorded results and the player actions timeline instead.
+// On desktop, the replay can also be re-simulated by the archived
+// simulator of the matching build (see session.LegacySimulatorPath).
+type ReplayCompatMenuController struct {
+	state *session.State
+
+	replay session.SavedReplay
+
+	timeline []string
+	page     int
+
+	timelineLabel *widget.Text
+	pageLabel     *widget.Text
+	verifyLabel   *widget.Text
+	verifyButton  *widget.Button
+
+	scene *ge.Scene
+}
+
+func NewReplayCompatMenuController(state *session.State, r session.SavedReplay) *ReplayCompatMenuController {
+	return &ReplayCompatMenuController{
+		state:  state,
+		replay: r,
+	}
+}
+
+func (c *ReplayCompatMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+	c.timeline = c.formatTimeline()
+	c.initUI()
+	c.setPage(0)
+}
+
+func (c *ReplayCompatMenuController) Update(delta float64) {
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *ReplayCompatMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainer(10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+
+	smallFont := c.state.Resources.UI.TextFont
+
+	navTree := gameui.NewNavTree()
+	navBlock := navTree.NewBlock()
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.profile.watch_replay")+" -> "+d.Get("menu.replay.compat"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	rootGrid := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{false, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+
+	leftPanel := eui.NewTextPanel(uiResources, 300, 0)
+	infoLabel := eui.NewLabel(descriptions.ReplayText(d, &c.replay), smallFont)
+	infoLabel.MaxWidth = 268
+	leftPanel.AddChild(infoLabel)
+
+	rightPanel := eui.NewTextPanel(uiResources, 340, 0)
+	c.timelineLabel = eui.NewLabel("", smallFont)
+	c.timelineLabel.MaxWidth = 308
+	rightPanel.AddChild(c.timelineLabel)
+
+	rootGrid.AddChild(leftPanel)
+	rootGrid.AddChild(rightPanel)
+	rowContainer.AddChild(rootGrid)
+
+	pageGrid := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(3),
+			widget.GridLayoutOpts.Stretch([]bool{true, true, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+	prevPageButton := eui.NewButton(uiResources, c.scene, "<", func() {
+		c.setPage(c.page - 1)
+	})
+	c.pageLabel = eui.NewCenteredLabel("", smallFont)
+	nextPageButton := eui.NewButton(uiResources, c.scene, ">", func() {
+		c.setPage(c.page + 1)
+	})
+	pageGrid.AddChild(prevPageButton)
+	pageGrid.AddChild(c.pageLabel)
+	pageGrid.AddChild(nextPageButton)
+	rowContainer.AddChild(pageGrid)
+	prevPageElem := navBlock.NewElem(prevPageButton)
+	nextPageElem := navBlock.NewElem(nextPageButton)
+	bindNavGrid([]*gameui.NavElem{prevPageElem, nextPageElem}, 2, 1)
+
+	c.verifyLabel = eui.NewCenteredLabel("", smallFont)
+	rowContainer.AddChild(c.verifyLabel)
+
+	simulatorPath := c.state.LegacySimulatorPath(c.replay.Replay.GameVersion)
+	c.verifyButton = eui.NewButton(uiResources, c.scene, d.Get("menu.replay.resimulate"), func() {
+		c.runSimulator(simulatorPath)
+	})
+	if simulatorPath == "" {
+		c.verifyButton.GetWidget().Disabled = true
+		if !c.state.Device.IsMobile() {
+			c.verifyLabel.Label = fmt.Sprintf("%s: runsim/runsim_%d", d.Get("menu.replay.no_simulator"), c.replay.Replay.GameVersion)
+		}
+	}
+	rowContainer.AddChild(c.verifyButton)
+	verifyElem := navBlock.NewElem(c.verifyButton)
+
+	rowContainer.AddChild(eui.NewTransparentSeparator())
+
+	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	})
+	rowContainer.AddChild(backButton)
+	backElem := navBlock.NewElem(backButton)
+
+	prevPageElem.Edges[gameui.NavDown] = verifyElem
+	nextPageElem.Edges[gameui.NavDown] = verifyElem
+	verifyElem.Edges[gameui.NavUp] = prevPageElem
+	verifyElem.Edges[gameui.NavDown] = backElem
+	backElem.Edges[gameui.NavUp] = verifyElem
+
+	setupUI(c.scene, root, c.state.MenuInput, navTree)
+}
+
+func (c *ReplayCompatMenuController) runSimulator(path string) {
+	d := c.scene.Dict()
+
+	c.verifyButton.GetWidget().Disabled = true
+	c.verifyLabel.Label = d.Get("menu.replay.simulating")
+
+	var results serverapi.GameResults
+	var simErr error
+	simTask := gtask.StartTask(func(ctx *gtask.TaskContext) {
+		results, simErr = session.RunLegacySimulator(path, c.replay.Replay)
+	})
+	simTask.EventCompleted.Connect(nil, func(gsignal.Void) {
+		c.verifyButton.GetWidget().Disabled = false
+		if simErr != nil {
+			c.state.Logf("legacy simulator error: %v", simErr)
+			c.verifyLabel.Label = d.Get("menu.replay.simulator_error")
+			return
+		}
+		status := d.Get("menu.replay.results_match")
+		if results != c.replay.Replay.Results {
+			status = d.Get("menu.replay.results_mismatch")
+		}
+		timePlayed := time.Second * time.Duration(results.Time)
+		c.verifyLabel.Label = fmt.Sprintf("%s: %s=%d, %s=%s (%s)",
+			d.Get("menu.replay.simulated"),
+			strings.ToLower(d.Get("menu.results.score")), results.Score,
+			strings.ToLower(d.Get("menu.results.time_played")), timeutil.FormatDurationCompact(timePlayed),
+			status)
+	})
+	c.scene.AddObject(simTask)
+}
+
+func (c *ReplayCompatMenuController) numPages() int {
+	return gmath.ClampMin((len(c.timeline)+replayTimelineLinesPerPage-1)/replayTimelineLinesPerPage, 1)
+}
+
+func (c *ReplayCompatMenuController) setPage(page int) {
+	c.page = gmath.Clamp(page, 0, c.numPages()-1)
+	from := c.page * replayTimelineLinesPerPage
+	to := gmath.ClampMax(from+replayTimelineLinesPerPage, len(c.timeline))
+	if len(c.timeline) == 0 {
+		c.timelineLabel.Label = c.scene.Dict().Get("menu.replay.no_actions")
+	} else {
+		c.timelineLabel.Label = strings.Join(c.timeline[from:to], "\n")
+	}
+	c.pageLabel.Label = fmt.Sprintf("%s %d/%d", c.scene.Dict().Get("menu.replay.page"), c.page+1, c.numPages())
+}
+
+// formatTimeline returns the recorded player actions in chronological order.
+//
+// The replay doesn't have the timestamps for the actions, but
+// the tick rate is constant, so they can be derived from the results.
+func (c *ReplayCompatMenuController) formatTimeline() []string {
+	d := c.scene.Dict()
+
+	type timelineAction struct {
+		player int
+		action serverapi.PlayerAction
+	}
+	var actions []timelineAction
+	for playerID, playerActions := range c.replay.Replay.Actions {
+		for _, a := range playerActions {
+			actions = append(actions, timelineAction{player: playerID, action: a})
+		}
+	}
+	// Merge the per-player action lists; each of them is already sorted.
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].action.Tick < actions[j].action.Tick
+	})
+
+	secondsPerTick := 0.0
+	if results := c.replay.Replay.Results; results.Ticks != 0 {
+		secondsPerTick = float64(results.Time) / float64(results.Ticks)
+	}
+
+	lines := make([]string, 0, len(actions))
+	for _, ta := range actions {
+		a := ta.action
+		var effect string
+		switch kind := a.Kind.Ungrouped(); kind {
+		case serverapi.ActionMove:
+			effect = fmt.Sprintf("%s (%d, %d)", d.Get("menu.replay.action.move"), int(a.Pos[0]), int(a.Pos[1]))
+		case serverapi.ActionQueueMove:
+			effect = fmt.Sprintf("%s (%d, %d)", d.Get("menu.replay.action.queue_move"), int(a.Pos[0]), int(a.Pos[1]))
+		case serverapi.ActionCard5:
+			effect = d.Get("menu.replay.action.special")
+		default:
+			effect = fmt.Sprintf("%s %d", d.Get("menu.replay.action.card"), int(kind))
+		}
+		if a.Kind.IsGroup() {
+			effect = fmt.Sprintf("%s [%s x%d]", effect, d.Get("menu.replay.action.group"), len(a.Group))
+		}
+		seconds := time.Duration(float64(a.Tick)*secondsPerTick) * time.Second
+		lines = append(lines, fmt.Sprintf("%s P%d: %s", timeutil.FormatDurationCompact(seconds), ta.player+1, effect))
+	}
+	return lines
+}
+
+func (c *ReplayCompatMenuController) back() {
+	c.scene.Context().ChangeScene(NewReplayMenuController(c.state))
+}
@@ -2,9 +2,12 @@ package menus
 
 import (
 	"fmt"
+	"runtime"
+	"strings"
 
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
 	"github.com/quasilyte/roboden-game/assets"
 	"github.com/quasilyte/roboden-game/controls"
 	"github.com/quasilyte/roboden-game/descriptions"
@@ -16,21 +19,62 @@ import (
 	"github.com/quasilyte/roboden-game/timeutil"
 )
 
+const replaysPerPage = 10
+
 type ReplayMenuController struct {
 	state *session.State
 
+	entries   []session.ReplayLibraryEntry
+	sortOrder int
+	page      int
+
+	// A selected library entry index (-1 means "nothing is selected").
+	selected int
+	// selectedRunnable reports whether the selected replay can be played.
+	selectedRunnable bool
+
+	slotSelectorPos gmath.Vec
+	slotSelector    *ge.Rect
+
+	buttons      []*widget.Button
+	pageLabel    *widget.Text
+	playButton   *widget.Button
+	exportButton *widget.Button
+	deleteButton *widget.Button
+
 	helpLabel *widget.Text
 
 	scene *ge.Scene
 }
 
 func NewReplayMenuController(state *session.State) *ReplayMenuController {
-	return &ReplayMenuController{state: state}
+	return &ReplayMenuController{
+		state:    state,
+		selected: -1,
+	}
 }
 
 func (c *ReplayMenuController) Init(scene *ge.Scene) {
 	c.scene = scene
+
+	c.entries = c.state.LoadReplayLibrary().Entries
+	session.SortReplayLibrary(c.entries, session.ReplaySortOrder(c.sortOrder))
+
 	c.initUI()
+
+	c.slotSelector = ge.NewRect(scene.Context(), 224, 29)
+	c.slotSelector.Centered = false
+	c.slotSelector.Pos.Base = &c.slotSelectorPos
+	c.slotSelector.OutlineWidth = 1
+	c.slotSelector.Visible = false
+	c.slotSelector.FillColorScale.SetRGBA(0, 0, 0, 0)
+	c.slotSelector.OutlineColorScale.SetColor(eui.CaretColor)
+	scene.AddGraphics(c.slotSelector)
+
+	// The button rects are only known after the first layout pass.
+	scene.DelayedCall(0.05, func() {
+		c.updatePage()
+	})
 }
 
 func (c *ReplayMenuController) Update(delta float64) {
@@ -51,7 +95,7 @@ func (c *ReplayMenuController) initUI() {
 
 	d := c.scene.Dict()
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	helpLabel := eui.NewLabel("", smallFont)
 	helpLabel.MaxWidth = 268
@@ -62,17 +106,66 @@ func (c *ReplayMenuController) initUI() {
 	})
 
 	navTree := gameui.NewNavTree()
-	bottomNavBlock := navTree.NewBlock()
-	leftNavBlock := navTree.NewBlock()
-	rightNavBlock := navTree.NewBlock()
-	var leftButtonElems []*gameui.NavElem
-	var rightButtonElems []*gameui.NavElem
-
-	bottomNavBlock.NewElem(backButton)
+	navBlock := navTree.NewBlock()
 
 	titleLabel := eui.NewCenteredLabel(d.Get("menu.main.profile")+" -> "+d.Get("menu.profile.watch_replay"), assets.BitmapFont3)
 	rowContainer.AddChild(titleLabel)
 
+	topGrid := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{true, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+
+	var lastPlayed session.SavedReplay
+	lastPlayedExists := c.state.CheckGameItem(c.state.ReplayDataKey(0))
+	if lastPlayedExists {
+		if err := c.state.LoadGameItem(c.state.ReplayDataKey(0), &lastPlayed); err != nil {
+			lastPlayedExists = false
+		}
+		if !gamedata.IsRunnableReplay(lastPlayed.Replay) {
+			lastPlayedExists = false
+		}
+	}
+	lastPlayedButton := eui.NewButton(uiResources, c.scene, d.Get("menu.replay.last_played"), func() {
+		c.playReplay(lastPlayed)
+	})
+	if lastPlayedExists {
+		lastPlayedButton.GetWidget().CursorEnterEvent.AddHandler(func(args interface{}) {
+			c.helpLabel.Label = descriptions.ReplayText(d, &lastPlayed)
+		})
+	}
+	lastPlayedButton.GetWidget().Disabled = !lastPlayedExists
+	topGrid.AddChild(lastPlayedButton)
+	lastPlayedElem := navBlock.NewElem(lastPlayedButton)
+
+	sortSelect := eui.NewSelectButton(eui.SelectButtonConfig{
+		Resources: uiResources,
+		Input:     c.state.MenuInput,
+		Value:     &c.sortOrder,
+		Label:     d.Get("menu.replay.sort"),
+		ValueNames: []string{
+			d.Get("menu.replay.sort.date"),
+			d.Get("menu.replay.sort.mode"),
+			d.Get("menu.replay.sort.score"),
+			d.Get("menu.replay.sort.result"),
+		},
+		OnPressed: func() {
+			session.SortReplayLibrary(c.entries, session.ReplaySortOrder(c.sortOrder))
+			c.page = 0
+			c.selected = -1
+			c.updatePage()
+		},
+	})
+	c.scene.AddObject(sortSelect)
+	topGrid.AddChild(sortSelect.Widget)
+	sortElem := navBlock.NewElem(sortSelect.Widget)
+
+	rowContainer.AddChild(topGrid)
+
 	rootGrid := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewGridLayout(
 			widget.GridLayoutOpts.Columns(2),
@@ -81,46 +174,19 @@ func (c *ReplayMenuController) initUI() {
 	leftGrid := eui.NewGridContainer(2, widget.GridLayoutOpts.Spacing(8, 4),
 		widget.GridLayoutOpts.Stretch([]bool{true, false}, nil))
 
-	for i := 0; i < 10; i++ {
-		key := c.state.ReplayDataKey(i)
-		replayExists := c.state.CheckGameItem(key)
-		var r session.SavedReplay
-		if replayExists {
-			if err := c.state.LoadGameItem(key, &r); err != nil {
-				replayExists = false
-			}
-			if !gamedata.IsRunnableReplay(r.Replay) {
-				replayExists = false
-			}
-		}
-		label := d.Get("menu.replay.empty")
-		if replayExists {
-			if i == 0 {
-				label = d.Get("menu.replay.last_played")
-			} else {
-				label = fmt.Sprintf("[%d] %s", i, timeutil.FormatDateISO8601(r.Date, true))
-			}
-		}
-		b := eui.NewSmallButton(uiResources, c.scene, label, func() {
-			config := gamedata.MakeLevelConfig(gamedata.ExecuteReplay, r.Replay.Config)
-			config.Finalize()
-			controller := staging.NewController(c.state, config, NewReplayMenuController(c.state))
-			controller.SetReplayActions(r.Replay)
-			c.scene.Context().ChangeScene(controller)
+	var gridButtonElems []*gameui.NavElem
+	for i := 0; i < replaysPerPage; i++ {
+		slotIndex := i
+		b := eui.NewSmallButton(uiResources, c.scene, "", func() {
+			c.selectEntry(c.page*replaysPerPage + slotIndex)
+		})
+		b.GetWidget().CursorEnterEvent.AddHandler(func(args interface{}) {
+			c.updateHelpText(c.page*replaysPerPage + slotIndex)
 		})
-		if replayExists {
-			b.GetWidget().CursorEnterEvent.AddHandler(func(args interface{}) {
-				c.helpLabel.Label = descriptions.ReplayText(d, &r)
-			})
-		}
-		b.GetWidget().Disabled = !replayExists || r.Replay.GameVersion != gamedata.BuildNumber
 		b.GetWidget().MinWidth = 220
+		c.buttons = append(c.buttons, b)
 		leftGrid.AddChild(b)
-		if i%2 == 0 {
-			leftButtonElems = append(leftButtonElems, leftNavBlock.NewElem(b))
-		} else {
-			rightButtonElems = append(rightButtonElems, rightNavBlock.NewElem(b))
-		}
+		gridButtonElems = append(gridButtonElems, navBlock.NewElem(b))
 	}
 
 	rightPanel := eui.NewTextPanel(uiResources, 320, 0)
@@ -131,23 +197,224 @@ func (c *ReplayMenuController) initUI() {
 
 	rowContainer.AddChild(rootGrid)
 
-	rowContainer.AddChild(eui.NewCenteredLabel(d.Get("menu.replay.notice"), smallFont))
+	pageGrid := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(3),
+			widget.GridLayoutOpts.Stretch([]bool{true, true, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+	prevPageButton := eui.NewButton(uiResources, c.scene, "<", func() {
+		c.setPage(c.page - 1)
+	})
+	c.pageLabel = eui.NewCenteredLabel("", smallFont)
+	nextPageButton := eui.NewButton(uiResources, c.scene, ">", func() {
+		c.setPage(c.page + 1)
+	})
+	pageGrid.AddChild(prevPageButton)
+	pageGrid.AddChild(c.pageLabel)
+	pageGrid.AddChild(nextPageButton)
+	rowContainer.AddChild(pageGrid)
+	prevPageElem := navBlock.NewElem(prevPageButton)
+	nextPageElem := navBlock.NewElem(nextPageButton)
+
+	buttonsGrid := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{true, true}, nil),
+			widget.GridLayoutOpts.Spacing(4, 4))))
+
+	c.playButton = eui.NewButton(uiResources, c.scene, d.Get("menu.replay.play"), func() {
+		if c.selected == -1 || c.selected >= len(c.entries) {
+			return
+		}
+		r, err := c.state.LoadLibraryReplay(c.entries[c.selected].ID)
+		if err != nil {
+			c.helpLabel.Label = err.Error()
+			return
+		}
+		c.playReplay(r)
+	})
+	c.playButton.GetWidget().Disabled = true
+
+	c.deleteButton = eui.NewButton(uiResources, c.scene, d.Get("menu.replay.delete"), func() {
+		c.state.DeleteLibraryReplay(c.entries[c.selected].ID)
+		c.entries = append(c.entries[:c.selected], c.entries[c.selected+1:]...)
+		c.selected = -1
+		c.setPage(c.page)
+		c.helpLabel.Label = ""
+	})
+	c.deleteButton.GetWidget().Disabled = true
+
+	buttonsGrid.AddChild(c.playButton)
+	buttonsGrid.AddChild(c.deleteButton)
+	controlButtonElems := []*gameui.NavElem{
+		navBlock.NewElem(c.playButton),
+		navBlock.NewElem(c.deleteButton),
+	}
+
+	// Replay files are only supported for the native desktop builds.
+	if !c.state.Device.IsMobile() && runtime.GOARCH != "wasm" {
+		c.exportButton = eui.NewButton(uiResources, c.scene, d.Get("menu.replay.export"), func() {
+			filename, err := c.state.ExportLibraryReplay(c.entries[c.selected].ID)
+			if err != nil {
+				c.state.Logf("replay export error: %v", err)
+				c.helpLabel.Label = d.Get("menu.replay.export_error")
+				return
+			}
+			c.entries[c.selected].Source = filename
+			c.helpLabel.Label = d.Get("menu.replay.exported") + ":\n" + filename
+		})
+		c.exportButton.GetWidget().Disabled = true
+
+		importButton := eui.NewButton(uiResources, c.scene, d.Get("menu.replay.import"), func() {
+			numImported, err := c.state.ImportReplayFiles()
+			if err != nil {
+				c.state.Logf("replay import error: %v", err)
+				c.helpLabel.Label = d.Get("menu.replay.import_error")
+				return
+			}
+			c.entries = c.state.LoadReplayLibrary().Entries
+			session.SortReplayLibrary(c.entries, session.ReplaySortOrder(c.sortOrder))
+			c.selected = -1
+			c.setPage(c.page)
+			c.helpLabel.Label = fmt.Sprintf("%s: %d", d.Get("menu.replay.imported"), numImported)
+		})
+
+		buttonsGrid.AddChild(c.exportButton)
+		buttonsGrid.AddChild(importButton)
+		controlButtonElems = append(controlButtonElems,
+			navBlock.NewElem(c.exportButton),
+			navBlock.NewElem(importButton))
+	}
+
+	rowContainer.AddChild(buttonsGrid)
 
 	rowContainer.AddChild(eui.NewTransparentSeparator())
 
 	rowContainer.AddChild(backButton)
+	backButtonElem := navBlock.NewElem(backButton)
 
-	bindNavListNoWrap(leftButtonElems, gameui.NavUp, gameui.NavDown)
-	bindNavListNoWrap(rightButtonElems, gameui.NavUp, gameui.NavDown)
-	bottomNavBlock.Edges[gameui.NavUp] = leftNavBlock
-	leftNavBlock.Edges[gameui.NavDown] = bottomNavBlock
-	leftNavBlock.Edges[gameui.NavRight] = rightNavBlock
-	rightNavBlock.Edges[gameui.NavDown] = bottomNavBlock
-	rightNavBlock.Edges[gameui.NavLeft] = leftNavBlock
+	numColumns := 2
+	bindNavGrid(gridButtonElems, numColumns, replaysPerPage/numColumns)
+	bindNavGrid([]*gameui.NavElem{lastPlayedElem, sortElem}, 2, 1)
+	bindNavGrid([]*gameui.NavElem{prevPageElem, nextPageElem}, 2, 1)
+	bindNavGrid(controlButtonElems, 2, len(controlButtonElems)/2)
+	lastPlayedElem.Edges[gameui.NavDown] = gridButtonElems[0]
+	sortElem.Edges[gameui.NavDown] = gridButtonElems[1]
+	gridButtonElems[0].Edges[gameui.NavUp] = lastPlayedElem
+	gridButtonElems[1].Edges[gameui.NavUp] = sortElem
+	gridButtonElems[len(gridButtonElems)-2].Edges[gameui.NavDown] = prevPageElem
+	gridButtonElems[len(gridButtonElems)-1].Edges[gameui.NavDown] = nextPageElem
+	prevPageElem.Edges[gameui.NavUp] = gridButtonElems[len(gridButtonElems)-2]
+	nextPageElem.Edges[gameui.NavUp] = gridButtonElems[len(gridButtonElems)-1]
+	prevPageElem.Edges[gameui.NavDown] = controlButtonElems[0]
+	nextPageElem.Edges[gameui.NavDown] = controlButtonElems[1]
+	controlButtonElems[0].Edges[gameui.NavUp] = prevPageElem
+	controlButtonElems[1].Edges[gameui.NavUp] = nextPageElem
+	controlButtonElems[len(controlButtonElems)-2].Edges[gameui.NavDown] = backButtonElem
+	controlButtonElems[len(controlButtonElems)-1].Edges[gameui.NavDown] = backButtonElem
+	backButtonElem.Edges[gameui.NavUp] = controlButtonElems[len(controlButtonElems)-2]
 
 	setupUI(c.scene, root, c.state.MenuInput, navTree)
 }
 
+func (c *ReplayMenuController) numPages() int {
+	return gmath.ClampMin((len(c.entries)+replaysPerPage-1)/replaysPerPage, 1)
+}
+
+func (c *ReplayMenuController) setPage(page int) {
+	c.page = gmath.Clamp(page, 0, c.numPages()-1)
+	c.updatePage()
+}
+
+func (c *ReplayMenuController) updatePage() {
+	d := c.scene.Dict()
+
+	c.pageLabel.Label = fmt.Sprintf("%s %d/%d", d.Get("menu.replay.page"), c.page+1, c.numPages())
+
+	for i, b := range c.buttons {
+		entryIndex := c.page*replaysPerPage + i
+		if entryIndex >= len(c.entries) {
+			b.Text().Label = d.Get("menu.replay.empty")
+			b.GetWidget().Disabled = true
+			continue
+		}
+		e := c.entries[entryIndex]
+		b.Text().Label = fmt.Sprintf("[%s] %s", d.Get("menu.replay.mode", e.Mode), timeutil.FormatDateISO8601(e.Date, true))
+		b.GetWidget().Disabled = false
+	}
+
+	c.updateSelection()
+}
+
+func (c *ReplayMenuController) selectEntry(i int) {
+	c.selected = i
+	c.selectedRunnable = false
+	if i >= 0 && i < len(c.entries) {
+		if r, err := c.state.LoadLibraryReplay(c.entries[i].ID); err == nil {
+			c.selectedRunnable = gamedata.IsRunnableReplay(r.Replay)
+		}
+	}
+	c.updateSelection()
+	c.updateHelpText(i)
+}
+
+func (c *ReplayMenuController) updateSelection() {
+	slot := c.selected - c.page*replaysPerPage
+	if c.selected == -1 || slot < 0 || slot >= replaysPerPage {
+		c.slotSelector.Visible = false
+	} else {
+		c.slotSelector.Visible = true
+		rect := c.buttons[slot].GetWidget().Rect
+		c.slotSelectorPos.X = float64(rect.Min.X) - 2
+		c.slotSelectorPos.Y = float64(rect.Min.Y) - 2
+	}
+
+	hasSelection := c.selected != -1
+	c.playButton.GetWidget().Disabled = !hasSelection || !c.selectedRunnable
+	c.deleteButton.GetWidget().Disabled = !hasSelection
+	if c.exportButton != nil {
+		c.exportButton.GetWidget().Disabled = !hasSelection
+	}
+}
+
+func (c *ReplayMenuController) updateHelpText(i int) {
+	if i >= len(c.entries) {
+		return
+	}
+	r, err := c.state.LoadLibraryReplay(c.entries[i].ID)
+	if err != nil {
+		c.helpLabel.Label = err.Error()
+		return
+	}
+	text := descriptions.ReplayText(c.scene.Dict(), &r)
+	if source := c.entries[i].Source; source != "" {
+		text += "\n" + strings.TrimSuffix(source, session.ReplayFileExt)
+	}
+	c.helpLabel.Label = text
+}
+
+func (c *ReplayMenuController) playReplay(r session.SavedReplay) {
+	if !gamedata.IsRunnableReplay(r.Replay) {
+		return
+	}
+	if r.Replay.GameVersion != gamedata.BuildNumber {
+		// The current simulation can't run this replay.
+		c.scene.Context().ChangeScene(NewReplayCompatMenuController(c.state, r))
+		return
+	}
+	config := gamedata.MakeLevelConfig(gamedata.ExecuteReplay, r.Replay.Config)
+	config.Finalize()
+	controller := staging.NewController(c.state, config, NewReplayMenuController(c.state))
+	controller.SetReplayActions(r.Replay)
+	c.scene.Context().ChangeScene(controller)
+}
+
 func (c *ReplayMenuController) back() {
 	c.scene.Context().ChangeScene(NewProfileMenuController(c.state))
 }
@@ -84,7 +84,7 @@ func (c *SchemaMenuController) initUI() {
 
 	d := c.scene.Dict()
 
-	smallFont := assets.BitmapFont1
+	smallFont := c.state.Resources.UI.TextFont
 
 	helpLabel := eui.NewLabel("", smallFont)
 	helpLabel.MaxWidth = 268
@@ -0,0 +1,285 @@
+package menus
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/gsignal"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/contentlock"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/session"
+)
+
+const maxShareCodeLen = 160
+
+type ShareCodeMenuController struct {
+	state *session.State
+
+	errorSoundDelay float64
+
+	mode gamedata.Mode
+	seed int64
+
+	ui          *eui.SceneObject
+	keyboard    *eui.Keyboard
+	textInput   *widget.TextInput
+	statusLabel *widget.Text
+
+	scene *ge.Scene
+}
+
+// NewShareCodeMenuController creates a screen that shows the share code
+// for the current lobby config and allows to load a config from a code.
+// The seed is passed separately as it's not stored inside the lobby config.
+func NewShareCodeMenuController(state *session.State, mode gamedata.Mode, seed int64) *ShareCodeMenuController {
+	return &ShareCodeMenuController{
+		state: state,
+		mode:  mode,
+		seed:  seed,
+	}
+}
+
+func (c *ShareCodeMenuController) Init(scene *ge.Scene) {
+	c.scene = scene
+	c.initUI()
+}
+
+func (c *ShareCodeMenuController) Update(delta float64) {
+	c.errorSoundDelay = gmath.ClampMin(c.errorSoundDelay-delta, 0)
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *ShareCodeMenuController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainer(10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+
+	smallFont := assets.BitmapFont1
+
+	var widgets []eui.Widget
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.lobby.share_code"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	config := c.state.GetConfigForMode(c.mode).ReplayLevelConfig
+	config.Seed = c.seed
+	code, err := gamedata.EncodeShareCode(config)
+	if err != nil {
+		// Can happen for a config loaded from an outdated schema.
+		code = d.Get("menu.share_code.unavailable")
+	}
+
+	codePanel := eui.NewTextPanel(uiResources, 0, 0)
+	codePanel.AddChild(eui.NewLabel(d.Get("menu.share_code.yours"), smallFont))
+	codePanel.AddChild(eui.NewCenteredLabel(code, assets.BitmapFont2))
+	rowContainer.AddChild(codePanel)
+
+	rowContainer.AddChild(eui.NewTransparentSeparator())
+
+	rowContainer.AddChild(eui.NewCenteredLabel(d.Get("menu.share_code.enter"), smallFont))
+
+	textinput := eui.NewTextInput(uiResources, eui.TextInputConfig{SteamDeck: c.state.Device.IsSteamDeck()},
+		widget.TextInputOpts.WidgetOpts(
+			widget.WidgetOpts.MinSize(640, 0),
+		),
+		widget.TextInputOpts.SubmitHandler(func(args *widget.TextInputChangedEventArgs) {
+			if args.InputText == "" {
+				return
+			}
+			c.load(args.InputText)
+		}),
+		widget.TextInputOpts.Validation(func(newInputText string) (bool, *string) {
+			good := len(newInputText) <= maxShareCodeLen && isShareCodeText(newInputText)
+			if !good && c.errorSoundDelay == 0 {
+				c.scene.Audio().PlaySound(assets.AudioError)
+				c.errorSoundDelay = 0.2
+			}
+			return good, nil
+		}),
+	)
+	rowContainer.AddChild(textinput)
+	widgets = append(widgets, textinput)
+
+	c.textInput = textinput
+	if runtime.GOOS == "android" {
+		c.textInput.GetWidget().FocusEvent.AddHandler(func(args any) {
+			e := args.(*widget.WidgetFocusEventArgs)
+			if e.Focused {
+				if c.keyboard == nil {
+					c.openKeyboard()
+				}
+			}
+		})
+	}
+
+	statusPanel := eui.NewTextPanel(uiResources, 0, 0)
+	c.statusLabel = eui.NewLabel(d.Get("menu.share_code.hint"), smallFont)
+	c.statusLabel.MaxWidth = 640
+	statusPanel.AddChild(c.statusLabel)
+	rowContainer.AddChild(statusPanel)
+
+	loadButton := eui.NewButton(uiResources, c.scene, d.Get("menu.share_code.load"), func() {
+		c.load(textinput.GetText())
+	})
+	rowContainer.AddChild(loadButton)
+	widgets = append(widgets, loadButton)
+
+	backButton := eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	})
+	rowContainer.AddChild(backButton)
+	widgets = append(widgets, backButton)
+
+	navTree := createSimpleNavTree(widgets)
+	c.ui = setupUI(c.scene, root, c.state.MenuInput, navTree).ui
+}
+
+func (c *ShareCodeMenuController) load(code string) {
+	config, errorText := c.decode(code)
+	if errorText != "" {
+		c.scene.Audio().PlaySound(assets.AudioError)
+		c.statusLabel.Label = errorText
+		return
+	}
+
+	c.state.GetConfigForMode(c.mode).ReplayLevelConfig = config
+
+	lobby := NewLobbyMenuController(c.state, c.mode)
+	lobby.initialSeed = config.Seed
+	c.scene.Context().ChangeScene(lobby)
+}
+
+func (c *ShareCodeMenuController) decode(code string) (serverapi.ReplayLevelConfig, string) {
+	d := c.scene.Dict()
+
+	config, err := gamedata.DecodeShareCode(code)
+	switch err {
+	case nil:
+		// OK.
+	case gamedata.ErrShareCodeChecksum:
+		return config, d.Get("menu.share_code.error.checksum")
+	case gamedata.ErrShareCodeVersion:
+		return config, d.Get("menu.share_code.error.version")
+	default:
+		return config, d.Get("menu.share_code.error.format")
+	}
+
+	currentMode := c.state.GetConfigForMode(c.mode).RawGameMode
+	if config.RawGameMode != currentMode {
+		return config, d.Get("menu.share_code.error.mode") + ": " + d.Get("menu.play", config.RawGameMode)
+	}
+
+	if config.CustomRules != nil {
+		schedules, err := gamedata.ParseCustomWaveSchedules(c.scene.LoadRaw(assets.RawCustomWavesJSON).Data)
+		if err != nil {
+			panic(err)
+		}
+		scheduleIndex := xslices.IndexWhere(schedules, func(schedule *gamedata.CustomWaveSchedule) bool {
+			return schedule.Name == config.CustomRules.WaveSchedule
+		})
+		if scheduleIndex == -1 {
+			return config, d.Get("menu.share_code.error.invalid")
+		}
+		config.CustomRules.Waves = schedules[scheduleIndex].Waves
+		config.CustomRules = gamedata.CloneCustomRules(config.CustomRules)
+		config.DifficultyScore = gamedata.CalcDifficultyScore(config, config.DronePointsAllocated)
+	}
+
+	if !gamedata.IsValidLevelConfig(&config) {
+		return config, d.Get("menu.share_code.error.invalid")
+	}
+
+	if locked := contentlock.CheckLevelConfig(&c.state.Persistent.PlayerStats, &config); locked != nil {
+		return config, c.lockedContentText(locked)
+	}
+
+	return config, ""
+}
+
+func (c *ShareCodeMenuController) lockedContentText(locked *contentlock.LockedContent) string {
+	d := c.scene.Dict()
+
+	var names []string
+	if locked.Mode != "" {
+		names = append(names, d.Get("menu.play", locked.Mode))
+	}
+	if locked.Core != "" {
+		names = append(names, d.Get("core", locked.Core))
+	}
+	if locked.Turret != "" {
+		names = append(names, d.Get("turret", strings.ToLower(locked.Turret)))
+	}
+	for _, name := range locked.Drones {
+		names = append(names, d.Get("drone", strings.ToLower(name)))
+	}
+	for _, id := range locked.Options {
+		names = append(names, d.Get("menu.lobby", id))
+	}
+
+	return d.Get("menu.share_code.error.locked") + ": " + strings.Join(names, ", ")
+}
+
+func (c *ShareCodeMenuController) back() {
+	lobby := NewLobbyMenuController(c.state, c.mode)
+	lobby.initialSeed = c.seed
+	c.scene.Context().ChangeScene(lobby)
+}
+
+func (c *ShareCodeMenuController) openKeyboard() {
+	k := eui.NewTextKeyboard(eui.KeyboardConfig{
+		Resources: c.state.Resources.UI,
+		Scene:     c.scene,
+		Input:     c.state.MenuInput,
+	})
+	c.ui.AddWindow(k.Window)
+
+	runeBuf := []rune{0}
+	k.EventKey.Connect(nil, func(ch rune) {
+		runeBuf[0] = ch
+		c.textInput.Insert(runeBuf)
+		c.textInput.Focus(true)
+	})
+	k.EventBackspace.Connect(nil, func(gsignal.Void) {
+		c.textInput.Backspace()
+		c.textInput.Focus(true)
+	})
+	k.EventSubmit.Connect(nil, func(gsignal.Void) {
+		c.textInput.Submit()
+		k.Close()
+	})
+	k.EventClosed.Connect(nil, func(gsignal.Void) {
+		c.keyboard = nil
+	})
+	c.keyboard = k
+	c.scene.AddObject(c.keyboard)
+}
+
+func isShareCodeText(s string) bool {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == ' ':
+		default:
+			return false
+		}
+	}
+	return true
+}
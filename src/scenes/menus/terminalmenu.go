@@ -89,7 +89,7 @@ func (c *TerminalMenu) initUI() {
 
 	var widgets []eui.Widget
 
-	tinyFont := assets.BitmapFont1
+	tinyFont := c.state.Resources.UI.TextFont
 
 	titleLabel := eui.NewCenteredLabel(d.Get("menu.main.settings")+" -> "+d.Get("menu.options.extra")+" -> "+d.Get("menu.terminal"), assets.BitmapFont3)
 	rowContainer.AddChild(titleLabel)
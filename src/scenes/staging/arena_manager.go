@@ -360,6 +360,7 @@ func (m *arenaManager) createWaveInfoText() string {
 
 func (m *arenaManager) spawnCreeps() {
 	m.scene.Audio().PlaySound(assets.AudioWaveStart)
+	m.world.events.WaveStarted(m.level)
 
 	isLastLevel := !m.infArena && m.level == m.lastLevel
 
@@ -84,7 +84,7 @@ func (m *blitzManager) spawnGrenadiers() {
 		})
 	}
 
-	m.grenadiersDelay = m.world.rand.FloatRange(100, 230)
+	m.grenadiersDelay = m.world.rand.FloatRange(100, 230) / m.world.creepProductionAdjustment
 	if m.grenadiersDelay >= 160 {
 		m.grenadierWave++
 	}
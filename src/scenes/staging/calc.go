@@ -52,6 +52,44 @@ func calcCreepPower(world *worldState, creep *creepNode) int {
 	return int(power)
 }
 
+func calcColonyPower(world *worldState, c *colonyCoreNode, targetFlags gamedata.TargetKind) int {
+	score := 0
+	targetInfo := targetInfo{flying: targetFlags&gamedata.TargetFlying != 0}
+	c.agents.Each(func(a *colonyAgentNode) {
+		if a.stats.PowerScore == 0 {
+			return
+		}
+		power := a.stats.PowerScore
+		if a.stats.Weapon != nil && targetFlags != gamedata.TargetAny {
+			if !a.CanAttack(targetFlags) {
+				return
+			}
+			power *= damageMultiplier(targetInfo, a.stats.Weapon)
+		}
+		droneScore := power
+		switch a.rank {
+		case 1:
+			droneScore += power * 0.25
+		case 2:
+			droneScore += power * 0.5
+		}
+		if a.faction == gamedata.RedFactionTag {
+			droneScore += power * 0.1
+		}
+		droneScore *= ((a.health / a.maxHealth) + 0.2) * world.dronePowerMultiplier
+		score += int(droneScore)
+	})
+	switch {
+	case c.realRadius < 150:
+		score = int(float64(score) * 1.2)
+	case c.realRadius < 200:
+		score = int(float64(score) * 1.1)
+	case c.realRadius < 250:
+		score = int(float64(score) * 1.05)
+	}
+	return score
+}
+
 func calcPosDanger(world *worldState, pstate *playerState, pos gmath.Vec, r float64) (int, gmath.Vec) {
 	total := 0
 	highestDanger := 0
@@ -199,6 +237,14 @@ func creepFragScore(stats *gamedata.CreepStats) int {
 }
 
 func calcScore(world *worldState) int {
+	score := calcModeScore(world)
+	if world.difficulty != nil {
+		score = int(math.Round(float64(score) * world.difficulty.ScoreMultiplier()))
+	}
+	return score
+}
+
+func calcModeScore(world *worldState) int {
 	switch world.config.GameMode {
 	case gamedata.ModeTutorial:
 		return 500
@@ -39,6 +39,9 @@ type cameraManager struct {
 	cameraToggleSnapProgress float64
 
 	cinematicSwitchDelay float64
+
+	// panned is set if the last HandleInput moved the camera.
+	panned bool
 }
 
 func newCameraManager(world *worldState, cam *viewport.Camera) *cameraManager {
@@ -101,6 +104,8 @@ func (m *cameraManager) HandleInput() {
 		return
 	}
 
+	m.panned = false
+
 	if !m.world.deviceInfo.IsMobile() {
 		// Camera panning only makes sense on non-mobile devices
 		// where we have a keyboard/gamepad or a cursor.
@@ -124,6 +129,7 @@ func (m *cameraManager) HandleInput() {
 					m.cameraPanStartPos = info.Pos
 				} else if info, ok := m.input.PressedActionInfo(controls.ActionPanAlt); ok {
 					m.cameraToggleTarget = gmath.Vec{}
+					m.panned = true
 					posDelta := m.cameraPanStartPos.Sub(info.Pos).Mulf(m.cameraDragSpeed)
 					newPos := m.cameraPanDragPos.Add(posDelta)
 					m.SetOffset(newPos)
@@ -153,6 +159,7 @@ func (m *cameraManager) HandleInput() {
 		}
 		if !cameraPan.IsZero() {
 			m.cameraToggleTarget = gmath.Vec{}
+			m.panned = true
 		}
 		m.Pan(cameraPan)
 	} else {
@@ -163,6 +170,7 @@ func (m *cameraManager) HandleInput() {
 		}
 		if info, ok := m.input.PressedActionInfo(controls.ActionPanDrag); ok {
 			m.cameraToggleTarget = gmath.Vec{}
+			m.panned = true
 			posDelta := info.StartPos.Sub(info.Pos).Mulf(m.cameraDragSpeed)
 			newPos := m.cameraPanDragPos.Add(posDelta)
 			m.SetOffset(newPos)
@@ -288,6 +296,11 @@ func (m *cameraManager) Update(delta float64) {
 	}
 }
 
+// IsToggling reports whether the camera is moving to the ToggleCamera target.
+func (m *cameraManager) IsToggling() bool {
+	return !m.cameraToggleTarget.IsZero()
+}
+
 func (m *cameraManager) ToggleCamera(pos gmath.Vec) {
 	m.cameraToggleTarget = pos
 	m.cameraToggleProgress = 0
@@ -56,6 +56,14 @@ type selectedChoice struct {
 	Pos      gmath.Vec
 	Player   player
 	Colony   *colonyCoreNode
+
+	// Queued is set for the shift-queued move orders.
+	Queued bool
+
+	// Group is only set for the control group actions.
+	// The action is applied to every group colony;
+	// Colony is the first colony of the group.
+	Group []*colonyCoreNode
 }
 
 type choiceOption struct {
@@ -409,15 +417,67 @@ func (g *choiceGenerator) TryExecute(colony *colonyCoreNode, cardIndex int, pos
 		return g.activateChoice(colony, cardIndex)
 	}
 
-	if colony.mode != colonyModeNormal {
-		return false
-	}
 	if cardIndex != -1 {
+		if colony.mode != colonyModeNormal {
+			return false
+		}
 		return g.activateChoice(colony, cardIndex)
 	}
+	// A colony with a relocation queue accepts the move orders
+	// even while relocating: the new order replaces the queue.
+	if colony.mode != colonyModeNormal && len(colony.relocationQueue) == 0 {
+		return false
+	}
 	return g.activateMoveChoice(colony, pos)
 }
 
+// TryExecuteGroup is like TryExecute, but the action is applied to all colonies of the group.
+// The colonies that can't act right now are skipped.
+// A card is consumed only once, no matter how many colonies are in the group.
+func (g *choiceGenerator) TryExecuteGroup(group []*colonyCoreNode, cardIndex int, pos gmath.Vec) bool {
+	var colonies []*colonyCoreNode
+	for _, colony := range group {
+		if colony.mode == colonyModeNormal {
+			colonies = append(colonies, colony)
+		}
+	}
+	if len(colonies) == 0 {
+		return false
+	}
+	if cardIndex != -1 {
+		return g.activateGroupChoice(colonies, cardIndex)
+	}
+	choice := selectedChoice{
+		Colony: colonies[0],
+		Index:  -1,
+		Option: choiceOption{special: specialChoiceMoveColony},
+		Pos:    pos,
+		Player: g.player,
+	}
+	if len(colonies) > 1 {
+		choice.Group = colonies
+	}
+	g.EventChoiceSelected.Emit(choice)
+	return true
+}
+
+// TryQueueMove adds a destination to the colony relocation queue.
+// Unlike the regular move order, it can be given to a relocating colony.
+func (g *choiceGenerator) TryQueueMove(colony *colonyCoreNode, pos gmath.Vec) bool {
+	if g.creepsState != nil {
+		return false
+	}
+	g.EventChoiceSelected.Emit(selectedChoice{
+		Colony: colony,
+		Index:  -1,
+		Option: choiceOption{special: specialChoiceMoveColony},
+		Pos:    pos,
+		Player: g.player,
+		Queued: true,
+	})
+	return true
+}
+
 func (g *choiceGenerator) activateCenturionsMoveChoice(pos gmath.Vec) bool {
 	if !g.world.AllCenturionsReady() || g.world.boss == nil {
 		return false
@@ -443,16 +503,23 @@ func (g *choiceGenerator) activateMoveChoice(colony *colonyCoreNode, pos gmath.V
 }
 
 func (g *choiceGenerator) activateChoice(colony *colonyCoreNode, i int) bool {
+	return g.activateGroupChoice([]*colonyCoreNode{colony}, i)
+}
+
+func (g *choiceGenerator) activateGroupChoice(colonies []*colonyCoreNode, i int) bool {
 	if g.state != choiceReady {
 		return false
 	}
 
 	choice := selectedChoice{
-		Colony:  colony,
+		Colony:  colonies[0],
 		Faction: gamedata.FactionTag(i + 1),
 		Index:   i,
 		Player:  g.player,
 	}
+	if len(colonies) > 1 {
+		choice.Group = colonies
+	}
 	cooldown := 10.0
 	if i == 4 {
 		// A special action is selected.
@@ -48,6 +48,7 @@ type choiceOptionSlot struct {
 	icon     *ge.Sprite
 	label1   *ge.Sprite
 	label2   *ge.Sprite
+	pattern  *ge.Sprite
 	rect     gmath.Rect
 	option   choiceOption
 }
@@ -89,6 +90,7 @@ func (w *choiceWindowNode) Init(scene *ge.Scene) {
 		assets.ImageFloppyBlueFlip,
 		assets.ImageFloppyGrayFlip,
 	}
+	style := w.world.diodeStyle
 	offsetY := 8.0
 	w.floppyOffsetX = (w.cam.Rect.Width() - 86 - 8)
 	offset := gmath.Vec{X: w.floppyOffsetX, Y: 8 + (scene.Context().ScreenHeight - 540)}
@@ -96,20 +98,32 @@ func (w *choiceWindowNode) Init(scene *ge.Scene) {
 	for i := range w.choices {
 		floppyImageID := floppies[i]
 		floppyFlipImageID := flipSprites[i]
+		// The alternative palettes use the tinted gray floppies.
+		var floppyColor ge.ColorScale
 		if w.creeps {
 			floppyImageID = assets.ImageFloppyDark
 			floppyFlipImageID = assets.ImageFloppyDarkFlip
+		} else if i < 4 && style.Palette != gamedata.FactionPaletteDefault {
+			floppyImageID = assets.ImageFloppyGray
+			floppyFlipImageID = assets.ImageFloppyGrayFlip
+			floppyColor.SetColor(gamedata.FactionColor(gamedata.FactionTag(i+1), style.Palette))
 		}
 
 		floppy := scene.NewSprite(floppyImageID)
 		floppy.Centered = false
 		floppy.Pos.Offset = offset
+		if floppyColor != (ge.ColorScale{}) {
+			floppy.SetColorScale(floppyColor)
+		}
 		w.cam.UI.AddGraphics(floppy)
 
 		flipSprite := scene.NewSprite(floppyFlipImageID)
 		flipSprite.Centered = false
 		flipSprite.Pos.Offset = offset
 		flipSprite.Visible = false
+		if floppyColor != (ge.ColorScale{}) {
+			flipSprite.SetColorScale(floppyColor)
+		}
 		w.cam.UI.AddGraphics(flipSprite)
 
 		offset.Y += floppy.ImageHeight() + offsetY
@@ -137,6 +151,22 @@ func (w *choiceWindowNode) Init(scene *ge.Scene) {
 		w.cam.UI.AddGraphics(label1)
 		w.cam.UI.AddGraphics(label2)
 
+		var pattern *ge.Sprite
+		if !w.creeps && i < 4 && style.Patterns {
+			faction := gamedata.FactionTag(i + 1)
+			pattern = ge.NewSprite(scene.Context())
+			pattern.Centered = false
+			pattern.SetImage(resource.Image{Data: gameui.FactionPatternImage(faction)})
+			pattern.SetScale(2, 2)
+			pattern.Pos.Base = &floppy.Pos.Offset
+			pattern.Pos.Offset = gmath.Vec{X: 10, Y: 8}
+			pattern.Visible = false
+			var colorScale ge.ColorScale
+			colorScale.SetColor(gamedata.FactionColor(faction, style.Palette))
+			pattern.SetColorScale(colorScale)
+			w.cam.UI.AddGraphics(pattern)
+		}
+
 		var icon *ge.Sprite
 		if i == 4 {
 			icon = ge.NewSprite(scene.Context())
@@ -162,6 +192,7 @@ func (w *choiceWindowNode) Init(scene *ge.Scene) {
 			flipAnim: ge.NewAnimation(flipSprite, -1),
 			label1:   label1,
 			label2:   label2,
+			pattern:  pattern,
 			floppy:   floppy,
 			icon:     icon,
 			rect:     choiceRect,
@@ -196,6 +227,9 @@ func (w *choiceWindowNode) RevealChoices(selection choiceSelection) {
 		if o.icon != nil {
 			o.icon.Visible = w.getFloppyVisibility(i)
 		}
+		if o.pattern != nil {
+			o.pattern.Visible = true
+		}
 	}
 
 	if w.creeps {
@@ -212,6 +246,10 @@ func (w *choiceWindowNode) RevealChoices(selection choiceSelection) {
 	} else {
 		for i, o := range selection.cards {
 			faction := gamedata.FactionTag(i + 1)
+			if w.world.diodeStyle.Palette != gamedata.FactionPaletteDefault {
+				// The faction-colored icons would clash with the palette.
+				faction = gamedata.NeutralFactionTag
+			}
 			choice := w.choices[i]
 			choice.option = o
 			if len(o.effects) == 1 {
@@ -253,6 +291,9 @@ func (w *choiceWindowNode) StartCharging(targetValue float64, cardIndex int) {
 		if o.icon != nil {
 			o.icon.Visible = false
 		}
+		if o.pattern != nil {
+			o.pattern.Visible = false
+		}
 	}
 }
 
@@ -148,7 +148,7 @@ func (m *classicManager) spawnGrenadiers() {
 		})
 	}
 
-	m.grenadiersDelay = m.world.rand.FloatRange(100, 230)
+	m.grenadiersDelay = m.world.rand.FloatRange(100, 230) / m.world.creepProductionAdjustment
 	if m.grenadiersDelay >= 160 {
 		m.grenadierWave++
 	}
@@ -156,6 +156,7 @@ func (m *classicManager) spawnGrenadiers() {
 	m.attackGroup.units = units
 	m.attackGroup.side = m.world.rand.IntRange(0, 3)
 	sendCreeps(m.world, m.attackGroup)
+	m.world.events.WaveStarted(len(units))
 }
 
 func (m *classicManager) spawnCrawlers() {
@@ -179,17 +180,18 @@ func (m *classicManager) spawnCrawlers() {
 		})
 	}
 
-	m.crawlersDelay = nextAttackDelay * m.spawnDelayMultiplier
+	m.crawlersDelay = (nextAttackDelay * m.spawnDelayMultiplier) / m.world.creepProductionAdjustment
 
 	m.attackGroup.units = units
 	m.attackGroup.side = m.world.rand.IntRange(0, 3)
 	sendCreeps(m.world, m.attackGroup)
+	m.world.events.WaveStarted(len(units))
 }
 
 func (m *classicManager) spawnTier3Creep() {
 	superChance := (1.0 - m.tier3spawnRate) * 0.5
 	m.tier3spawnRate = gmath.ClampMin(m.tier3spawnRate-0.02, 0.35)
-	m.tier3spawnDelay = (m.world.rand.FloatRange(60, 90) * m.tier3spawnRate) * m.spawnDelayMultiplier
+	m.tier3spawnDelay = ((m.world.rand.FloatRange(60, 90) * m.tier3spawnRate) * m.spawnDelayMultiplier) / m.world.creepTechAdjustment
 
 	var spawnPos gmath.Vec
 	roll := m.world.rand.Float()
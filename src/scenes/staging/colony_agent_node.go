@@ -12,6 +12,7 @@ import (
 
 	"github.com/quasilyte/roboden-game/assets"
 	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui"
 	"github.com/quasilyte/roboden-game/pathing"
 )
 
@@ -118,6 +119,7 @@ type colonyAgentNode struct {
 	anim       *ge.Animation
 	sprite     *ge.Sprite
 	diode      *ge.Sprite
+	pattern    *ge.Sprite
 	colonyCore *colonyCoreNode
 
 	flashComponent damageFlashComponent
@@ -357,21 +359,36 @@ func (a *colonyAgentNode) Init(scene *ge.Scene) {
 	a.flashComponent.sprite = a.sprite
 
 	if a.faction != gamedata.NeutralFactionTag {
+		style := a.world().diodeStyle
 		diodeImg := assets.ImageFactionDiode
-		if a.world().gameSettings.LargeDiodes {
+		if style.Large {
 			diodeImg = assets.ImageFactionDiodeLarge
 		}
 		a.diode = scene.NewSprite(diodeImg)
 		a.diode.Pos.Base = &a.pos
 		a.diode.Pos.Offset.Y = a.stats.DiodeOffset
 		var colorScale ge.ColorScale
-		colorScale.SetColor(gamedata.FactionByTag(a.faction).Color)
+		colorScale.SetColor(gamedata.FactionColor(a.faction, style.Palette))
 		a.diode.SetColorScale(colorScale)
 
+		if style.Patterns {
+			a.pattern = ge.NewSprite(scene.Context())
+			a.pattern.SetImage(resource.Image{Data: gameui.FactionPatternImage(a.faction)})
+			a.pattern.Pos.Base = &a.pos
+			a.pattern.Pos.Offset.Y = a.stats.DiodeOffset - 6
+			a.pattern.SetColorScale(colorScale)
+		}
+
 		if a.IsFlying() {
 			a.world().stage.AddSpriteAbove(a.diode)
+			if a.pattern != nil {
+				a.world().stage.AddSpriteAbove(a.pattern)
+			}
 		} else {
 			a.world().stage.AddSprite(a.diode)
+			if a.pattern != nil {
+				a.world().stage.AddSprite(a.pattern)
+			}
 		}
 	}
 
@@ -950,6 +967,9 @@ func (a *colonyAgentNode) dispose() {
 	if a.diode != nil {
 		a.diode.Dispose()
 	}
+	if a.pattern != nil {
+		a.pattern.Dispose()
+	}
 	if a.cloningBeam != nil {
 		a.cloningBeam.Dispose()
 		a.cloningBeam = nil
@@ -1140,6 +1160,10 @@ func (a *colonyAgentNode) OnDamage(damage gamedata.DamageValue, source targetabl
 		a.health -= healthDamage
 
 		if a.health < 0 {
+			a.world().events.DroneDestroyed(a, source)
+			if a.world().difficulty != nil {
+				a.world().difficulty.OnDroneDestroyed(a)
+			}
 			a.explode()
 			a.Destroy()
 			return
@@ -1800,6 +1824,9 @@ func (a *colonyAgentNode) movementSpeed() float64 {
 	if a.slow > 0 {
 		multiplier *= 0.55
 	}
+	if a.world().weatherKind == weatherBlizzard && a.IsFlying() {
+		multiplier *= 1.0 - 0.3*a.world().weatherPower
+	}
 	if a.tether {
 		multiplier *= 2
 		baseSpeed += 5
@@ -1943,6 +1970,9 @@ func (a *colonyAgentNode) setVisibility(visible bool) {
 	if a.diode != nil {
 		a.diode.Visible = visible
 	}
+	if a.pattern != nil {
+		a.pattern.Visible = visible
+	}
 }
 
 func (a *colonyAgentNode) handleForestTransition(nextWaypoint gmath.Vec) {
@@ -2634,6 +2664,9 @@ func (a *colonyAgentNode) updateKamikazeAttack(delta float64) {
 			}
 			otherCreep.OnDamage(gamedata.DamageValue{Health: explosionDamage * 0.5, Flags: damageFlags}, a)
 		}
+		// The recycled, merged and consumed drones are not reported,
+		// but the kamikaze explosion is a drone death.
+		a.world().events.DroneDestroyed(a, creep)
 		a.Destroy()
 		return
 	}
@@ -2867,6 +2900,10 @@ func (a *colonyAgentNode) updateMerging(delta float64) {
 		}
 		newAgent.faction = newFaction
 		a.world().nodeRunner.AddObject(newAgent)
+		a.world().events.DroneMerged(newAgent, a, target)
+		if newStats.Tier == 3 {
+			a.world().EventTier3DroneCreated.Emit(newAgent)
+		}
 		if newAgent.stats == gamedata.RoombaAgentStats {
 			newAgent.mode = agentModeRoombaWait
 			newAgent.dist = 1
@@ -2914,6 +2951,7 @@ func (a *colonyAgentNode) updateMakeClone(delta float64) {
 		clone := a.colonyCore.CloneAgentNode(target)
 		a.world().nodeRunner.AddObject(clone)
 		a.world().result.DronesProduced++
+		a.world().events.DroneCreated(clone)
 		clone.AssignMode(agentModeStandby, gmath.Vec{}, nil)
 		createEffect(a.world(), effectConfig{
 			Pos:     clone.pos,
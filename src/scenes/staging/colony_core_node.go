@@ -20,6 +20,8 @@ const (
 	maxEvoPoints     float64 = 20
 	maxEvoGain       float64 = 1.0
 	blueEvoThreshold float64 = 18.0
+
+	maxRelocationQueueLen = 6
 )
 
 type colonyCoreMode int
@@ -71,6 +73,8 @@ type colonyCoreNode struct {
 	id     int
 	tether int
 
+	resourcesGathered float64
+
 	heavyDamageWarningCooldown float64
 
 	mode colonyCoreMode
@@ -80,6 +84,13 @@ type colonyCoreNode struct {
 	relocationPoint        gmath.Vec
 	plannedRelocationPoint gmath.Vec
 
+	// relocationQueue holds the shift-queued destinations.
+	// The colony relocates to the next one as soon as it becomes idle.
+	relocationQueue []gmath.Vec
+	// relocationQueueDist is a distance to the first queued destination
+	// measured during the previous leg; zero means "no legs made yet".
+	relocationQueueDist float64
+
 	path pathing.GridPath
 
 	resourceShortage int
@@ -410,6 +421,7 @@ func (c *colonyCoreNode) OnDamage(damage gamedata.DamageValue, source targetable
 			c.EventUnderAttack.Emit(c)
 		}
 		c.EventOnDamage.Emit(source)
+		c.world.events.ColonyDamaged(c, source, healthDamage)
 	}
 
 	c.updateHealthShader()
@@ -504,7 +516,12 @@ func (c *colonyCoreNode) AcceptRoomba(roomba *colonyAgentNode) {
 
 func (c *colonyCoreNode) AddGatheredResources(value float64) {
 	c.resources += value
+	c.resourcesGathered += value
 	c.world.result.ResourcesGathered += value
+	c.world.events.ResourceMined(c, value)
+	if c.world.difficulty != nil {
+		c.world.difficulty.OnResourcesGathered(c, value)
+	}
 }
 
 func (c *colonyCoreNode) AcceptTurret(turret *colonyAgentNode) {
@@ -525,6 +542,7 @@ func (c *colonyCoreNode) AcceptTurret(turret *colonyAgentNode) {
 		c.world.MarkPos(turret.pos, ptagBlocked)
 	}
 	c.world.turrets = append(c.world.turrets, turret)
+	c.world.spatial.MarkTurretsDirty()
 	c.turrets = append(c.turrets, turret)
 	turret.colonyCore = c
 	c.EventTurretAccepted.Emit(turret)
@@ -552,6 +570,7 @@ func (c *colonyCoreNode) AcceptAgent(a *colonyAgentNode) {
 	})
 	c.agents.Add(a)
 	a.colonyCore = c
+	c.world.spatial.MarkColonyDirty(c)
 }
 
 func (c *colonyCoreNode) NumAgents() int { return c.agents.TotalNum() }
@@ -671,6 +690,9 @@ func (c *colonyCoreNode) updateTeleporting(delta float64) {
 			Image: assets.ImageTeleportEffectBig,
 		})
 
+		c.world.spatial.MarkColonyDirty(c)
+		c.world.spatial.MarkColoniesDirty()
+
 		c.unmarkCells(c.pos)
 		c.pos = relocationPoint
 		c.drawOrder = c.pos.Y
@@ -1202,6 +1224,7 @@ func (c *colonyCoreNode) crushCrawlers() {
 
 		// Defeat without an explosion
 		creep.Destroy()
+		creep.onKilled(c)
 		c.world.result.CreepsStomped++
 		return false
 	})
@@ -1562,6 +1585,7 @@ func (c *colonyCoreNode) tryExecutingAction(action colonyAction) bool {
 		c.world.nodeRunner.AddObject(a)
 		a.SetHeight(c.shadowComponent.height)
 		c.world.result.DronesProduced++
+		c.world.events.DroneCreated(a)
 		c.resources = gmath.ClampMin(c.resources-(a.stats.Cost*c.stats.DroneProductionCost), 0)
 		a.AssignMode(agentModeTakeoff, gmath.Vec{}, nil)
 		playSound(c.world, assets.AudioAgentProduced, c.pos)
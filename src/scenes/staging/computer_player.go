@@ -38,6 +38,8 @@ type computerPlayer struct {
 	colonyTargetRadius float64
 	maxColonies        int
 
+	personality *gamedata.BotPersonality
+
 	colonyPower           int
 	calculatedColonyPower bool
 	disposed              bool
@@ -74,12 +76,13 @@ type computerColony struct {
 	howitzerAttacker *creepNode
 }
 
-func newComputerPlayer(world *worldState, state *playerState, choiceGen *choiceGenerator) *computerPlayer {
+func newComputerPlayer(world *worldState, state *playerState, choiceGen *choiceGenerator, personality *gamedata.BotPersonality) *computerPlayer {
 	p := &computerPlayer{
-		world:     world,
-		state:     state,
-		scene:     world.rootScene,
-		choiceGen: choiceGen,
+		world:       world,
+		state:       state,
+		scene:       world.rootScene,
+		choiceGen:   choiceGen,
+		personality: personality,
 
 		resourceCards:  make([]int, 0, 4),
 		growthCards:    make([]int, 0, 4),
@@ -194,6 +197,8 @@ func newComputerPlayer(world *worldState, state *playerState, choiceGen *choiceG
 		p.maxColonies++
 	}
 
+	p.applyPersonality()
+
 	p.world.EventColonyCreated.Connect(p, func(colony *colonyCoreNode) {
 		if colony.player != p {
 			return
@@ -206,6 +211,7 @@ func newComputerPlayer(world *worldState, state *playerState, choiceGen *choiceG
 		if p.isHive {
 			wrapped.maxTurrets++
 		}
+		wrapped.maxTurrets = gmath.ClampMin(wrapped.maxTurrets+p.personality.MaxTurretsBonus, 0)
 		colony.EventDestroyed.Connect(p, func(_ *colonyCoreNode) {
 			p.colonies = xslices.Remove(p.colonies, wrapped)
 		})
@@ -224,6 +230,19 @@ func newComputerPlayer(world *worldState, state *playerState, choiceGen *choiceG
 	return p
 }
 
+func (p *computerPlayer) applyPersonality() {
+	personality := p.personality
+
+	p.maxColonies = gmath.ClampMin(p.maxColonies+personality.MaxColoniesBonus, 1)
+	p.colonyTargetRadius *= personality.ColonyTargetRadiusMultiplier
+	p.turretCostMultiplier *= personality.TurretCostMultiplier
+	p.buildColonyDelay *= personality.BuildColonyDelayMultiplier
+
+	if p.world.debugLogs {
+		p.world.sessionState.Logf("bot personality: %s (max colonies: %d)", personality.Name, p.maxColonies)
+	}
+}
+
 func (p *computerPlayer) maxTurretsForColony() int {
 	switch p.world.turretDesign {
 	case gamedata.GunpointAgentStats:
@@ -360,7 +379,7 @@ func (p *computerPlayer) maybeDoComeback() bool {
 		return false
 	}
 
-	power := p.calcColonyPower(leaderColony.node, gamedata.TargetAny) + 10
+	power := calcColonyPower(p.world, leaderColony.node, gamedata.TargetAny) + 10
 
 	// Try to find a place that worths a shot.
 	candidatePos1, candidateScore1 := p.findGoodComebackSpot(leaderColony, power, 1.2*leaderColony.node.MaxFlyDistance())
@@ -418,9 +437,9 @@ func (p *computerPlayer) maybeDoColonyAction(colony *computerColony) bool {
 	// going to send their colonies into combat.
 	if colony.attackDelay == 0 {
 		if p.maybeStartAttackingDreadnought(colony) {
-			colony.attackDelay = p.world.rand.FloatRange(50, 110)
+			colony.attackDelay = p.world.rand.FloatRange(50, 110) * p.personality.AttackDelayMultiplier
 		} else {
-			colony.attackDelay = p.world.rand.FloatRange(15, 30)
+			colony.attackDelay = p.world.rand.FloatRange(15, 30) * p.personality.AttackDelayMultiplier
 		}
 	}
 
@@ -492,6 +511,7 @@ func (p *computerPlayer) maybeDoColonyAction(colony *computerColony) bool {
 			} else {
 				p.buildColonyDelay = p.world.rand.FloatRange(80, 6*60)
 			}
+			p.buildColonyDelay *= p.personality.BuildColonyDelayMultiplier
 			return true
 		}
 		if p.isHive {
@@ -499,14 +519,15 @@ func (p *computerPlayer) maybeDoColonyAction(colony *computerColony) bool {
 		} else {
 			p.buildColonyDelay = p.world.rand.FloatRange(30, 60)
 		}
+		p.buildColonyDelay *= p.personality.BuildColonyDelayMultiplier
 	}
 
 	if p.buildTurretDelay == 0 && p.choiceSelection.special.special == specialBuildGunpoint && colony.node.numTurretsBuilt < colony.maxTurrets {
 		if p.maybeBuildTurret(colony) {
-			p.buildTurretDelay = p.world.rand.FloatRange(40, 2*90)
+			p.buildTurretDelay = p.world.rand.FloatRange(40, 2*90) * p.personality.BuildTurretDelayMultiplier
 			return true
 		}
-		p.buildTurretDelay = p.world.rand.FloatRange(5, 20)
+		p.buildTurretDelay = p.world.rand.FloatRange(5, 20) * p.personality.BuildTurretDelayMultiplier
 	}
 
 	if colony.specialDelay == 0 {
@@ -773,7 +794,7 @@ func (p *computerPlayer) maybeStartAttackingDreadnought(colony *computerColony)
 		if !addToGroup {
 			continue
 		}
-		colonyPower := p.maybeAddColonyPower(otherColony.node, p.calcColonyPower(otherColony.node, gamedata.TargetAny))
+		colonyPower := p.maybeAddColonyPower(otherColony.node, calcColonyPower(p.world, otherColony.node, gamedata.TargetAny))
 		if colonyPower < 90 {
 			continue
 		}
@@ -985,7 +1006,7 @@ func (p *computerPlayer) maybeHandleHowitzerThreat(colony *computerColony) bool
 			if c == colony.node || cc.attacking != 0 {
 				return false
 			}
-			otherColonyPower := p.calcColonyPower(c, gamedata.TargetGround)
+			otherColonyPower := calcColonyPower(p.world, c, gamedata.TargetGround)
 			return otherColonyPower >= requiredPower &&
 				c.resources > 40 &&
 				c.mode == colonyModeNormal &&
@@ -1406,6 +1427,7 @@ func (p *computerPlayer) maybeChangePriorities(colony *computerColony) bool {
 		if needMoreEvolution {
 			// 10 Scarab drones give extra 20%.
 			extraChance := 0.02 * float64(colony.node.planner.agentCountTable[gamedata.AgentScarab])
+			extraChance += p.personality.EvolutionChanceBonus
 			increaseElolutionChance := gmath.Clamp(extraChance+0.1+(1.0-(p.world.rand.FloatRange(0.7, 1.0)*c.GetGrowthPriority())), 0, 1)
 			if p.world.rand.Chance(increaseElolutionChance) {
 				return p.tryExecuteAction(colony.node, gmath.RandElem(p.world.rand, p.evolutionCards), gmath.Vec{})
@@ -1491,12 +1513,12 @@ func (p *computerPlayer) maybeMoveColony(colony *computerColony) float64 {
 		if b != nil {
 			danger, _ := p.calcPosDangerWithHazards(b.pos, colony.node.realRadius+100)
 			if danger < 2*p.selectedColonyPower(gamedata.TargetAny) {
-				p.captureDelay = p.world.rand.FloatRange(50, 100)
+				p.captureDelay = p.world.rand.FloatRange(50, 100) * p.personality.CaptureDelayMultiplier
 				p.executeMoveAction(colony.node, b.pos.Add(p.world.rand.Offset(-128, 128)))
 				colony.retreatPos = gmath.Vec{}
 				return 70
 			} else {
-				p.captureDelay = p.world.rand.FloatRange(15, 30)
+				p.captureDelay = p.world.rand.FloatRange(15, 30) * p.personality.CaptureDelayMultiplier
 			}
 		}
 	}
@@ -1555,49 +1577,11 @@ func (p *computerPlayer) maybeMoveColony(colony *computerColony) float64 {
 func (p *computerPlayer) selectedColonyPower(targetFlags gamedata.TargetKind) int {
 	if !p.calculatedColonyPower {
 		p.calculatedColonyPower = true
-		p.colonyPower = p.calcColonyPower(p.state.selectedColony, targetFlags)
+		p.colonyPower = calcColonyPower(p.world, p.state.selectedColony, targetFlags)
 	}
 	return p.colonyPower
 }
 
-func (p *computerPlayer) calcColonyPower(c *colonyCoreNode, targetFlags gamedata.TargetKind) int {
-	score := 0
-	targetInfo := targetInfo{flying: targetFlags&gamedata.TargetFlying != 0}
-	c.agents.Each(func(a *colonyAgentNode) {
-		if a.stats.PowerScore == 0 {
-			return
-		}
-		power := a.stats.PowerScore
-		if a.stats.Weapon != nil && targetFlags != gamedata.TargetAny {
-			if !a.CanAttack(targetFlags) {
-				return
-			}
-			power *= damageMultiplier(targetInfo, a.stats.Weapon)
-		}
-		droneScore := power
-		switch a.rank {
-		case 1:
-			droneScore += power * 0.25
-		case 2:
-			droneScore += power * 0.5
-		}
-		if a.faction == gamedata.RedFactionTag {
-			droneScore += power * 0.1
-		}
-		droneScore *= ((a.health / a.maxHealth) + 0.2) * p.world.dronePowerMultiplier
-		score += int(droneScore)
-	})
-	switch {
-	case c.realRadius < 150:
-		score = int(float64(score) * 1.2)
-	case c.realRadius < 200:
-		score = int(float64(score) * 1.1)
-	case c.realRadius < 250:
-		score = int(float64(score) * 1.05)
-	}
-	return score
-}
-
 func (p *computerPlayer) calcPosResources(colony *colonyCoreNode, pos gmath.Vec, r float64) (int, gmath.Vec, bool) {
 	resourcesScore := 0
 	bestResource := 0
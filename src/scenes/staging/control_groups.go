@@ -0,0 +1,85 @@
+package staging
+
+import (
+	"math"
+
+	"github.com/quasilyte/gmath"
+)
+
+// numControlGroups is a number of colony groups the player can assign.
+const numControlGroups = 4
+
+const (
+	// The group colonies keep their relative positions when moving together,
+	// but these distances from the destination point are enforced.
+	groupFormationMinDist = 96.0
+	groupFormationMaxDist = 196.0
+)
+
+// groupFormationOffsets returns a destination offset for every group colony.
+//
+// The offsets are computed from the colony positions at the execution time,
+// so the replays and network games get the same results.
+func groupFormationOffsets(colonies []*colonyCoreNode) []gmath.Vec {
+	var center gmath.Vec
+	for _, colony := range colonies {
+		center = center.Add(colony.pos)
+	}
+	center = center.Divf(float64(len(colonies)))
+
+	offsets := make([]gmath.Vec, len(colonies))
+	for i, colony := range colonies {
+		offset := colony.pos.Sub(center)
+		switch dist := offset.Len(); {
+		case dist < 1:
+			// Stacked colonies are spread around the destination point.
+			angle := gmath.Rad(2 * math.Pi * float64(i) / float64(len(colonies)))
+			offset = gmath.Vec{X: groupFormationMinDist}.Rotated(angle)
+		case dist < groupFormationMinDist:
+			offset = offset.Mulf(groupFormationMinDist / dist)
+		default:
+			offset = offset.ClampLen(groupFormationMaxDist)
+		}
+		offsets[i] = offset
+	}
+	return offsets
+}
+
+// executeGroupAction applies the choice to every colony of the group.
+// It reports whether the action succeeded for at least one of them.
+func (c *Controller) executeGroupAction(choice selectedChoice) bool {
+	var offsets []gmath.Vec
+	if choice.Option.special == specialChoiceMoveColony {
+		offsets = groupFormationOffsets(choice.Group)
+	}
+
+	ok := false
+	for i, colony := range choice.Group {
+		colonyChoice := choice
+		colonyChoice.Colony = colony
+		colonyChoice.Group = nil
+		if offsets != nil {
+			colonyChoice.Pos = choice.Pos.Add(offsets[i])
+		}
+		if c.executeAction(colonyChoice) {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// resolveControlGroup maps the action colony indexes to the player colonies.
+// It returns nil if any of the indexes is invalid.
+func resolveControlGroup(pstate *playerState, indexes []int) []*colonyCoreNode {
+	if len(indexes) == 0 {
+		return nil
+	}
+	group := make([]*colonyCoreNode, len(indexes))
+	for i, index := range indexes {
+		if index < 0 || index >= len(pstate.colonies) {
+			return nil
+		}
+		group[i] = pstate.colonies[index]
+	}
+	return group
+}
@@ -255,6 +255,16 @@ func (c *creepNode) Destroy() {
 
 func (c *creepNode) IsDisposed() bool { return c.disposed }
 
+// onKilled reports a creep defeat.
+// It should be called for every creep destroyed in favor of the player,
+// the killer can be nil if there is no specific unit to credit.
+func (c *creepNode) onKilled(killer targetable) {
+	c.world.events.CreepKilled(c, killer)
+	if c.world.stats != nil {
+		c.world.stats.AddCreepKill(c)
+	}
+}
+
 func (c *creepNode) Update(delta float64) {
 	c.flashComponent.Update(delta)
 
@@ -478,6 +488,7 @@ func (c *creepNode) OnDamage(damage gamedata.DamageValue, source targetable) {
 	}
 
 	if c.onHealthDamage(damage) {
+		c.onKilled(source)
 		return
 	}
 
@@ -775,6 +786,11 @@ func (c *creepNode) findTargets() []targetable {
 		}
 	}
 
+	if !c.world.spatial.MayHaveColonies(c.pos, c.stats.Weapon.AttackRange) {
+		// Keep the rand state in sync with a full scan.
+		randIterateSkip(c.world.rand, c.world.allColonies)
+		return targets
+	}
 	randIterate(c.world.rand, c.world.allColonies, func(colony *colonyCoreNode) bool {
 		if !colony.IsFlying() && skipGroundTargets {
 			return false
@@ -1305,6 +1321,7 @@ func (c *creepNode) updateTurretConstruction(delta float64) {
 	if c.specialTarget == nil || c.specialTarget.(*creepNode).IsDisposed() {
 		c.explode()
 		c.Destroy()
+		c.onKilled(nil)
 	}
 
 	if c.specialModifier >= 1 {
@@ -1623,7 +1640,7 @@ func (c *creepNode) updateCreepCrawlerBase(delta float64) {
 		Y: c.scene.Rand().FloatRange(-160, 160),
 	}
 	dstPos := spawnPos.Add(dstOffset)
-	c.attackDelay = (c.scene.Rand().FloatRange(15, 30) * productionDelay) * (1 / c.world.creepProductionMultiplier)
+	c.attackDelay = (c.scene.Rand().FloatRange(15, 30) * productionDelay) * (1 / (c.world.creepProductionMultiplier * c.world.creepProductionAdjustment))
 	c.specialModifier++
 
 	crawler := c.world.NewCreepNode(spawnPos, gamedata.CrawlerCreepStats)
@@ -1645,7 +1662,7 @@ func (c *creepNode) updateCreepCrawlerBase(delta float64) {
 func (c *creepNode) updateCreepBase(delta float64) {
 	c.specialDelay = gmath.ClampMin(c.specialDelay-delta, 0)
 	if c.specialDelay == 0 && c.specialModifier < 15 {
-		c.specialDelay = c.scene.Rand().FloatRange(80, 120)
+		c.specialDelay = c.scene.Rand().FloatRange(80, 120) / c.world.creepTechAdjustment
 		c.specialModifier += 1 // base level up
 	}
 
@@ -1677,7 +1694,7 @@ func (c *creepNode) updateCreepBase(delta float64) {
 	} else if level >= 14 {
 		spawnDelay *= 0.75
 	}
-	c.attackDelay = (spawnDelay * (1 / c.world.creepProductionMultiplier))
+	c.attackDelay = (spawnDelay * (1 / (c.world.creepProductionMultiplier * c.world.creepProductionAdjustment)))
 
 	spawnPoints := [...]gmath.Vec{
 		c.pos.Add(gmath.Vec{X: -5, Y: -5}),
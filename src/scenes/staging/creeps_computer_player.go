@@ -0,0 +1,260 @@
+package staging
+
+import (
+	"math"
+
+	"github.com/quasilyte/gmath"
+)
+
+// creepsComputerPlayer is a bot that plays the creeps side of the reverse mode.
+//
+// It uses the same cards economy as the human creeps player:
+// it buys the creep groups for the attack sides and then sends them
+// with a special action; it also commands the dreadnought and centurions.
+//
+// All decisions are made using the world rand, so the replays
+// are reproducible without recording the bot actions.
+type creepsComputerPlayer struct {
+	world *worldState
+	state *playerState
+
+	choiceGen   *choiceGenerator
+	creepsState *creepsPlayerState
+
+	difficulty *creepsBotDifficulty
+
+	choiceDelay     float64
+	centurionsDelay float64
+}
+
+type creepsBotDifficulty struct {
+	// A reaction time range in seconds.
+	minChoiceDelay float64
+	maxChoiceDelay float64
+
+	// How full (relative to the max side cost) the attack
+	// groups should be before the bot sends them.
+	sendCreepsThreshold float64
+
+	// A chance to take the tech upgrade when it's available.
+	techChance float64
+
+	// Whether the bot concentrates the units on the side
+	// that is the closest to the colonies.
+	focusSide bool
+
+	// Whether the bot prefers the higher tier creep cards.
+	preferStrongCards bool
+
+	bossAttacks   bool
+	useCenturions bool
+}
+
+var creepsBotDifficultyList = [...]creepsBotDifficulty{
+	// Easy.
+	{
+		minChoiceDelay:      6,
+		maxChoiceDelay:      12,
+		sendCreepsThreshold: 0.35,
+		techChance:          0.3,
+	},
+	// Normal.
+	{
+		minChoiceDelay:      3,
+		maxChoiceDelay:      6,
+		sendCreepsThreshold: 0.5,
+		techChance:          0.5,
+		focusSide:           true,
+		bossAttacks:         true,
+	},
+	// Hard.
+	{
+		minChoiceDelay:      1,
+		maxChoiceDelay:      3,
+		sendCreepsThreshold: 0.7,
+		techChance:          0.7,
+		focusSide:           true,
+		preferStrongCards:   true,
+		bossAttacks:         true,
+		useCenturions:       true,
+	},
+	// Brutal.
+	{
+		minChoiceDelay:      0.3,
+		maxChoiceDelay:      1,
+		sendCreepsThreshold: 0.85,
+		techChance:          0.9,
+		focusSide:           true,
+		preferStrongCards:   true,
+		bossAttacks:         true,
+		useCenturions:       true,
+	},
+}
+
+func newCreepsComputerPlayer(world *worldState, state *playerState, choiceGen *choiceGenerator) *creepsComputerPlayer {
+	return &creepsComputerPlayer{
+		world:           world,
+		state:           state,
+		choiceGen:       choiceGen,
+		creepsState:     choiceGen.creepsState,
+		difficulty:      &creepsBotDifficultyList[world.config.ReverseBotDifficulty],
+		centurionsDelay: world.rand.FloatRange(60, 90),
+	}
+}
+
+func (p *creepsComputerPlayer) Init() {
+	p.choiceGen.EventChoiceReady.Connect(p, func(selection choiceSelection) {
+		p.choiceDelay = p.world.rand.FloatRange(p.difficulty.minChoiceDelay, p.difficulty.maxChoiceDelay)
+	})
+}
+
+func (p *creepsComputerPlayer) IsDisposed() bool { return false }
+
+func (p *creepsComputerPlayer) GetState() *playerState { return p.state }
+
+func (p *creepsComputerPlayer) Update(computedDelta, delta float64) {
+	if p.world.boss == nil {
+		return
+	}
+
+	p.choiceDelay = gmath.ClampMin(p.choiceDelay-computedDelta, 0)
+	p.centurionsDelay = gmath.ClampMin(p.centurionsDelay-computedDelta, 0)
+
+	if p.centurionsDelay == 0 && p.difficulty.useCenturions {
+		p.centurionsDelay = p.world.rand.FloatRange(40, 70)
+		p.maybeSendCenturions()
+	}
+
+	if p.choiceDelay != 0 || !p.choiceGen.IsReady() {
+		return
+	}
+	p.choiceGen.TryExecute(nil, p.pickChoice(), gmath.Vec{})
+}
+
+func (p *creepsComputerPlayer) maybeSendCenturions() {
+	if len(p.world.centurions) < 2 || !p.world.AllCenturionsReady() {
+		return
+	}
+	target := p.findClosestColony(p.world.boss.pos)
+	if target == nil {
+		return
+	}
+	p.choiceGen.TryExecute(nil, -1, target.pos.Add(p.world.rand.Offset(-96, 96)))
+}
+
+func (p *creepsComputerPlayer) pickChoice() int {
+	selection := p.choiceGen.GetChoices()
+	if p.shouldUseSpecial(selection.special.special) {
+		return 4
+	}
+
+	targetSide := p.findTargetSide()
+	bestIndex := -1
+	bestScore := 0.0
+	for i, card := range selection.cards {
+		if p.isSideFull(card.direction) {
+			continue
+		}
+		info := creepOptionInfoList[creepCardID(card.special)]
+		score := p.world.rand.FloatRange(0, 1)
+		if p.difficulty.focusSide && card.direction == targetSide {
+			score += 2
+		}
+		if p.difficulty.preferStrongCards {
+			score += info.minTechLevel * 2
+		}
+		if score > bestScore {
+			bestScore = score
+			bestIndex = i
+		}
+	}
+	if bestIndex != -1 {
+		return bestIndex
+	}
+
+	// All sides are full: even a sub-optimal special action
+	// is better than a card that can't add any units.
+	return 4
+}
+
+func (p *creepsComputerPlayer) shouldUseSpecial(kind specialChoiceKind) bool {
+	boss := p.world.boss
+
+	switch kind {
+	case specialSendCreeps:
+		return p.readyToSendCreeps()
+
+	case specialIncreaseTech, specialIncreaseTechX2:
+		return p.creepsState.techLevel < 2 && p.world.rand.Chance(p.difficulty.techChance)
+
+	case specialBossAttack:
+		return p.difficulty.bossAttacks &&
+			boss.health >= boss.maxHealth*0.4 &&
+			p.world.rand.Chance(0.5)
+
+	case specialAtomicBomb:
+		return p.difficulty.bossAttacks
+
+	case specialSpawnCrawlers:
+		return p.world.rand.Chance(0.6)
+
+	case specialRally:
+		return len(p.world.creeps) >= 10 && p.world.rand.Chance(0.4)
+	}
+
+	return false
+}
+
+func (p *creepsComputerPlayer) readyToSendCreeps() bool {
+	threshold := float64(p.creepsState.maxSideCost) * p.difficulty.sendCreepsThreshold
+	if p.difficulty.focusSide {
+		cg := p.creepsState.attackSides[p.findTargetSide()]
+		if float64(cg.totalCost) >= threshold {
+			return true
+		}
+	}
+	allFull := true
+	totalCost := 0
+	for side, cg := range p.creepsState.attackSides {
+		totalCost += cg.totalCost
+		if !p.isSideFull(side) {
+			allFull = false
+		}
+	}
+	return allFull || float64(totalCost) >= threshold*2
+}
+
+func (p *creepsComputerPlayer) isSideFull(side int) bool {
+	return p.creepsState.attackSides[side].totalCost >= p.creepsState.maxSideCost
+}
+
+// findTargetSide returns the attack side that is the closest to the colonies.
+func (p *creepsComputerPlayer) findTargetSide() int {
+	targetSide := 0
+	closestDist := math.MaxFloat64
+	for side, area := range p.world.spawnAreas {
+		colony := p.findClosestColony(area.Center())
+		if colony == nil {
+			break
+		}
+		dist := colony.pos.DistanceSquaredTo(area.Center())
+		if dist < closestDist {
+			closestDist = dist
+			targetSide = side
+		}
+	}
+	return targetSide
+}
+
+func (p *creepsComputerPlayer) findClosestColony(pos gmath.Vec) *colonyCoreNode {
+	var closestColony *colonyCoreNode
+	closestDist := math.MaxFloat64
+	for _, colony := range p.world.allColonies {
+		dist := colony.pos.DistanceSquaredTo(pos)
+		if dist < closestDist {
+			closestDist = dist
+			closestColony = colony
+		}
+	}
+	return closestColony
+}
@@ -0,0 +1,346 @@
+package staging
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/gsignal"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/serverapi"
+	"github.com/quasilyte/roboden-game/timeutil"
+)
+
+// customTriggerDelay is a number of seconds after the last wave
+// when the trigger objective starts to check the remaining creeps.
+// It gives the delayed spawners some time to put their creeps into the world.
+const customTriggerDelay = 30.0
+
+type customModeWave struct {
+	time      float64
+	budget    int
+	sides     int
+	super     bool
+	selection []*arenaCreepInfo
+}
+
+type customModeManager struct {
+	world *worldState
+	scene *ge.Scene
+
+	rules *serverapi.CustomModeRules
+
+	time        float64
+	timeLimit   float64
+	waves       []customModeWave
+	nextWave    int
+	attackSides []int
+	attackGroup arenaWaveGroup
+
+	objectives []gamedata.GameObjective
+	completed  []bool
+
+	objectivesCheckDelay float64
+
+	info            *messageNode
+	infoUpdateDelay float64
+
+	victory bool
+	timeUp  bool
+
+	EventVictory gsignal.Event[gsignal.Void]
+}
+
+func newCustomModeManager(world *worldState) *customModeManager {
+	return &customModeManager{
+		world:       world,
+		rules:       world.config.CustomRules,
+		attackSides: []int{0, 1, 2, 3},
+	}
+}
+
+func (m *customModeManager) IsDisposed() bool {
+	return false
+}
+
+func (m *customModeManager) Init(scene *ge.Scene) {
+	m.scene = scene
+
+	m.timeLimit = float64(m.rules.TimeLimit)
+
+	m.objectives = make([]gamedata.GameObjective, 0, len(m.rules.Objectives))
+	for _, name := range m.rules.Objectives {
+		o, ok := gamedata.ParseGameObjective(name)
+		if !ok {
+			panic("unexpected custom mode objective: " + name)
+		}
+		m.objectives = append(m.objectives, o)
+	}
+	m.completed = make([]bool, len(m.objectives))
+
+	m.waves = make([]customModeWave, len(m.rules.Waves))
+	for i, w := range m.rules.Waves {
+		selection := make([]*arenaCreepInfo, len(w.Creeps))
+		for j, name := range w.Creeps {
+			stats := gamedata.CustomWaveCreeps[name]
+			selection[j] = &arenaCreepInfo{
+				stats: stats,
+				cost:  creepFragScore(stats),
+			}
+		}
+		m.waves[i] = customModeWave{
+			time:      float64(w.Time),
+			budget:    w.Budget,
+			sides:     w.Sides,
+			super:     w.Super,
+			selection: selection,
+		}
+	}
+
+	m.objectivesCheckDelay = 1
+	m.infoUpdateDelay = 1
+}
+
+func (m *customModeManager) LateInit() {
+	if !m.world.simulation && len(m.world.cameras) != 0 {
+		m.info = m.createInfoMessageNode()
+		m.world.nodeRunner.AddObject(m.info)
+	}
+}
+
+// IsTimeUp reports whether the time limit was reached.
+// It's a defeat condition unless there is a survive objective.
+func (m *customModeManager) IsTimeUp() bool {
+	return m.timeUp
+}
+
+func (m *customModeManager) Update(delta float64) {
+	if m.victory || m.timeUp {
+		return
+	}
+
+	m.time += delta
+
+	for m.nextWave < len(m.waves) && m.time >= m.waves[m.nextWave].time {
+		m.spawnWave(&m.waves[m.nextWave])
+		m.nextWave++
+	}
+
+	if m.rules.ResourceCap != 0 {
+		resourceCap := float64(m.rules.ResourceCap)
+		for _, colony := range m.world.allColonies {
+			if colony.resources > resourceCap {
+				colony.resources = resourceCap
+			}
+		}
+	}
+
+	// The objectives are checked right before the time is up,
+	// so the survive objective is completed instead of a defeat.
+	timeLimitReached := m.timeLimit != 0 && m.time >= m.timeLimit
+
+	m.objectivesCheckDelay -= delta
+	if m.objectivesCheckDelay <= 0 || timeLimitReached {
+		m.objectivesCheckDelay = 1 + m.objectivesCheckDelay
+		m.checkObjectives()
+		if m.victory {
+			if m.info != nil {
+				m.info.Dispose()
+			}
+			m.EventVictory.Emit(gsignal.Void{})
+			return
+		}
+	}
+
+	if timeLimitReached {
+		m.timeUp = true
+		return
+	}
+
+	m.infoUpdateDelay -= delta
+	if m.infoUpdateDelay <= 0 && m.info != nil {
+		m.infoUpdateDelay = 1 + m.infoUpdateDelay
+		m.info.UpdateText(m.createInfoText())
+	}
+}
+
+func (m *customModeManager) checkObjectives() {
+	allCompleted := true
+	for i, o := range m.objectives {
+		if !m.completed[i] {
+			// Once completed, the objective stays completed.
+			m.completed[i] = m.isObjectiveCompleted(o)
+		}
+		if !m.completed[i] {
+			allCompleted = false
+		}
+	}
+	m.victory = allCompleted
+}
+
+func (m *customModeManager) isObjectiveCompleted(o gamedata.GameObjective) bool {
+	switch o {
+	case gamedata.ObjectiveBoss:
+		return m.world.boss == nil
+
+	case gamedata.ObjectiveBuildBase:
+		goal := m.rules.ColoniesGoal
+		if goal == 0 {
+			goal = 3
+		}
+		for _, p := range m.world.players {
+			if len(p.GetState().colonies) >= goal {
+				return true
+			}
+		}
+		return false
+
+	case gamedata.ObjectiveDestroyCreepBases:
+		for _, c := range m.world.creeps {
+			if c.stats.Kind == gamedata.CreepBase || c.stats.Kind == gamedata.CreepCrawlerBase {
+				return false
+			}
+		}
+		return true
+
+	case gamedata.ObjectiveAcquireSuperElite:
+		for _, colony := range m.world.allColonies {
+			found := false
+			colony.agents.Find(searchWorkers|searchFighters, func(a *colonyAgentNode) bool {
+				found = a.rank == 2
+				return found
+			})
+			if found {
+				return true
+			}
+		}
+		return false
+
+	case gamedata.ObjectiveTrigger:
+		if m.nextWave < len(m.waves) {
+			return false
+		}
+		if m.time < m.waves[len(m.waves)-1].time+customTriggerDelay {
+			return false
+		}
+		for _, c := range m.world.creeps {
+			if c.stats.Building || c == m.world.boss || c.stats.Kind == gamedata.CreepWisp {
+				continue
+			}
+			return false
+		}
+		return true
+
+	case gamedata.ObjectiveSurvive:
+		return m.timeLimit != 0 && m.time >= m.timeLimit
+
+	default:
+		return false
+	}
+}
+
+func (m *customModeManager) spawnWave(w *customModeWave) {
+	m.scene.Audio().PlaySound(assets.AudioWaveStart)
+	m.world.events.WaveStarted(m.nextWave + 1)
+
+	// Same as in the arena mode: attacking from several
+	// directions reduces the per-side budget.
+	budgetMultiplier := 1.0
+	switch w.sides {
+	case 2:
+		budgetMultiplier = 0.75
+	case 3:
+		budgetMultiplier = 0.55
+	case 4:
+		budgetMultiplier = 0.4
+	}
+	sideBudget := int(math.Round(float64(w.budget) * budgetMultiplier))
+
+	gmath.Shuffle(m.world.rand, m.attackSides)
+	for _, side := range m.attackSides[:w.sides] {
+		units := m.attackGroup.units[:0]
+		budget := sideBudget
+		for {
+			u, remaining, ok := m.pickUnit(budget, w)
+			if !ok {
+				break
+			}
+			budget = remaining
+			units = append(units, u)
+		}
+		m.attackGroup.units = units
+		m.attackGroup.side = side
+		sendCreeps(m.world, m.attackGroup)
+	}
+}
+
+func (m *customModeManager) pickUnit(budget int, w *customModeWave) (arenaWaveUnit, int, bool) {
+	var u arenaWaveUnit
+	creepInfo := randIterate(m.world.rand, w.selection, func(x *arenaCreepInfo) bool {
+		return x.cost <= budget
+	})
+	if creepInfo == nil {
+		return u, budget, false
+	}
+	u.stats = creepInfo.stats
+	if w.super && m.world.config.SuperCreeps {
+		superCost := creepInfo.cost * superCreepCostMultiplier(u.stats)
+		if superCost <= budget && m.world.rand.Chance(0.3) {
+			u.super = true
+			return u, budget - superCost, true
+		}
+	}
+	return u, budget - creepInfo.cost, true
+}
+
+func (m *customModeManager) createInfoMessageNode() *messageNode {
+	s := m.createInfoText()
+	message := newScreenTutorialHintNode(m.world.cameras[0], gmath.Vec{X: 16, Y: 70}, gmath.Vec{}, s)
+	message.xpadding = 20
+	return message
+}
+
+func (m *customModeManager) createInfoText() string {
+	d := m.scene.Dict()
+
+	var buf strings.Builder
+	buf.Grow(256)
+
+	buf.WriteString(d.Get("game.objectives"))
+	buf.WriteByte(':')
+	for i, o := range m.objectives {
+		buf.WriteByte('\n')
+		if m.completed[i] {
+			buf.WriteString("[x] ")
+		} else {
+			buf.WriteString("[ ] ")
+		}
+		buf.WriteString(d.Get("game.objective", o.String()))
+	}
+
+	if m.timeLimit != 0 {
+		buf.WriteByte('\n')
+		buf.WriteString(d.Get("game.time_left"))
+		buf.WriteString(": ")
+		timeLeft := gmath.ClampMin(m.timeLimit-m.time, 0)
+		buf.WriteString(timeutil.FormatDuration(d, time.Duration(timeLeft*float64(time.Second))))
+	}
+
+	if m.nextWave < len(m.waves) {
+		buf.WriteByte('\n')
+		buf.WriteString(d.Get("game.wave"))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.Itoa(m.nextWave + 1))
+		buf.WriteByte(' ')
+		buf.WriteString(d.Get("game.wave_starts_in"))
+		buf.WriteByte(' ')
+		startsIn := gmath.ClampMin(m.waves[m.nextWave].time-m.time, 0)
+		buf.WriteString(timeutil.FormatDuration(d, time.Duration(startsIn*float64(time.Second))))
+	}
+
+	return buf.String()
+}
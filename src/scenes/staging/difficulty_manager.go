@@ -0,0 +1,208 @@
+package staging
+
+import (
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+const (
+	// difficultyCheckDelay is a number of seconds between the difficulty re-evaluations.
+	difficultyCheckDelay = 60.0
+
+	// difficultyGracePeriod is a number of seconds before the first evaluation.
+	// The colonies are too weak to judge during the first minutes.
+	difficultyGracePeriod = 3 * 60.0
+
+	// The difficulty level is kept inside [-maxDifficultyLevel, maxDifficultyLevel].
+	maxDifficultyLevel = 1.0
+
+	// difficultyThreatRadius is a distance from the colony
+	// where the creeps are considered to be a threat.
+	difficultyThreatRadius = 500.0
+)
+
+// difficultyManager implements the adaptive difficulty option.
+//
+// It watches the player strength (colonies power, resource income, losses)
+// and moves the difficulty level up or down in small steps.
+// Only the human players colonies are considered:
+// the bot allies don't make the game easier or harder for the player.
+// The level affects the creep production and tech progression rates
+// (see creepProductionAdjustment and creepTechAdjustment world fields).
+//
+// Only the world state is used for the decisions, no rand calls are involved,
+// so the replays are reproducible.
+type difficultyManager struct {
+	world *worldState
+
+	ticker  float64
+	started bool
+
+	level float64
+
+	// levelSum and numChecks are used to calculate the average level
+	// that affects the final score.
+	levelSum  float64
+	numChecks int
+
+	// Collected since the last evaluation.
+	dronesLost int
+	income     float64
+
+	prevColonies []*colonyCoreNode
+	prevIncome   float64
+}
+
+func newDifficultyManager(world *worldState) *difficultyManager {
+	return &difficultyManager{
+		world:  world,
+		ticker: difficultyGracePeriod,
+	}
+}
+
+func (m *difficultyManager) Init(scene *ge.Scene) {}
+
+func (m *difficultyManager) IsDisposed() bool {
+	return false
+}
+
+func (m *difficultyManager) Update(delta float64) {
+	if !m.world.gameStarted {
+		return
+	}
+	if !m.started {
+		m.started = true
+		m.prevColonies = m.appendHumanColonies(m.prevColonies[:0])
+	}
+	m.ticker = gmath.ClampMin(m.ticker-delta, 0)
+	if m.ticker != 0 {
+		return
+	}
+	m.ticker = difficultyCheckDelay
+	m.evaluate()
+}
+
+// OnDroneDestroyed is called for every drone or turret killed.
+//
+// The kamikaze explosions are not reported here:
+// the deliberate sacrifices are not the player losses.
+func (m *difficultyManager) OnDroneDestroyed(a *colonyAgentNode) {
+	if a.colonyCore == nil || !m.isHumanColony(a.colonyCore) {
+		return
+	}
+	m.dronesLost++
+}
+
+// OnResourcesGathered is called for every resource delivery.
+func (m *difficultyManager) OnResourcesGathered(colony *colonyCoreNode, value float64) {
+	if !m.isHumanColony(colony) {
+		return
+	}
+	m.income += value
+}
+
+// AverageLevel returns the difficulty level averaged over the game time.
+func (m *difficultyManager) AverageLevel() float64 {
+	if m.numChecks == 0 {
+		return 0
+	}
+	return m.levelSum / float64(m.numChecks)
+}
+
+// ScoreMultiplier reports how the final score should be adjusted.
+//
+// The easier game costs more than the harder game gives:
+// the difficulty score already accounts for the lowered baseline.
+func (m *difficultyManager) ScoreMultiplier() float64 {
+	level := m.AverageLevel()
+	if level < 0 {
+		return 1.0 + 0.3*level
+	}
+	return 1.0 + 0.1*level
+}
+
+func (m *difficultyManager) evaluate() {
+	world := m.world
+
+	numDrones := 0
+	power := 0
+	threat := 0
+	weakestColony := 1.0
+	for _, colony := range world.allColonies {
+		if !m.isHumanColony(colony) {
+			continue
+		}
+		numDrones += colony.NumAgents()
+		power += calcColonyPower(world, colony, gamedata.TargetAny)
+		weakestColony = gmath.ClampMax(weakestColony, colony.health/colony.maxHealth)
+		world.WalkCreepsWithRand(nil, colony.pos, difficultyThreatRadius, func(creep *creepNode) bool {
+			threat += calcCreepPower(world, creep)
+			return false
+		})
+	}
+
+	coloniesLost := 0
+	for _, colony := range m.prevColonies {
+		if !xslices.Contains(world.allColonies, colony) {
+			coloniesLost++
+		}
+	}
+	lossRate := float64(m.dronesLost) / float64(numDrones+m.dronesLost+1)
+	pressure := float64(threat) / float64(power+1)
+	income := m.income
+
+	struggling := coloniesLost > 0 ||
+		weakestColony < 0.4 ||
+		lossRate > 0.35 ||
+		pressure > 1.5
+	dominating := !struggling &&
+		weakestColony > 0.8 &&
+		lossRate < 0.1 &&
+		pressure < 0.5 &&
+		income >= m.prevIncome
+
+	// The difficulty goes down faster than it goes up:
+	// it's more important to rescue the struggling player.
+	switch {
+	case struggling:
+		m.setLevel(m.level - 0.2)
+	case dominating:
+		m.setLevel(m.level + 0.1)
+	}
+
+	m.levelSum += m.level
+	m.numChecks++
+
+	m.dronesLost = 0
+	m.income = 0
+	m.prevColonies = m.appendHumanColonies(m.prevColonies[:0])
+	m.prevIncome = income
+}
+
+// isHumanColony reports whether the colony belongs to a human player.
+//
+// The level config is used instead of the player implementation:
+// the replays are executed by the replay players and the blitz mode
+// humans are controlled by the computer players.
+func (m *difficultyManager) isHumanColony(colony *colonyCoreNode) bool {
+	id := colony.player.GetState().id
+	return m.world.config.Players[id] == gamedata.PlayerHuman
+}
+
+func (m *difficultyManager) appendHumanColonies(dst []*colonyCoreNode) []*colonyCoreNode {
+	for _, colony := range m.world.allColonies {
+		if m.isHumanColony(colony) {
+			dst = append(dst, colony)
+		}
+	}
+	return dst
+}
+
+func (m *difficultyManager) setLevel(level float64) {
+	m.level = gmath.Clamp(level, -maxDifficultyLevel, maxDifficultyLevel)
+	// Creep production: 0.7-1.3; creep tech progression: 0.75-1.25.
+	m.world.creepProductionAdjustment = 1.0 + 0.3*m.level
+	m.world.creepTechAdjustment = 1.0 + 0.25*m.level
+}
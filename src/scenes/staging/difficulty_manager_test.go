@@ -0,0 +1,81 @@
+package staging
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+type testPlayer struct {
+	state *playerState
+}
+
+func (p *testPlayer) Init()                               {}
+func (p *testPlayer) Update(computedDelta, delta float64) {}
+func (p *testPlayer) GetState() *playerState              { return p.state }
+
+func runDifficultyScenario(botLosses bool) (level, scoreMultiplier float64) {
+	world := &worldState{
+		config: &gamedata.LevelConfig{
+			Players: []gamedata.PlayerKind{gamedata.PlayerHuman, gamedata.PlayerComputer},
+		},
+		width:       1024,
+		height:      1024,
+		gameStarted: true,
+	}
+	world.spatial.Init(world)
+	rand := &gmath.Rand{}
+	rand.SetSeed(1)
+	newColony := func(id int) *colonyCoreNode {
+		return &colonyCoreNode{
+			world:     world,
+			player:    &testPlayer{state: &playerState{id: id}},
+			agents:    newColonyAgentContainer(rand),
+			health:    100,
+			maxHealth: 100,
+		}
+	}
+	human := newColony(0)
+	bot := newColony(1)
+	world.allColonies = []*colonyCoreNode{human, bot}
+
+	m := newDifficultyManager(world)
+	for second := 0; second < 20*60; second++ {
+		if second%10 == 0 {
+			m.OnResourcesGathered(human, 10)
+			m.OnResourcesGathered(bot, float64(second))
+		}
+		minute := second / 60
+		if botLosses && minute >= 6 && minute < 9 && second%10 == 0 {
+			m.OnDroneDestroyed(&colonyAgentNode{colonyCore: bot})
+		}
+		if minute >= 12 && minute < 14 && second%10 == 0 {
+			m.OnDroneDestroyed(&colonyAgentNode{colonyCore: human})
+		}
+		m.Update(1)
+	}
+
+	return m.level, m.ScoreMultiplier()
+}
+
+func TestDifficultyManagerDeterministic(t *testing.T) {
+	level1, score1 := runDifficultyScenario(true)
+	level2, score2 := runDifficultyScenario(true)
+	if level1 != level2 || score1 != score2 {
+		t.Fatalf("results mismatch:\nrun1: level=%v score=%v\nrun2: level=%v score=%v",
+			level1, score1, level2, score2)
+	}
+	if level1 == 0 {
+		t.Fatal("the difficulty level is expected to change")
+	}
+}
+
+func TestDifficultyManagerIgnoresBots(t *testing.T) {
+	level1, score1 := runDifficultyScenario(true)
+	level2, score2 := runDifficultyScenario(false)
+	if level1 != level2 || score1 != score2 {
+		t.Fatalf("bot losses affected the results:\nwith: level=%v score=%v\nwithout: level=%v score=%v",
+			level1, score1, level2, score2)
+	}
+}
@@ -348,6 +348,9 @@ func (e *essenceSourceNode) Harvest(n int) int {
 
 	n = gmath.ClampMax(n, e.resource)
 	e.resource -= n
+	if e.world.minimap != nil {
+		e.world.minimap.AddMining(e.pos, n)
+	}
 	e.percengage = float64(e.resource) / float64(e.capacity)
 
 	if e.resource <= 0 && e.stats.canDeplete {
@@ -0,0 +1,199 @@
+package staging
+
+import (
+	"github.com/quasilyte/gmath"
+)
+
+// GameEvent is a structured simulation event.
+//
+// Unlike the gsignal events, these events are not used by the game itself;
+// they're intended for the external tools (analytics, balance research, etc).
+// See Controller.SubscribeEvents.
+//
+// The fields meaning depends on the event kind:
+//
+//	DroneCreated:   Unit is a new drone
+//	DroneMerged:    Unit is a merge result, Other is the merged drones, Value is its tier
+//	DroneDestroyed: Unit is a destroyed drone, Other is its killer
+//	CreepKilled:    Unit is a killed creep, Other is its killer
+//	ResourceMined:  Value is the amount of resources delivered to the colony
+//	ColonyDamaged:  Other is the attacker, Value is the health damage
+//	WaveStarted:    Value is the wave number (or the number of units sent)
+//	CardChosen:     Info is the card description
+type GameEvent struct {
+	Tick int           `json:"tick"`
+	Kind GameEventKind `json:"kind"`
+
+	// Player is the player ID the event belongs to (-1 for creeps).
+	Player int `json:"player"`
+
+	// Colony is a player-local colony ID; 0 means "no colony".
+	Colony int `json:"colony,omitempty"`
+
+	Unit  string  `json:"unit,omitempty"`
+	Other string  `json:"other,omitempty"`
+	Info  string  `json:"info,omitempty"`
+	Value float64 `json:"value,omitempty"`
+
+	Pos [2]float64 `json:"pos"`
+}
+
+type GameEventKind string
+
+const (
+	GameEventDroneCreated   GameEventKind = "drone_created"
+	GameEventDroneMerged    GameEventKind = "drone_merged"
+	GameEventDroneDestroyed GameEventKind = "drone_destroyed"
+	GameEventCreepKilled    GameEventKind = "creep_killed"
+	GameEventResourceMined  GameEventKind = "resource_mined"
+	GameEventColonyDamaged  GameEventKind = "colony_damaged"
+	GameEventWaveStarted    GameEventKind = "wave_started"
+	GameEventCardChosen     GameEventKind = "card_chosen"
+)
+
+// eventBus delivers the GameEvent values to the subscribers.
+//
+// The event construction is skipped entirely when there are no subscribers,
+// so the normal game runs don't pay for it.
+// The bus never touches the world rand; subscribing to the events
+// doesn't affect the simulation results.
+type eventBus struct {
+	world    *worldState
+	handlers []func(GameEvent)
+}
+
+func (b *eventBus) Enabled() bool { return len(b.handlers) != 0 }
+
+func (b *eventBus) emit(e GameEvent) {
+	e.Tick = b.world.nodeRunner.ticks
+	for _, h := range b.handlers {
+		h(e)
+	}
+}
+
+func (b *eventBus) DroneCreated(a *colonyAgentNode) {
+	if !b.Enabled() {
+		return
+	}
+	b.emit(newAgentGameEvent(GameEventDroneCreated, a))
+}
+
+func (b *eventBus) DroneMerged(a, x, y *colonyAgentNode) {
+	if !b.Enabled() {
+		return
+	}
+	e := newAgentGameEvent(GameEventDroneMerged, a)
+	e.Other = x.stats.Kind.String() + "+" + y.stats.Kind.String()
+	e.Value = float64(a.stats.Tier)
+	b.emit(e)
+}
+
+func (b *eventBus) DroneDestroyed(a *colonyAgentNode, killer targetable) {
+	if !b.Enabled() {
+		return
+	}
+	e := newAgentGameEvent(GameEventDroneDestroyed, a)
+	e.Other = gameEventUnitName(killer)
+	b.emit(e)
+}
+
+func (b *eventBus) CreepKilled(c *creepNode, killer targetable) {
+	if !b.Enabled() {
+		return
+	}
+	e := GameEvent{
+		Kind:   GameEventCreepKilled,
+		Player: -1,
+		Unit:   c.stats.Kind.String(),
+		Other:  gameEventUnitName(killer),
+		Pos:    gameEventPos(c.pos),
+	}
+	if colony := getUnitColony(killer); colony != nil {
+		e.Player = colony.player.GetState().id
+		e.Colony = colony.id
+	}
+	b.emit(e)
+}
+
+func (b *eventBus) ResourceMined(colony *colonyCoreNode, value float64) {
+	if !b.Enabled() {
+		return
+	}
+	e := newColonyGameEvent(GameEventResourceMined, colony)
+	e.Value = value
+	b.emit(e)
+}
+
+func (b *eventBus) ColonyDamaged(colony *colonyCoreNode, attacker targetable, value float64) {
+	if !b.Enabled() {
+		return
+	}
+	e := newColonyGameEvent(GameEventColonyDamaged, colony)
+	e.Other = gameEventUnitName(attacker)
+	e.Value = value
+	b.emit(e)
+}
+
+func (b *eventBus) WaveStarted(value int) {
+	if !b.Enabled() {
+		return
+	}
+	b.emit(GameEvent{
+		Kind:   GameEventWaveStarted,
+		Player: -1,
+		Value:  float64(value),
+	})
+}
+
+func (b *eventBus) CardChosen(choice selectedChoice) {
+	if !b.Enabled() {
+		return
+	}
+	e := GameEvent{
+		Kind:   GameEventCardChosen,
+		Player: choice.Player.GetState().id,
+	}
+	if choice.Colony != nil {
+		e.Colony = choice.Colony.id
+		e.Pos = gameEventPos(choice.Colony.pos)
+	}
+	_, e.Info = describeChoice(choice)
+	b.emit(e)
+}
+
+func newAgentGameEvent(kind GameEventKind, a *colonyAgentNode) GameEvent {
+	e := GameEvent{Kind: kind, Player: -1}
+	if a.colonyCore != nil {
+		// Neutral buildings may have no colony.
+		e = newColonyGameEvent(kind, a.colonyCore)
+	}
+	e.Unit = a.stats.Kind.String()
+	e.Pos = gameEventPos(a.pos)
+	return e
+}
+
+func newColonyGameEvent(kind GameEventKind, colony *colonyCoreNode) GameEvent {
+	return GameEvent{
+		Kind:   kind,
+		Player: colony.player.GetState().id,
+		Colony: colony.id,
+		Pos:    gameEventPos(colony.pos),
+	}
+}
+
+func gameEventPos(pos gmath.Vec) [2]float64 {
+	return [2]float64{pos.X, pos.Y}
+}
+
+func gameEventUnitName(u targetable) string {
+	switch u := u.(type) {
+	case *colonyAgentNode:
+		return u.stats.Kind.String()
+	case *colonyCoreNode:
+		return u.stats.Name
+	case *creepNode:
+		return u.stats.Kind.String()
+	default:
+		return ""
+	}
+}
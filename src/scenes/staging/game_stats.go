@@ -0,0 +1,144 @@
+package staging
+
+import (
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+// statsSampleTicks is the GameStats sampling period (10 seconds).
+const statsSampleTicks = 60 * 10
+
+// GameStats is the game state sampled over time.
+//
+// The stats are collected for the normal games and the replay playbacks.
+// They don't affect the simulation, so the same replay
+// always produces the same stats (see cmd/replaystats).
+type GameStats struct {
+	Samples []GameStatsSample `json:"samples"`
+}
+
+type GameStatsSample struct {
+	Tick int     `json:"tick"`
+	Time float64 `json:"time"`
+
+	// Colonies are the colonies that exist at the moment of sampling.
+	Colonies []ColonyStatsSample `json:"colonies"`
+
+	// The counters below are cumulative.
+
+	// CreepsKilled maps the creep kind to the number of kills.
+	CreepsKilled map[string]int `json:"creeps_killed"`
+
+	// CardsPicked maps the card category to the number of picks.
+	// The categories are the faction names, "special" and "creeps".
+	CardsPicked map[string]int `json:"cards_picked"`
+}
+
+type ColonyStatsSample struct {
+	Player int `json:"player"`
+
+	// Colony is a player-local colony ID.
+	Colony int `json:"colony"`
+
+	// ResourcesGathered is a cumulative counter.
+	ResourcesGathered float64 `json:"resources_gathered"`
+
+	// Health is a [0, 1] colony health percentage.
+	Health float64 `json:"health"`
+
+	// DronesByFaction is indexed by the gamedata.FactionTag.
+	DronesByFaction [5]int `json:"drones_by_faction"`
+
+	// DronesByTier is indexed by the drone tier minus 1.
+	DronesByTier [3]int `json:"drones_by_tier"`
+}
+
+// FinalSample returns the last stats sample.
+func (stats *GameStats) FinalSample() *GameStatsSample {
+	if len(stats.Samples) == 0 {
+		return nil
+	}
+	return &stats.Samples[len(stats.Samples)-1]
+}
+
+type statsRecorder struct {
+	world *worldState
+	stats GameStats
+
+	nextSampleTick int
+	creepsKilled   map[string]int
+	cardsPicked    map[string]int
+}
+
+func (r *statsRecorder) Init(world *worldState) {
+	r.world = world
+	r.creepsKilled = make(map[string]int)
+	r.cardsPicked = make(map[string]int)
+}
+
+func (r *statsRecorder) Update() {
+	if r.world.nodeRunner.ticks < r.nextSampleTick {
+		return
+	}
+	r.nextSampleTick = r.world.nodeRunner.ticks + statsSampleTicks
+	r.AddSample()
+}
+
+func (r *statsRecorder) AddCreepKill(c *creepNode) {
+	r.creepsKilled[c.stats.Kind.String()]++
+}
+
+func (r *statsRecorder) AddChoice(choice selectedChoice) {
+	switch special := choice.Option.special; {
+	case special == specialChoiceNone:
+		r.cardsPicked[StatsFactionNames[choice.Faction]]++
+	case special == specialChoiceMoveColony || special == specialSendCenturions:
+		// Not a card.
+	case special > _creepCardFirst && special < _creepCardLast:
+		r.cardsPicked["creeps"]++
+	default:
+		r.cardsPicked["special"]++
+	}
+}
+
+func (r *statsRecorder) AddSample() {
+	sample := GameStatsSample{
+		Tick:         r.world.nodeRunner.ticks,
+		Time:         r.world.nodeRunner.timePlayed,
+		Colonies:     make([]ColonyStatsSample, 0, len(r.world.allColonies)),
+		CreepsKilled: make(map[string]int, len(r.creepsKilled)),
+		CardsPicked:  make(map[string]int, len(r.cardsPicked)),
+	}
+	for k, v := range r.creepsKilled {
+		sample.CreepsKilled[k] = v
+	}
+	for k, v := range r.cardsPicked {
+		sample.CardsPicked[k] = v
+	}
+
+	for _, colony := range r.world.allColonies {
+		colonySample := ColonyStatsSample{
+			Player:            colony.player.GetState().id,
+			Colony:            colony.id,
+			ResourcesGathered: colony.resourcesGathered,
+			Health:            colony.health / colony.maxHealth,
+		}
+		colony.agents.Each(func(a *colonyAgentNode) {
+			colonySample.DronesByFaction[a.faction]++
+			if a.stats.Tier >= 1 && a.stats.Tier <= 3 {
+				colonySample.DronesByTier[a.stats.Tier-1]++
+			}
+		})
+		sample.Colonies = append(sample.Colonies, colonySample)
+	}
+
+	r.stats.Samples = append(r.stats.Samples, sample)
+}
+
+// StatsFactionNames are the GameStats faction keys, indexed by gamedata.FactionTag.
+var StatsFactionNames = [...]string{
+	gamedata.NeutralFactionTag: "neutral",
+	gamedata.YellowFactionTag:  "yellow",
+	gamedata.RedFactionTag:     "red",
+	gamedata.GreenFactionTag:   "green",
+	gamedata.BlueFactionTag:    "blue",
+}
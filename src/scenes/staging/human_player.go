@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/input"
 	"github.com/quasilyte/ge/xslices"
 	"github.com/quasilyte/gmath"
 	"github.com/quasilyte/gsignal"
@@ -12,6 +13,7 @@ import (
 	"github.com/quasilyte/roboden-game/gamedata"
 	"github.com/quasilyte/roboden-game/gameinput"
 	"github.com/quasilyte/roboden-game/gameui"
+	"github.com/quasilyte/roboden-game/netplay"
 	"github.com/quasilyte/roboden-game/serverapi"
 )
 
@@ -38,13 +40,36 @@ type humanPlayer struct {
 	droneSelectorsUsed int
 	droneSelectors     []*ge.Sprite
 
+	// selectedGroup is an index of the active control group (-1 for none).
+	// While a group is active, the cards and move orders are applied to all its colonies.
+	selectedGroup  int
+	groupSelectors []*ge.Sprite
+
 	choiceCardColony     *colonyCoreNode
+	choiceCardGroup      []*colonyCoreNode
 	choiceCardIndex      int
 	choiceCardHighligh   *ge.Sprite
 	choiceCenturionPoint gmath.Vec
 
+	plannedGroup     []*colonyCoreNode
+	plannedGroupMove gmath.Vec
+
+	// plannedQueueMoves are the shift-queued move orders given during the pause.
+	plannedQueueMoves []plannedQueueMove
+	// relocationQueueLines draw the selected colony queued path.
+	relocationQueueLines []*ge.Line
+
 	creepsState *creepsPlayerState
 
+	// observer is only set for the spectator in the observer mode.
+	// followPlayer is an index of the player the camera is following (-1 for a free camera).
+	observer     *observerOverlayNode
+	followPlayer int
+
+	// lockstep is only set for the network games.
+	// The actions are sent to the other player and executed after the input delay.
+	lockstep *netplay.Lockstep
+
 	spectator          bool
 	permanentSeparator bool
 	canPing            bool
@@ -57,6 +82,11 @@ type humanPlayer struct {
 	EventPing               gsignal.Event[gmath.Vec]
 }
 
+type plannedQueueMove struct {
+	colony *colonyCoreNode
+	pos    gmath.Vec
+}
+
 type humanPlayerConfig struct {
 	world       *worldState
 	state       *playerState
@@ -65,12 +95,14 @@ type humanPlayerConfig struct {
 	choiceGen   *choiceGenerator
 	creepsState *creepsPlayerState
 	spectator   bool
+	lockstep    *netplay.Lockstep
 }
 
 func newHumanPlayer(config humanPlayerConfig) *humanPlayer {
 	canPing := config.world.config.GameMode != gamedata.ModeReverse &&
 		config.world.config.PlayersMode == serverapi.PmodeTwoPlayers &&
-		config.world.config.ExecMode == gamedata.ExecuteNormal
+		config.world.config.ExecMode == gamedata.ExecuteNormal &&
+		config.lockstep == nil && !config.spectator
 	p := &humanPlayer{
 		world:           config.world,
 		state:           config.state,
@@ -82,6 +114,9 @@ func newHumanPlayer(config humanPlayerConfig) *humanPlayer {
 		canPing:         canPing,
 		spectator:       config.spectator,
 		choiceCardIndex: -1,
+		followPlayer:    -1,
+		selectedGroup:   -1,
+		lockstep:        config.lockstep,
 	}
 	return p
 }
@@ -192,7 +227,7 @@ func (p *humanPlayer) Init() {
 		if p.creepsState != nil {
 			p.rpanel = newCreepsRpanelNode(p.state.camera.Camera, p.creepsState)
 		} else {
-			p.rpanel = newRpanelNode(p.state.camera.Camera)
+			p.rpanel = newRpanelNode(p.state.camera.Camera, p.world.diodeStyle.Palette)
 		}
 		p.scene.AddObject(p.rpanel)
 	}
@@ -315,6 +350,7 @@ func (p *humanPlayer) AfterUpdateStep() {
 		p.colonySelector.Visible = !flying
 		p.flyingColonySelector.Visible = flying
 		p.updateWaypointLine()
+		p.updateGroupSelectors()
 	}
 }
 
@@ -349,6 +385,10 @@ func (p *humanPlayer) BeforeUpdateStep(delta float64) {
 	}
 
 	p.handleInput()
+
+	if p.followPlayer != -1 {
+		p.updateFollowCamera()
+	}
 }
 
 func (p *humanPlayer) Update(computedDelta, delta float64) {
@@ -361,12 +401,37 @@ func (p *humanPlayer) Update(computedDelta, delta float64) {
 		p.radar.Update(delta)
 	}
 
+	if p.lockstep != nil {
+		p.sendLockstepActions()
+		for _, a := range p.lockstep.TakeActions(p.state.id, p.world.nodeRunner.ticks) {
+			if !executeNetworkAction(p.state, p.choiceGen, a) {
+				p.scene.Audio().PlaySound(assets.AudioError)
+			}
+		}
+		return
+	}
+
 	if p.choiceCardIndex != -1 {
-		if !p.choiceGen.TryExecute(p.choiceCardColony, p.choiceCardIndex, gmath.Vec{}) {
+		var ok bool
+		if p.choiceCardGroup != nil {
+			ok = p.choiceGen.TryExecuteGroup(p.choiceCardGroup, p.choiceCardIndex, gmath.Vec{})
+		} else {
+			ok = p.choiceGen.TryExecute(p.choiceCardColony, p.choiceCardIndex, gmath.Vec{})
+		}
+		if !ok {
 			p.scene.Audio().PlaySound(assets.AudioError)
 		}
 		p.choiceCardIndex = -1
 		p.choiceCardColony = nil
+		p.choiceCardGroup = nil
+	}
+
+	if !p.plannedGroupMove.IsZero() {
+		if !p.choiceGen.TryExecuteGroup(p.plannedGroup, -1, p.plannedGroupMove) {
+			p.scene.Audio().PlaySound(assets.AudioError)
+		}
+		p.plannedGroupMove = gmath.Vec{}
+		p.plannedGroup = nil
 	}
 
 	for _, colony := range p.state.colonies {
@@ -380,6 +445,16 @@ func (p *humanPlayer) Update(computedDelta, delta float64) {
 		}
 	}
 
+	for _, m := range p.plannedQueueMoves {
+		if m.colony.IsDisposed() {
+			continue
+		}
+		if !p.choiceGen.TryQueueMove(m.colony, m.pos) {
+			p.scene.Audio().PlaySound(assets.AudioError)
+		}
+	}
+	p.plannedQueueMoves = p.plannedQueueMoves[:0]
+
 	if !p.choiceCenturionPoint.IsZero() {
 		if !p.choiceGen.TryExecute(nil, -1, p.choiceCenturionPoint) {
 			p.scene.Audio().PlaySound(assets.AudioError)
@@ -388,8 +463,94 @@ func (p *humanPlayer) Update(computedDelta, delta float64) {
 	}
 }
 
+// sendLockstepActions converts the planned actions into the network game actions.
+// They're executed on both sides after the input delay.
+func (p *humanPlayer) sendLockstepActions() {
+	if p.choiceCardIndex != -1 {
+		if p.choiceGen.IsReady() {
+			a := serverapi.PlayerAction{
+				Kind:           serverapi.PlayerActionKind(p.choiceCardIndex + 1),
+				SelectedColony: p.colonyIndex(p.choiceCardColony),
+			}
+			if p.choiceCardGroup != nil {
+				a.Kind = a.Kind.Grouped()
+				a.Group = p.colonyIndexes(p.choiceCardGroup)
+			}
+			p.lockstep.AddLocalAction(a)
+		} else {
+			p.scene.Audio().PlaySound(assets.AudioError)
+		}
+		p.choiceCardIndex = -1
+		p.choiceCardColony = nil
+		p.choiceCardGroup = nil
+	}
+
+	if !p.plannedGroupMove.IsZero() {
+		pos := p.plannedGroupMove
+		p.lockstep.AddLocalAction(serverapi.PlayerAction{
+			Kind:           serverapi.ActionGroupMove,
+			Pos:            [2]float64{pos.X, pos.Y},
+			SelectedColony: p.colonyIndex(p.plannedGroup[0]),
+			Group:          p.colonyIndexes(p.plannedGroup),
+		})
+		p.plannedGroupMove = gmath.Vec{}
+		p.plannedGroup = nil
+	}
+
+	for _, colony := range p.state.colonies {
+		if colony.plannedRelocationPoint.IsZero() {
+			continue
+		}
+		pos := colony.plannedRelocationPoint
+		colony.plannedRelocationPoint = gmath.Vec{}
+		p.lockstep.AddLocalAction(serverapi.PlayerAction{
+			Kind:           serverapi.ActionMove,
+			Pos:            [2]float64{pos.X, pos.Y},
+			SelectedColony: p.colonyIndex(colony),
+		})
+	}
+
+	for _, m := range p.plannedQueueMoves {
+		if m.colony.IsDisposed() {
+			continue
+		}
+		p.lockstep.AddLocalAction(serverapi.PlayerAction{
+			Kind:           serverapi.ActionQueueMove,
+			Pos:            [2]float64{m.pos.X, m.pos.Y},
+			SelectedColony: p.colonyIndex(m.colony),
+		})
+	}
+	p.plannedQueueMoves = p.plannedQueueMoves[:0]
+
+	if !p.choiceCenturionPoint.IsZero() {
+		pos := p.choiceCenturionPoint
+		p.choiceCenturionPoint = gmath.Vec{}
+		p.lockstep.AddLocalAction(serverapi.PlayerAction{
+			Kind:           serverapi.ActionMove,
+			Pos:            [2]float64{pos.X, pos.Y},
+			SelectedColony: -1,
+		})
+	}
+}
+
+func (p *humanPlayer) colonyIndex(colony *colonyCoreNode) int {
+	if colony == nil {
+		return -1
+	}
+	return p.world.GetColonyIndex(colony)
+}
+
+func (p *humanPlayer) colonyIndexes(colonies []*colonyCoreNode) []int {
+	indexes := make([]int, len(colonies))
+	for i, colony := range colonies {
+		indexes[i] = p.colonyIndex(colony)
+	}
+	return indexes
+}
+
 func (p *humanPlayer) updateWaypointLine() {
 	colony := p.state.selectedColony
+	p.updateRelocationQueueLines(colony)
 	if p.world.nodeRunner.IsPaused() {
 		dstPos := &colony.relocationPoint
 		if dstPos.IsZero() {
@@ -405,6 +566,51 @@ func (p *humanPlayer) updateWaypointLine() {
 	}
 }
 
+func (p *humanPlayer) canMove(colony *colonyCoreNode) bool {
+	if len(colony.relocationQueue) != 0 {
+		return true
+	}
+	return colony.relocationPoint.IsZero() && colony.mode == colonyModeNormal
+}
+
+// updateRelocationQueueLines draws a path preview through the queued destinations.
+// The orders that are not executed yet (given during the pause) are included.
+func (p *humanPlayer) updateRelocationQueueLines(colony *colonyCoreNode) {
+	numUsed := 0
+	from := colony.relocationPoint
+	if from.IsZero() {
+		from = colony.plannedRelocationPoint
+	}
+	if from.IsZero() {
+		from = colony.GetRallyPoint()
+	}
+	addLine := func(to gmath.Vec) {
+		if numUsed == len(p.relocationQueueLines) {
+			l := ge.NewLine(ge.Pos{}, ge.Pos{})
+			l.SetColorScaleRGBA(0x6e, 0x8e, 0xbd, 100)
+			p.state.camera.Private.AddGraphics(l)
+			p.relocationQueueLines = append(p.relocationQueueLines, l)
+		}
+		l := p.relocationQueueLines[numUsed]
+		l.BeginPos.Offset = from
+		l.EndPos.Offset = to
+		l.Visible = true
+		numUsed++
+		from = to
+	}
+	for _, pos := range colony.relocationQueue {
+		addLine(pos)
+	}
+	for _, m := range p.plannedQueueMoves {
+		if m.colony == colony {
+			addLine(m.pos)
+		}
+	}
+	for _, l := range p.relocationQueueLines[numUsed:] {
+		l.Visible = false
+	}
+}
+
 func (p *humanPlayer) GetCursor() *gameui.CursorNode {
 	return p.cursor
 }
@@ -449,6 +655,11 @@ func (p *humanPlayer) handleInput() {
 		return
 	}
 
+	// Control groups selection is OK during the pause.
+	if !p.spectator && p.creepsState == nil && p.handleControlGroupsInput() {
+		return
+	}
+
 	// Interface on/off toggle is OK during the pause.
 	if p.input.ActionIsJustPressed(controls.ActionToggleInterface) {
 		p.state.camera.UI.Visible = !p.state.camera.UI.Visible
@@ -464,10 +675,12 @@ func (p *humanPlayer) handleInput() {
 			if p.choiceCardIndex != cardIndex || selectedColony != p.choiceCardColony {
 				p.choiceCardIndex = cardIndex
 				p.choiceCardColony = selectedColony
+				p.choiceCardGroup = p.activeGroup()
 				p.choiceCardHighligh.Pos = cardPos
 			} else {
 				p.choiceCardIndex = -1
 				p.choiceCardColony = nil
+				p.choiceCardGroup = nil
 			}
 			return
 		}
@@ -546,7 +759,26 @@ func (p *humanPlayer) handleInput() {
 		}
 	}
 
-	if !p.spectator && selectedColony != nil && selectedColony.relocationPoint.IsZero() && selectedColony.mode == colonyModeNormal {
+	// The shift-queued orders are checked first: Shift+RMB also triggers the plain RMB binding.
+	if !p.spectator && selectedColony != nil && p.activeGroup() == nil {
+		if pos, ok := p.cursor.ClickPos(controls.ActionQueueMoveChoice); ok {
+			p.plannedQueueMoves = append(p.plannedQueueMoves, plannedQueueMove{
+				colony: selectedColony,
+				pos:    p.state.camera.AbsClickPos(pos),
+			})
+			return
+		}
+	}
+
+	if group := p.activeGroup(); !p.spectator && group != nil {
+		if pos, ok := p.cursor.ClickPos(controls.ActionMoveChoice); ok {
+			p.plannedGroup = group
+			p.plannedGroupMove = p.state.camera.AbsClickPos(pos)
+			return
+		}
+	}
+
+	if !p.spectator && selectedColony != nil && p.canMove(selectedColony) {
 		if pos, ok := p.cursor.ClickPos(controls.ActionMoveChoice); ok {
 			globalClickPos := p.state.camera.AbsClickPos(pos)
 			if globalClickPos.DistanceTo(selectedColony.GetRallyPoint()) >= 40 {
@@ -592,6 +824,9 @@ func (p *humanPlayer) findNextColony(colonies []*colonyCoreNode) *colonyCoreNode
 }
 
 func (p *humanPlayer) selectColony(colony *colonyCoreNode) {
+	if p.selectedGroup != -1 && !xslices.Contains(p.state.controlGroups[p.selectedGroup], colony) {
+		p.selectedGroup = -1
+	}
 	if p.state.selectedColony == colony {
 		return
 	}
@@ -619,6 +854,11 @@ func (p *humanPlayer) selectColony(colony *colonyCoreNode) {
 		return
 	}
 	p.state.selectedColony.EventDestroyed.Connect(p, func(_ *colonyCoreNode) {
+		// The destroyed colony is already removed from the control groups.
+		if p.selectedGroup != -1 && len(p.state.controlGroups[p.selectedGroup]) != 0 {
+			p.selectColony(p.state.controlGroups[p.selectedGroup][0])
+			return
+		}
 		p.selectNextColony(false)
 	})
 	p.state.selectedColony.EventTeleported.Connect(p, func(colony *colonyCoreNode) {
@@ -639,6 +879,87 @@ func (p *humanPlayer) selectColony(colony *colonyCoreNode) {
 	p.updateWaypointLine()
 }
 
+func (p *humanPlayer) handleControlGroupsInput() bool {
+	// The assignment is checked first: the modifier key combinations
+	// like Ctrl+F1 also trigger the plain F1 binding.
+	for i := 0; i < numControlGroups; i++ {
+		if !p.input.ActionIsJustPressed(controls.ActionAssignGroup1 + input.Action(i)) {
+			continue
+		}
+		colony := p.state.selectedColony
+		if colony == nil {
+			return true
+		}
+		p.state.ToggleGroupColony(i, colony)
+		p.selectedGroup = -1
+		if xslices.Contains(p.state.controlGroups[i], colony) {
+			p.selectedGroup = i
+		}
+		p.scene.Audio().PlaySound(assets.AudioBaseSelect)
+		return true
+	}
+
+	for i := 0; i < numControlGroups; i++ {
+		if !p.input.ActionIsJustPressed(controls.ActionSelectGroup1 + input.Action(i)) {
+			continue
+		}
+		group := p.state.controlGroups[i]
+		if len(group) == 0 {
+			p.scene.Audio().PlaySound(assets.AudioError)
+			return true
+		}
+		colony := group[0]
+		if p.selectedGroup == i {
+			// Selecting the active group again cycles through its colonies.
+			colony = p.findNextColony(group)
+		}
+		p.selectColony(colony)
+		p.selectedGroup = i
+		p.state.camera.ToggleCamera(colony.GetRallyPoint())
+		return true
+	}
+
+	return false
+}
+
+// activeGroup returns a copy of the active control group colonies list.
+// A group of one colony is no different from a regular selection,
+// so nil is returned in this case.
+func (p *humanPlayer) activeGroup() []*colonyCoreNode {
+	if p.selectedGroup == -1 {
+		return nil
+	}
+	group := p.state.controlGroups[p.selectedGroup]
+	if len(group) < 2 {
+		return nil
+	}
+	return append([]*colonyCoreNode(nil), group...)
+}
+
+func (p *humanPlayer) updateGroupSelectors() {
+	numUsed := 0
+	if p.selectedGroup != -1 {
+		for _, colony := range p.state.controlGroups[p.selectedGroup] {
+			if colony == p.state.selectedColony {
+				continue
+			}
+			if numUsed == len(p.groupSelectors) {
+				s := p.scene.NewSprite(p.world.coreDesign.SelectorImageID())
+				s.SetAlpha(0.5)
+				p.state.camera.Private.AddSpriteSlightlyAbove(s)
+				p.groupSelectors = append(p.groupSelectors, s)
+			}
+			s := p.groupSelectors[numUsed]
+			s.Pos.Base = &colony.pos
+			s.Visible = true
+			numUsed++
+		}
+	}
+	for _, s := range p.groupSelectors[numUsed:] {
+		s.Visible = false
+	}
+}
+
 func (p *humanPlayer) highlightDrones(droneStats *gamedata.AgentStats) {
 	if p.state.selectedColony == nil {
 		return
@@ -688,6 +1009,11 @@ func (p *humanPlayer) onToggleButtonClicked(gsignal.Void) {
 		p.tooltipManager.removeTooltip()
 	}
 
+	if p.observer != nil {
+		p.followNextPlayer()
+		return
+	}
+
 	if p.spectator {
 		colony := p.findNextColony(p.world.allColonies)
 		if colony != nil {
@@ -706,3 +1032,48 @@ func (p *humanPlayer) onToggleButtonClicked(gsignal.Void) {
 		p.state.camera.ToggleCamera(p.world.boss.pos)
 	}
 }
+
+func (p *humanPlayer) followNextPlayer() {
+	// Cycle through the players; the free camera goes after the last one.
+	p.followPlayer++
+	if p.followPlayer >= len(p.world.players) {
+		p.followPlayer = -1
+	}
+	p.observer.SetFollowing(p.followPlayer)
+	if p.followPlayer == -1 {
+		return
+	}
+	if pos, ok := p.followPos(); ok {
+		p.state.camera.ToggleCamera(pos)
+	}
+}
+
+func (p *humanPlayer) updateFollowCamera() {
+	if p.state.camera.panned {
+		// A manual camera movement cancels the follow mode.
+		p.followPlayer = -1
+		p.observer.SetFollowing(-1)
+		return
+	}
+	if p.state.camera.IsToggling() {
+		return
+	}
+	if pos, ok := p.followPos(); ok {
+		p.state.camera.CenterOn(pos)
+	}
+}
+
+func (p *humanPlayer) followPos() (gmath.Vec, bool) {
+	followed := p.world.players[p.followPlayer].GetState()
+	if followed.selectedColony != nil && !followed.selectedColony.IsDisposed() {
+		return followed.selectedColony.pos, true
+	}
+	if len(followed.colonies) != 0 {
+		return followed.colonies[0].pos, true
+	}
+	if p.world.boss != nil && p.world.config.GameMode == gamedata.ModeReverse && followed.id == 0 {
+		// The creeps player "selected colony" is the boss.
+		return p.world.boss.pos, true
+	}
+	return gmath.Vec{}, false
+}
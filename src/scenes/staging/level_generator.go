@@ -104,47 +104,67 @@ func newLevelGenerator(scene *ge.Scene, bg *ge.TiledBackground, world *worldStat
 }
 
 func (g *levelGenerator) Generate() {
-	g.playerSpawn = g.world.rect.Center()
-
-	if g.world.mapShape == gamedata.WorldSquare {
-		g.activeSectors = g.sectors
+	type genStep struct {
+		name string
+		fn   func()
+	}
+	var steps []genStep
+
+	if m := g.world.config.Map; m != nil {
+		// The map editor levels are placed as described,
+		// only the background details are still randomized.
+		g.playerSpawn = g.mapCellPos(m.SpawnPoints[0])
+		steps = []genStep{
+			{"place_map_tiles", g.placeMapTiles},
+			{"place_map_forests", g.placeMapForests},
+			{"place_map_teleporters", g.placeMapTeleporters},
+			{"place_map_players", g.placeMapPlayers},
+			{"place_map_walls", g.placeMapWalls},
+			{"place_map_creep_bases", g.placeMapCreepBases},
+			{"place_map_resources", g.placeMapResources},
+			{"place_boss", g.placeBoss},
+			{"fill_pathgrid", g.fillPathgrid},
+		}
 	} else {
-		if g.rng.Bool() {
-			if g.world.mapShape == gamedata.WorldHorizontal {
-				g.playerSpawn.X = 320
-			} else {
-				g.playerSpawn.Y = 320
-			}
-			g.activeSectors = g.sectors[1:]
+		g.playerSpawn = g.world.rect.Center()
+
+		if g.world.mapShape == gamedata.WorldSquare {
+			g.activeSectors = g.sectors
 		} else {
-			if g.world.mapShape == gamedata.WorldHorizontal {
-				g.playerSpawn.X = g.world.width - 320
+			if g.rng.Bool() {
+				if g.world.mapShape == gamedata.WorldHorizontal {
+					g.playerSpawn.X = 320
+				} else {
+					g.playerSpawn.Y = 320
+				}
+				g.activeSectors = g.sectors[1:]
 			} else {
-				g.playerSpawn.Y = g.world.height - 320
+				if g.world.mapShape == gamedata.WorldHorizontal {
+					g.playerSpawn.X = g.world.width - 320
+				} else {
+					g.playerSpawn.Y = g.world.height - 320
+				}
+				g.activeSectors = g.sectors[:len(g.sectors)-1]
 			}
-			g.activeSectors = g.sectors[:len(g.sectors)-1]
+		}
+		g.activeSectorSlider.SetBounds(0, len(g.activeSectors)-1)
+
+		steps = []genStep{
+			{"place_landmarks", g.placeLandmarks},
+			{"place_teleporters", g.placeTeleporters},
+			{"place_relicts", g.placeRelicts},
+			{"place_players", g.placePlayers},
+			{"place_walls", g.placeWalls},
+			{"place_creep_bases", g.placeCreepBases},
+			{"place_creeps", g.placeCreeps},
+			{"place_resources", g.placeResources},
+			{"place_boss", g.placeBoss},
+			{"fill_pathgrid", g.fillPathgrid},
 		}
 	}
-	g.activeSectorSlider.SetBounds(0, len(g.activeSectors)-1)
 
 	g.world.spawnPos = g.playerSpawn
 
-	type genStep struct {
-		name string
-		fn   func()
-	}
-	var steps = []genStep{
-		{"place_landmarks", g.placeLandmarks},
-		{"place_teleporters", g.placeTeleporters},
-		{"place_relicts", g.placeRelicts},
-		{"place_players", g.placePlayers},
-		{"place_walls", g.placeWalls},
-		{"place_creep_bases", g.placeCreepBases},
-		{"place_creeps", g.placeCreeps},
-		{"place_resources", g.placeResources},
-		{"place_boss", g.placeBoss},
-		{"fill_pathgrid", g.fillPathgrid},
-	}
 	var timeTotal float64
 	for _, step := range steps {
 		start := time.Now()
@@ -397,6 +417,14 @@ func (g *levelGenerator) createBase(p player, pos gmath.Vec, mainBase bool) {
 			a.AssignMode(agentModeStandby, gmath.Vec{}, nil)
 		}
 	}
+	if mainBase && g.world.config.CustomRules != nil {
+		for _, name := range g.world.config.CustomRules.StartingDrones {
+			stats := gamedata.FindStartingDroneStats(name)
+			a := core.NewColonyAgentNode(stats, core.pos.Add(g.rng.Offset(-20, 20)))
+			g.world.nodeRunner.AddObject(a)
+			a.AssignMode(agentModeStandby, gmath.Vec{}, nil)
+		}
+	}
 	if mainBase {
 		for _, stats := range g.world.config.ExtraDrones {
 			a := core.NewColonyAgentNode(stats, core.pos.Add(g.scene.Rand().Offset(-20, 20)))
@@ -692,6 +720,10 @@ func (g *levelGenerator) placeResources() {
 		g.deployStartingResources()
 	}
 
+	g.addPendingResources()
+}
+
+func (g *levelGenerator) addPendingResources() {
 	// Now sort all resources by their Y coordinate and only
 	// then add them to the scene.
 	sort.Slice(g.pendingResources, func(i, j int) bool {
@@ -1436,6 +1468,10 @@ func (g *levelGenerator) placeForests() {
 		}
 	}
 
+	g.drawTrees(trees)
+}
+
+func (g *levelGenerator) drawTrees(trees []pendingImage) {
 	if len(trees) != 0 {
 		sort.SliceStable(trees, func(i, j int) bool {
 			return trees[i].drawOrder < trees[j].drawOrder
@@ -0,0 +1,189 @@
+package staging
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/pathing"
+)
+
+// This file contains the level generator steps for the map editor levels.
+// See gamedata.LevelMap.
+
+var mapResourceStats = map[string]*essenceSourceStats{
+	"iron":        ironSource,
+	"gold":        goldSource,
+	"crystal":     crystalSource,
+	"red_crystal": redCrystalSource,
+	"oil":         oilSource,
+	"red_oil":     redOilSource,
+	"sulfur":      sulfurSource,
+	"organic":     organicSource,
+	"mineral":     mineralSource,
+	"scrap":       scrapSource,
+	"artifact":    artifactSource,
+}
+
+func (g *levelGenerator) mapCellPos(c gamedata.MapCell) gmath.Vec {
+	return g.world.pathgrid.CoordToPos(pathing.GridCoord{X: c.X, Y: c.Y})
+}
+
+func (g *levelGenerator) placeMapTiles() {
+	if g.bg == nil {
+		return
+	}
+
+	imageID, _ := gamedata.EnvironmentTileset(g.world.envKind)
+	tileset := g.scene.LoadImage(imageID).Data
+	numFrames := tileset.Bounds().Dx() / int(pathing.CellSize)
+
+	for _, t := range g.world.config.Map.Tiles {
+		if t.Frame >= numFrames {
+			continue
+		}
+		frameX := t.Frame * int(pathing.CellSize)
+		frame := tileset.SubImage(image.Rect(frameX, 0, frameX+int(pathing.CellSize), int(pathing.CellSize))).(*ebiten.Image)
+		var drawOptions ebiten.DrawImageOptions
+		drawOptions.GeoM.Translate(float64(t.X)*pathing.CellSize, float64(t.Y)*pathing.CellSize)
+		g.bg.DrawImage(frame, &drawOptions)
+	}
+}
+
+func (g *levelGenerator) placeMapForests() {
+	isSnowy := g.world.envKind == gamedata.EnvSnow
+
+	var trees []pendingImage
+	for _, r := range g.world.config.Map.Forests {
+		forest := newForestClusterNode(g.world, forestClusterConfig{
+			pos: gmath.Vec{
+				X: float64(r.X) * pathing.CellSize,
+				Y: float64(r.Y) * pathing.CellSize,
+			},
+			width:  r.Width,
+			height: r.Height,
+		})
+		trees = append(trees, forest.init(g.scene, isSnowy)...)
+		forest.walkRects(func(rect gmath.Rect) {
+			if isSnowy {
+				g.fillPathgridRect(rect, ptagBlocked)
+			} else {
+				g.fillPathgridRect(rect, ptagForest)
+			}
+		})
+		g.world.forests = append(g.world.forests, forest)
+	}
+
+	g.drawTrees(trees)
+}
+
+func (g *levelGenerator) placeMapTeleporters() {
+	for i, tp := range g.world.config.Map.Teleporters {
+		tp1 := &teleporterNode{id: i, pos: g.world.Adjust2x2CellPos(g.mapCellPos(tp.From), 0).Sub(teleportOffset), world: g.world}
+		tp2 := &teleporterNode{id: i, pos: g.world.Adjust2x2CellPos(g.mapCellPos(tp.To), 0).Sub(teleportOffset), world: g.world}
+
+		tp1.other = tp2
+		tp2.other = tp1
+
+		g.world.teleporters = append(g.world.teleporters, tp1)
+		g.world.nodeRunner.AddObject(tp1)
+		g.world.teleporters = append(g.world.teleporters, tp2)
+		g.world.nodeRunner.AddObject(tp2)
+	}
+}
+
+func (g *levelGenerator) placeMapPlayers() {
+	spawnPoints := g.world.config.Map.SpawnPoints
+	if g.world.config.GameMode == gamedata.ModeReverse || len(spawnPoints) < len(g.world.config.Players) {
+		// Not enough spawn points, use the default placement around the first one.
+		g.placePlayers()
+		return
+	}
+
+	extraOffset := gmath.Vec{}
+	if g.world.coreDesign == gamedata.TankCoreStats {
+		extraOffset = gmath.Vec{X: -16, Y: -16}
+	}
+	for i := range g.world.config.Players {
+		g.createBase(g.world.players[i], g.mapCellPos(spawnPoints[i]).Add(extraOffset), true)
+	}
+}
+
+func (g *levelGenerator) placeMapWalls() {
+	walls := g.world.config.Map.Walls
+
+	pending := make(map[gamedata.MapCell]struct{}, len(walls))
+	for _, c := range walls {
+		pending[c] = struct{}{}
+	}
+
+	directions := [4]gamedata.MapCell{
+		{X: 1},
+		{X: -1},
+		{Y: 1},
+		{Y: -1},
+	}
+
+	atlas := wallAtras{layers: landcrackAtlas}
+	if g.world.envKind == gamedata.EnvSnow {
+		atlas = wallAtras{layers: snowyLandcrackAtlas}
+	}
+
+	// The connected wall cells are grouped into clusters,
+	// so the wall tiles are oriented properly.
+	// A cluster can't have more than maxWallSegments tiles;
+	// this also keeps the cluster inside the orientation map bounds.
+	for _, start := range walls {
+		if _, ok := pending[start]; !ok {
+			continue
+		}
+		delete(pending, start)
+		cluster := []gamedata.MapCell{start}
+	BFS:
+		for i := 0; i < len(cluster); i++ {
+			for _, d := range directions {
+				if len(cluster) == maxWallSegments {
+					break BFS
+				}
+				next := gamedata.MapCell{X: cluster[i].X + d.X, Y: cluster[i].Y + d.Y}
+				if _, ok := pending[next]; !ok {
+					continue
+				}
+				delete(pending, next)
+				cluster = append(cluster, next)
+			}
+		}
+
+		config := wallClusterConfig{
+			world:  g.world,
+			atlas:  atlas,
+			points: make([]gmath.Vec, len(cluster)),
+		}
+		for i, c := range cluster {
+			config.points[i] = g.mapCellPos(c)
+		}
+		wall := g.world.NewWallClusterNode(config)
+		g.scene.AddObject(wall)
+		wall.initOriented(g.bg, g.scene)
+	}
+}
+
+func (g *levelGenerator) placeMapCreepBases() {
+	for i, c := range g.world.config.Map.CreepBases {
+		g.createCreepBase(i, g.mapCellPos(c))
+	}
+}
+
+func (g *levelGenerator) placeMapResources() {
+	for _, r := range g.world.config.Map.Resources {
+		stats, ok := mapResourceStats[r.Kind]
+		if !ok {
+			panic(fmt.Sprintf("unexpected map resource kind: %q", r.Kind))
+		}
+		source := g.world.NewEssenceSourceNode(stats, g.adjustResourcePos(g.mapCellPos(r.MapCell)))
+		g.pendingResources = append(g.pendingResources, source)
+	}
+	g.addPendingResources()
+}
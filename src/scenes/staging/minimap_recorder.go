@@ -0,0 +1,203 @@
+package staging
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/minimap"
+	"github.com/quasilyte/roboden-game/pathing"
+)
+
+// MinimapConfig describes the minimap recording settings.
+type MinimapConfig struct {
+	// CellSize is a pathing grid cell size in the image pixels.
+	// Zero means "use the default" (4 pixels).
+	CellSize int
+
+	// SnapshotTicks is a snapshot period; there are 60 ticks per second.
+	// Zero means "use the default" (30 seconds).
+	SnapshotTicks int
+}
+
+// MinimapRecording is a rasterized game history.
+//
+// It's recorded without any Ebitengine rendering,
+// so it works for the simulation runs too (see cmd/replayrender).
+type MinimapRecording struct {
+	Snapshots []MinimapSnapshot
+
+	renderer *minimap.Renderer
+	combat   *minimap.Heatmap
+	mining   *minimap.Heatmap
+}
+
+type MinimapSnapshot struct {
+	Tick  int
+	Image *image.RGBA
+}
+
+var (
+	minimapCombatHeatColor = color.RGBA{R: 255, G: 80, B: 40, A: 255}
+	minimapMiningHeatColor = color.RGBA{R: 60, G: 220, B: 255, A: 255}
+)
+
+// CombatHeatmap renders the places where the units were killed
+// and the colonies were damaged.
+func (rec *MinimapRecording) CombatHeatmap() *image.RGBA {
+	return rec.renderer.RenderHeatmap(rec.combat, minimapCombatHeatColor)
+}
+
+// MiningHeatmap renders the places where the resources were harvested.
+func (rec *MinimapRecording) MiningHeatmap() *image.RGBA {
+	return rec.renderer.RenderHeatmap(rec.mining, minimapMiningHeatColor)
+}
+
+// Timelapse combines the snapshots into an animated GIF.
+// The delay is measured in 100ths of a second.
+func (rec *MinimapRecording) Timelapse(delay int) *gif.GIF {
+	frames := make([]*image.RGBA, len(rec.Snapshots))
+	for i, s := range rec.Snapshots {
+		frames[i] = s.Image
+	}
+	return minimap.Timelapse(frames, delay)
+}
+
+type minimapRecorder struct {
+	world     *worldState
+	config    MinimapConfig
+	recording MinimapRecording
+
+	nextSnapshotTick int
+
+	units []minimap.Unit
+}
+
+func (r *minimapRecorder) Init(world *worldState) {
+	r.world = world
+	if r.config.CellSize == 0 {
+		r.config.CellSize = 4
+	}
+	if r.config.SnapshotTicks == 0 {
+		r.config.SnapshotTicks = 60 * 30
+	}
+	cols, rows := world.pathgrid.Size()
+	r.recording.renderer = minimap.NewRenderer(cols, rows, r.config.CellSize)
+	r.recording.combat = minimap.NewHeatmap(cols, rows)
+	r.recording.mining = minimap.NewHeatmap(cols, rows)
+}
+
+func (r *minimapRecorder) Update() {
+	if r.world.nodeRunner.ticks < r.nextSnapshotTick {
+		return
+	}
+	r.nextSnapshotTick = r.world.nodeRunner.ticks + r.config.SnapshotTicks
+	r.AddSnapshot()
+}
+
+func (r *minimapRecorder) HandleEvent(e GameEvent) {
+	switch e.Kind {
+	case GameEventCreepKilled, GameEventDroneDestroyed, GameEventColonyDamaged:
+		r.recording.combat.Add(e.Pos[0]/pathing.CellSize, e.Pos[1]/pathing.CellSize, 1)
+	}
+}
+
+// AddMining is called for every harvest action.
+// The ResourceMined event can't be used here: it happens at the colony
+// when the resource is delivered, not at the source.
+func (r *minimapRecorder) AddMining(pos gmath.Vec, amount int) {
+	r.recording.mining.Add(pos.X/pathing.CellSize, pos.Y/pathing.CellSize, float64(amount))
+}
+
+func (r *minimapRecorder) AddSnapshot() {
+	r.updateTerrain()
+
+	r.units = r.units[:0]
+	for _, source := range r.world.essenceSources {
+		r.addUnit(minimap.UnitResource, -1, source.pos)
+	}
+	for _, tp := range r.world.teleporters {
+		r.addUnit(minimap.UnitTeleporter, -1, tp.pos)
+	}
+	for _, turret := range r.world.turrets {
+		r.addUnit(minimap.UnitTurret, agentPlayerID(turret), turret.pos)
+	}
+	for _, colony := range r.world.allColonies {
+		player := colony.player.GetState().id
+		r.addUnit(minimap.UnitColony, player, colony.pos)
+		colony.agents.Each(func(a *colonyAgentNode) {
+			r.addUnit(minimap.UnitDrone, player, a.pos)
+		})
+	}
+	for _, creep := range r.world.creeps {
+		kind := minimap.UnitCreep
+		if creep == r.world.boss {
+			kind = minimap.UnitBoss
+		}
+		r.addUnit(kind, -1, creep.pos)
+	}
+
+	r.recording.Snapshots = append(r.recording.Snapshots, MinimapSnapshot{
+		Tick:  r.world.nodeRunner.ticks,
+		Image: r.recording.renderer.Render(r.units),
+	})
+}
+
+func (r *minimapRecorder) addUnit(kind minimap.UnitKind, player int, pos gmath.Vec) {
+	r.units = append(r.units, minimap.Unit{
+		Kind:   kind,
+		Player: player,
+		X:      pos.X / pathing.CellSize,
+		Y:      pos.Y / pathing.CellSize,
+	})
+}
+
+// minimapTileLayer maps the pathing grid tags to the minimap tiles.
+var minimapTileLayer = pathing.MakeGridLayer(
+	uint8(minimap.TileFree),
+	uint8(minimap.TileBlocked),
+	uint8(minimap.TileForest),
+	uint8(minimap.TileLava),
+)
+
+func (r *minimapRecorder) updateTerrain() {
+	// The grid is re-read for every snapshot: the cells
+	// are occupied by the new turrets and freed by the destroyed ones.
+	grid := r.world.pathgrid
+	cols, rows := grid.Size()
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			v := grid.GetCellValue(pathing.GridCoord{X: x, Y: y}, minimapTileLayer)
+			r.recording.renderer.SetTile(x, y, minimap.Tile(v))
+		}
+	}
+
+	// The walls are blocked cells too, but they're highlighted
+	// to separate them from the occupied cells.
+	for _, wall := range r.world.walls {
+		if wall.rectShape {
+			for y := wall.rect.Min.Y; y < wall.rect.Max.Y; y += pathing.CellSize {
+				for x := wall.rect.Min.X; x < wall.rect.Max.X; x += pathing.CellSize {
+					r.setWallTile(gmath.Vec{X: x, Y: y})
+				}
+			}
+			continue
+		}
+		for _, pos := range wall.points {
+			r.setWallTile(pos)
+		}
+	}
+}
+
+func (r *minimapRecorder) setWallTile(pos gmath.Vec) {
+	coord := r.world.pathgrid.PosToCoord(pos)
+	r.recording.renderer.SetTile(coord.X, coord.Y, minimap.TileWall)
+}
+
+func agentPlayerID(a *colonyAgentNode) int {
+	if a.colonyCore == nil {
+		return -1
+	}
+	return a.colonyCore.player.GetState().id
+}
@@ -0,0 +1,88 @@
+package staging
+
+import (
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/netplay"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// networkPlayer is the remote player of the network game.
+//
+// Its actions are received by the lockstep and executed
+// at the same ticks the other side executes them.
+type networkPlayer struct {
+	world *worldState
+
+	choiceGen *choiceGenerator
+
+	state *playerState
+
+	lockstep *netplay.Lockstep
+}
+
+func newNetworkPlayer(world *worldState, state *playerState, choiceGen *choiceGenerator, lockstep *netplay.Lockstep) *networkPlayer {
+	return &networkPlayer{
+		world:     world,
+		state:     state,
+		choiceGen: choiceGen,
+		lockstep:  lockstep,
+	}
+}
+
+func isNetworkPlayer(p player) bool {
+	_, ok := p.(*networkPlayer)
+	return ok
+}
+
+func (p *networkPlayer) Init() {
+	if p.choiceGen.creepsState == nil {
+		p.state.selectedColony = p.state.colonies[0]
+	}
+}
+
+func (p *networkPlayer) Update(computedDelta, delta float64) {
+	for _, a := range p.lockstep.TakeActions(p.state.id, p.world.nodeRunner.ticks) {
+		if p.choiceGen.creepsState == nil && a.SelectedColony >= 0 && a.SelectedColony < len(p.state.colonies) {
+			p.state.selectedColony = p.state.colonies[a.SelectedColony]
+		}
+		executeNetworkAction(p.state, p.choiceGen, a)
+	}
+}
+
+func (p *networkPlayer) GetState() *playerState { return p.state }
+
+// executeNetworkAction is like the replay action execution,
+// but it never panics: the action could become illegal while it was
+// waiting for the input delay (e.g. the colony was destroyed).
+// Both sides run this code at the same tick, so they get the same results.
+func executeNetworkAction(pstate *playerState, choiceGen *choiceGenerator, a serverapi.PlayerAction) bool {
+	var colony *colonyCoreNode
+	if choiceGen.creepsState == nil {
+		if a.SelectedColony < 0 || a.SelectedColony >= len(pstate.colonies) {
+			return false
+		}
+		colony = pstate.colonies[a.SelectedColony]
+	}
+
+	if a.Kind.IsGroup() {
+		group := resolveControlGroup(pstate, a.Group)
+		if group == nil {
+			return false
+		}
+		if a.Kind == serverapi.ActionGroupMove {
+			return choiceGen.TryExecuteGroup(group, -1, gmath.Vec{X: a.Pos[0], Y: a.Pos[1]})
+		}
+		return choiceGen.TryExecuteGroup(group, int(a.Kind.Ungrouped())-1, gmath.Vec{})
+	}
+
+	switch {
+	case a.Kind == serverapi.ActionMove:
+		return choiceGen.TryExecute(colony, -1, gmath.Vec{X: a.Pos[0], Y: a.Pos[1]})
+	case a.Kind == serverapi.ActionQueueMove:
+		return choiceGen.TryQueueMove(colony, gmath.Vec{X: a.Pos[0], Y: a.Pos[1]})
+	case a.Kind >= serverapi.ActionCard1 && a.Kind <= serverapi.ActionCard5:
+		return choiceGen.TryExecute(colony, int(a.Kind)-1, gmath.Vec{})
+	default:
+		return false
+	}
+}
@@ -0,0 +1,138 @@
+package staging
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/viewport"
+)
+
+// observerOverlayNode renders the players summary for the observer camera.
+//
+// It only reads the players state, so it's safe to use it
+// in any game (it doesn't affect the simulation).
+type observerOverlayNode struct {
+	world  *worldState
+	camera *viewport.Camera
+
+	players []*observedPlayer
+
+	following   int
+	updateDelay float64
+}
+
+type observedPlayer struct {
+	state     *playerState
+	choiceGen *choiceGenerator
+
+	rect  *ge.Rect
+	label *ge.Label
+}
+
+const observerPanelWidth = 260
+
+func newObserverOverlayNode(world *worldState, camera *viewport.Camera, choiceGens []*choiceGenerator) *observerOverlayNode {
+	n := &observerOverlayNode{
+		world:     world,
+		camera:    camera,
+		following: -1,
+	}
+	for i, p := range world.players {
+		n.players = append(n.players, &observedPlayer{
+			state:     p.GetState(),
+			choiceGen: choiceGens[i],
+		})
+	}
+	return n
+}
+
+func (n *observerOverlayNode) Init(scene *ge.Scene) {
+	for i, p := range n.players {
+		pos := gmath.Vec{X: 8, Y: 40}
+		if i%2 == 1 {
+			pos.X = n.camera.Rect.Width() - observerPanelWidth - 8
+		}
+
+		p.rect = ge.NewRect(scene.Context(), observerPanelWidth, 0)
+		p.rect.OutlineColorScale.SetColor(ge.RGB(0x5e5a5d))
+		p.rect.OutlineWidth = 1
+		p.rect.FillColorScale.SetRGBA(0x13, 0x1a, 0x22, 200)
+		p.rect.Centered = false
+		p.rect.Pos.Offset = pos
+
+		p.label = ge.NewLabel(assets.BitmapFont1)
+		p.label.Pos.Offset = pos.Add(gmath.Vec{X: 8, Y: 8})
+		p.label.SetColorScaleRGBA(0x9d, 0xd7, 0x93, 0xff)
+
+		n.camera.UI.AddGraphicsAbove(p.rect)
+		n.camera.UI.AddGraphicsAbove(p.label)
+	}
+	n.updateText()
+}
+
+func (n *observerOverlayNode) IsDisposed() bool { return false }
+
+func (n *observerOverlayNode) Update(delta float64) {
+	n.updateDelay -= delta
+	if n.updateDelay > 0 {
+		return
+	}
+	n.updateDelay = 0.25
+	n.updateText()
+}
+
+// SetFollowing marks the player panel as followed by the camera; -1 means "nobody".
+func (n *observerOverlayNode) SetFollowing(playerID int) {
+	n.following = playerID
+	n.updateText()
+}
+
+func (n *observerOverlayNode) updateText() {
+	d := n.world.rootScene.Dict()
+
+	for i, p := range n.players {
+		var buf strings.Builder
+
+		buf.WriteString(fmt.Sprintf("P%d", i+1))
+		if i == n.following {
+			buf.WriteString(" [" + d.Get("game.observer.following") + "]")
+		}
+		buf.WriteByte('\n')
+
+		if p.choiceGen.creepsState != nil {
+			buf.WriteString(fmt.Sprintf("%s: %d%%\n", d.Get("game.observer.tech"), int(math.Round(p.choiceGen.creepsState.techLevel*100))))
+		} else {
+			resources := 0.0
+			for _, colony := range p.state.colonies {
+				resources += colony.resources
+			}
+			buf.WriteString(fmt.Sprintf("%s: %d\n", d.Get("game.observer.resources"), int(resources)))
+			buf.WriteString(fmt.Sprintf("%s: %d\n", d.Get("game.observer.colonies"), len(p.state.colonies)))
+		}
+
+		if p.choiceGen.IsReady() {
+			choices := p.choiceGen.GetChoices()
+			buf.WriteString(d.Get("game.observer.cards") + ":\n")
+			for _, card := range choices.cards {
+				buf.WriteString("- " + strings.ReplaceAll(choiceOptionHint(d, p.choiceGen.creepsState, card), "\n", " ") + "\n")
+			}
+			buf.WriteString("* " + choiceOptionHint(d, p.choiceGen.creepsState, choices.special))
+		} else {
+			progress := 0.0
+			if p.choiceGen.targetValue != 0 {
+				progress = gmath.Clamp(p.choiceGen.value/p.choiceGen.targetValue, 0, 1)
+			}
+			buf.WriteString(fmt.Sprintf("%s: %d%%", d.Get("game.observer.charging"), int(progress*100)))
+		}
+
+		s := buf.String()
+		p.label.Text = s
+		bounds := text.BoundString(assets.BitmapFont1, s)
+		p.rect.Height = float64(bounds.Dy()) + 16
+	}
+}
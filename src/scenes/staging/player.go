@@ -35,6 +35,10 @@ type playerState struct {
 
 	selectedColony *colonyCoreNode
 
+	// controlGroups are the colony groups assigned by the player.
+	// The destroyed colonies are removed from the groups automatically.
+	controlGroups [numControlGroups][]*colonyCoreNode
+
 	replay []serverapi.PlayerAction
 
 	messageManager *messageManager
@@ -59,6 +63,23 @@ func (pstate *playerState) Init(world *worldState) {
 	pstate.hasRoombas = xslices.Contains(world.tier2recipes, gamedata.FindRecipe(gamedata.RoombaAgentStats))
 }
 
+// ToggleGroupColony adds the colony to the i-th control group.
+// If the colony is already a group member, it's removed from the group instead.
+func (pstate *playerState) ToggleGroupColony(i int, colony *colonyCoreNode) {
+	group := pstate.controlGroups[i]
+	if xslices.Contains(group, colony) {
+		pstate.controlGroups[i] = xslices.Remove(group, colony)
+		return
+	}
+	pstate.controlGroups[i] = append(group, colony)
+}
+
+func (pstate *playerState) removeFromControlGroups(colony *colonyCoreNode) {
+	for i, group := range pstate.controlGroups {
+		pstate.controlGroups[i] = xslices.Remove(group, colony)
+	}
+}
+
 func (pstate *playerState) CanTransferResourcesTo(colony *colonyCoreNode) bool {
 	if pstate.resourceStash < 1 {
 		return false
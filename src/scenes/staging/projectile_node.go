@@ -108,6 +108,12 @@ func (p *projectileNode) Init(scene *ge.Scene) {
 			p.rotation = -math.Pi / 2
 		}
 
+		if p.world.weatherKind == weatherLowGravity {
+			// The projectiles fly higher and slower.
+			arcPower *= 1.0 + 0.6*p.world.weatherPower
+			speed *= 1.0 - 0.2*p.world.weatherPower
+		}
+
 		if inversed {
 			if p.toPos.Y <= p.pos.Y {
 				arcPower *= 0.45
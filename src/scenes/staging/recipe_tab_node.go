@@ -9,6 +9,7 @@ import (
 	"github.com/quasilyte/gmath"
 	"github.com/quasilyte/roboden-game/assets"
 	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui"
 )
 
 type recipeTabNode struct {
@@ -64,8 +65,9 @@ func (tab *recipeTabNode) Init(scene *ge.Scene) {
 		}).(*ebiten.Image)
 	}
 
+	style := tab.world.diodeStyle
 	diodeImg := assets.ImageFactionDiode
-	if tab.world.gameSettings.LargeDiodes {
+	if style.Large {
 		diodeImg = assets.ImageFactionDiodeLarge
 	}
 	diode := scene.LoadImage(diodeImg).Data
@@ -97,8 +99,15 @@ func (tab *recipeTabNode) Init(scene *ge.Scene) {
 			drawOptions.GeoM.Reset()
 			drawOptions.GeoM.Translate(offsetX, offsetY)
 			drawOptions.GeoM.Translate(halfWidth-(float64(diodeSize.X)*0.5), 15-(float64(diodeSize.Y)*0.5)+stats.DiodeOffset)
-			drawOptions.ColorM.ScaleWithColor(gamedata.FactionByTag(faction).Color)
+			drawOptions.ColorM.ScaleWithColor(gamedata.FactionColor(faction, style.Palette))
 			dst.DrawImage(diode, &drawOptions)
+			if style.Patterns {
+				pattern := gameui.FactionPatternImage(faction)
+				drawOptions.GeoM.Reset()
+				drawOptions.GeoM.Translate(offsetX, offsetY)
+				drawOptions.GeoM.Translate(halfWidth+(float64(frameSize.X)*0.5), 15+(float64(frameSize.Y)*0.5)-float64(pattern.Bounds().Dy()))
+				dst.DrawImage(pattern, &drawOptions)
+			}
 		}
 	}
 
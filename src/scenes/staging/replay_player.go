@@ -51,7 +51,19 @@ func (p *replayPlayer) Update(computedDelta, delta float64) {
 		}
 
 		ok := false
-		if a.Kind == serverapi.ActionMove {
+		if a.Kind.IsGroup() {
+			group := resolveControlGroup(p.state, a.Group)
+			if group == nil {
+				panic(errInvalidColonyIndex)
+			}
+			if a.Kind == serverapi.ActionGroupMove {
+				ok = p.choiceGen.TryExecuteGroup(group, -1, gmath.Vec{X: a.Pos[0], Y: a.Pos[1]})
+			} else {
+				ok = p.choiceGen.TryExecuteGroup(group, int(a.Kind.Ungrouped())-1, gmath.Vec{})
+			}
+		} else if a.Kind == serverapi.ActionQueueMove {
+			ok = p.choiceGen.TryQueueMove(p.state.selectedColony, gmath.Vec{X: a.Pos[0], Y: a.Pos[1]})
+		} else if a.Kind == serverapi.ActionMove {
 			ok = p.choiceGen.TryExecute(p.state.selectedColony, -1, gmath.Vec{X: a.Pos[0], Y: a.Pos[1]})
 		} else {
 			ok = p.choiceGen.TryExecute(p.state.selectedColony, int(a.Kind)-1, gmath.Vec{})
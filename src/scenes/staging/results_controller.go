@@ -34,6 +34,12 @@ type resultsController struct {
 	highScore bool
 	rewards   *gameRewards
 
+	missionStars int
+
+	// The controller is re-entered after the stats dashboard;
+	// the progress should be updated only once.
+	progressUpdated bool
+
 	results battleResults
 }
 
@@ -93,6 +99,50 @@ type battleResults struct {
 	NumPauses        int
 	NumFastForwards  int
 	DebugCheckpoints []int
+
+	Stats *GameStats
+}
+
+func (r *battleResults) achievementResults() gamedata.AchievementResults {
+	factionsUsed := 0
+	for _, used := range [...]bool{r.YellowFactionUsed, r.RedFactionUsed, r.GreenFactionUsed, r.BlueFactionUsed} {
+		if used {
+			factionsUsed++
+		}
+	}
+	return gamedata.AchievementResults{
+		Victory:              r.Victory,
+		TimePlayed:           r.TimePlayed.Seconds(),
+		Ticks:                r.Ticks,
+		FastforwardTicks:     r.FastforwardTicks,
+		SeedKind:             r.SeedKind,
+		Score:                r.Score,
+		DifficultyScore:      r.DifficultyScore,
+		DronePointsAllocated: r.DronePointsAllocated,
+		ArenaLevel:           r.ArenaLevel,
+
+		Paused:             r.Paused,
+		OpenedEvolutionTab: r.OpenedEvolutionTab,
+		T3Created:          r.T3created,
+		ColoniesBuilt:      r.ColoniesBuilt,
+		RadiusIncreases:    r.RadiusIncreases,
+		OnlyTier1Military:  r.OnlyTier1Military,
+		FactionsUsed:       factionsUsed,
+
+		CreepsStomped:        r.CreepsStomped,
+		CreepBasesDestroyed:  r.CreepBasesDestroyed,
+		DominatorsSurvived:   r.DominatorsSurvived,
+		GrenadierColonyHit:   r.GrenadierColonyHit,
+		GroundBossDefeat:     r.GroundBossDefeat,
+		CoordinatorRallyUsed: r.CoordinatorRallyUsed,
+
+		EnemyColonyDamage:            r.EnemyColonyDamage,
+		EnemyColonyDamageFromTurrets: r.EnemyColonyDamageFromTurrets,
+
+		GroundControl:     r.GroundControl,
+		AtomicBombUsed:    r.AtomicBombUsed,
+		AtomicBombVictory: r.AtomicBombVictory,
+	}
 }
 
 func newResultsController(state *session.State, config *gamedata.LevelConfig, backController ge.SceneController, results battleResults) *resultsController {
@@ -110,15 +160,19 @@ func (c *resultsController) Init(scene *ge.Scene) {
 
 	switch c.config.PlayersMode {
 	case serverapi.PmodeSinglePlayer, serverapi.PmodeTwoPlayers:
-		c.hasPlayers = true
+		// The reverse mode score and rewards are calculated
+		// for the dreadnought side only.
+		c.hasPlayers = !gamedata.IsCreepsBotGame(c.config.ReplayLevelConfig)
 	}
 
-	c.rewards = &gameRewards{}
-
-	victory := c.results.Victory || c.config.GameMode == gamedata.ModeInfArena
-	if victory {
-		c.updateProgress()
-		c.state.SaveGameItem("save.json", c.state.Persistent)
+	if !c.progressUpdated {
+		c.progressUpdated = true
+		c.rewards = &gameRewards{}
+		victory := c.results.Victory || c.config.GameMode == gamedata.ModeInfArena
+		if victory {
+			c.updateProgress()
+			c.state.SaveGameItem("save.json", c.state.Persistent)
+		}
 	}
 
 	c.initUI()
@@ -222,6 +276,10 @@ func (c *resultsController) updateProgress() {
 		}
 	}
 
+	if c.config.Mission != nil && c.results.Victory {
+		c.updateCampaignProgress()
+	}
+
 	contentUpdates := contentlock.Update(c.state)
 	c.rewards.newAchievements, c.rewards.upgradedAchievements = c.checkAchievements()
 	c.rewards.newCores = contentUpdates.CoresUnlocked
@@ -231,6 +289,30 @@ func (c *resultsController) updateProgress() {
 	c.rewards.newModes = contentUpdates.ModesUnlocked
 }
 
+func (c *resultsController) updateCampaignProgress() {
+	stats := &c.state.Persistent.PlayerStats
+	mission := c.config.Mission
+
+	secondsPlayed := int(math.Floor(c.results.TimePlayed.Seconds()))
+	c.missionStars = mission.CalcStars(secondsPlayed)
+
+	progress := stats.FindCampaignProgress(mission.Name)
+	if progress == nil {
+		stats.Campaign = append(stats.Campaign, session.CampaignProgress{
+			Mission:  mission.Name,
+			Stars:    c.missionStars,
+			BestTime: secondsPlayed,
+		})
+		return
+	}
+	if progress.Stars < c.missionStars {
+		progress.Stars = c.missionStars
+	}
+	if progress.BestTime > secondsPlayed {
+		progress.BestTime = secondsPlayed
+	}
+}
+
 func (c *resultsController) Update(delta float64) {
 	c.state.MenuInput.Update()
 	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
@@ -250,6 +332,10 @@ func (c *resultsController) checkAchievements() ([]string, []string) {
 	if c.config.PlayersMode != serverapi.PmodeSinglePlayer {
 		return nil, nil
 	}
+	if c.config.GameMode == gamedata.ModeCustom {
+		// The custom rules can make any achievement trivial.
+		return nil, nil
+	}
 
 	stats := &c.state.Persistent.PlayerStats
 
@@ -267,6 +353,15 @@ func (c *resultsController) checkAchievements() ([]string, []string) {
 		difficultyLevel = 2
 	}
 
+	achievementResults := c.results.achievementResults()
+	ruleContext := gamedata.AchievementContext{
+		Config:          &c.config.ReplayLevelConfig,
+		Results:         &achievementResults,
+		NumVictories:    stats.NumVictories,
+		Tier3DronesSeen: len(stats.Tier3DronesSeen),
+		Roll:            c.scene.Rand().Float(),
+	}
+
 	needSave := false
 	for _, a := range gamedata.AchievementList {
 		if alreadyAchieved[a.Name] >= difficultyLevel {
@@ -280,86 +375,7 @@ func (c *resultsController) checkAchievements() ([]string, []string) {
 		if a.NeedsVictory && !c.results.Victory {
 			continue
 		}
-		unlocked := false
-
-		switch a.Name {
-		case "t3engineer":
-			unlocked = len(stats.Tier3DronesSeen) >= len(gamedata.Tier3agentMergeRecipes) && c.config.GameMode != gamedata.ModeReverse
-		case "trample":
-			unlocked = c.results.CreepsStomped != 0 && c.config.GameMode != gamedata.ModeReverse
-		case "nopeeking":
-			unlocked = !c.results.OpenedEvolutionTab && c.config.GameMode != gamedata.ModeReverse
-		case "nonstop":
-			unlocked = !c.results.Paused && c.config.GameMode != gamedata.ModeReverse
-		case "darkness":
-			unlocked = c.config.WorldShape != int(gamedata.WorldSquare) && c.config.FogOfWar
-		case "fastforward":
-			unlocked = c.config.GameSpeed == 3 && (float64(c.results.FastforwardTicks)/float64(c.results.Ticks)) >= 0.85
-		case "lucky":
-			unlocked = stats.NumVictories >= 64 || c.scene.Rand().Chance(0.02)
-
-		case "impossible":
-			unlocked = c.results.DifficultyScore > 200
-		case "speedrunning":
-			unlocked = c.results.TimePlayed.Minutes() < 15
-		case "victorydrag":
-			unlocked = c.results.TimePlayed.Hours() >= 2
-		case "t3less":
-			unlocked = c.results.T3created == 0
-		case "cheapbuild10":
-			unlocked = c.results.DronePointsAllocated <= 10
-		case "hightension":
-			unlocked = c.config.WorldSize == 0 &&
-				c.config.NumCreepBases != 0 &&
-				c.results.CreepBasesDestroyed == 0
-		case "solobase":
-			unlocked = c.results.ColoniesBuilt == 0
-		case "uiless":
-			unlocked = c.config.InterfaceMode == 0
-		case "powerof3":
-			unlocked = !c.results.YellowFactionUsed || !c.results.RedFactionUsed || !c.results.GreenFactionUsed || !c.results.BlueFactionUsed
-		case "tinyradius":
-			unlocked = c.results.RadiusIncreases == 0
-		case "t1army":
-			unlocked = c.results.OnlyTier1Military
-		case "groundwin":
-			unlocked = c.results.GroundBossDefeat
-		case "turretdamage":
-			unlocked = c.results.EnemyColonyDamageFromTurrets >= (c.results.EnemyColonyDamage * 0.25)
-		case "cheese":
-			unlocked = len(c.config.Tier2Recipes) <= 1 &&
-				c.config.TurretDesign == "BeamTower" &&
-				c.config.CoreDesign == "tank" &&
-				c.config.BossDifficulty < 3 &&
-				c.results.DifficultyScore >= 200 &&
-				c.results.TimePlayed.Minutes() < 20
-		case "leet":
-			unlocked = c.results.SeedKind == gamedata.SeedLeet
-
-		case "antidominator":
-			unlocked = c.results.DominatorsSurvived == 0
-		case "quicksilver":
-			unlocked = c.config.GrenadierCreeps && !c.results.GrenadierColonyHit &&
-				c.config.CoreDesign != "ark"
-		case "infernal":
-			unlocked = c.results.SeedKind == gamedata.SeedInfernal
-
-		case "infinite":
-			unlocked = c.results.ArenaLevel >= 35
-
-		case "colonyhunter":
-			unlocked = c.results.ColoniesBuilt >= 3
-		case "groundcontrol":
-			unlocked = c.results.GroundControl
-		case "atomicfinisher":
-			unlocked = c.results.AtomicBombVictory
-		case "coordinator":
-			unlocked = c.results.CoordinatorRallyUsed
-		case "siege":
-			unlocked = !c.results.AtomicBombUsed && c.config.CoreDesign == "hive"
-		}
-
-		if !unlocked {
+		if !a.CheckRule(&ruleContext) {
 			continue
 		}
 
@@ -458,6 +474,9 @@ func (c *resultsController) initUI() {
 	if c.config.GameMode == gamedata.ModeInfArena {
 		lines = append(lines, [2]string{d.Get("game.wave"), itoa(c.results.ArenaLevel)})
 	}
+	if c.missionStars != 0 {
+		lines = append(lines, [2]string{d.Get("menu.campaign.stars"), fmt.Sprintf("%d/%d", c.missionStars, c.config.Mission.MaxStars())})
+	}
 
 	for _, pair := range lines {
 		grid.AddChild(eui.NewLabel(pair[0], smallFont))
@@ -489,8 +508,7 @@ func (c *resultsController) initUI() {
 				return
 			}
 			saved = true
-			k := c.state.ReplayDataKey(c.state.FindNextReplayIndex())
-			c.state.SaveGameItem(k, r)
+			c.state.AddLibraryReplay(r)
 		}))
 	}
 	if gamedata.IsSendableReplay(replay) {
@@ -509,6 +527,12 @@ func (c *resultsController) initUI() {
 		}))
 	}
 
+	if c.results.Stats != nil {
+		rowContainer.AddChild(eui.NewButton(uiResources, c.scene, d.Get("menu.results.stats"), func() {
+			c.scene.Context().ChangeScene(newStatsController(c.state, c.results.Stats, c))
+		}))
+	}
+
 	if c.rewards.IsEmpty() {
 		rowContainer.AddChild(eui.NewButton(uiResources, c.scene, d.Get("menu.lobby_back"), func() {
 			c.back()
@@ -48,6 +48,7 @@ type rpanelNode struct {
 	factionRects []*ge.Rect
 
 	// For colonies.
+	palette       gamedata.FactionPalette
 	colony        *colonyCoreNode
 	priorityIcons []*ge.Sprite
 	priorityBars  []*ge.Sprite
@@ -65,9 +66,10 @@ func newCreepsRpanelNode(cam *viewport.Camera, creepsState *creepsPlayerState) *
 	}
 }
 
-func newRpanelNode(cam *viewport.Camera) *rpanelNode {
+func newRpanelNode(cam *viewport.Camera, palette gamedata.FactionPalette) *rpanelNode {
 	return &rpanelNode{
-		cam: cam,
+		cam:     cam,
+		palette: palette,
 	}
 }
 
@@ -108,11 +110,12 @@ func (panel *rpanelNode) GetItemUnderCursor(pos gmath.Vec) (rpanelItemKind, floa
 
 func (panel *rpanelNode) initFactionsForColonies() {
 	cameraWidth := panel.cam.Rect.Width()
+	palette := panel.palette
 	colors := [...]color.RGBA{
-		gamedata.FactionByTag(gamedata.YellowFactionTag).Color,
-		gamedata.FactionByTag(gamedata.RedFactionTag).Color,
-		gamedata.FactionByTag(gamedata.GreenFactionTag).Color,
-		gamedata.FactionByTag(gamedata.BlueFactionTag).Color,
+		gamedata.FactionColor(gamedata.YellowFactionTag, palette),
+		gamedata.FactionColor(gamedata.RedFactionTag, palette),
+		gamedata.FactionColor(gamedata.GreenFactionTag, palette),
+		gamedata.FactionColor(gamedata.BlueFactionTag, palette),
 	}
 	for _, clr := range colors {
 		rect := ge.NewRect(panel.scene.Context(), 5, 0)
@@ -0,0 +1,321 @@
+package staging
+
+import (
+	"math"
+
+	"github.com/quasilyte/gmath"
+)
+
+// spatialIndex is a uniform grid that is rebuilt once per tick.
+//
+// It's a conservative index: it can tell for sure that there are no
+// colony agents, turrets or colony cores around some point,
+// but a positive answer still requires the caller to do the precise check.
+// This property is important: the index is used to skip the linear scans
+// without affecting the simulation results (and the replay checkpoints).
+//
+// Objects keep moving after the index is built, so all queries are
+// extended by the spatialIndexPad distance. Objects that were added or
+// relocated in a non-continuous way (teleported, cloned, transferred
+// between colonies) mark the index as dirty until the next rebuild.
+//
+// The grid resolution follows the number of agents: the cells become
+// smaller when they're overpopulated and grow back when the agents are gone.
+//
+// Creeps are bucketed into the fixed 8x8 worldState.creepClusters instead;
+// their traversal order is a part of the target selection logic,
+// so they can't be re-bucketed without changing the game behavior.
+// The index still rebuilds these clusters and walks them (see WalkCreepsWithRand).
+type spatialIndex struct {
+	world *worldState
+
+	valid bool
+
+	// baseCellSize is the initial cell size.
+	// The grid never becomes coarser than that.
+	baseCellSize   float64
+	cellSize       float64
+	cellMultiplier float64
+	numCols        int
+	numRows        int
+
+	cells []spatialCell
+
+	coloniesDirty bool
+	turretsDirty  bool
+	dirtyColonies []*colonyCoreNode
+}
+
+type spatialCell struct {
+	// A list of unique colonies that have at least 1 agent inside this cell.
+	agentColonies []*colonyCoreNode
+
+	// Colony cores that are located inside this cell.
+	cores []*colonyCoreNode
+
+	numTurrets int
+}
+
+const (
+	// spatialIndexPad is a max distance that any indexed object
+	// can travel in-between the index rebuilds.
+	// The fastest drones have a speed of ~300 px/sec and
+	// it can be multiplied by 2 with the game speed settings.
+	spatialIndexPad = 64.0
+
+	spatialIndexMinCellSize = 96.0
+	spatialIndexMaxCellSize = 256.0
+
+	// When an average cell population exceeds this threshold,
+	// the index grid becomes 2 times more fine-grained.
+	// When the coarser grid would be less than half-populated,
+	// the grid becomes 2 times more coarse.
+	spatialIndexCellPopulation = 24
+)
+
+func (idx *spatialIndex) Init(world *worldState) {
+	idx.world = world
+	idx.valid = false
+	idx.dirtyColonies = make([]*colonyCoreNode, 0, 4)
+
+	world.creepClusterWidth = world.width / 8
+	world.creepClusterHeight = world.height / 8
+	world.creepClusterMultiplierX = 1.0 / world.creepClusterWidth
+	world.creepClusterMultiplierY = 1.0 / world.creepClusterHeight
+	world.fallbackCreepCluster = make([]*creepNode, 0, 32)
+	for y := range world.creepClusters {
+		for x := range world.creepClusters {
+			world.creepClusters[y][x] = make([]*creepNode, 0, 16)
+		}
+	}
+
+	// A typical map is split into ~16x16 cells.
+	idx.baseCellSize = gmath.Clamp(math.Max(world.width, world.height)/16, spatialIndexMinCellSize, spatialIndexMaxCellSize)
+	idx.resize(idx.baseCellSize)
+}
+
+func (idx *spatialIndex) resize(cellSize float64) {
+	idx.cellSize = cellSize
+	idx.cellMultiplier = 1.0 / cellSize
+	idx.numCols = int(math.Ceil(idx.world.width*idx.cellMultiplier)) + 1
+	idx.numRows = int(math.Ceil(idx.world.height*idx.cellMultiplier)) + 1
+	idx.cells = make([]spatialCell, idx.numCols*idx.numRows)
+}
+
+func (idx *spatialIndex) maybeResize(numAgents int) {
+	population := len(idx.cells) * spatialIndexCellPopulation
+	switch {
+	case numAgents > population && idx.cellSize/2 >= spatialIndexMinCellSize:
+		idx.resize(idx.cellSize / 2)
+	case numAgents*8 < population && idx.cellSize*2 <= idx.baseCellSize:
+		// The coarser grid has ~4 times less cells.
+		idx.resize(idx.cellSize * 2)
+	}
+}
+
+func (idx *spatialIndex) Rebuild() {
+	w := idx.world
+
+	idx.rebuildCreepClusters()
+
+	numAgents := 0
+	for _, c := range w.allColonies {
+		numAgents += c.agents.TotalNum()
+	}
+	idx.maybeResize(numAgents)
+
+	for i := range idx.cells {
+		cell := &idx.cells[i]
+		cell.agentColonies = cell.agentColonies[:0]
+		cell.cores = cell.cores[:0]
+		cell.numTurrets = 0
+	}
+
+	for _, c := range w.allColonies {
+		cell := &idx.cells[idx.cellIndex(c.pos)]
+		cell.cores = append(cell.cores, c)
+		c.agents.Each(func(a *colonyAgentNode) {
+			cell := &idx.cells[idx.cellIndex(a.pos)]
+			// Colonies are traversed one by one, so checking
+			// the last element is enough to keep the list unique.
+			if n := len(cell.agentColonies); n != 0 && cell.agentColonies[n-1] == c {
+				return
+			}
+			cell.agentColonies = append(cell.agentColonies, c)
+		})
+	}
+
+	for _, turret := range w.turrets {
+		idx.cells[idx.cellIndex(turret.pos)].numTurrets++
+	}
+
+	idx.coloniesDirty = false
+	idx.turretsDirty = false
+	idx.dirtyColonies = idx.dirtyColonies[:0]
+	idx.valid = true
+}
+
+func (idx *spatialIndex) rebuildCreepClusters() {
+	w := idx.world
+
+	w.fallbackCreepCluster = w.fallbackCreepCluster[:0]
+	for y := range w.creepClusters {
+		for x := range w.creepClusters[y] {
+			w.creepClusters[y][x] = w.creepClusters[y][x][:0]
+		}
+	}
+
+	for _, creep := range w.creeps {
+		if creep.marked == 0 {
+			x, y, ok := w.GetPosCell(creep.pos)
+			if ok && y < len(w.creepClusters) {
+				if x < len(w.creepClusters[y]) {
+					w.creepClusters[y][x] = append(w.creepClusters[y][x], creep)
+					continue
+				}
+			}
+		}
+		w.fallbackCreepCluster = append(w.fallbackCreepCluster, creep)
+	}
+}
+
+// MarkColonyDirty should be called when colony agents set
+// is changed (or agents moved) in a way that index can't predict.
+func (idx *spatialIndex) MarkColonyDirty(c *colonyCoreNode) {
+	for _, other := range idx.dirtyColonies {
+		if other == c {
+			return
+		}
+	}
+	idx.dirtyColonies = append(idx.dirtyColonies, c)
+}
+
+func (idx *spatialIndex) MarkColoniesDirty() { idx.coloniesDirty = true }
+
+func (idx *spatialIndex) MarkTurretsDirty() { idx.turretsDirty = true }
+
+// MayHaveColonyAgents reports whether there could be any agents
+// of the specified colony inside the circle.
+func (idx *spatialIndex) MayHaveColonyAgents(c *colonyCoreNode, pos gmath.Vec, r float64) bool {
+	if !idx.valid {
+		return true
+	}
+	for _, other := range idx.dirtyColonies {
+		if other == c {
+			return true
+		}
+	}
+	found := false
+	idx.walkCells(pos, r, func(cell *spatialCell) bool {
+		for _, other := range cell.agentColonies {
+			if other == c {
+				found = true
+				return true
+			}
+		}
+		return false
+	})
+	return found
+}
+
+// MayHaveTurrets reports whether there could be any turrets inside the circle.
+func (idx *spatialIndex) MayHaveTurrets(pos gmath.Vec, r float64) bool {
+	if !idx.valid || idx.turretsDirty {
+		return true
+	}
+	found := false
+	idx.walkCells(pos, r, func(cell *spatialCell) bool {
+		found = cell.numTurrets != 0
+		return found
+	})
+	return found
+}
+
+// MayHaveColonies reports whether there could be any colony cores inside the circle.
+func (idx *spatialIndex) MayHaveColonies(pos gmath.Vec, r float64) bool {
+	if !idx.valid || idx.coloniesDirty {
+		return true
+	}
+	found := false
+	idx.walkCells(pos, r, func(cell *spatialCell) bool {
+		found = len(cell.cores) != 0
+		return found
+	})
+	return found
+}
+
+// WalkCreepsWithRand calls f for the creeps that may be inside the circle
+// until it returns true; that creep is returned.
+// f should do the precise range check.
+// The traversal order is randomized unless rand is nil.
+func (idx *spatialIndex) WalkCreepsWithRand(rand *gmath.Rand, pos gmath.Vec, r float64, f func(creep *creepNode) bool) *creepNode {
+	w := idx.world
+
+	creeps := w.creeps
+	if len(creeps) == 0 {
+		return nil
+	}
+
+	startX, startY, endX, endY := w.findSearchClusters(pos, r)
+	numStepsX := endX - startX + 1
+	numStepsY := endY - startY + 1
+
+	// Now decide the sector traversal order.
+	// This is needed to add some randomness to the target selection.
+	dx := 1
+	dy := 1
+	if rand != nil {
+		if rand.Bool() {
+			dx = -1
+			startX = endX
+		}
+		if rand.Bool() {
+			dy = -1
+			startY = endY
+		}
+	}
+
+	for i, y := 0, startY; i < numStepsY; i, y = i+1, y+dy {
+		for j, x := 0, startX; j < numStepsX; j, x = j+1, x+dx {
+			clusterCreeps := w.creepClusters[y][x]
+			if creep := randIterate(rand, clusterCreeps, f); creep != nil {
+				return creep
+			}
+		}
+	}
+
+	// New creeps are created outside of the map, so they end up
+	// in the fallback cluster that includes everything that is out of bounds.
+	if len(w.fallbackCreepCluster) != 0 {
+		return randIterate(rand, w.fallbackCreepCluster, f)
+	}
+	return nil
+}
+
+func (idx *spatialIndex) walkCells(pos gmath.Vec, r float64, f func(cell *spatialCell) bool) {
+	r += spatialIndexPad
+	startX, startY := idx.cellCoord(gmath.Vec{X: pos.X - r, Y: pos.Y - r})
+	endX, endY := idx.cellCoord(gmath.Vec{X: pos.X + r, Y: pos.Y + r})
+	for y := startY; y <= endY; y++ {
+		row := y * idx.numCols
+		for x := startX; x <= endX; x++ {
+			if f(&idx.cells[row+x]) {
+				return
+			}
+		}
+	}
+}
+
+func (idx *spatialIndex) cellCoord(pos gmath.Vec) (int, int) {
+	// Out of bounds positions are clamped to the border cells.
+	// Both the objects and the query rects are clamped in the same way,
+	// so it doesn't make the index less precise.
+	x := gmath.Clamp(int(math.Floor(pos.X*idx.cellMultiplier)), 0, idx.numCols-1)
+	y := gmath.Clamp(int(math.Floor(pos.Y*idx.cellMultiplier)), 0, idx.numRows-1)
+	return x, y
+}
+
+func (idx *spatialIndex) cellIndex(pos gmath.Vec) int {
+	x, y := idx.cellCoord(pos)
+	return y*idx.numCols + x
+}
@@ -0,0 +1,161 @@
+package staging
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+func TestSpatialIndexTurrets(t *testing.T) {
+	world := &worldState{
+		width:  2000,
+		height: 1000,
+	}
+	world.turrets = []*colonyAgentNode{
+		{pos: gmath.Vec{X: 100, Y: 100}},
+		{pos: gmath.Vec{X: 1900, Y: 900}},
+		{pos: gmath.Vec{X: -50, Y: 500}},
+	}
+	world.spatial.Init(world)
+
+	// The index is not built yet, so every query is a "maybe".
+	if !world.spatial.MayHaveTurrets(gmath.Vec{X: 1000, Y: 500}, 10) {
+		t.Fatal("unbuilt index should always report a possible match")
+	}
+
+	world.spatial.Rebuild()
+
+	tests := []struct {
+		pos  gmath.Vec
+		r    float64
+		want bool
+	}{
+		{gmath.Vec{X: 100, Y: 100}, 0, true},
+		{gmath.Vec{X: 250, Y: 100}, 100, true},
+		{gmath.Vec{X: 1000, Y: 500}, 10, false},
+		{gmath.Vec{X: 1000, Y: 500}, 900, true},
+		{gmath.Vec{X: 10, Y: 500}, 10, true},
+		{gmath.Vec{X: 1500, Y: 500}, 100, false},
+		{gmath.Vec{X: 1900, Y: 750}, 100, true},
+	}
+	for i, test := range tests {
+		have := world.spatial.MayHaveTurrets(test.pos, test.r)
+		if have != test.want {
+			t.Fatalf("test[%d]: pos=%v r=%.2f:\nhave: %v\nwant: %v",
+				i, test.pos, test.r, have, test.want)
+		}
+	}
+
+	world.spatial.MarkTurretsDirty()
+	if !world.spatial.MayHaveTurrets(gmath.Vec{X: 1000, Y: 500}, 10) {
+		t.Fatal("dirty index should always report a possible match")
+	}
+}
+
+func TestSpatialIndexCreeps(t *testing.T) {
+	world := &worldState{
+		width:  800,
+		height: 800,
+	}
+	world.creeps = []*creepNode{
+		{pos: gmath.Vec{X: 50, Y: 50}},
+		{pos: gmath.Vec{X: 750, Y: 750}},
+		{pos: gmath.Vec{X: -100, Y: 400}},
+		{pos: gmath.Vec{X: 400, Y: 400}, marked: 1},
+	}
+	world.spatial.Init(world)
+	world.spatial.Rebuild()
+
+	tests := []struct {
+		pos  gmath.Vec
+		r    float64
+		want []int
+	}{
+		{gmath.Vec{X: 50, Y: 50}, 10, []int{0}},
+		{gmath.Vec{X: 750, Y: 700}, 100, []int{1}},
+		{gmath.Vec{X: 400, Y: 400}, 200, []int{3}},
+		{gmath.Vec{X: 0, Y: 400}, 150, []int{2}},
+		{gmath.Vec{X: 400, Y: 100}, 50, nil},
+		{gmath.Vec{X: 400, Y: 400}, 1000, []int{0, 1, 2, 3}},
+	}
+	for i, test := range tests {
+		var have []int
+		world.spatial.WalkCreepsWithRand(nil, test.pos, test.r, func(creep *creepNode) bool {
+			if creep.pos.DistanceTo(test.pos) <= test.r {
+				have = append(have, xslices.Index(world.creeps, creep))
+			}
+			return false
+		})
+		sort.Ints(have)
+		if !reflect.DeepEqual(have, test.want) {
+			t.Fatalf("test[%d]: pos=%v r=%.2f:\nhave: %v\nwant: %v",
+				i, test.pos, test.r, have, test.want)
+		}
+	}
+
+	// The found creep is returned.
+	creep := world.spatial.WalkCreepsWithRand(nil, gmath.Vec{X: 700, Y: 700}, 100, func(creep *creepNode) bool {
+		return creep.pos.DistanceTo(gmath.Vec{X: 700, Y: 700}) <= 100
+	})
+	if creep != world.creeps[1] {
+		t.Fatalf("unexpected creep: %v", creep)
+	}
+}
+
+func TestSpatialIndexResize(t *testing.T) {
+	world := &worldState{
+		width:  4000,
+		height: 4000,
+	}
+	rand := &gmath.Rand{}
+	rand.SetSeed(1)
+	colony := &colonyCoreNode{
+		pos:    gmath.Vec{X: 2000, Y: 2000},
+		agents: newColonyAgentContainer(rand),
+	}
+	world.allColonies = []*colonyCoreNode{colony}
+	world.spatial.Init(world)
+	world.spatial.Rebuild()
+
+	baseCellSize := world.spatial.cellSize
+	stats := &gamedata.AgentStats{}
+	var agents []*colonyAgentNode
+	for i := 0; i < len(world.spatial.cells)*spatialIndexCellPopulation+1; i++ {
+		a := &colonyAgentNode{
+			pos:   gmath.Vec{X: float64(i % 4000), Y: 2000},
+			stats: stats,
+		}
+		agents = append(agents, a)
+		colony.agents.Add(a)
+	}
+	world.spatial.Rebuild()
+	if world.spatial.cellSize != baseCellSize/2 {
+		t.Fatalf("the grid is not refined: cell size is %v (base %v)", world.spatial.cellSize, baseCellSize)
+	}
+	if !world.spatial.MayHaveColonyAgents(colony, gmath.Vec{X: 3000, Y: 2000}, 10) {
+		t.Fatal("the refined index lost the agents")
+	}
+
+	colony.agents = newColonyAgentContainer(rand)
+	colony.agents.Add(agents[0])
+	world.spatial.Rebuild()
+	if world.spatial.cellSize != baseCellSize {
+		t.Fatalf("the grid is not coarsened: cell size is %v (base %v)", world.spatial.cellSize, baseCellSize)
+	}
+	if world.spatial.MayHaveColonyAgents(colony, gmath.Vec{X: 3000, Y: 2000}, 10) {
+		t.Fatal("the coarsened index reports the removed agents")
+	}
+	if !world.spatial.MayHaveColonyAgents(colony, gmath.Vec{X: 0, Y: 2000}, 10) {
+		t.Fatal("the coarsened index lost the agent")
+	}
+
+	// It never becomes coarser than the base grid.
+	world.spatial.Rebuild()
+	if world.spatial.cellSize != baseCellSize {
+		t.Fatalf("cell size is %v (base %v)", world.spatial.cellSize, baseCellSize)
+	}
+}
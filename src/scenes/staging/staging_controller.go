@@ -22,6 +22,7 @@ import (
 	"github.com/quasilyte/roboden-game/gamedata"
 	"github.com/quasilyte/roboden-game/gameinput"
 	"github.com/quasilyte/roboden-game/gameui"
+	"github.com/quasilyte/roboden-game/netplay"
 	"github.com/quasilyte/roboden-game/pathing"
 	"github.com/quasilyte/roboden-game/serverapi"
 	"github.com/quasilyte/roboden-game/session"
@@ -29,14 +30,8 @@ import (
 	"github.com/quasilyte/roboden-game/viewport"
 )
 
-type weatherState int
-
-const (
-	weatherStateFadeIn weatherState = iota
-	weatherStateNormal
-	weatherStateFadeOut
-	weatherStateCooldown
-)
+// netChecksumTicks is a number of ticks between the network game state checksums.
+const netChecksumTicks = 300
 
 type Controller struct {
 	state *session.State
@@ -49,6 +44,20 @@ type Controller struct {
 
 	fogOfWar *ebiten.Image
 
+	// observerMode is enabled for the spectator-only games
+	// if the player opted in. The observer sees the entire map.
+	observerMode bool
+	choiceGens   []*choiceGenerator
+
+	// lockstep is only set for the network games.
+	// The local player is a humanPlayer while the remote one is a networkPlayer.
+	lockstep       *netplay.Lockstep
+	netStatusLabel *ge.Label
+	netStalled     bool
+	netStallTime   float64
+	netChecksum    uint32
+	netChecksums   []int
+
 	musicPlayer *musicPlayer
 
 	exitNotices       []*messageNode
@@ -62,20 +71,25 @@ type Controller struct {
 
 	tutorialManager *tutorialManager
 
-	arenaManager *arenaManager
-	nodeRunner   *nodeRunner
+	arenaManager  *arenaManager
+	customManager *customModeManager
+	nodeRunner    *nodeRunner
 
 	debugInfo        *ge.Label
 	debugUpdateDelay float64
 
-	weatherState  weatherState
-	weatherPower  float64
-	weatherTicker float64
+	weather *weatherManager
 
 	controllerTick    int
 	replayActions     [][]serverapi.PlayerAction
 	replayCheckpoints []int
 
+	timeline *timelineRecorder
+	stats    *statsRecorder
+	minimap  *minimapRecorder
+
+	eventHandlers []func(GameEvent)
+
 	EventBeforeLeaveScene gsignal.Event[gsignal.Void]
 }
 
@@ -93,6 +107,65 @@ func NewController(state *session.State, config gamedata.LevelConfig, back ge.Sc
 	}
 }
 
+// EnableTimeline makes the controller record the game events timeline.
+// It should be called before the scene is initialized.
+func (c *Controller) EnableTimeline() {
+	c.timeline = &timelineRecorder{}
+}
+
+// GetTimeline returns the events recorded so far.
+// Returns nil if the timeline recording is not enabled.
+func (c *Controller) GetTimeline() []TimelineEvent {
+	if c.timeline == nil {
+		return nil
+	}
+	return c.timeline.events
+}
+
+// EnableStats makes the controller collect the GameStats.
+// The normal games and the replay playbacks collect them anyway.
+// It should be called before the scene is initialized.
+func (c *Controller) EnableStats() {
+	c.stats = &statsRecorder{}
+}
+
+// GetStats returns the stats collected so far.
+// Returns nil if the stats recording is not enabled.
+func (c *Controller) GetStats() *GameStats {
+	if c.stats == nil {
+		return nil
+	}
+	return &c.stats.stats
+}
+
+// EnableMinimap makes the controller record the MinimapRecording.
+// It should be called before the scene is initialized.
+func (c *Controller) EnableMinimap(config MinimapConfig) {
+	c.minimap = &minimapRecorder{config: config}
+	c.SubscribeEvents(c.minimap.HandleEvent)
+}
+
+// GetMinimap returns the minimap recorded so far.
+// Returns nil if the minimap recording is not enabled.
+func (c *Controller) GetMinimap() *MinimapRecording {
+	if c.minimap == nil {
+		return nil
+	}
+	return &c.minimap.recording
+}
+
+// SubscribeEvents adds a simulation events handler.
+// It should be called before the scene is initialized.
+func (c *Controller) SubscribeEvents(h func(GameEvent)) {
+	c.eventHandlers = append(c.eventHandlers, h)
+}
+
+// SetLockstep turns the game into a network game.
+// It should be called before the scene is initialized.
+func (c *Controller) SetLockstep(l *netplay.Lockstep) {
+	c.lockstep = l
+}
+
 func (c *Controller) SetReplayActions(replay serverapi.GameReplay) {
 	c.replayActions = replay.Actions
 	c.replayCheckpoints = replay.Debug.Checkpoints
@@ -301,6 +374,8 @@ func (c *Controller) doInit(scene *ge.Scene) {
 	c.musicPlayer = newMusicPlayer(scene)
 	c.musicPlayer.Start()
 
+	c.observerMode = c.state.Persistent.Settings.ObserverMode && c.isSpectatorGame()
+
 	if c.state.CPUProfile != "" {
 		f, err := os.Create(c.state.CPUProfile)
 		if err != nil {
@@ -317,32 +392,7 @@ func (c *Controller) doInit(scene *ge.Scene) {
 		c.state.MemProfileWriter = f
 	}
 
-	var worldWidth float64
-	var worldHeight float64
-	switch c.config.WorldSize {
-	case 0:
-		worldWidth = 1856
-	case 1:
-		worldWidth = 2368
-	case 2:
-		worldWidth = 2880
-	case 3:
-		worldWidth = 3392
-	}
-	worldHeight = worldWidth
-	switch gamedata.WorldShape(c.config.WorldShape) {
-	case gamedata.WorldHorizontal:
-		worldWidth += float64(512 * (c.config.WorldSize + 1))
-		worldHeight = 1088
-	case gamedata.WorldVertical:
-		worldWidth = 1280
-		// Can't have a world width less than a max screen width.
-		// Otherwise that would create a buggy camera experience.
-		if worldWidth < gamedata.MaxDisplayWidth() {
-			panic("new display ratio is added without adjusting the vertical world camera size")
-		}
-		worldHeight += float64(512 * (c.config.WorldSize + 1))
-	}
+	worldWidth, worldHeight := gamedata.WorldDimensions(c.config.WorldSize, gamedata.WorldShape(c.config.WorldShape))
 	c.viewportWorld = &viewport.World{
 		Width:  worldWidth,
 		Height: worldHeight,
@@ -379,6 +429,7 @@ func (c *Controller) doInit(scene *ge.Scene) {
 		pathgrid:             pathing.NewGrid(c.viewportWorld.Width, c.viewportWorld.Height, 0),
 		config:               &c.config,
 		gameSettings:         &c.state.Persistent.Settings,
+		diodeStyle:           gameui.NewDiodeStyle(&c.state.Persistent.Settings),
 		deviceInfo:           c.state.Device,
 		hintsMode:            c.state.Persistent.Settings.HintMode,
 		debugLogs:            c.state.Persistent.Settings.DebugLogs,
@@ -434,6 +485,7 @@ func (c *Controller) doInit(scene *ge.Scene) {
 	world.creepCoordinator = newCreepCoordinator(world)
 	world.bfs = pathing.NewGreedyBFS(world.pathgrid.Size())
 	c.world = world
+	world.events.handlers = c.eventHandlers
 	world.Init()
 
 	world.EventCheckDefeatState.Connect(c, func(gsignal.Void) {
@@ -444,7 +496,7 @@ func (c *Controller) doInit(scene *ge.Scene) {
 		world.EventCameraShake.Connect(c, c.onCameraShake)
 	}
 
-	if c.config.FogOfWar && !c.world.simulation {
+	if c.config.FogOfWar && !c.world.simulation && !c.observerMode {
 		fogOfWar := ebiten.NewImage(int(world.width), int(world.height))
 		gedraw.DrawRect(fogOfWar, world.rect, color.RGBA{A: 255})
 		c.world.stage.SetFogOfWar(fogOfWar)
@@ -458,22 +510,7 @@ func (c *Controller) doInit(scene *ge.Scene) {
 		// Use local rand for the tileset generation.
 		// Otherwise, we'll get incorrect results during the simulation.
 		bg = ge.NewTiledBackground(scene.Context())
-		var img resource.ImageID
-		var tileset resource.RawID
-		switch gamedata.EnvironmentKind(c.config.Environment) {
-		case gamedata.EnvMoon:
-			img = assets.ImageBackgroundTiles
-			tileset = assets.RawTilesJSON
-		case gamedata.EnvForest:
-			img = assets.ImageBackgroundForestTiles
-			tileset = assets.RawForestTilesJSON
-		case gamedata.EnvInferno:
-			img = assets.ImageBackgroundInfernoTiles
-			tileset = assets.RawInfernoTilesJSON
-		case gamedata.EnvSnow:
-			img = assets.ImageBackgroundSnowTiles
-			tileset = assets.RawSnowTilesJSON
-		}
+		img, tileset := gamedata.EnvironmentTileset(gamedata.EnvironmentKind(c.config.Environment))
 		bg.LoadTilesetWithRand(scene.Context(), &localRand, c.viewportWorld.Width, c.viewportWorld.Height, img, tileset)
 	}
 	c.world.stage.SetBackground(bg)
@@ -513,6 +550,24 @@ func (c *Controller) doInit(scene *ge.Scene) {
 
 	c.nodeRunner.creepCoordinator = world.creepCoordinator
 
+	if c.timeline != nil {
+		c.timeline.Init(c.world)
+	}
+	switch c.config.ExecMode {
+	case gamedata.ExecuteNormal, gamedata.ExecuteReplay:
+		if c.stats == nil {
+			c.EnableStats()
+		}
+	}
+	if c.stats != nil {
+		c.stats.Init(c.world)
+		c.world.stats = c.stats
+	}
+	if c.minimap != nil {
+		c.minimap.Init(c.world)
+		c.world.minimap = c.minimap
+	}
+
 	c.world.EventColonyCreated.Connect(c, func(colony *colonyCoreNode) {
 		if c.fogOfWar != nil {
 			c.updateFogOfWar(colony.pos)
@@ -564,6 +619,27 @@ func (c *Controller) doInit(scene *ge.Scene) {
 	case gamedata.ModeBlitz:
 		blitz = newBlitzManager(world)
 		c.nodeRunner.AddObject(blitz)
+	case gamedata.ModeCustom:
+		c.customManager = newCustomModeManager(world)
+		c.nodeRunner.AddObject(c.customManager)
+		c.customManager.EventVictory.Connect(c, c.onVictoryTrigger)
+	}
+
+	if world.config.AdaptiveDifficulty && (c.config.GameMode == gamedata.ModeClassic || c.config.GameMode == gamedata.ModeBlitz) {
+		world.difficulty = newDifficultyManager(world)
+		c.nodeRunner.AddObject(world.difficulty)
+	}
+
+	if kind := weatherKindForEnv(world.envKind); kind != weatherNone {
+		switch {
+		case world.config.WeatherEffects:
+			c.weather = newWeatherManager(world, kind, true)
+			c.nodeRunner.AddObject(c.weather)
+		case world.weatherEnabled:
+			// The visual-only weather is not a part of the simulation.
+			c.weather = newWeatherManager(world, kind, false)
+			c.weather.Init(scene)
+		}
 	}
 
 	c.createPlayers()
@@ -608,6 +684,22 @@ func (c *Controller) doInit(scene *ge.Scene) {
 		}
 	}
 
+	if c.config.Mission != nil && len(c.world.humanPlayers) != 0 {
+		c.world.humanPlayers[0].GetState().messageManager.AddMessage(queuedMessageInfo{
+			text:  scene.Dict().Get("campaign", c.config.Mission.Name, "briefing"),
+			timer: 20,
+		})
+	}
+
+	if c.lockstep != nil {
+		c.netStatusLabel = ge.NewLabel(assets.BitmapFont2)
+		c.netStatusLabel.SetColorScaleRGBA(0xe7, 0x4d, 0x4d, 0xff)
+		c.netStatusLabel.Width = c.world.cameras[0].Rect.Width()
+		c.netStatusLabel.AlignHorizontal = ge.AlignHorizontalCenter
+		c.netStatusLabel.Pos.Offset = gmath.Vec{Y: 48}
+		c.world.cameras[0].UI.AddGraphicsAbove(c.netStatusLabel)
+	}
+
 	if c.state.Persistent.Settings.ShowFPS || c.state.Persistent.Settings.ShowTimer {
 		if len(c.world.cameras) != 0 {
 			c.debugInfo = ge.NewLabel(assets.BitmapFont1)
@@ -640,6 +732,9 @@ func (c *Controller) doInit(scene *ge.Scene) {
 	if c.arenaManager != nil {
 		c.arenaManager.LateInit()
 	}
+	if c.customManager != nil {
+		c.customManager.LateInit()
+	}
 }
 
 func (c *Controller) runBlitzSetup(blitz *blitzManager) {
@@ -718,7 +813,15 @@ func (c *Controller) updateFogOfWar(pos gmath.Vec) {
 
 	var options ebiten.DrawImageOptions
 	options.CompositeMode = ebiten.CompositeModeDestinationOut
-	options.GeoM.Translate(pos.X-c.world.visionRadius, pos.Y-c.world.visionRadius)
+	visionRadius := c.world.visionRadius
+	if c.world.weatherKind == weatherAshStorm {
+		// The ash storm makes the vision radius up to 2 times smaller.
+		// It also reduces the drones targeting range, see isValidCreepTarget.
+		scale := 1.0 - 0.5*c.world.weatherPower
+		visionRadius *= scale
+		options.GeoM.Scale(scale, scale)
+	}
+	options.GeoM.Translate(pos.X-visionRadius, pos.Y-visionRadius)
 	c.fogOfWar.DrawImage(c.world.visionCircle, &options)
 }
 
@@ -728,7 +831,6 @@ func (c *Controller) createCameraManager(viewportWorld *viewport.World, main boo
 		cam.ScreenPos.X = c.scene.Context().ScreenWidth / 2
 	}
 	if c.world.weatherEnabled {
-		c.weatherTicker = math.Round(c.world.localRand.FloatRange(50, 70))
 		shader := c.scene.Context().Loader.LoadShader(assets.ShaderSnow).Data
 		cam.WeatherShader = shader
 		cam.WeatherShaderParams = map[string]any{
@@ -754,7 +856,8 @@ func (c *Controller) createCamera(viewportWorld *viewport.World) *viewport.Camer
 	width := c.scene.Context().ScreenWidth
 	height := c.scene.Context().ScreenHeight
 	if c.config.PlayersMode == serverapi.PmodeTwoPlayers {
-		if c.config.ExecMode != gamedata.ExecuteReplay {
+		// The network game players have their own screens.
+		if c.config.ExecMode != gamedata.ExecuteReplay && c.lockstep == nil {
 			width /= 2
 		}
 	}
@@ -786,13 +889,20 @@ func (c *Controller) createHumanPlayer(pstate *playerState, choiceGen *choiceGen
 		return p
 	}
 
+	if c.isRemotePlayer(i) {
+		return newNetworkPlayer(c.world, pstate, choiceGen, c.lockstep)
+	}
+
 	var creepsState *creepsPlayerState
 	if i == 0 && c.world.config.GameMode == gamedata.ModeReverse {
 		creepsState = c.world.creepsPlayerState
 	}
 
-	playerInput := c.state.GetInput(i)
-	pstate.camera = c.createCameraManager(c.viewportWorld, i == 0, playerInput)
+	// The human player is not always the first one (see the reverse mode),
+	// but the first human player always gets the first input and the main screen.
+	inputIndex := len(c.world.humanPlayers)
+	playerInput := c.state.GetInput(inputIndex)
+	pstate.camera = c.createCameraManager(c.viewportWorld, inputIndex == 0, playerInput)
 	pstate.messageManager = newMessageManager(c.world, pstate.camera.Camera)
 	cursor := c.createPlayerCursorNode(pstate, playerInput)
 	human := newHumanPlayer(humanPlayerConfig{
@@ -802,6 +912,7 @@ func (c *Controller) createHumanPlayer(pstate *playerState, choiceGen *choiceGen
 		cursor:      cursor,
 		choiceGen:   choiceGen,
 		creepsState: creepsState,
+		lockstep:    c.lockstep,
 	})
 	c.world.humanPlayers = append(c.world.humanPlayers, human)
 
@@ -821,7 +932,7 @@ func (c *Controller) connectPlayerEvents(p *humanPlayer) {
 	p.EventFastForwardPressed.Connect(c, func(gsignal.Void) {
 		c.onFastForwardPressed()
 	})
-	if p.state.id == 0 {
+	if p == c.world.humanPlayers[0] {
 		p.EventRecipesToggled.Connect(c, func(visible bool) {
 			c.world.result.OpenedEvolutionTab = true
 			if c.debugInfo != nil {
@@ -843,6 +954,14 @@ func (c *Controller) connectPlayerEvents(p *humanPlayer) {
 	}
 }
 
+func (c *Controller) findBotPersonality(botIndex int) *gamedata.BotPersonality {
+	name := ""
+	if botIndex >= 0 && botIndex < len(c.config.BotPersonalities) {
+		name = c.config.BotPersonalities[botIndex]
+	}
+	return gamedata.ResolveBotPersonality(name)
+}
+
 func (c *Controller) createPlayers() {
 	c.world.players = make([]player, 0, len(c.config.Players))
 	hasMouseInput := false
@@ -850,6 +969,7 @@ func (c *Controller) createPlayers() {
 	hasPlayerWithCamera := false
 	isSimulation := c.world.config.ExecMode == gamedata.ExecuteReplay ||
 		c.world.config.ExecMode == gamedata.ExecuteSimulation
+	numBots := 0
 	for i, pk := range c.config.Players {
 		var creepsState *creepsPlayerState
 		if i == 0 && c.world.config.GameMode == gamedata.ModeReverse {
@@ -867,27 +987,34 @@ func (c *Controller) createPlayers() {
 		switch pk {
 		case gamedata.PlayerHuman:
 			hasPlayers = true
-			if !isSimulation {
+			if !isSimulation && !c.isRemotePlayer(i) {
 				hasPlayerWithCamera = true
-				playerInput := c.state.GetInput(i)
+				playerInput := c.state.GetInput(len(c.world.humanPlayers))
 				if playerInput.HasMouseInput() {
 					hasMouseInput = true
 				}
 			}
 			if c.config.GameMode == gamedata.ModeBlitz {
-				p = newComputerPlayer(c.world, pstate, choiceGen)
+				// Blitz mode players don't have a personality selection.
+				p = newComputerPlayer(c.world, pstate, choiceGen, c.findBotPersonality(-1))
 			} else {
 				p = c.createHumanPlayer(pstate, choiceGen)
 			}
 
 		case gamedata.PlayerComputer:
-			p = newComputerPlayer(c.world, pstate, choiceGen)
+			if creepsState != nil {
+				p = newCreepsComputerPlayer(c.world, pstate, choiceGen)
+				break
+			}
+			p = newComputerPlayer(c.world, pstate, choiceGen, c.findBotPersonality(numBots))
+			numBots++
 		default:
 			panic(fmt.Sprintf("unexpected player kind: %d", pk))
 		}
 
 		choiceGen.player = p
 		c.nodeRunner.AddObject(choiceGen)
+		c.choiceGens = append(c.choiceGens, choiceGen)
 		c.world.players = append(c.world.players, p)
 	}
 
@@ -910,6 +1037,10 @@ func (c *Controller) createPlayers() {
 		c.world.humanPlayers = append(c.world.humanPlayers, spectator)
 		c.connectPlayerEvents(spectator)
 		c.scene.AddObject(cursor)
+		if c.observerMode {
+			spectator.observer = newObserverOverlayNode(c.world, pstate.camera.Camera, c.choiceGens)
+			c.scene.AddObject(spectator.observer)
+		}
 	}
 
 	if c.world.config.ExecMode == gamedata.ExecuteNormal {
@@ -919,6 +1050,35 @@ func (c *Controller) createPlayers() {
 	}
 }
 
+// isRemotePlayer reports whether the player is controlled by the other side of the network game.
+// For the spectators, both players are remote.
+func (c *Controller) isRemotePlayer(playerID int) bool {
+	return c.lockstep != nil && playerID != c.lockstep.LocalPlayer()
+}
+
+// isNetSpectator reports whether the local player watches the network game.
+func (c *Controller) isNetSpectator() bool {
+	return c.lockstep != nil && c.lockstep.IsSpectator()
+}
+
+// isSpectatorGame reports whether the local player only watches the game.
+// It's true for the bot-only games, for the replays and
+// for the network games joined as a spectator.
+func (c *Controller) isSpectatorGame() bool {
+	switch c.config.ExecMode {
+	case gamedata.ExecuteReplay:
+		return true
+	case gamedata.ExecuteNormal:
+		if c.isNetSpectator() {
+			return true
+		}
+		return c.config.PlayersMode == serverapi.PmodeSingleBot ||
+			c.config.PlayersMode == serverapi.PmodeTwoBots
+	default:
+		return false
+	}
+}
+
 func (c *Controller) createPlayerCursorNode(pstate *playerState, h *gameinput.Handler) *gameui.CursorNode {
 	cursorRect := pstate.camera.Rect
 	cursorRect.Min = cursorRect.Min.Add(pstate.camera.ScreenPos)
@@ -1014,22 +1174,24 @@ func (c *Controller) executeAction(choice selectedChoice) bool {
 		return true
 	}
 
-	var relocationPos gmath.Vec
 	switch choice.Option.special {
 	case specialAttack:
 		c.launchAttack(selectedColony)
 		return true
 	case specialChoiceMoveColony:
-		maxDist := selectedColony.MaxFlyDistance()
-		clickPos := choice.Pos
-		clickDist := selectedColony.pos.DistanceTo(clickPos)
-		dist := gmath.ClampMax(clickDist, maxDist)
-		relocationVec := selectedColony.pos.VecTowards(clickPos, 1).Mulf(dist)
-		relocationPos = correctedPos(c.world.rect, relocationVec.Add(selectedColony.pos), 128)
-		if c.world.coreDesign == gamedata.HiveCoreStats {
-			return c.setHiveRallyPoint(selectedColony, clickPos, relocationPos)
-		}
-		return c.launchRelocation(selectedColony, dist, relocationPos)
+		if choice.Queued {
+			return c.queueColonyMove(selectedColony, choice.Pos)
+		}
+		// A new move order cancels the queued ones.
+		selectedColony.relocationQueue = selectedColony.relocationQueue[:0]
+		selectedColony.relocationQueueDist = 0
+		if selectedColony.mode != colonyModeNormal {
+			// The current relocation can't be interrupted,
+			// so the new destination becomes the next one.
+			selectedColony.relocationQueue = append(selectedColony.relocationQueue, choice.Pos)
+			return true
+		}
+		return c.moveColony(selectedColony, choice.Pos)
 	case specialIncreaseRadius:
 		c.world.result.RadiusIncreases++
 		selectedColony.realRadius += c.world.rand.FloatRange(24, 32)
@@ -1272,6 +1434,7 @@ func (c *Controller) doBossAttack() bool {
 }
 
 func (c *Controller) doSendCreeps() {
+	numUnits := 0
 	for dir := range c.world.creepsPlayerState.attackSides {
 		cg := c.world.creepsPlayerState.attackSides[dir]
 		for i := range cg.groups {
@@ -1279,9 +1442,11 @@ func (c *Controller) doSendCreeps() {
 			if len(g.units) == 0 {
 				continue
 			}
+			numUnits += len(g.units)
 			sendCreeps(c.world, g)
 		}
 	}
+	c.world.events.WaveStarted(numUnits)
 
 	c.world.creepsPlayerState.ResetGroups()
 	c.world.creepsPlayerState.RecalcMaxCost()
@@ -1300,6 +1465,9 @@ func (c *Controller) saveExecutedAction(choice selectedChoice) {
 	switch choice.Option.special {
 	case specialChoiceMoveColony, specialSendCenturions:
 		kind = serverapi.ActionMove
+		if choice.Queued {
+			kind = serverapi.ActionQueueMove
+		}
 	}
 	colonyIndex := -1
 	if selectedColony != nil {
@@ -1311,6 +1479,13 @@ func (c *Controller) saveExecutedAction(choice selectedChoice) {
 		SelectedColony: colonyIndex,
 		Tick:           c.nodeRunner.ticks,
 	}
+	if len(choice.Group) != 0 {
+		a.Kind = a.Kind.Grouped()
+		a.Group = make([]int, len(choice.Group))
+		for i, colony := range choice.Group {
+			a.Group[i] = c.world.GetColonyIndex(colony)
+		}
+	}
 	pstate.replay = append(pstate.replay, a)
 }
 
@@ -1319,8 +1494,20 @@ func (c *Controller) onChoiceSelected(choice selectedChoice) {
 		c.tutorialManager.OnChoice(choice)
 	}
 
-	ok := c.executeAction(choice)
-	if c.config.ExecMode == gamedata.ExecuteNormal && isHumanPlayer(choice.Player) {
+	var ok bool
+	if len(choice.Group) != 0 {
+		ok = c.executeGroupAction(choice)
+	} else {
+		ok = c.executeAction(choice)
+	}
+	if c.timeline != nil {
+		c.timeline.AddChoice(choice, ok)
+	}
+	c.world.events.CardChosen(choice)
+	if c.stats != nil && ok {
+		c.stats.AddChoice(choice)
+	}
+	if c.config.ExecMode == gamedata.ExecuteNormal && (isHumanPlayer(choice.Player) || isNetworkPlayer(choice.Player)) {
 		if ok || choice.Option.special != specialChoiceMoveColony {
 			c.saveExecutedAction(choice)
 		}
@@ -1444,6 +1631,64 @@ func (c *Controller) findRelocationPoint(core *colonyCoreNode, dist float64, dst
 	return gmath.Vec{}
 }
 
+func (c *Controller) moveColony(colony *colonyCoreNode, clickPos gmath.Vec) bool {
+	maxDist := colony.MaxFlyDistance()
+	clickDist := colony.pos.DistanceTo(clickPos)
+	dist := gmath.ClampMax(clickDist, maxDist)
+	relocationVec := colony.pos.VecTowards(clickPos, 1).Mulf(dist)
+	relocationPos := correctedPos(c.world.rect, relocationVec.Add(colony.pos), 128)
+	if c.world.coreDesign == gamedata.HiveCoreStats {
+		return c.setHiveRallyPoint(colony, clickPos, relocationPos)
+	}
+	return c.launchRelocation(colony, dist, relocationPos)
+}
+
+func (c *Controller) queueColonyMove(colony *colonyCoreNode, pos gmath.Vec) bool {
+	if c.world.coreDesign == gamedata.HiveCoreStats {
+		// Hive cores never move, only their rally point is changed.
+		// There is nothing to wait for, so the queue is not used.
+		return c.moveColony(colony, pos)
+	}
+	if colony.mode == colonyModeNormal && len(colony.relocationQueue) == 0 {
+		// Nothing to wait for, this is the first leg.
+		return c.moveColony(colony, pos)
+	}
+	if len(colony.relocationQueue) >= maxRelocationQueueLen {
+		return false
+	}
+	colony.relocationQueue = append(colony.relocationQueue, pos)
+	return true
+}
+
+// advanceRelocationQueues sends the idle colonies to their next queued destinations.
+func (c *Controller) advanceRelocationQueues() {
+	for _, colony := range c.world.allColonies {
+		if colony.mode != colonyModeNormal || len(colony.relocationQueue) == 0 {
+			continue
+		}
+		dst := colony.relocationQueue[0]
+		dist := colony.pos.DistanceTo(dst)
+		// A distant destination can take several relocations to reach.
+		// If the colony can't get any closer (the relocation point search
+		// could pick a spot that is not on the way), the destination is skipped.
+		if colony.relocationQueueDist != 0 && dist > colony.relocationQueueDist-32 {
+			c.popRelocationQueue(colony)
+			continue
+		}
+		lastLeg := dist <= colony.MaxFlyDistance()
+		if !c.moveColony(colony, dst) || lastLeg {
+			c.popRelocationQueue(colony)
+			continue
+		}
+		colony.relocationQueueDist = dist
+	}
+}
+
+func (c *Controller) popRelocationQueue(colony *colonyCoreNode) {
+	colony.relocationQueue = colony.relocationQueue[1:]
+	colony.relocationQueueDist = 0
+}
+
 func (c *Controller) setHiveRallyPoint(core *colonyCoreNode, origDst, dst gmath.Vec) bool {
 	if origDst.DistanceTo(core.GetRallyPoint()) <= 20 {
 		return false
@@ -1496,6 +1741,14 @@ func (c *Controller) prepareBattleResults() {
 	c.world.result.Score = calcScore(c.world)
 	c.world.result.DifficultyScore = c.config.DifficultyScore
 	c.world.result.DronePointsAllocated = c.config.DronePointsAllocated
+
+	if c.stats != nil {
+		c.stats.AddSample()
+		c.world.result.Stats = &c.stats.stats
+	}
+	if c.minimap != nil {
+		c.minimap.AddSnapshot()
+	}
 }
 
 func (c *Controller) defeat() {
@@ -1511,8 +1764,19 @@ func (c *Controller) defeat() {
 		c.gameFinished = true
 		switch c.config.ExecMode {
 		case gamedata.ExecuteNormal:
+			if c.config.Map != nil {
+				c.leaveMapTestPlay()
+				return
+			}
+			if c.isNetSpectator() {
+				// The spectator doesn't get any rewards.
+				c.leaveScene(newStatsController(c.state, c.world.result.Stats, c.backController))
+				return
+			}
 			c.leaveScene(newResultsController(c.state, &c.config, c.backController, c.world.result))
-		case gamedata.ExecuteDemo, gamedata.ExecuteReplay:
+		case gamedata.ExecuteReplay:
+			c.leaveScene(newStatsController(c.state, c.world.result.Stats, c.backController))
+		case gamedata.ExecuteDemo:
 			c.leaveScene(c.backController)
 		}
 	})
@@ -1532,8 +1796,21 @@ func (c *Controller) victory() {
 		c.gameFinished = true
 		switch c.config.ExecMode {
 		case gamedata.ExecuteNormal:
+			if c.config.Map != nil {
+				c.leaveMapTestPlay()
+				return
+			}
+			if c.isNetSpectator() {
+				// The spectator doesn't get any rewards.
+				c.leaveScene(newStatsController(c.state, c.world.result.Stats, c.backController))
+				return
+			}
 			t3set := map[gamedata.ColonyAgentKind]struct{}{}
-			for _, colony := range c.world.players[0].GetState().colonies {
+			colonyPlayer := c.world.players[0]
+			if c.config.GameMode == gamedata.ModeReverse {
+				colonyPlayer = c.world.players[1]
+			}
+			for _, colony := range colonyPlayer.GetState().colonies {
 				colony.agents.Each(func(a *colonyAgentNode) {
 					if a.stats.Tier != 3 {
 						return
@@ -1545,17 +1822,26 @@ func (c *Controller) victory() {
 				c.world.result.Tier3Drones = append(c.world.result.Tier3Drones, k)
 			}
 			c.leaveScene(newResultsController(c.state, &c.config, c.backController, c.world.result))
-		case gamedata.ExecuteDemo, gamedata.ExecuteReplay:
+		case gamedata.ExecuteReplay:
+			c.leaveScene(newStatsController(c.state, c.world.result.Stats, c.backController))
+		case gamedata.ExecuteDemo:
 			c.leaveScene(c.backController)
 		}
 	})
 }
 
+// leaveMapTestPlay finishes the map editor test play.
+// These games can't be replayed without the map,
+// so only the battle stats are shown.
+func (c *Controller) leaveMapTestPlay() {
+	c.leaveScene(newStatsController(c.state, c.world.result.Stats, c.backController))
+}
+
 func (c *Controller) sharedActionIsJustPressed(a input.Action) bool {
 	if c.state.GetInput(0).ActionIsJustPressed(a) {
 		return true
 	}
-	if c.world.config.PlayersMode == serverapi.PmodeTwoPlayers {
+	if c.world.config.PlayersMode == serverapi.PmodeTwoPlayers && c.lockstep == nil {
 		if c.state.GetInput(1).ActionIsJustPressed(a) {
 			return true
 		}
@@ -1653,14 +1939,27 @@ func (c *Controller) isDefeatState() bool {
 			}
 		}
 
+	case gamedata.ModeCustom:
+		for _, p := range c.world.players {
+			if len(p.GetState().colonies) == 0 {
+				return true
+			}
+		}
+		if c.customManager.IsTimeUp() {
+			return true
+		}
+
 	case gamedata.ModeReverse:
-		if c.config.PlayersMode == serverapi.PmodeTwoPlayers {
+		creepsBot := gamedata.IsCreepsBotGame(c.config.ReplayLevelConfig)
+		if c.config.PlayersMode == serverapi.PmodeTwoPlayers || creepsBot {
 			colonyPlayer := c.world.players[1]
 			if len(colonyPlayer.GetState().colonies) == 0 {
 				return true
 			}
 		}
-		if c.world.boss == nil {
+		// When playing against the creeps bot, destroying
+		// the dreadnought is a victory condition.
+		if c.world.boss == nil && !creepsBot {
 			return true
 		}
 	}
@@ -1689,7 +1988,7 @@ func (c *Controller) checkVictory() {
 	case gamedata.ModeClassic:
 		victory = c.world.boss == nil
 
-	case gamedata.ModeArena, gamedata.ModeTutorial:
+	case gamedata.ModeArena, gamedata.ModeTutorial, gamedata.ModeCustom:
 		// Do nothing. This mode is ended with a trigger.
 
 	case gamedata.ModeInfArena:
@@ -1698,7 +1997,10 @@ func (c *Controller) checkVictory() {
 	case gamedata.ModeReverse:
 		// In two players mode, the only way to finish a match
 		// is to trigger a defeat to either players.
-		if c.config.PlayersMode == serverapi.PmodeSinglePlayer {
+		switch {
+		case gamedata.IsCreepsBotGame(c.config.ReplayLevelConfig):
+			victory = c.world.boss == nil
+		case c.config.PlayersMode == serverapi.PmodeSinglePlayer:
 			colonyPlayer := c.world.players[1]
 			victory = len(colonyPlayer.GetState().colonies) == 0
 		}
@@ -1777,35 +2079,9 @@ func (c *Controller) updateWeather(delta float64) {
 	}
 
 	shaderDelta := float64(c.nodeRunner.NumSteps()) * c.nodeRunner.ComputeDelta(delta)
-	if !c.nodeRunner.IsPaused() {
-		switch c.weatherState {
-		case weatherStateFadeIn:
-			c.weatherPower = gmath.ClampMax(c.weatherPower+0.12*shaderDelta, 1.0)
-			c.weatherTicker -= shaderDelta
-			if c.weatherPower == 1.0 {
-				c.weatherState = weatherStateNormal
-			}
-		case weatherStateNormal:
-			c.weatherTicker -= shaderDelta
-			if c.weatherTicker <= 0 {
-				c.weatherState = weatherStateFadeOut
-			}
-		case weatherStateFadeOut:
-			c.weatherPower = gmath.ClampMin(c.weatherPower-0.04*shaderDelta, 0.0)
-			c.weatherTicker -= shaderDelta
-			if c.weatherPower == 0.0 {
-				c.weatherState = weatherStateCooldown
-				c.weatherTicker = math.Round(c.world.localRand.FloatRange(30, 200))
-			}
-		case weatherStateCooldown:
-			c.weatherTicker -= shaderDelta
-			if c.weatherTicker <= 0 {
-				c.weatherState = weatherStateFadeIn
-				c.weatherTicker = math.Round(c.world.localRand.FloatRange(40, 70))
-			}
-		}
+	if !c.weather.gameplay && !c.nodeRunner.IsPaused() {
+		c.weather.Update(shaderDelta)
 	}
-
 	for _, cam := range c.world.cameras {
 		c.updateCameraWeather(cam, shaderDelta)
 	}
@@ -1821,8 +2097,8 @@ func (c *Controller) updateCameraWeather(cam *viewport.Camera, shaderDelta float
 		if c.nodeRunner.IsPaused() {
 			break
 		}
-		cam.WeatherShaderParams["Time"] = float32(c.weatherTicker)
-		cam.WeatherShaderParams["Power"] = float32(c.weatherPower)
+		cam.WeatherShaderParams["Time"] = float32(c.weather.Ticker())
+		cam.WeatherShaderParams["Power"] = float32(c.weather.Power())
 	}
 }
 
@@ -1854,9 +2130,15 @@ func (c *Controller) Update(delta float64) {
 	if !c.nodeRunner.IsPaused() {
 		computedDelta := c.nodeRunner.ComputeDelta(delta)
 		for i := 0; i < c.nodeRunner.NumSteps(); i++ {
+			if !c.syncLockstep() {
+				break
+			}
 			c.runUpdateStep(computedDelta, delta)
 		}
 	}
+	if c.lockstep != nil {
+		c.updateNetStatus(delta)
+	}
 	for _, p := range c.world.humanPlayers {
 		p.AfterUpdateStep()
 	}
@@ -1866,8 +2148,42 @@ func (c *Controller) Update(delta float64) {
 	}
 }
 
+// syncLockstep reports whether the next update step can be executed.
+// The network game can't advance until the remote player actions are received.
+func (c *Controller) syncLockstep() bool {
+	if c.lockstep == nil || !c.world.gameStarted {
+		return true
+	}
+	ok := c.lockstep.Sync(c.nodeRunner.ticks, c.netChecksums)
+	c.netStalled = !ok
+	return ok
+}
+
+func (c *Controller) updateNetStatus(delta float64) {
+	d := c.scene.Dict()
+	switch c.lockstep.Status() {
+	case netplay.LockstepDisconnected:
+		c.netStatusLabel.Text = d.Get("game.net.disconnected")
+	case netplay.LockstepDesync:
+		c.netStatusLabel.Text = fmt.Sprintf(d.Get("game.net.desync_f"), c.lockstep.DesyncChecksum())
+	default:
+		// Short stalls are expected; don't make the label blink.
+		if c.netStalled && !c.nodeRunner.IsPaused() {
+			c.netStallTime += delta
+		} else {
+			c.netStallTime = 0
+		}
+		if c.netStallTime > 0.3 {
+			c.netStatusLabel.Text = d.Get("game.net.waiting")
+		} else {
+			c.netStatusLabel.Text = ""
+		}
+	}
+}
+
 func (c *Controller) runUpdateStep(computedDelta, delta float64) {
 	c.nodeRunner.Update(delta)
+	c.advanceRelocationQueues()
 
 	checkpoint := false
 	if len(c.world.result.DebugCheckpoints) < 48 {
@@ -1892,8 +2208,22 @@ func (c *Controller) runUpdateStep(computedDelta, delta float64) {
 		}
 	}
 
+	if c.lockstep != nil && c.world.gameStarted && c.controllerTick%netChecksumTicks == 0 {
+		// Unlike the debug checkpoints, these are not limited:
+		// a desync can happen at any point of the game.
+		c.netChecksum = c.world.stateChecksum(c.netChecksum)
+		c.netChecksums = append(c.netChecksums, int(c.netChecksum))
+	}
+
 	c.controllerTick++
 
+	if c.stats != nil && c.world.gameStarted {
+		c.stats.Update()
+	}
+	if c.minimap != nil && c.world.gameStarted {
+		c.minimap.Update()
+	}
+
 	if !c.transitionQueued {
 		c.victoryCheckDelay = gmath.ClampMin(c.victoryCheckDelay-delta, 0)
 		if c.victoryCheckDelay == 0 {
@@ -1946,6 +2276,10 @@ func (c *Controller) leaveScene(controller ge.SceneController) {
 		h.input.EventGamepadDisconnected.Reset()
 	}
 
+	if c.lockstep != nil {
+		c.lockstep.Close()
+	}
+
 	c.scene.Audio().PauseCurrentMusic()
 	c.scene.Context().ChangeScene(controller)
 }
@@ -0,0 +1,418 @@
+package staging
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui/eui"
+	"github.com/quasilyte/roboden-game/session"
+	"github.com/quasilyte/roboden-game/timeutil"
+)
+
+const (
+	statsGraphWidth  = 640
+	statsGraphHeight = 280
+
+	// The legend is drawn to the right of the plot area.
+	statsLegendWidth = 150
+)
+
+type statsGraphKind int
+
+const (
+	statsGraphResources statsGraphKind = iota
+	statsGraphDronesByFaction
+	statsGraphDronesByTier
+	statsGraphCreepsKilled
+	statsGraphCardsPicked
+	statsGraphColonyHealth
+	numStatsGraphKinds
+)
+
+var statsGraphKeys = [...]string{
+	statsGraphResources:       "resources",
+	statsGraphDronesByFaction: "drones_by_faction",
+	statsGraphDronesByTier:    "drones_by_tier",
+	statsGraphCreepsKilled:    "creeps_killed",
+	statsGraphCardsPicked:     "cards_picked",
+	statsGraphColonyHealth:    "colony_health",
+}
+
+// statsSeriesColors are used for the series that have no natural color.
+var statsSeriesColors = [...]color.RGBA{
+	ge.RGB(0x9dd793),
+	ge.RGB(0xe7c34b),
+	ge.RGB(0x6cb6e0),
+	ge.RGB(0xe06c6c),
+	ge.RGB(0xc58ae0),
+	ge.RGB(0xe0a26c),
+	ge.RGB(0x6ce0c8),
+	ge.RGB(0xd0d0d0),
+}
+
+type statsSeries struct {
+	name  string
+	color color.RGBA
+
+	// values are indexed by the sample index.
+	// NaN means "no value" (e.g. the colony didn't exist at that moment).
+	values []float64
+}
+
+// statsController is a post-game statistics dashboard.
+// It shows the GameStats as the graphs over time.
+type statsController struct {
+	state *session.State
+	stats *GameStats
+
+	backController ge.SceneController
+
+	players      []int
+	playerIndex  int
+	graphKind    int
+	graphImage   *ebiten.Image
+	graphWidget  *widget.Graphic
+	factionColor func(gamedata.FactionTag) color.RGBA
+
+	scene *ge.Scene
+}
+
+func newStatsController(state *session.State, stats *GameStats, backController ge.SceneController) *statsController {
+	return &statsController{
+		state:          state,
+		stats:          stats,
+		backController: backController,
+	}
+}
+
+func (c *statsController) Init(scene *ge.Scene) {
+	c.scene = scene
+
+	palette := gamedata.FactionPalette(c.state.Persistent.Settings.Graphics.FactionPalette)
+	c.factionColor = func(tag gamedata.FactionTag) color.RGBA {
+		return gamedata.FactionColor(tag, palette)
+	}
+
+	for _, sample := range c.stats.Samples {
+		for _, colony := range sample.Colonies {
+			if !xslices.Contains(c.players, colony.Player) {
+				c.players = append(c.players, colony.Player)
+			}
+		}
+	}
+	sort.Ints(c.players)
+
+	c.initUI()
+}
+
+func (c *statsController) Update(delta float64) {
+	c.state.MenuInput.Update()
+	if c.state.MenuInput.ActionIsJustPressed(controls.ActionMenuBack) {
+		c.back()
+		return
+	}
+}
+
+func (c *statsController) initUI() {
+	eui.AddBackground(c.state.BackgroundImage, c.scene)
+	uiResources := c.state.Resources.UI
+
+	root := eui.NewAnchorContainer()
+	rowContainer := eui.NewRowLayoutContainerWithMinWidth(statsGraphWidth, 10, nil)
+	root.AddChild(rowContainer)
+
+	d := c.scene.Dict()
+
+	titleLabel := eui.NewCenteredLabel(d.Get("menu.stats.title"), assets.BitmapFont3)
+	rowContainer.AddChild(titleLabel)
+
+	{
+		graphNames := make([]string, numStatsGraphKinds)
+		for i := range graphNames {
+			graphNames[i] = d.Get("menu.stats." + statsGraphKeys[i])
+		}
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			Resources:  uiResources,
+			Input:      c.state.MenuInput,
+			Value:      &c.graphKind,
+			Label:      d.Get("menu.stats.graph"),
+			ValueNames: graphNames,
+			OnPressed:  c.redrawGraph,
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+	}
+
+	if len(c.players) > 1 {
+		playerNames := make([]string, len(c.players))
+		for i, id := range c.players {
+			playerNames[i] = fmt.Sprintf(d.Get("menu.stats.player_f"), id+1)
+		}
+		b := eui.NewSelectButton(eui.SelectButtonConfig{
+			Resources:  uiResources,
+			Input:      c.state.MenuInput,
+			Value:      &c.playerIndex,
+			Label:      d.Get("menu.stats.player"),
+			ValueNames: playerNames,
+			OnPressed:  c.redrawGraph,
+		})
+		c.scene.AddObject(b)
+		rowContainer.AddChild(b.Widget)
+	}
+
+	panel := eui.NewTextPanel(uiResources, 0, 0)
+	c.graphImage = ebiten.NewImage(statsGraphWidth, statsGraphHeight)
+	c.graphWidget = widget.NewGraphic(widget.GraphicOpts.Image(c.graphImage))
+	panel.AddChild(c.graphWidget)
+	rowContainer.AddChild(panel)
+	c.redrawGraph()
+
+	rowContainer.AddChild(eui.NewButton(uiResources, c.scene, d.Get("menu.back"), func() {
+		c.back()
+	}))
+
+	uiObject := eui.NewSceneObject(root)
+	c.scene.AddGraphics(uiObject)
+	c.scene.AddObject(uiObject)
+}
+
+func (c *statsController) selectedPlayer() int {
+	if len(c.players) == 0 {
+		return 0
+	}
+	return c.players[c.playerIndex]
+}
+
+func (c *statsController) redrawGraph() {
+	d := c.scene.Dict()
+	series := c.buildSeries(statsGraphKind(c.graphKind))
+
+	dst := c.graphImage
+	dst.Clear()
+
+	plotWidth := float32(statsGraphWidth - statsLegendWidth - 16)
+	plotHeight := float32(statsGraphHeight - 40)
+	const plotX = 8
+	const plotY = 16
+	axisColor := eui.NormalTextColor
+
+	if len(c.stats.Samples) == 0 || len(series) == 0 {
+		text.Draw(dst, d.Get("menu.stats.no_data"), assets.BitmapFont1, plotX, plotY+16, axisColor)
+		return
+	}
+
+	maxValue := 1.0
+	for _, s := range series {
+		for _, v := range s.values {
+			if !math.IsNaN(v) {
+				maxValue = math.Max(maxValue, v)
+			}
+		}
+	}
+	maxTime := c.stats.Samples[len(c.stats.Samples)-1].Time
+	if maxTime == 0 {
+		maxTime = 1
+	}
+
+	toScreen := func(t, v float64) (float32, float32) {
+		x := plotX + float32(t/maxTime)*plotWidth
+		y := plotY + plotHeight - float32(v/maxValue)*plotHeight
+		return x, y
+	}
+
+	vector.StrokeLine(dst, plotX, plotY, plotX, plotY+plotHeight, 1, axisColor, false)
+	vector.StrokeLine(dst, plotX, plotY+plotHeight, plotX+plotWidth, plotY+plotHeight, 1, axisColor, false)
+
+	for _, s := range series {
+		prevSet := false
+		var prevX, prevY float32
+		for i, v := range s.values {
+			if math.IsNaN(v) {
+				prevSet = false
+				continue
+			}
+			x, y := toScreen(c.stats.Samples[i].Time, v)
+			if prevSet {
+				vector.StrokeLine(dst, prevX, prevY, x, y, 2, s.color, false)
+			}
+			prevX, prevY = x, y
+			prevSet = true
+		}
+	}
+
+	maxValueLabel := strconv.Itoa(int(math.Round(maxValue)))
+	if statsGraphKind(c.graphKind) == statsGraphColonyHealth {
+		maxValueLabel += "%"
+	}
+	text.Draw(dst, maxValueLabel, assets.BitmapFont1, plotX+4, plotY+8, axisColor)
+	text.Draw(dst, "0", assets.BitmapFont1, plotX, int(plotY+plotHeight)+16, axisColor)
+	timeLabel := timeutil.FormatDurationCompact(time.Duration(maxTime * float64(time.Second)))
+	timeLabelBounds := text.BoundString(assets.BitmapFont1, timeLabel)
+	text.Draw(dst, timeLabel, assets.BitmapFont1, int(plotX+plotWidth)-timeLabelBounds.Dx(), int(plotY+plotHeight)+16, axisColor)
+
+	legendX := float32(statsGraphWidth - statsLegendWidth)
+	legendY := float32(plotY)
+	for _, s := range series {
+		vector.DrawFilledRect(dst, legendX, legendY, 10, 10, s.color, false)
+		text.Draw(dst, s.name, assets.BitmapFont1, int(legendX)+16, int(legendY)+10, s.color)
+		legendY += 18
+		if legendY > statsGraphHeight-18 {
+			break
+		}
+	}
+}
+
+func (c *statsController) buildSeries(kind statsGraphKind) []statsSeries {
+	d := c.scene.Dict()
+	samples := c.stats.Samples
+	player := c.selectedPlayer()
+
+	newSeries := func(name string, clr color.RGBA) statsSeries {
+		s := statsSeries{name: name, color: clr, values: make([]float64, len(samples))}
+		for i := range s.values {
+			s.values[i] = math.NaN()
+		}
+		return s
+	}
+
+	switch kind {
+	case statsGraphResources, statsGraphColonyHealth:
+		// One series per colony.
+		var result []statsSeries
+		seriesIndex := map[int]int{}
+		for i, sample := range samples {
+			for _, colony := range sample.Colonies {
+				if colony.Player != player {
+					continue
+				}
+				j, ok := seriesIndex[colony.Colony]
+				if !ok {
+					j = len(result)
+					seriesIndex[colony.Colony] = j
+					clr := statsSeriesColors[j%len(statsSeriesColors)]
+					result = append(result, newSeries(fmt.Sprintf(d.Get("menu.stats.colony_f"), colony.Colony), clr))
+				}
+				if kind == statsGraphResources {
+					result[j].values[i] = colony.ResourcesGathered
+				} else {
+					result[j].values[i] = math.Round(colony.Health * 100)
+				}
+			}
+		}
+		return result
+
+	case statsGraphDronesByFaction:
+		factions := []gamedata.FactionTag{
+			gamedata.YellowFactionTag,
+			gamedata.RedFactionTag,
+			gamedata.GreenFactionTag,
+			gamedata.BlueFactionTag,
+		}
+		result := make([]statsSeries, len(factions))
+		for j, tag := range factions {
+			result[j] = newSeries(d.Get("menu.stats.faction."+StatsFactionNames[tag]), c.factionColor(tag))
+		}
+		for i, sample := range samples {
+			for j := range result {
+				result[j].values[i] = 0
+			}
+			for _, colony := range sample.Colonies {
+				if colony.Player != player {
+					continue
+				}
+				for j, tag := range factions {
+					result[j].values[i] += float64(colony.DronesByFaction[tag])
+				}
+			}
+		}
+		return result
+
+	case statsGraphDronesByTier:
+		result := make([]statsSeries, 3)
+		for j := range result {
+			result[j] = newSeries(fmt.Sprintf(d.Get("menu.stats.tier_f"), j+1), statsSeriesColors[j])
+		}
+		for i, sample := range samples {
+			for j := range result {
+				result[j].values[i] = 0
+			}
+			for _, colony := range sample.Colonies {
+				if colony.Player != player {
+					continue
+				}
+				for j := range result {
+					result[j].values[i] += float64(colony.DronesByTier[j])
+				}
+			}
+		}
+		return result
+
+	case statsGraphCreepsKilled:
+		// Only the most killed creep kinds are displayed.
+		const maxKinds = 8
+		final := samples[len(samples)-1].CreepsKilled
+		kinds := make([]string, 0, len(final))
+		for k := range final {
+			kinds = append(kinds, k)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			if final[kinds[i]] != final[kinds[j]] {
+				return final[kinds[i]] > final[kinds[j]]
+			}
+			return kinds[i] < kinds[j]
+		})
+		if len(kinds) > maxKinds {
+			kinds = kinds[:maxKinds]
+		}
+		result := make([]statsSeries, len(kinds))
+		for j, k := range kinds {
+			result[j] = newSeries(k, statsSeriesColors[j%len(statsSeriesColors)])
+			for i, sample := range samples {
+				result[j].values[i] = float64(sample.CreepsKilled[k])
+			}
+		}
+		return result
+
+	case statsGraphCardsPicked:
+		categories := []string{"yellow", "red", "green", "blue", "special", "creeps"}
+		var result []statsSeries
+		final := samples[len(samples)-1].CardsPicked
+		for j, category := range categories {
+			if final[category] == 0 {
+				continue
+			}
+			clr := statsSeriesColors[j%len(statsSeriesColors)]
+			name := d.Get("menu.stats.cards." + category)
+			if j < 4 {
+				clr = c.factionColor(gamedata.FactionTag(j + 1))
+				name = d.Get("menu.stats.faction." + category)
+			}
+			s := newSeries(name, clr)
+			for i, sample := range samples {
+				s.values[i] = float64(sample.CardsPicked[category])
+			}
+			result = append(result, s)
+		}
+		return result
+
+	default:
+		return nil
+	}
+}
+
+func (c *statsController) back() {
+	c.scene.Context().ChangeScene(c.backController)
+}
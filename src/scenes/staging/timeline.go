@@ -0,0 +1,138 @@
+package staging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TimelineEvent is a single annotated entry of the game timeline.
+//
+// The timeline is recorded only when it's requested via Controller.EnableTimeline.
+// It's mostly useful for the replay analysis tools.
+type TimelineEvent struct {
+	Tick int     `json:"tick"`
+	Time float64 `json:"time"`
+
+	Player int `json:"player"`
+
+	// Colony is a player-local colony ID; 0 means "no colony".
+	Colony int `json:"colony,omitempty"`
+
+	Kind TimelineEventKind `json:"kind"`
+
+	// Effect is a human-readable description of the event.
+	Effect string `json:"effect"`
+
+	Pos *[2]float64 `json:"pos,omitempty"`
+
+	// Resources is a colony resources amount at the moment of the event.
+	Resources int `json:"resources"`
+
+	// Failed is set for the actions that were selected,
+	// but couldn't be executed (like a colony relocation to a blocked spot).
+	Failed bool `json:"failed,omitempty"`
+}
+
+type TimelineEventKind string
+
+const (
+	TimelineCard          TimelineEventKind = "card"
+	TimelineSpecial       TimelineEventKind = "special"
+	TimelineMove          TimelineEventKind = "move"
+	TimelineColonyCreated TimelineEventKind = "colony_created"
+	TimelineColonyLost    TimelineEventKind = "colony_lost"
+	TimelineTier3Merge    TimelineEventKind = "tier3_merge"
+)
+
+type timelineRecorder struct {
+	world  *worldState
+	events []TimelineEvent
+}
+
+func (r *timelineRecorder) Init(world *worldState) {
+	r.world = world
+
+	world.EventColonyCreated.Connect(nil, func(colony *colonyCoreNode) {
+		r.addColonyEvent(TimelineColonyCreated, colony, colony.stats.Name)
+		colony.EventDestroyed.Connect(nil, func(colony *colonyCoreNode) {
+			r.addColonyEvent(TimelineColonyLost, colony, colony.stats.Name)
+		})
+	})
+	world.EventTier3DroneCreated.Connect(nil, func(a *colonyAgentNode) {
+		r.addColonyEvent(TimelineTier3Merge, a.colonyCore, fmt.Sprintf("%s (%s)", a.stats.Kind, strings.ToLower(a.faction.String())))
+	})
+}
+
+func (r *timelineRecorder) AddChoice(choice selectedChoice, ok bool) {
+	e := r.newEvent(choice.Player.GetState().id, choice.Colony)
+	e.Failed = !ok
+	e.Kind, e.Effect = describeChoice(choice)
+	switch choice.Option.special {
+	case specialChoiceMoveColony, specialSendCenturions:
+		e.Pos = &[2]float64{choice.Pos.X, choice.Pos.Y}
+	}
+	r.events = append(r.events, e)
+}
+
+func describeChoice(choice selectedChoice) (TimelineEventKind, string) {
+	switch special := choice.Option.special; {
+	case special == specialChoiceNone:
+		parts := make([]string, 0, len(choice.Option.effects)+1)
+		parts = append(parts, strings.ToLower(choice.Faction.String())+" faction")
+		for _, effect := range choice.Option.effects {
+			parts = append(parts, fmt.Sprintf("%s +%d%%", strings.ToLower(effect.priority.String()), int(effect.value*100)))
+		}
+		return TimelineCard, strings.Join(parts, ", ")
+
+	case special == specialChoiceMoveColony || special == specialSendCenturions:
+		return TimelineMove, special.String()
+
+	case special > _creepCardFirst && special < _creepCardLast:
+		return TimelineCard, fmt.Sprintf("%s (side %d)", special, choice.Option.direction)
+
+	default:
+		return TimelineSpecial, special.String()
+	}
+}
+
+func (r *timelineRecorder) addColonyEvent(kind TimelineEventKind, colony *colonyCoreNode, effect string) {
+	e := r.newEvent(colony.player.GetState().id, colony)
+	e.Kind = kind
+	e.Effect = effect
+	e.Pos = &[2]float64{colony.pos.X, colony.pos.Y}
+	r.events = append(r.events, e)
+}
+
+func (r *timelineRecorder) newEvent(playerID int, colony *colonyCoreNode) TimelineEvent {
+	e := TimelineEvent{
+		Tick:   r.world.nodeRunner.ticks,
+		Time:   r.world.nodeRunner.timePlayed,
+		Player: playerID,
+	}
+	if colony != nil {
+		e.Colony = colony.id
+		e.Resources = int(colony.resources)
+	}
+	return e
+}
+
+// FormatTimelineEvent returns a single-line text representation of the event.
+func FormatTimelineEvent(e TimelineEvent) string {
+	var sb strings.Builder
+	seconds := int(e.Time)
+	fmt.Fprintf(&sb, "%02d:%02d:%02d [tick %d] player %d", seconds/3600, (seconds/60)%60, seconds%60, e.Tick, e.Player)
+	if e.Colony != 0 {
+		fmt.Fprintf(&sb, " colony %d", e.Colony)
+	}
+	fmt.Fprintf(&sb, ": %s %s", e.Kind, e.Effect)
+	if e.Pos != nil {
+		fmt.Fprintf(&sb, " at (%d, %d)", int(e.Pos[0]), int(e.Pos[1]))
+	}
+	if e.Colony != 0 {
+		fmt.Fprintf(&sb, ", resources=%d", e.Resources)
+	}
+	if e.Failed {
+		sb.WriteString(" (failed)")
+	}
+	return sb.String()
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/quasilyte/ge"
 	"github.com/quasilyte/ge/input"
+	"github.com/quasilyte/ge/langs"
 	"github.com/quasilyte/gmath"
 	"github.com/quasilyte/gsignal"
 	"github.com/quasilyte/roboden-game/controls"
@@ -140,26 +141,7 @@ func (m *tooltipManager) OnHover(pos gmath.Vec) {
 	if !m.player.spectator && m.player.choiceGen.IsReady() && m.player.choiceWindow != nil {
 		choice := m.player.choiceWindow.GetChoiceUnderCursor(pos.Sub(m.player.state.camera.ScreenPos))
 		if choice != nil {
-			var hint string
-			if choice.option.special != specialChoiceNone {
-				if choice.option.special > _creepCardFirst && choice.option.special < _creepCardLast {
-					side := d.Get(sideName(choice.option.direction))
-					info := creepOptionInfoList[creepCardID(choice.option.special)]
-					hint = fmt.Sprintf(d.Get("game.hint.action.garrison_f"), side) + "\n" +
-						fmt.Sprintf("x%d %s", numCreepsPerCard(m.player.creepsState, info), d.Get("creep", info.stats.NameTag))
-				} else {
-					key := strings.ToLower(choice.option.special.String())
-					hint = d.Get("game.hint.action", key)
-				}
-			} else {
-				if len(choice.option.effects) == 1 {
-					hint = fmt.Sprintf(d.Get("game.hint.action.priorities1_f"), d.Get("game.choice", strings.ToLower(choice.option.effects[0].priority.String())))
-				} else if len(choice.option.effects) == 2 {
-					p1 := d.Get("game.choice", strings.ToLower(choice.option.effects[0].priority.String()))
-					p2 := d.Get("game.choice", strings.ToLower(choice.option.effects[1].priority.String()))
-					hint = fmt.Sprintf(d.Get("game.hint.action.priorities2_f"), p1, p2)
-				}
-			}
+			hint := choiceOptionHint(d, m.player.creepsState, choice.option)
 			if hint != "" {
 				m.createTooltip(pos, hint)
 				return
@@ -326,3 +308,27 @@ func (m *tooltipManager) createTooltip(pos gmath.Vec, s string) {
 	m.message = newScreenTutorialHintNode(camera, messagePos, gmath.Vec{}, s)
 	m.scene.AddObject(m.message)
 }
+
+// choiceOptionHint returns a human-readable card description.
+// The creepsState is only needed for the reverse mode creep cards.
+func choiceOptionHint(d *langs.Dictionary, creepsState *creepsPlayerState, option choiceOption) string {
+	if option.special != specialChoiceNone {
+		if option.special > _creepCardFirst && option.special < _creepCardLast {
+			side := d.Get(sideName(option.direction))
+			info := creepOptionInfoList[creepCardID(option.special)]
+			return fmt.Sprintf(d.Get("game.hint.action.garrison_f"), side) + "\n" +
+				fmt.Sprintf("x%d %s", numCreepsPerCard(creepsState, info), d.Get("creep", info.stats.NameTag))
+		}
+		key := strings.ToLower(option.special.String())
+		return d.Get("game.hint.action", key)
+	}
+	switch len(option.effects) {
+	case 1:
+		return fmt.Sprintf(d.Get("game.hint.action.priorities1_f"), d.Get("game.choice", strings.ToLower(option.effects[0].priority.String())))
+	case 2:
+		p1 := d.Get("game.choice", strings.ToLower(option.effects[0].priority.String()))
+		p2 := d.Get("game.choice", strings.ToLower(option.effects[1].priority.String()))
+		return fmt.Sprintf(d.Get("game.hint.action.priorities2_f"), p1, p2)
+	}
+	return ""
+}
@@ -85,6 +85,12 @@ func isValidCreepTarget(pos gmath.Vec, creep *creepNode, weapon *gamedata.Weapon
 	if creep.marked > 0 {
 		attackRangeSqr *= weapon.AttackRangeMarkMultiplier
 	}
+	if creep.world.weatherKind == weatherAshStorm {
+		// The targets are harder to spot inside the ash clouds:
+		// up to 25% shorter targeting range.
+		rangeMultiplier := 1.0 - 0.25*creep.world.weatherPower
+		attackRangeSqr *= rangeMultiplier * rangeMultiplier
+	}
 	if creep.pos.DistanceSquaredTo(pos) > attackRangeSqr {
 		return false
 	}
@@ -227,6 +233,17 @@ func randIterate[T any](rand *gmath.Rand, slice []T, f func(x T) bool) T {
 	return result
 }
 
+// randIterateSkip consumes the rand values exactly like randIterate
+// would do for this slice if the callback never returned true.
+// It's useful when the caller knows that no element would match.
+func randIterateSkip[T any](rand *gmath.Rand, slice []T) {
+	if rand == nil || len(slice) <= 1 {
+		return
+	}
+	rand.IntRange(0, len(slice)-1)
+	rand.Bool()
+}
+
 func randomSectorPos(rng *gmath.Rand, sector gmath.Rect) gmath.Vec {
 	return gmath.Vec{
 		X: rng.FloatRange(sector.Min.X, sector.Max.X),
@@ -0,0 +1,173 @@
+package staging
+
+import (
+	"math"
+
+	"github.com/quasilyte/ge"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+// weatherForecastTime is a number of seconds between
+// the forecast message and the weather effect start.
+const weatherForecastTime = 20.0
+
+type weatherKind int
+
+const (
+	weatherNone weatherKind = iota
+	weatherBlizzard
+	weatherAshStorm
+	weatherLowGravity
+)
+
+func (k weatherKind) String() string {
+	switch k {
+	case weatherBlizzard:
+		return "blizzard"
+	case weatherAshStorm:
+		return "ash_storm"
+	case weatherLowGravity:
+		return "low_gravity"
+	default:
+		return "none"
+	}
+}
+
+func weatherKindForEnv(env gamedata.EnvironmentKind) weatherKind {
+	switch env {
+	case gamedata.EnvSnow:
+		return weatherBlizzard
+	case gamedata.EnvInferno:
+		return weatherAshStorm
+	case gamedata.EnvMoon:
+		return weatherLowGravity
+	default:
+		return weatherNone
+	}
+}
+
+type weatherState int
+
+const (
+	weatherStateFadeIn weatherState = iota
+	weatherStateNormal
+	weatherStateFadeOut
+	weatherStateCooldown
+)
+
+// weatherManager runs the weather cycle.
+//
+// The gameplay weather is a part of the simulation:
+// it only uses the world rand, so the replays are reproducible.
+// The current effect is stored inside the world state
+// (see weatherKind and weatherPower), the effects are applied by the units.
+//
+// Without the weather effects option, the cycle is purely visual:
+// it uses the local rand and it's driven by the controller instead of the node runner.
+type weatherManager struct {
+	world *worldState
+	rand  *gmath.Rand
+
+	kind     weatherKind
+	gameplay bool
+	state    weatherState
+	power    float64
+	ticker   float64
+
+	forecastSent bool
+}
+
+func newWeatherManager(world *worldState, kind weatherKind, gameplay bool) *weatherManager {
+	m := &weatherManager{
+		world:    world,
+		kind:     kind,
+		gameplay: gameplay,
+		rand:     world.localRand,
+	}
+	if gameplay {
+		m.rand = world.rand
+	}
+	return m
+}
+
+func (m *weatherManager) IsDisposed() bool { return false }
+
+func (m *weatherManager) Init(scene *ge.Scene) {
+	if !m.gameplay {
+		// The visual weather is there right from the start.
+		m.state = weatherStateFadeIn
+		m.ticker = math.Round(m.rand.FloatRange(50, 70))
+		return
+	}
+	// Give the players some time before the first weather front arrives.
+	m.state = weatherStateCooldown
+	m.ticker = math.Round(m.rand.FloatRange(90, 150))
+	m.world.weatherKind = m.kind
+}
+
+func (m *weatherManager) Update(delta float64) {
+	switch m.state {
+	case weatherStateFadeIn:
+		m.power = gmath.ClampMax(m.power+0.12*delta, 1.0)
+		m.ticker -= delta
+		if m.power == 1.0 {
+			m.state = weatherStateNormal
+		}
+	case weatherStateNormal:
+		m.ticker -= delta
+		if m.ticker <= 0 {
+			m.state = weatherStateFadeOut
+		}
+	case weatherStateFadeOut:
+		m.power = gmath.ClampMin(m.power-0.04*delta, 0.0)
+		m.ticker -= delta
+		if m.power == 0.0 {
+			m.state = weatherStateCooldown
+			m.ticker = m.cooldownTime()
+			m.sendMessage("game.weather.end")
+		}
+	case weatherStateCooldown:
+		m.ticker -= delta
+		if !m.forecastSent && m.ticker <= weatherForecastTime {
+			m.forecastSent = true
+			m.sendMessage("game.weather.forecast")
+		}
+		if m.ticker <= 0 {
+			m.state = weatherStateFadeIn
+			m.ticker = math.Round(m.rand.FloatRange(40, 70))
+			m.forecastSent = false
+		}
+	}
+
+	if m.gameplay {
+		m.world.weatherPower = m.power
+	}
+}
+
+func (m *weatherManager) cooldownTime() float64 {
+	if !m.gameplay {
+		return math.Round(m.rand.FloatRange(30, 200))
+	}
+	// The gameplay cooldown is never shorter than the forecast time.
+	return math.Round(m.rand.FloatRange(45, 200))
+}
+
+// Ticker is used as a weather shader time parameter.
+func (m *weatherManager) Ticker() float64 { return m.ticker }
+
+// Power is used as a weather shader power parameter.
+func (m *weatherManager) Power() float64 { return m.power }
+
+func (m *weatherManager) sendMessage(key string) {
+	if m.world.simulation || !m.gameplay {
+		return
+	}
+	text := m.world.rootScene.Dict().Get(key, m.kind.String())
+	for _, p := range m.world.humanPlayers {
+		p.GetState().messageManager.AddMessage(queuedMessageInfo{
+			text:  text,
+			timer: 8,
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package staging
 
 import (
+	"encoding/binary"
+	"hash/fnv"
 	"image/color"
 	"math"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/quasilyte/gmath"
 	"github.com/quasilyte/gsignal"
 	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/gameui"
 	"github.com/quasilyte/roboden-game/pathing"
 	"github.com/quasilyte/roboden-game/serverapi"
 	"github.com/quasilyte/roboden-game/session"
@@ -66,13 +69,20 @@ type worldState struct {
 	creepClusters           [8][8][]*creepNode
 	fallbackCreepCluster    []*creepNode
 
+	spatial spatialIndex
+
 	graphicsSettings session.GraphicsSettings
+	diodeStyle       gameui.DiodeStyle
 	tier2recipes     []gamedata.AgentMergeRecipe
 	tier2recipeIndex map[gamedata.RecipeSubject][]gamedata.AgentMergeRecipe
 	turretDesign     *gamedata.AgentStats
 	coreDesign       *gamedata.ColonyCoreStats
 
+	// weatherEnabled controls the weather visuals;
+	// the gameplay effects are described by weatherKind and weatherPower.
 	weatherEnabled       bool
+	weatherKind          weatherKind
+	weatherPower         float64
 	hasForests           bool
 	droneLabels          bool
 	debugLogs            bool
@@ -97,6 +107,11 @@ type worldState struct {
 	oilRegenMultiplier             float64
 	creepProductionMultiplier      float64
 
+	// These are controlled by the adaptive difficulty (see difficultyManager).
+	// They're always 1.0 when this option is disabled.
+	creepProductionAdjustment float64
+	creepTechAdjustment       float64
+
 	superCreepChanceMultiplier float64
 
 	envKind gamedata.EnvironmentKind
@@ -137,6 +152,18 @@ type worldState struct {
 	EventColonyCreated         gsignal.Event[*colonyCoreNode]
 	EventCenturionCreated      gsignal.Event[*creepNode]
 	EventCrawlerFactoryCreated gsignal.Event[*creepNode]
+	EventTier3DroneCreated     gsignal.Event[*colonyAgentNode]
+
+	events eventBus
+
+	// stats is nil if the stats recording is disabled.
+	stats *statsRecorder
+
+	// minimap is nil if the minimap recording is disabled.
+	minimap *minimapRecorder
+
+	// difficulty is nil if the adaptive difficulty is disabled.
+	difficulty *difficultyManager
 
 	EventCameraShake gsignal.Event[CameraShakeData]
 }
@@ -226,6 +253,7 @@ func (w *worldState) UnmarkCell(coord pathing.GridCoord) {
 
 func (w *worldState) Init() {
 	w.gridCounters = make(map[int]uint8)
+	w.events.world = w
 	w.gameStarted = w.config.GameMode != gamedata.ModeBlitz
 
 	w.canFastForward = w.config.PlayersMode != serverapi.PmodeTwoPlayers ||
@@ -246,16 +274,7 @@ func (w *worldState) Init() {
 		}
 	}
 
-	w.creepClusterWidth = w.width / 8
-	w.creepClusterHeight = w.height / 8
-	w.creepClusterMultiplierX = 1.0 / w.creepClusterWidth
-	w.creepClusterMultiplierY = 1.0 / w.creepClusterHeight
-	w.fallbackCreepCluster = make([]*creepNode, 0, 32)
-	for y := range w.creepClusters {
-		for x := range w.creepClusters {
-			w.creepClusters[y][x] = make([]*creepNode, 0, 16)
-		}
-	}
+	w.spatial.Init(w)
 
 	w.projectilePool = make([]*projectileNode, 0, 128)
 	w.simulation = w.config.ExecMode == gamedata.ExecuteSimulation
@@ -305,6 +324,8 @@ func (w *worldState) Init() {
 	w.oilRegenMultiplier = float64(w.config.OilRegenRate) * 0.5
 	w.superCreepChanceMultiplier = 0.1 + (float64(w.config.ReverseSuperCreepRate) * 0.3)
 	w.creepProductionMultiplier = 1.0 + (float64(w.config.CreepProductionRate) * 0.2)
+	w.creepProductionAdjustment = 1.0
+	w.creepTechAdjustment = 1.0
 
 	if w.config.FogOfWar && w.config.ExecMode != gamedata.ExecuteSimulation {
 		w.visionRadius = 500.0
@@ -391,25 +412,7 @@ func (w *worldState) GetPingDst(src *humanPlayer) *humanPlayer {
 }
 
 func (w *worldState) Update() {
-	w.fallbackCreepCluster = w.fallbackCreepCluster[:0]
-	for y := range w.creepClusters {
-		for x := range w.creepClusters[y] {
-			w.creepClusters[y][x] = w.creepClusters[y][x][:0]
-		}
-	}
-
-	for _, creep := range w.creeps {
-		if creep.marked == 0 {
-			x, y, ok := w.GetPosCell(creep.pos)
-			if ok && y < len(w.creepClusters) {
-				if x < len(w.creepClusters[y]) {
-					w.creepClusters[y][x] = append(w.creepClusters[y][x], creep)
-					continue
-				}
-			}
-		}
-		w.fallbackCreepCluster = append(w.fallbackCreepCluster, creep)
-	}
+	w.spatial.Rebuild()
 }
 
 func (w *worldState) freeProjectileNode(p *projectileNode) {
@@ -430,10 +433,12 @@ func (w *worldState) NewColonyCoreNode(config colonyConfig) *colonyCoreNode {
 	n.EventDestroyed.Connect(nil, func(x *colonyCoreNode) {
 		w.allColonies = xslices.Remove(w.allColonies, x)
 		playerState.colonies = xslices.Remove(playerState.colonies, x)
+		playerState.removeFromControlGroups(x)
 		w.EventCheckDefeatState.Emit(gsignal.Void{})
 	})
 	w.allColonies = append(w.allColonies, n)
 	playerState.colonies = append(playerState.colonies, n)
+	w.spatial.MarkColoniesDirty()
 	w.EventColonyCreated.Emit(n)
 	return n
 }
@@ -613,6 +618,15 @@ func (w *worldState) findColonyAgent(agents []*colonyAgentNode, pos gmath.Vec, r
 	return nil
 }
 
+// skipColonyAgentSearch consumes the rand values
+// exactly like findColonyAgent would do for these agents.
+func (w *worldState) skipColonyAgentSearch(agents []*colonyAgentNode) {
+	if len(agents) == 0 {
+		return
+	}
+	w.rand.IntRange(0, len(agents)-1)
+}
+
 func (w *worldState) BuildPath(from, to gmath.Vec, l pathing.GridLayer) pathing.BuildPathResult {
 	return w.bfs.BuildPath(w.pathgrid, w.pathgrid.PosToCoord(from), w.pathgrid.PosToCoord(to), l)
 }
@@ -660,49 +674,11 @@ func (w *worldState) findSearchClusters(pos gmath.Vec, r float64) (startX, start
 }
 
 func (w *worldState) WalkCreepsWithRand(rand *gmath.Rand, pos gmath.Vec, r float64, f func(creep *creepNode) bool) *creepNode {
-	creeps := w.creeps
-	if len(creeps) == 0 {
-		return nil
-	}
-
-	startX, startY, endX, endY := w.findSearchClusters(pos, r)
-	numStepsX := endX - startX + 1
-	numStepsY := endY - startY + 1
-
-	// Now decide the sector traversal order.
-	// This is needed to add some randomness to the target selection.
-	dx := 1
-	dy := 1
-	if rand != nil {
-		if rand.Bool() {
-			dx = -1
-			startX = endX
-		}
-		if rand.Bool() {
-			dy = -1
-			startY = endY
-		}
-	}
-
-	for i, y := 0, startY; i < numStepsY; i, y = i+1, y+dy {
-		for j, x := 0, startX; j < numStepsX; j, x = j+1, x+dx {
-			clusterCreeps := w.creepClusters[y][x]
-			if creep := randIterate(rand, clusterCreeps, f); creep != nil {
-				return creep
-			}
-		}
-	}
-
-	// New creeps are created outside of the map, so they end up
-	// in the fallback cluster that includes everything that is out of bounds.
-	if len(w.fallbackCreepCluster) != 0 {
-		return randIterate(rand, w.fallbackCreepCluster, f)
-	}
-	return nil
+	return w.spatial.WalkCreepsWithRand(rand, pos, r, f)
 }
 
 func (w *worldState) WalkCreeps(pos gmath.Vec, r float64, f func(creep *creepNode) bool) *creepNode {
-	return w.WalkCreepsWithRand(w.rand, pos, r, f)
+	return w.spatial.WalkCreepsWithRand(w.rand, pos, r, f)
 }
 
 func (w *worldState) AllCenturionsReady() bool {
@@ -740,20 +716,25 @@ func (w *worldState) FindTargetableAgents(pos gmath.Vec, skipGround bool, r floa
 
 	if !skipGround {
 		// Turrets have the second highest targeting priority.
-		randIterate(w.rand, w.turrets, func(turret *colonyAgentNode) bool {
-			if turret.insideForest {
-				return false
-			}
-			distSqr := turret.pos.DistanceSquaredTo(pos)
-			if distSqr > radiusSqr {
+		if w.spatial.MayHaveTurrets(pos, r) {
+			randIterate(w.rand, w.turrets, func(turret *colonyAgentNode) bool {
+				if turret.insideForest {
+					return false
+				}
+				distSqr := turret.pos.DistanceSquaredTo(pos)
+				if distSqr > radiusSqr {
+					return false
+				}
+				if f(turret) {
+					found = true
+					return true
+				}
 				return false
-			}
-			if f(turret) {
-				found = true
-				return true
-			}
-			return false
-		})
+			})
+		} else {
+			// Keep the rand state in sync with a full scan.
+			randIterateSkip(w.rand, w.turrets)
+		}
 		if found {
 			return
 		}
@@ -784,6 +765,12 @@ func (w *worldState) FindTargetableAgents(pos gmath.Vec, skipGround bool, r floa
 	}
 
 	randIterate(w.rand, w.allColonies, func(c *colonyCoreNode) bool {
+		if !w.spatial.MayHaveColonyAgents(c, pos, r) {
+			// Keep the rand state in sync with a full scan.
+			w.skipColonyAgentSearch(c.agents.fighters)
+			w.skipColonyAgentSearch(c.agents.workers)
+			return false
+		}
 		skipIdling := false
 		dist := c.GetRallyPoint().DistanceTo(pos)
 		colonyEffectiveRadius := c.PatrolRadius()
@@ -826,3 +813,44 @@ func (w *worldState) onCreepTurretBuild() {
 	}
 	w.result.GroundControl = turrets >= 20
 }
+
+// stateChecksum hashes the simulation state that should be identical
+// for all network game participants.
+// The previous checksum is mixed in, so the checksums are rolling:
+// once the simulations diverge, all following checksums are different.
+//
+// It doesn't use any rand, so calculating it doesn't affect the simulation.
+func (w *worldState) stateChecksum(prev uint32) uint32 {
+	h := fnv.New32a()
+	var buf [8]byte
+	writeUint := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	writeFloat := func(v float64) {
+		writeUint(math.Float64bits(v))
+	}
+
+	writeUint(uint64(prev))
+	writeUint(uint64(w.nodeRunner.ticks))
+	writeUint(uint64(len(w.creeps)))
+	for _, creep := range w.creeps {
+		writeFloat(creep.pos.X)
+		writeFloat(creep.pos.Y)
+		writeFloat(creep.health)
+	}
+	writeUint(uint64(len(w.allColonies)))
+	for _, colony := range w.allColonies {
+		writeFloat(colony.pos.X)
+		writeFloat(colony.pos.Y)
+		writeFloat(colony.health)
+		writeFloat(colony.resources)
+		writeUint(uint64(colony.NumAgents()))
+	}
+	writeUint(uint64(len(w.turrets)))
+	writeUint(uint64(w.result.CreepsDefeated))
+	writeUint(uint64(w.result.DronesProduced))
+	writeFloat(w.result.ResourcesGathered)
+
+	return h.Sum32()
+}
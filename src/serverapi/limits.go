@@ -1,3 +1,10 @@
 package serverapi
 
 const MaxNameLength = 20
+
+// SyncKeyLength is a length of the cloud sync key.
+const SyncKeyLength = 16
+
+// MaxProgressListLength limits the number of entries
+// in every PlayerProgress list.
+const MaxProgressListLength = 512
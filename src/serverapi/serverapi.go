@@ -53,6 +53,10 @@ type PlayerAction struct {
 	Pos            [2]float64       `json:"pos"`
 	Kind           PlayerActionKind `json:"kind"`
 	SelectedColony int              `json:"selected_colony"`
+
+	// Group lists the colony indexes for the control group actions.
+	// It's empty for all other action kinds.
+	Group []int `json:"group,omitempty"`
 }
 
 const (
@@ -73,12 +77,47 @@ const (
 	ActionCard4
 	ActionCard5
 	ActionMove
+
+	// The group actions are applied to every colony listed in PlayerAction.Group.
+	ActionGroupCard1
+	ActionGroupCard2
+	ActionGroupCard3
+	ActionGroupCard4
+	ActionGroupCard5
+	ActionGroupMove
+
+	// ActionQueueMove adds a destination to the colony relocation queue.
+	ActionQueueMove
 )
 
+// IsGroup reports whether k is a control group action kind.
+func (k PlayerActionKind) IsGroup() bool {
+	return k >= ActionGroupCard1 && k <= ActionGroupMove
+}
+
+// Grouped returns a group action kind that matches k.
+// For the group kinds it returns k itself.
+func (k PlayerActionKind) Grouped() PlayerActionKind {
+	if k >= ActionCard1 && k <= ActionMove {
+		return k - ActionCard1 + ActionGroupCard1
+	}
+	return k
+}
+
+// Ungrouped returns a single colony action kind that matches k.
+// For the non-group kinds it returns k itself.
+func (k PlayerActionKind) Ungrouped() PlayerActionKind {
+	if k.IsGroup() {
+		return k - ActionGroupCard1 + ActionCard1
+	}
+	return k
+}
+
 type ReplayLevelConfig struct {
 	Resources      int  `json:"resources"`
 	GoldEnabled    bool `json:"gold_enabled"`
 	WeatherEnabled bool `json:"weather_enabled"`
+	WeatherEffects bool `json:"weather_effects"`
 
 	RawGameMode string `json:"mode"`
 
@@ -95,6 +134,9 @@ type ReplayLevelConfig struct {
 	EliteFleet        bool `json:"elite_fleet"`
 	IonMortars        bool `json:"ion_mortars"`
 
+	AdaptiveDifficulty bool `json:"adaptive_difficulty"`
+	ReverseCreepsBot   bool `json:"reverse_creeps_bot"`
+
 	InitialCreeps         int  `json:"initial_creeps"`
 	NumCreepBases         int  `json:"num_creep_bases"`
 	CreepDifficulty       int  `json:"creep_difficulty"`
@@ -103,6 +145,7 @@ type ReplayLevelConfig struct {
 	CreepProductionRate   int  `json:"creep_production_rate"`
 	TechProgressRate      int  `json:"tech_progress_rate"`
 	ReverseSuperCreepRate int  `json:"reverse_super_creep_rate"`
+	ReverseBotDifficulty  int  `json:"reverse_bot_difficulty"`
 	BossDifficulty        int  `json:"boss_difficulty"`
 	ArenaProgression      int  `json:"arena_progression"`
 	GameSpeed             int  `json:"game_speed"`
@@ -125,6 +168,55 @@ type ReplayLevelConfig struct {
 
 	TurretDesign string `json:"turret_design"`
 	CoreDesign   string `json:"core_design"`
+
+	// BotPersonalities are assigned to the computer players in their order.
+	// An empty (or missing) name means the default personality.
+	BotPersonalities []string `json:"bot_personalities,omitempty"`
+
+	// CustomRules are only used by the "custom" game mode.
+	CustomRules *CustomModeRules `json:"custom_rules,omitempty"`
+}
+
+// CustomModeRules describe the victory and defeat conditions of the "custom" mode.
+//
+// The game is won when all objectives are completed.
+// The objective names are the gamedata.GameObjective strings.
+type CustomModeRules struct {
+	Objectives []string `json:"objectives"`
+
+	// ColoniesGoal is used by the "build_base" objective.
+	ColoniesGoal int `json:"colonies_goal,omitempty"`
+
+	// TimeLimit is measured in seconds; 0 means "no limit".
+	// Reaching the time limit is a defeat unless
+	// there is a "survive" objective.
+	TimeLimit int `json:"time_limit,omitempty"`
+
+	// ResourceCap limits the amount of resources a colony can store; 0 means "no limit".
+	ResourceCap int `json:"resource_cap,omitempty"`
+
+	// StartingDrones are spawned near the main colony in addition
+	// to the default workers; they're specified by the drone names.
+	// The lobby doesn't allow to set them, it's used by the campaign missions.
+	StartingDrones []string `json:"starting_drones,omitempty"`
+
+	// WaveSchedule is a wave schedule name (used by the lobby only).
+	WaveSchedule string           `json:"wave_schedule,omitempty"`
+	Waves        []CustomModeWave `json:"waves,omitempty"`
+}
+
+type CustomModeWave struct {
+	// Time is a number of seconds since the game start.
+	Time int `json:"time"`
+
+	// Budget is spent on the Creeps in the same way as arena wave budget.
+	Budget int      `json:"budget"`
+	Creeps []string `json:"creeps"`
+
+	// Sides is a number of attack directions, from 1 to 4.
+	Sides int `json:"sides"`
+
+	Super bool `json:"super,omitempty"`
 }
 
 type LeaderboardResp struct {
@@ -137,3 +229,72 @@ type SavePlayerScoreResp struct {
 	Queued           bool `json:"queued"`
 	CurrentHighscore int  `json:"current_highscore"`
 }
+
+// PlayerProgress is a part of the player stats that is synchronized
+// between the devices through the leaderboard server.
+type PlayerProgress struct {
+	Achievements []ProgressAchievement `json:"achievements,omitempty"`
+
+	OptionsUnlocked []string `json:"options_unlocked,omitempty"`
+	CoresUnlocked   []string `json:"cores_unlocked,omitempty"`
+	TurretsUnlocked []string `json:"turrets_unlocked,omitempty"`
+	DronesUnlocked  []string `json:"drones_unlocked,omitempty"`
+	Tier3DronesSeen []string `json:"tier3_drones_seen,omitempty"`
+	ModesUnlocked   []string `json:"modes_unlocked,omitempty"`
+
+	TutorialCompleted bool `json:"tutorial_completed"`
+
+	Campaign []ProgressMission `json:"campaign,omitempty"`
+
+	NumVictories int `json:"num_victories"`
+
+	// TotalPlayTime is measured in seconds.
+	TotalPlayTime int64 `json:"total_play_time"`
+	TotalScore    int   `json:"total_score"`
+
+	Highscores []ProgressHighscore `json:"highscores,omitempty"`
+}
+
+type ProgressAchievement struct {
+	Name  string `json:"name"`
+	Elite bool   `json:"elite"`
+}
+
+type ProgressMission struct {
+	Mission  string `json:"mission"`
+	Stars    int    `json:"stars"`
+	BestTime int    `json:"best_time"`
+}
+
+type ProgressHighscore struct {
+	Mode       string `json:"mode"`
+	Score      int    `json:"score"`
+	Difficulty int    `json:"difficulty"`
+}
+
+// SignedProgress is an encoded PlayerProgress with its signature.
+//
+// The signature is a hex-encoded HMAC-SHA256 of the data;
+// the player sync key is used as a secret.
+// Only the devices that know the sync key can produce a valid signature.
+type SignedProgress struct {
+	Data      []byte `json:"data"`
+	Signature string `json:"signature"`
+}
+
+type UploadProgressReq struct {
+	PlayerName string         `json:"player_name"`
+	SyncKey    string         `json:"sync_key"`
+	Progress   SignedProgress `json:"progress"`
+}
+
+type DownloadProgressReq struct {
+	PlayerName string `json:"player_name"`
+	SyncKey    string `json:"sync_key"`
+}
+
+// SyncProgressResp is returned by both upload and download endpoints.
+// For the upload, it contains the progress merged with the stored one.
+type SyncProgressResp struct {
+	Progress SignedProgress `json:"progress"`
+}
@@ -0,0 +1,32 @@
+//go:build linux || darwin || windows
+
+package session
+
+import (
+	"os"
+	"strings"
+)
+
+func writeDataFile(path string, data []byte) error {
+	return os.WriteFile(path, data, 0o644)
+}
+
+func readDataFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// listDataFiles returns the names of the files with the given extension.
+func listDataFiles(dir, ext string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var filenames []string
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ext) {
+			continue
+		}
+		filenames = append(filenames, f.Name())
+	}
+	return filenames, nil
+}
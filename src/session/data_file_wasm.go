@@ -0,0 +1,21 @@
+//go:build wasm
+
+package session
+
+import (
+	"errors"
+)
+
+var errNoDataFiles = errors.New("data files are not supported on this platform")
+
+func writeDataFile(path string, data []byte) error {
+	return errNoDataFiles
+}
+
+func readDataFile(path string) ([]byte, error) {
+	return nil, errNoDataFiles
+}
+
+func listDataFiles(dir, ext string) ([]string, error) {
+	return nil, errNoDataFiles
+}
@@ -0,0 +1,29 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+)
+
+// LegacySimulatorPath returns a path to the archived simulator (runsim)
+// binary that matches the specified game build.
+// An empty string is returned if there is no such simulator.
+//
+// The archived simulators are expected to be inside the "runsim"
+// sub-folder of the game data folder; they're named the same way
+// as on the leaderboard server: runsim_<build> (plus ".exe" on Windows).
+func (state *State) LegacySimulatorPath(version int) string {
+	if state.GameDataFolder == "" {
+		return ""
+	}
+	filename := fmt.Sprintf("runsim_%d", version)
+	if runtime.GOOS == "windows" {
+		filename += ".exe"
+	}
+	path := filepath.Join(state.GameDataFolder, "runsim", filename)
+	if !legacySimulatorExists(path) {
+		return ""
+	}
+	return path
+}
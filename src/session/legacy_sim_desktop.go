@@ -0,0 +1,59 @@
+//go:build linux || darwin || windows
+
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// RunLegacySimulator executes the replay using the archived simulator binary.
+// It's a blocking operation that can take a while (up to a minute).
+func RunLegacySimulator(path string, r serverapi.GameReplay) (serverapi.GameResults, error) {
+	var results serverapi.GameResults
+
+	replayData, err := json.Marshal(r)
+	if err != nil {
+		return results, err
+	}
+
+	timeout := 30
+	if r.Config.RawGameMode == "inf_arena" {
+		timeout = 60
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, fmt.Sprintf("--timeout=%d", timeout))
+	cmd.Stdin = bytes.NewReader(replayData)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return results, err
+	}
+	// The simulator has its own timeout, but it can't hurt to be extra careful.
+	timer := time.AfterFunc(time.Duration(timeout+5)*time.Second, func() {
+		cmd.Process.Kill()
+	})
+	err = cmd.Wait()
+	timer.Stop()
+	if err != nil {
+		return results, fmt.Errorf("runsim: %w: %s", err, stderr.String())
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		return results, fmt.Errorf("decode runsim results: %w", err)
+	}
+	return results, nil
+}
+
+func legacySimulatorExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
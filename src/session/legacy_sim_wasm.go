@@ -0,0 +1,17 @@
+//go:build wasm
+
+package session
+
+import (
+	"errors"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+func RunLegacySimulator(path string, r serverapi.GameReplay) (serverapi.GameResults, error) {
+	return serverapi.GameResults{}, errors.New("legacy simulators are not supported on this platform")
+}
+
+func legacySimulatorExists(path string) bool {
+	return false
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+// ListLevelMaps returns the map editor file names from the game data folder.
+func (state *State) ListLevelMaps() ([]string, error) {
+	filenames, err := listDataFiles(state.GameDataFolder, gamedata.LevelMapFileExt)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+func (state *State) LoadLevelMap(filename string) (*gamedata.LevelMap, error) {
+	data, err := readDataFile(filepath.Join(state.GameDataFolder, filename))
+	if err != nil {
+		return nil, err
+	}
+	return gamedata.ParseLevelMap(data)
+}
+
+// SaveLevelMap writes the map to a file inside the game data folder.
+// The map doesn't have to be playable, so the unfinished maps can be saved too.
+func (state *State) SaveLevelMap(filename string, m *gamedata.LevelMap) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeDataFile(filepath.Join(state.GameDataFolder, filename), data)
+}
+
+// NewLevelMapFilename returns a map file name that is not used yet.
+func (state *State) NewLevelMapFilename() string {
+	existing, _ := state.ListLevelMaps()
+	for i := 1; ; i++ {
+		filename := fmt.Sprintf("map%d%s", i, gamedata.LevelMapFileExt)
+		if !xslices.Contains(existing, filename) {
+			return filename
+		}
+	}
+}
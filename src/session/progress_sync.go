@@ -0,0 +1,113 @@
+package session
+
+import (
+	"time"
+
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+type highscoreRef struct {
+	mode       string
+	score      *int
+	difficulty *int
+}
+
+func (stats *PlayerStats) highscoreRefs() []highscoreRef {
+	return []highscoreRef{
+		{"classic", &stats.HighestClassicScore, &stats.HighestClassicScoreDifficulty},
+		{"blitz", &stats.HighestBlitzScore, &stats.HighestBlitzScoreDifficulty},
+		{"arena", &stats.HighestArenaScore, &stats.HighestArenaScoreDifficulty},
+		{"inf_arena", &stats.HighestInfArenaScore, &stats.HighestInfArenaScoreDifficulty},
+		{"reverse", &stats.HighestReverseScore, &stats.HighestReverseScoreDifficulty},
+	}
+}
+
+// CloudProgress returns the part of stats that is synchronized
+// between the devices.
+func (stats *PlayerStats) CloudProgress() *serverapi.PlayerProgress {
+	p := &serverapi.PlayerProgress{
+		OptionsUnlocked:   append([]string(nil), stats.OptionsUnlocked...),
+		CoresUnlocked:     append([]string(nil), stats.CoresUnlocked...),
+		TurretsUnlocked:   append([]string(nil), stats.TurretsUnlocked...),
+		DronesUnlocked:    append([]string(nil), stats.DronesUnlocked...),
+		Tier3DronesSeen:   append([]string(nil), stats.Tier3DronesSeen...),
+		ModesUnlocked:     append([]string(nil), stats.ModesUnlocked...),
+		TutorialCompleted: stats.TutorialCompleted,
+		NumVictories:      stats.NumVictories,
+		TotalPlayTime:     int64(stats.TotalPlayTime / time.Second),
+		TotalScore:        stats.TotalScore,
+	}
+	for _, a := range stats.Achievements {
+		p.Achievements = append(p.Achievements, serverapi.ProgressAchievement{
+			Name:  a.Name,
+			Elite: a.Elite,
+		})
+	}
+	for _, m := range stats.Campaign {
+		p.Campaign = append(p.Campaign, serverapi.ProgressMission{
+			Mission:  m.Mission,
+			Stars:    m.Stars,
+			BestTime: m.BestTime,
+		})
+	}
+	for _, h := range stats.highscoreRefs() {
+		if *h.score == 0 {
+			continue
+		}
+		p.Highscores = append(p.Highscores, serverapi.ProgressHighscore{
+			Mode:       h.mode,
+			Score:      *h.score,
+			Difficulty: *h.difficulty,
+		})
+	}
+	return p
+}
+
+// ApplyCloudProgress replaces the synchronized part of stats with p.
+//
+// The p is expected to be merged with the local progress already,
+// see gamedata.MergePlayerProgress.
+// The content that is unknown to this game build is kept:
+// it can come from a newer build on another device.
+func (stats *PlayerStats) ApplyCloudProgress(p *serverapi.PlayerProgress) {
+	stats.OptionsUnlocked = append([]string(nil), p.OptionsUnlocked...)
+	stats.CoresUnlocked = append([]string(nil), p.CoresUnlocked...)
+	stats.TurretsUnlocked = append([]string(nil), p.TurretsUnlocked...)
+	stats.DronesUnlocked = append([]string(nil), p.DronesUnlocked...)
+	stats.Tier3DronesSeen = append([]string(nil), p.Tier3DronesSeen...)
+	stats.ModesUnlocked = append([]string(nil), p.ModesUnlocked...)
+	stats.TutorialCompleted = p.TutorialCompleted
+	stats.NumVictories = p.NumVictories
+	stats.TotalScore = p.TotalScore
+
+	// The local play time is more precise, so it's only updated
+	// if the other devices were played for longer.
+	if playTime := time.Duration(p.TotalPlayTime) * time.Second; playTime > stats.TotalPlayTime {
+		stats.TotalPlayTime = playTime
+	}
+
+	stats.Achievements = stats.Achievements[:0]
+	for _, a := range p.Achievements {
+		stats.Achievements = append(stats.Achievements, Achievement{
+			Name:  a.Name,
+			Elite: a.Elite,
+		})
+	}
+	stats.Campaign = stats.Campaign[:0]
+	for _, m := range p.Campaign {
+		stats.Campaign = append(stats.Campaign, CampaignProgress{
+			Mission:  m.Mission,
+			Stars:    m.Stars,
+			BestTime: m.BestTime,
+		})
+	}
+	for _, ref := range stats.highscoreRefs() {
+		for _, h := range p.Highscores {
+			if h.Mode == ref.mode {
+				*ref.score = h.Score
+				*ref.difficulty = h.Difficulty
+				break
+			}
+		}
+	}
+}
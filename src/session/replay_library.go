@@ -0,0 +1,312 @@
+package session
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/quasilyte/roboden-game/gamedata"
+	"github.com/quasilyte/roboden-game/serverapi"
+)
+
+// ReplayFileExt is an extension of the standalone replay files.
+//
+// These files are stored in the game data folder (see the -data flag),
+// so they can be shared between the players.
+const ReplayFileExt = ".roboden-replay"
+
+const replayLibraryKey = "replay_library.json"
+
+// maxReplayFileSize is a limit for the decompressed replay file data.
+// The longest games produce a few megabytes of JSON.
+const maxReplayFileSize = 16 << 20
+
+// ReplayLibrary is an index of the saved replays.
+//
+// The replay data itself is stored separately (see LibraryReplayKey),
+// so the replay browser doesn't have to load all replays at once.
+type ReplayLibrary struct {
+	NextID int
+
+	Entries []ReplayLibraryEntry
+}
+
+type ReplayLibraryEntry struct {
+	ID int
+
+	Date        time.Time
+	Mode        string
+	Score       int
+	ResultTag   string
+	GameVersion int
+
+	// Source is a replay file name that is associated with this entry.
+	// It's set for the imported and exported replays.
+	Source string
+}
+
+type ReplaySortOrder int
+
+const (
+	ReplaySortByDate ReplaySortOrder = iota
+	ReplaySortByMode
+	ReplaySortByScore
+	ReplaySortByResult
+)
+
+func SortReplayLibrary(entries []ReplayLibraryEntry, order ReplaySortOrder) {
+	// The newest replays go first unless the sort keys are equal.
+	sort.SliceStable(entries, func(i, j int) bool {
+		x := &entries[i]
+		y := &entries[j]
+		switch order {
+		case ReplaySortByMode:
+			if x.Mode != y.Mode {
+				return x.Mode < y.Mode
+			}
+		case ReplaySortByScore:
+			if x.Score != y.Score {
+				return x.Score > y.Score
+			}
+		case ReplaySortByResult:
+			if x.ResultTag != y.ResultTag {
+				return x.ResultTag > y.ResultTag
+			}
+		}
+		return x.Date.After(y.Date)
+	})
+}
+
+func (state *State) LibraryReplayKey(id int) string {
+	return fmt.Sprintf("library_replay_%d.json", id)
+}
+
+// LoadReplayLibrary returns the replay library index.
+//
+// The first call also moves the replays from the old
+// saved_replay_%d.json slots (except the last played one) to the library.
+func (state *State) LoadReplayLibrary() *ReplayLibrary {
+	var lib ReplayLibrary
+	if state.CheckGameItem(replayLibraryKey) {
+		if err := state.LoadGameItem(replayLibraryKey, &lib); err != nil {
+			state.Logf("can't load replay library: %v", err)
+		}
+		return &lib
+	}
+
+	lib.NextID = 1
+	for i := 1; i < 10; i++ {
+		k := state.ReplayDataKey(i)
+		if !state.CheckGameItem(k) {
+			continue
+		}
+		var r SavedReplay
+		if err := state.LoadGameItem(k, &r); err != nil {
+			state.Logf("can't migrate %q replay: %v", k, err)
+			continue
+		}
+		state.addLibraryReplay(&lib, r, "")
+	}
+	state.SaveGameItem(replayLibraryKey, lib)
+	for i := 1; i < 10; i++ {
+		k := state.ReplayDataKey(i)
+		if state.CheckGameItem(k) {
+			state.GameData.DeleteItem(k)
+		}
+	}
+	return &lib
+}
+
+func (state *State) AddLibraryReplay(r SavedReplay) ReplayLibraryEntry {
+	lib := state.LoadReplayLibrary()
+	e := state.addLibraryReplay(lib, r, "")
+	state.SaveGameItem(replayLibraryKey, lib)
+	return e
+}
+
+func (state *State) LoadLibraryReplay(id int) (SavedReplay, error) {
+	var r SavedReplay
+	err := state.LoadGameItem(state.LibraryReplayKey(id), &r)
+	return r, err
+}
+
+func (state *State) DeleteLibraryReplay(id int) {
+	lib := state.LoadReplayLibrary()
+	for i, e := range lib.Entries {
+		if e.ID != id {
+			continue
+		}
+		lib.Entries = append(lib.Entries[:i], lib.Entries[i+1:]...)
+		break
+	}
+	state.SaveGameItem(replayLibraryKey, lib)
+	if state.GameData != nil {
+		state.GameData.DeleteItem(state.LibraryReplayKey(id))
+	}
+}
+
+// ExportLibraryReplay writes the replay to a file inside the game data folder.
+// It returns the created file name.
+func (state *State) ExportLibraryReplay(id int) (string, error) {
+	lib := state.LoadReplayLibrary()
+	var entry *ReplayLibraryEntry
+	for i := range lib.Entries {
+		if lib.Entries[i].ID == id {
+			entry = &lib.Entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return "", fmt.Errorf("replay %d is not found", id)
+	}
+	r, err := state.LoadLibraryReplay(id)
+	if err != nil {
+		return "", err
+	}
+	data, err := EncodeReplayFile(r.Replay)
+	if err != nil {
+		return "", err
+	}
+
+	filename := entry.Source
+	if filename == "" {
+		filename = fmt.Sprintf("%s_%s_%d%s", r.Replay.Config.RawGameMode, r.Date.Format("2006-01-02_15-04"), id, ReplayFileExt)
+	}
+	if err := writeDataFile(filepath.Join(state.GameDataFolder, filename), data); err != nil {
+		return "", err
+	}
+
+	// Remember the file name, so this replay won't be imported twice.
+	entry.Source = filename
+	state.SaveGameItem(replayLibraryKey, lib)
+
+	return filename, nil
+}
+
+// ImportReplayFiles adds all replay files from the game data folder to the library.
+// Files that were imported (or exported) before are skipped.
+// It returns the number of imported replays.
+func (state *State) ImportReplayFiles() (int, error) {
+	filenames, err := listDataFiles(state.GameDataFolder, ReplayFileExt)
+	if err != nil {
+		return 0, err
+	}
+
+	lib := state.LoadReplayLibrary()
+	knownSources := make(map[string]struct{}, len(lib.Entries))
+	for _, e := range lib.Entries {
+		if e.Source != "" {
+			knownSources[e.Source] = struct{}{}
+		}
+	}
+
+	numImported := 0
+	for _, filename := range filenames {
+		if _, ok := knownSources[filename]; ok {
+			continue
+		}
+		data, err := readDataFile(filepath.Join(state.GameDataFolder, filename))
+		if err != nil {
+			state.Logf("can't read %q replay file: %v", filename, err)
+			continue
+		}
+		replay, err := DecodeReplayFile(data)
+		if err != nil {
+			state.Logf("can't decode %q replay file: %v", filename, err)
+			continue
+		}
+		if !gamedata.IsWellFormedReplay(replay) {
+			state.Logf("skip %q replay file: malformed replay data", filename)
+			continue
+		}
+		date, err := time.Parse("2006-01-02", replay.Date)
+		if err != nil {
+			date = time.Now()
+		}
+		r := SavedReplay{
+			Date:      date,
+			ResultTag: replayResultTag(replay),
+			Replay:    replay,
+		}
+		state.addLibraryReplay(lib, r, filename)
+		numImported++
+	}
+	if numImported != 0 {
+		state.SaveGameItem(replayLibraryKey, lib)
+	}
+
+	return numImported, nil
+}
+
+func (state *State) addLibraryReplay(lib *ReplayLibrary, r SavedReplay, source string) ReplayLibraryEntry {
+	if lib.NextID == 0 {
+		lib.NextID = 1
+	}
+	e := ReplayLibraryEntry{
+		ID:          lib.NextID,
+		Date:        r.Date,
+		Mode:        r.Replay.Config.RawGameMode,
+		Score:       r.Replay.Results.Score,
+		ResultTag:   r.ResultTag,
+		GameVersion: r.Replay.GameVersion,
+		Source:      source,
+	}
+	lib.NextID++
+	state.SaveGameItem(state.LibraryReplayKey(e.ID), r)
+	lib.Entries = append(lib.Entries, e)
+	return e
+}
+
+// replayResultTag is a simplified version of the results screen tag.
+// The replay files don't store the tag, so we can only
+// recover it from the game results.
+func replayResultTag(r serverapi.GameReplay) string {
+	switch {
+	case r.Results.Victory:
+		return "menu.results.victory"
+	case r.Config.RawGameMode == "inf_arena":
+		return "menu.results.the_end"
+	default:
+		return "menu.results.defeat"
+	}
+}
+
+// EncodeReplayFile returns the replay file contents: a gzip-compressed replay JSON.
+func EncodeReplayFile(r serverapi.GameReplay) ([]byte, error) {
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(jsonData); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func DecodeReplayFile(data []byte) (serverapi.GameReplay, error) {
+	var r serverapi.GameReplay
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return r, err
+	}
+	// Read one extra byte to detect the files that exceed the limit.
+	jsonData, err := io.ReadAll(io.LimitReader(gz, maxReplayFileSize+1))
+	if err != nil {
+		return r, err
+	}
+	if len(jsonData) > maxReplayFileSize {
+		return r, fmt.Errorf("replay data exceeds %d bytes", maxReplayFileSize)
+	}
+	err = json.Unmarshal(jsonData, &r)
+	return r, err
+}
@@ -0,0 +1,190 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SaveDataVersion is a current PersistentData schema version.
+// Every version above zero has a saveMigrations entry.
+const SaveDataVersion = 1
+
+// saveMigration upgrades the decoded save data to the specified version.
+//
+// The migrations work with the raw JSON objects instead of PersistentData:
+// the struct describes only the latest schema.
+type saveMigration struct {
+	version int
+	migrate func(data map[string]any) error
+}
+
+// saveMigrations is a list of all save schema updates.
+// A new migration should be appended to the end with the next version number.
+var saveMigrations = []saveMigration{
+	{version: 1, migrate: migrateSaveFirstLaunch},
+}
+
+// migrateSaveFirstLaunch resets the FirstLaunch flag for the players
+// who have already played the game before this flag was introduced.
+func migrateSaveFirstLaunch(data map[string]any) error {
+	firstLaunch, _ := data["FirstLaunch"].(bool)
+	if !firstLaunch {
+		return nil
+	}
+	stats, ok := data["PlayerStats"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	playTime, ok := stats["TotalPlayTime"].(json.Number)
+	if !ok {
+		return nil
+	}
+	if n, err := playTime.Int64(); err == nil && n > 0 {
+		data["FirstLaunch"] = false
+	}
+	return nil
+}
+
+// ErrNewerSaveData is reported for the save data written by a newer game build.
+// Such data can't be loaded, but it shouldn't be overwritten either.
+var ErrNewerSaveData = errors.New("save data is newer than supported")
+
+// SaveDataBackupKey returns a game item key for the save data backup.
+// A backup is created before the save of this version is migrated.
+func SaveDataBackupKey(version int) string {
+	return fmt.Sprintf("save_v%d_backup.json", version)
+}
+
+// SaveDataCorruptKey returns a game item key for the save data that can't be loaded.
+// The timestamp makes it unique, so the older copies are never overwritten.
+func SaveDataCorruptKey(t time.Time) string {
+	return fmt.Sprintf("save_corrupt_%d.json", t.Unix())
+}
+
+// MigrateSaveData upgrades the encoded PersistentData to the SaveDataVersion.
+// It also returns the version the data had before the migration.
+// If the data is already up to date, it's returned as is.
+func MigrateSaveData(data []byte) ([]byte, int, error) {
+	var object map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// The numbers are decoded as json.Number to keep the int64 precision.
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return nil, 0, err
+	}
+	if object == nil {
+		return nil, 0, errors.New("save data is not an object")
+	}
+
+	version := 0
+	if v, ok := object["Version"].(json.Number); ok {
+		n, err := v.Int64()
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid version: %w", err)
+		}
+		version = int(n)
+	}
+	if version < 0 {
+		return nil, version, fmt.Errorf("invalid version %d", version)
+	}
+	if version > SaveDataVersion {
+		return nil, version, fmt.Errorf("%w: version %d, supported %d", ErrNewerSaveData, version, SaveDataVersion)
+	}
+	if version == SaveDataVersion {
+		return data, version, nil
+	}
+
+	for _, m := range saveMigrations {
+		if m.version <= version {
+			continue
+		}
+		if err := m.migrate(object); err != nil {
+			return nil, version, fmt.Errorf("migrate to v%d: %w", m.version, err)
+		}
+		object["Version"] = m.version
+	}
+
+	migrated, err := json.Marshal(object)
+	if err != nil {
+		return nil, version, err
+	}
+	return migrated, version, nil
+}
+
+// LoadPersistentData loads the save data into state.Persistent.
+//
+// An outdated save is migrated to the current version;
+// the original data is kept under the SaveDataBackupKey.
+//
+// The save data is never repaired here: the unknown entries
+// could be written by a newer game build.
+// The problems are only reported, see cmd/saverepair.
+//
+// The save data that can't be loaded is copied under the SaveDataCorruptKey.
+// The ErrNewerSaveData is returned as is: the caller should not overwrite
+// the save (see State.PersistentReadOnly).
+func (state *State) LoadPersistentData() error {
+	if state.GameData == nil {
+		return nil
+	}
+	data, err := state.GameData.LoadItem("save.json")
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		state.SaveGameItem("save.json", state.Persistent)
+		return nil
+	}
+
+	migrated, version, err := MigrateSaveData(data)
+	if err != nil {
+		if errors.Is(err, ErrNewerSaveData) {
+			return err
+		}
+		// The caller is likely to overwrite the unloadable save,
+		// so it's kept as a separate copy.
+		corruptKey := SaveDataCorruptKey(time.Now())
+		if !state.GameData.ItemExists(corruptKey) {
+			if copyErr := state.GameData.SaveItem(corruptKey, data); copyErr != nil {
+				state.Logf("copy unloadable save data: %v", copyErr)
+			} else {
+				state.Logf("unloadable save data is copied to %q", corruptKey)
+			}
+		}
+		return err
+	}
+	needsSaving := false
+	if version != SaveDataVersion {
+		// A failed backup is not a reason to reject the save:
+		// the caller would replace it with the default data.
+		// The existing backup is the oldest copy, it's never overwritten.
+		backupKey := SaveDataBackupKey(version)
+		if !state.GameData.ItemExists(backupKey) {
+			if err := state.GameData.SaveItem(backupKey, data); err != nil {
+				state.Logf("backup save data: %v", err)
+			}
+		}
+		state.Logf("migrated save data from v%d to v%d, the backup is %q", version, SaveDataVersion, backupKey)
+		needsSaving = true
+	}
+
+	if err := json.Unmarshal(migrated, &state.Persistent); err != nil {
+		return err
+	}
+
+	// A dry run on a separate copy: it only reports the problems.
+	var scratch PersistentData
+	if err := json.Unmarshal(migrated, &scratch); err == nil {
+		for _, problem := range RepairPersistentData(&scratch) {
+			state.Logf("save data: %s (use saverepair to fix it)", problem)
+		}
+	}
+
+	if needsSaving {
+		state.SaveGameItem("save.json", state.Persistent)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/ge/xslices"
+	"github.com/quasilyte/gmath"
+	"github.com/quasilyte/roboden-game/gamedata"
+)
+
+// RepairPersistentData validates the save data against the gamedata.
+// The invalid entries are removed and the problems are returned
+// as human-readable messages.
+//
+// A save data can have invalid entries after a content removal
+// or a manual edit of the save file.
+func RepairPersistentData(data *PersistentData) []string {
+	var problems []string
+	stats := &data.PlayerStats
+
+	stats.Achievements = xslices.RemoveIf(stats.Achievements, makeAchievementCheck(stats, &problems))
+
+	stats.DronesUnlocked = repairNames(&problems, "unlocked drone", stats.DronesUnlocked, func(name string) bool {
+		return xslices.Any(gamedata.Tier2agentMergeRecipes, func(r gamedata.AgentMergeRecipe) bool {
+			return r.Result.Kind.String() == name
+		})
+	})
+	stats.Tier3DronesSeen = repairNames(&problems, "seen tier 3 drone", stats.Tier3DronesSeen, func(name string) bool {
+		return xslices.Any(gamedata.Tier3agentMergeRecipes, func(r gamedata.AgentMergeRecipe) bool {
+			return r.Result.Kind.String() == name
+		})
+	})
+	stats.TurretsUnlocked = repairNames(&problems, "unlocked turret", stats.TurretsUnlocked, func(name string) bool {
+		return xslices.Any(gamedata.TurretStatsList, func(turret *gamedata.AgentStats) bool {
+			return turret.Kind.String() == name
+		})
+	})
+	stats.CoresUnlocked = repairNames(&problems, "unlocked core", stats.CoresUnlocked, func(name string) bool {
+		return xslices.Any(gamedata.CoreStatsList, func(core *gamedata.ColonyCoreStats) bool {
+			return core.Name == name
+		})
+	})
+	stats.ModesUnlocked = repairNames(&problems, "unlocked mode", stats.ModesUnlocked, func(name string) bool {
+		_, ok := gamedata.GameModeInfoMap[name]
+		return ok
+	})
+	stats.OptionsUnlocked = repairNames(&problems, "unlocked option", stats.OptionsUnlocked, func(name string) bool {
+		_, ok := gamedata.LobbyOptionMap[name]
+		return ok
+	})
+
+	for i := range stats.Campaign {
+		p := &stats.Campaign[i]
+		if p.Stars < 0 || p.Stars > gamedata.MaxCampaignStars {
+			problems = append(problems, fmt.Sprintf("%q mission has %d stars", p.Mission, p.Stars))
+			p.Stars = gmath.Clamp(p.Stars, 0, gamedata.MaxCampaignStars)
+		}
+	}
+
+	return problems
+}
+
+func makeAchievementCheck(stats *PlayerStats, problems *[]string) func(a Achievement) bool {
+	seen := make(map[string]struct{}, len(stats.Achievements))
+	return func(a Achievement) bool {
+		if _, ok := seen[a.Name]; ok {
+			*problems = append(*problems, fmt.Sprintf("duplicated %q achievement", a.Name))
+			return true
+		}
+		seen[a.Name] = struct{}{}
+
+		i := xslices.IndexWhere(gamedata.AchievementList, func(info *gamedata.Achievement) bool {
+			return info.Name == a.Name
+		})
+		if i == -1 {
+			*problems = append(*problems, fmt.Sprintf("unknown %q achievement", a.Name))
+			return true
+		}
+		info := gamedata.AchievementList[i]
+		if info.OnlyElite && !a.Elite {
+			*problems = append(*problems, fmt.Sprintf("%q achievement can't be non-elite", a.Name))
+			return true
+		}
+		return false
+	}
+}
+
+// repairNames removes the unknown and duplicated names from the list.
+func repairNames(problems *[]string, kind string, names []string, isKnown func(name string) bool) []string {
+	seen := make(map[string]struct{}, len(names))
+	return xslices.RemoveIf(names, func(name string) bool {
+		if _, ok := seen[name]; ok {
+			*problems = append(*problems, fmt.Sprintf("duplicated %s %q", kind, name))
+			return true
+		}
+		seen[name] = struct{}{}
+		if !isKnown(name) {
+			*problems = append(*problems, fmt.Sprintf("unknown %s %q", kind, name))
+			return true
+		}
+		return false
+	})
+}
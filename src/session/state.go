@@ -8,13 +8,12 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
-	resource "github.com/quasilyte/ebitengine-resource"
 	"github.com/quasilyte/gdata"
 	"github.com/quasilyte/ge"
-	"github.com/quasilyte/ge/langs"
 	"github.com/quasilyte/ge/xslices"
 
 	"github.com/quasilyte/roboden-game/assets"
+	"github.com/quasilyte/roboden-game/controls"
 	"github.com/quasilyte/roboden-game/gamedata"
 	"github.com/quasilyte/roboden-game/gameinput"
 	"github.com/quasilyte/roboden-game/gameui/eui"
@@ -53,6 +52,7 @@ type State struct {
 	InfArenaLevelConfig *gamedata.LevelConfig
 	ReverseLevelConfig  *gamedata.LevelConfig
 	TutorialLevelConfig *gamedata.LevelConfig
+	CustomLevelConfig   *gamedata.LevelConfig
 
 	Persistent PersistentData
 
@@ -66,6 +66,21 @@ type State struct {
 
 	GameData *gdata.Manager
 
+	// GameDataFolder is a folder specified by the -data flag.
+	// It's used for the user-provided files, like the replay files.
+	GameDataFolder string
+
+	// LangPacks are all language packs available, see assets.DiscoverLanguagePacks.
+	LangPacks []*assets.LangPack
+
+	// PersistentReadOnly disables the save.json updates.
+	// It's set when the save data was written by a newer game build,
+	// so the downgraded game doesn't wipe the player progress.
+	PersistentReadOnly bool
+
+	// Campaign is an ordered list of the campaign missions.
+	Campaign []*gamedata.CampaignMission
+
 	SentHighscores bool
 
 	GameCommitHash string
@@ -99,6 +114,9 @@ func (state *State) SaveGameItem(key string, data any) {
 	if state.GameData == nil {
 		return
 	}
+	if key == "save.json" && state.PersistentReadOnly {
+		return
+	}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		panic(fmt.Sprintf("can't save game data with key %q: %v", key, err))
@@ -135,12 +153,21 @@ func (state *State) GetGamepadInput(id int) *gameinput.Handler {
 }
 
 type PersistentData struct {
+	// Version is a save data schema version, see SaveDataVersion.
+	// The saves created before the versioning have a zero version.
+	Version int
+
 	Settings GameSettings
 
 	FirstLaunch bool
 
 	PlayerName string
 
+	// CloudSyncKey is a secret that is shared between the devices
+	// of the same player to synchronize the progress.
+	// An empty key means that the cloud sync was never used.
+	CloudSyncKey string
+
 	NumPendingSubmissions int
 
 	PlayerStats PlayerStats
@@ -164,6 +191,8 @@ type PlayerStats struct {
 
 	TutorialCompleted bool
 
+	Campaign []CampaignProgress
+
 	NumVictories int
 
 	TotalPlayTime time.Duration
@@ -185,6 +214,27 @@ type PlayerStats struct {
 	HighestReverseScoreDifficulty int
 }
 
+// CampaignProgress is a record of a completed campaign mission.
+type CampaignProgress struct {
+	Mission string
+
+	Stars int
+
+	// BestTime is measured in seconds.
+	BestTime int
+}
+
+// FindCampaignProgress returns the mission progress record.
+// A nil result means that the mission was not completed yet.
+func (stats *PlayerStats) FindCampaignProgress(mission string) *CampaignProgress {
+	for i := range stats.Campaign {
+		if stats.Campaign[i].Mission == mission {
+			return &stats.Campaign[i]
+		}
+	}
+	return nil
+}
+
 type Achievement struct {
 	Name  string
 	Elite bool
@@ -217,12 +267,22 @@ type GameSettings struct {
 	Demo               bool
 	ScreenButtons      bool
 	NoPauseSpeedToggle bool
+	ObserverMode       bool
 	IntroDifficulty    int
 	IntroSpeed         int
 	GamepadSettings    [2]GamepadSettings
 	Graphics           GraphicsSettings
 	Player1InputMethod int
 	Player2InputMethod int
+
+	// Custom key bindings: an action name => key names mapping.
+	// See controls.RebindableActions.
+	KeyboardBindings map[string][]string
+	GamepadBindings  map[string][]string
+
+	// The last used network game settings.
+	NetplayRelay      string
+	NetplayInputDelay int
 }
 
 type GraphicsSettings struct {
@@ -233,6 +293,11 @@ type GraphicsSettings struct {
 	ScreenFilter         int
 	FullscreenEnabled    bool
 	AspectRatio          int
+
+	// Accessibility settings.
+	FactionPalette  int
+	FactionPatterns bool
+	UIScale         int
 }
 
 const (
@@ -266,6 +331,8 @@ func (state *State) GetConfigForMode(m gamedata.Mode) *gamedata.LevelConfig {
 		return state.ReverseLevelConfig
 	case gamedata.ModeTutorial:
 		return state.TutorialLevelConfig
+	case gamedata.ModeCustom:
+		return state.CustomLevelConfig
 	default:
 		panic("unexpected game mode")
 	}
@@ -283,6 +350,16 @@ func (state *State) ReloadInputs() {
 	state.BoundInputs[1] = state.resolveInputMethod(gameinput.PlayerInputMethod(state.Persistent.Settings.Player2InputMethod))
 }
 
+// ReloadKeymaps applies the custom key bindings to the input handlers.
+func (state *State) ReloadKeymaps() {
+	settings := &state.Persistent.Settings
+	keymaps := controls.MakeKeymapSet(settings.KeyboardBindings, settings.GamepadBindings)
+	state.CombinedInput.Remap(keymaps.CombinedKeymap)
+	state.KeyboardInput.Remap(keymaps.KeyboardKeymap)
+	state.FirstGamepadInput.Remap(keymaps.FirstGamepadKeymap)
+	state.SecondGamepadInput.Remap(keymaps.SecondGamepadKeymap)
+}
+
 func (state *State) UnlockAchievement(a Achievement) bool {
 	stats := &state.Persistent.PlayerStats
 
@@ -330,29 +407,17 @@ func (state *State) resolveInputMethod(method gameinput.PlayerInputMethod) *game
 }
 
 func (state *State) ReloadLanguage(ctx *ge.Context) {
-	var id resource.RawID
 	lang := state.Persistent.Settings.Lang
-	switch lang {
-	case "en":
-		id = assets.RawDictEn
-	case "ru":
-		id = assets.RawDictRu
-	default:
-		panic(fmt.Sprintf("unsupported lang: %q", lang))
+	if assets.FindLanguagePack(state.LangPacks, lang) == nil {
+		// The user-provided pack could be removed since the last launch.
+		state.Logf("language pack %q is not found, using en instead", lang)
+		lang = "en"
+		state.Persistent.Settings.Lang = lang
 	}
-	dict, err := langs.ParseDictionary(lang, 4, ctx.Loader.LoadRaw(id).Data)
+	dict, err := assets.LoadLanguagePack(ctx, state.LangPacks, lang)
 	if err != nil {
 		panic(err)
 	}
-	if err := dict.Load("", ctx.Loader.LoadRaw(id+1).Data); err != nil {
-		panic(err)
-	}
-	if err := dict.Load("", ctx.Loader.LoadRaw(id+2).Data); err != nil {
-		panic(err)
-	}
-	if err := dict.Load("", ctx.Loader.LoadRaw(id+3).Data); err != nil {
-		panic(err)
-	}
 	ctx.Dict = dict
 }
 
@@ -373,38 +438,14 @@ func (state *State) CacheGlyphs() {
 	}
 
 	alphabet := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	if state.Persistent.Settings.Lang == "ru" {
-		alphabet = "абвгдеёжзийклмнопрстуфхцчшщъыьэюяАБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯabxyABXYRL"
+	if p := assets.FindLanguagePack(state.LangPacks, state.Persistent.Settings.Lang); p != nil && p.Manifest.Glyphs != "" {
+		alphabet = p.Manifest.Glyphs
 	}
 	text.CacheGlyphs(assets.BitmapFont1, alphabet)
 	text.CacheGlyphs(assets.BitmapFont2, alphabet)
 	text.CacheGlyphs(assets.BitmapFont3, alphabet)
 }
 
-func (state *State) FindNextReplayIndex() int {
-	var minDate time.Time
-	minIndex := 0
-	for i := 1; i < 10; i++ {
-		k := state.ReplayDataKey(i)
-		if !state.CheckGameItem(k) {
-			return i
-		}
-		var r SavedReplay
-		err := state.LoadGameItem(k, &r)
-		if err != nil {
-			return i
-		}
-		if minIndex == 0 || r.Date.Before(minDate) {
-			minDate = r.Date
-			minIndex = i
-		}
-	}
-	if minIndex != 0 {
-		return minIndex
-	}
-	return 1
-}
-
 func (state *State) ReplayDataKey(i int) string {
 	return fmt.Sprintf("saved_replay_%d.json", i)
 }